@@ -0,0 +1,351 @@
+package osl
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Журнал аудита изменений данных: после добавления, изменения или удаления записей в той же
+// транзакции в таблицу osl_audit записываются время, логин, операция, таблица, id записи и JSON
+// прежних и новых значений (прежние читаются в транзакции до изменения). Ошибка записи аудита
+// откатывает всю транзакцию, поэтому изменение без записи в журнале невозможно.
+// Таблица создается при подключении, если ее нет; OSL_AUDIT=false отключает журнал.
+
+// Таблица журнала аудита
+const auditTableName = "osl_audit"
+
+// Операции журнала аудита
+const (
+	auditInsert = "insert"
+	auditUpdate = "update"
+	auditDelete = "delete"
+	auditSQL    = "sql" // изменение запросом в режиме SQL-запросов
+)
+
+// Максимальное количество записей в отчёте по журналу аудита
+const auditReportLimit = 1000
+
+// Измененная запись для журнала аудита
+type auditRecord struct {
+	ID  string                 // пусто — id записи неизвестен (например, после COPY)
+	Old map[string]interface{} // прежние значения; nil — запись добавлена
+	New map[string]interface{} // новые значения; nil — запись удалена
+}
+
+// Таблица журнала аудита текущей базы создана и доступна для записи
+var auditReady bool
+
+// Функция для проверки, ведется ли журнал аудита (OSL_AUDIT, по умолчанию включен)
+func auditEnabled() bool {
+	return envBool("OSL_AUDIT", true)
+}
+
+// Функция для построения запроса создания таблицы журнала аудита для текущей СУБД
+func auditTableDDL() string {
+	idColumn, timeType, jsonType := "BIGSERIAL PRIMARY KEY", "TIMESTAMPTZ", "JSONB"
+	switch dialect.(type) {
+	case mysqlDialect:
+		idColumn, timeType, jsonType = "BIGINT AUTO_INCREMENT PRIMARY KEY", "DATETIME(6)", "JSON"
+	case sqliteDialect:
+		idColumn, timeType, jsonType = "INTEGER PRIMARY KEY AUTOINCREMENT", "TIMESTAMP", "TEXT"
+	}
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id %s,
+	changed_at %s NOT NULL,
+	login VARCHAR(255) NOT NULL,
+	operation VARCHAR(16) NOT NULL,
+	table_name VARCHAR(255) NOT NULL,
+	record_id VARCHAR(255),
+	old_values %s,
+	new_values %s
+)`, auditTableName, idColumn, timeType, jsonType, jsonType)
+}
+
+// Функция для создания таблицы журнала аудита, если ее нет (вызывается при подключении к базе)
+func prepareAuditTable() {
+	auditReady = false
+	if !auditEnabled() {
+		logToFileAndScreen("Журнал аудита отключен (OSL_AUDIT=false)")
+		return
+	}
+	// В режиме только для чтения изменений нет, и таблица журнала не создается
+	if readOnly {
+		return
+	}
+	if _, err := dbExec(auditTableDDL()); err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка создания журнала аудита %s: %v, изменения данных будут отклоняться", auditTableName, err))
+		return
+	}
+	auditReady = true
+}
+
+// Функция для записи измененных записей в журнал аудита внутри транзакции изменения.
+// Ошибка должна откатывать транзакцию.
+func writeAudit(tx *sql.Tx, operation, table string, records []auditRecord) error {
+	if !auditEnabled() || len(records) == 0 {
+		return nil
+	}
+	if !auditReady {
+		return fmt.Errorf("журнал аудита %s недоступен", auditTableName)
+	}
+
+	const columnCount = 7
+	changedAt := time.Now()
+	for start := 0; start < len(records); start += maxQueryParams / columnCount {
+		end := start + maxQueryParams/columnCount
+		if end > len(records) {
+			end = len(records)
+		}
+		rows := make([]string, 0, end-start)
+		args := make([]interface{}, 0, (end-start)*columnCount)
+		for _, record := range records[start:end] {
+			oldValues, err := auditJSON(record.Old)
+			if err != nil {
+				return err
+			}
+			newValues, err := auditJSON(record.New)
+			if err != nil {
+				return err
+			}
+			var recordID interface{}
+			if record.ID != "" {
+				recordID = record.ID
+			}
+			placeholders := make([]string, columnCount)
+			for i := range placeholders {
+				placeholders[i] = fmt.Sprintf("$%d", len(args)+i+1)
+			}
+			rows = append(rows, "("+strings.Join(placeholders, ", ")+")")
+			args = append(args, changedAt, activeConfig.User, operation, table, recordID, oldValues, newValues)
+		}
+		query := fmt.Sprintf("INSERT INTO %s (changed_at, login, operation, table_name, record_id, old_values, new_values) VALUES %s",
+			auditTableName, strings.Join(rows, ", "))
+		if _, err := txExec(tx, query, args...); err != nil {
+			return fmt.Errorf("запись журнала аудита: %w", err)
+		}
+	}
+	logToFileAndScreen(fmt.Sprintf("Журнал аудита: %s в таблице %s, записей %d", operation, table, len(records)))
+	return nil
+}
+
+// Функция для преобразования значений записи в JSON для журнала (nil — NULL)
+func auditJSON(values map[string]interface{}) (interface{}, error) {
+	if values == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Функция для получения значений колонок записи для журнала (чувствительные значения скрываются)
+func auditValues(columns []string, values []interface{}) map[string]interface{} {
+	masked := maskParams(columns, values)
+	result := make(map[string]interface{}, len(columns))
+	for i, column := range columns {
+		if i < len(masked) {
+			result[column] = masked[i]
+		}
+	}
+	return result
+}
+
+// Функция для получения записей аудита добавления; ids сопоставляются с записями, только если известны для всех
+func insertedAudit(columns []string, records [][]string, ids []string) []auditRecord {
+	result := make([]auditRecord, len(records))
+	for i, record := range records {
+		result[i].New = auditValues(columns, stringArgs(record))
+		if len(ids) == len(records) {
+			result[i].ID = ids[i]
+		}
+	}
+	return result
+}
+
+// Функция для чтения полных записей, удовлетворяющих условию, внутри транзакции (до их удаления)
+func auditSnapshot(tx *sql.Tx, table, where string, args []interface{}) ([]auditRecord, error) {
+	if !auditEnabled() {
+		return nil, nil
+	}
+	keyColumn := tableKeyColumn(table)
+
+	boundQuery, boundArgs := rebind(fmt.Sprintf("SELECT * FROM %s WHERE %s", tableRef(table), where), args)
+	rows, err := tx.Query(boundQuery, boundArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []auditRecord
+	for rows.Next() {
+		raw := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range raw {
+			pointers[i] = &raw[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		values := make([]interface{}, len(columns))
+		record := auditRecord{}
+		for i, value := range raw {
+			if value != nil {
+				values[i] = formatRawValue(value, "")
+			}
+			if columns[i] == keyColumn {
+				record.ID = formatRawValue(value, "")
+			}
+		}
+		record.Old = auditValues(columns, values)
+		result = append(result, record)
+	}
+	return result, rows.Err()
+}
+
+// Функция для получения записей аудита изменения колонки: прежние значения из before
+// и новые, прочитанные в той же транзакции после изменения
+func columnChangesAudit(tx *sql.Tx, before undoStep) ([]auditRecord, error) {
+	if !auditEnabled() || len(before.Previous) == 0 {
+		return nil, nil
+	}
+	ids := sortedUndoIDs(before.Previous)
+	where, args := inListCondition(tableKeyColumn(before.Table), ids, 1)
+	after, err := captureUndoValues(tx, before.Table, before.Column, where, args)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]auditRecord, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, auditRecord{
+			ID:  id,
+			Old: auditValues([]string{before.Column}, []interface{}{nullStringValue(before.Previous[id])}),
+			New: auditValues([]string{before.Column}, []interface{}{nullStringValue(after.Previous[id])}),
+		})
+	}
+	return result, nil
+}
+
+// Функция для получения значения NULL-допускающей строки (nil — NULL)
+func nullStringValue(value sql.NullString) interface{} {
+	if !value.Valid {
+		return nil
+	}
+	return value.String
+}
+
+// Функция для выполнения шага отмены с записью в журнал аудита: удаление добавленных записей
+// или восстановление прежних значений колонки
+func auditedUndoStep(tx *sql.Tx, step undoStep, run func() error) error {
+	if step.Column == "" {
+		where, args := inListCondition(tableKeyColumn(step.Table), step.IDs, 1)
+		deleted, err := auditSnapshot(tx, step.Table, where, args)
+		if err != nil {
+			return err
+		}
+		if err := run(); err != nil {
+			return err
+		}
+		return writeAudit(tx, auditDelete, step.Table, deleted)
+	}
+
+	where, args := inListCondition(tableKeyColumn(step.Table), sortedUndoIDs(step.Previous), 1)
+	before, err := captureUndoValues(tx, step.Table, step.Column, where, args)
+	if err != nil {
+		return err
+	}
+	if err := run(); err != nil {
+		return err
+	}
+	changes, err := columnChangesAudit(tx, before)
+	if err != nil {
+		return err
+	}
+	return writeAudit(tx, auditUpdate, step.Table, changes)
+}
+
+// Отчёт по журналу аудита с отбором по таблице и интервалу дат
+func auditReport(reader *bufio.Reader) {
+	var exists int
+	if err := dbScanRow(dialect.TableExistsQuery(), []interface{}{auditTableName}, &exists); err != nil || exists == 0 {
+		fmt.Fprintln(stdout, msg("audit.unavailable", auditTableName))
+		return
+	}
+
+	fmt.Fprintln(stdout, msg("audit.table_title"))
+	names := make([]string, len(tables))
+	for i, table := range tables {
+		names[i] = table.Name
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		fmt.Fprintf(stdout, "%d. %s\n", i+1, name)
+	}
+	fmt.Fprintln(stdout, msg("audit.all_tables"))
+	choice, ok := promptInt(reader, msg("common.choose_table"), 0, len(names))
+	if !ok {
+		return
+	}
+
+	from, ok := promptAuditDate(reader, msg("audit.from_prompt"))
+	if !ok {
+		return
+	}
+	to, ok := promptAuditDate(reader, msg("audit.to_prompt"))
+	if !ok {
+		return
+	}
+
+	var conditions []string
+	var args []interface{}
+	if choice > 0 {
+		args = append(args, names[choice-1])
+		conditions = append(conditions, fmt.Sprintf("table_name = $%d", len(args)))
+	}
+	if !from.IsZero() {
+		args = append(args, from)
+		conditions = append(conditions, fmt.Sprintf("changed_at >= $%d", len(args)))
+	}
+	if !to.IsZero() {
+		// Дата окончания входит в интервал целиком
+		args = append(args, to.AddDate(0, 0, 1))
+		conditions = append(conditions, fmt.Sprintf("changed_at < $%d", len(args)))
+	}
+
+	query := "SELECT changed_at, login, operation, table_name, record_id, old_values, new_values FROM " + auditTableName
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY changed_at DESC, id DESC LIMIT %d", auditReportLimit)
+	runReport(msg("reports.audit"), query, args...)
+}
+
+// Функция для ввода даты отбора журнала аудита (пустой ввод — без ограничения)
+func promptAuditDate(reader *bufio.Reader, prompt string) (time.Time, bool) {
+	input, ok := promptValidated(reader, prompt, func(input string) error {
+		if input == "" {
+			return nil
+		}
+		if _, err := time.ParseInLocation(dateInputLayout, input, displayLocation()); err != nil {
+			return errors.New(msg("audit.bad_date"))
+		}
+		return nil
+	})
+	if !ok || input == "" {
+		return time.Time{}, ok
+	}
+	date, _ := time.ParseInLocation(dateInputLayout, input, displayLocation())
+	return date, true
+}
@@ -0,0 +1,182 @@
+package osl
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Несколько записей добавляются одним многострочным INSERT (частями, если параметров
+// больше, чем допускает протокол), а большие пакеты в PostgreSQL — через COPY.
+
+// Максимальное число параметров в одном запросе (ограничение протокола PostgreSQL)
+const maxQueryParams = 65535
+
+// Функция для получения размера пакета, начиная с которого в PostgreSQL используется COPY
+func copyThreshold() int {
+	return envInt("OSL_COPY_THRESHOLD", 1000)
+}
+
+// Функция для построения INSERT на rowCount записей: VALUES ($1, $2), ($3, $4), ...
+func multiRowInsertQuery(table string, columns []string, rowCount int) string {
+	rows := make([]string, rowCount)
+	placeholders := make([]string, len(columns))
+	for i := range rows {
+		for j := range columns {
+			placeholders[j] = fmt.Sprintf("$%d", i*len(columns)+j+1)
+		}
+		rows[i] = "(" + strings.Join(placeholders, ", ") + ")"
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", tableRef(table), columnList(columns), strings.Join(rows, ", "))
+}
+
+// Функция для разбиения записей на части, каждая из которых укладывается в лимит параметров
+func insertChunks(records [][]string, columnCount int) [][][]string {
+	size := len(records)
+	if columnCount > 0 && size*columnCount > maxQueryParams {
+		size = maxQueryParams / columnCount
+	}
+	var chunks [][][]string
+	for start := 0; start < len(records); start += size {
+		end := start + size
+		if end > len(records) {
+			end = len(records)
+		}
+		chunks = append(chunks, records[start:end])
+	}
+	return chunks
+}
+
+// Функция для получения параметров многострочного INSERT и колонок параметров (для маскирования в журнале)
+func batchArgs(columns []string, records [][]string) ([]interface{}, []string) {
+	args := make([]interface{}, 0, len(records)*len(columns))
+	argColumns := make([]string, 0, len(records)*len(columns))
+	for _, record := range records {
+		args = append(args, stringArgs(record)...)
+		argColumns = append(argColumns, columns...)
+	}
+	return args, argColumns
+}
+
+// Функция для вывода запросов вставки в режиме проверки
+func printDryRunInsert(table string, columns []string, records [][]string) {
+	for _, chunk := range insertChunks(records, len(columns)) {
+		args, argColumns := batchArgs(columns, chunk)
+		printDryRun(multiRowInsertQuery(table, columns, len(chunk)), argColumns, args)
+	}
+}
+
+// Функция для добавления записей внутри транзакции. Возвращает ключи добавленных записей,
+// если их можно получить (введены вместе с записями, RETURNING или одна запись в запросе);
+// при вставке через COPY и в таблицу без первичного ключа — nil.
+func insertRecords(tx *sql.Tx, table string, columns []string, records [][]string) ([]string, error) {
+	if _, ok := dialect.(postgresDialect); ok && len(records) > 1 && len(records) >= copyThreshold() {
+		logToFileAndScreen(fmt.Sprintf("Выполнение вставки через COPY: %d записей в %s (%s)",
+			len(records), table, strings.Join(columns, ", ")))
+		if err := copyRecords(tx, table, columns, records); err != nil {
+			return nil, err
+		}
+		return nil, writeAudit(tx, auditInsert, table, insertedAudit(columns, records, nil))
+	}
+
+	// Записи таблицы без ключа нельзя найти для отмены, поэтому их ключи не запрашиваются.
+	// Ключ, введенный вместе с записью, берется из нее самой.
+	key := tableKeyColumn(table)
+	keyIndex := -1
+	for i, column := range columns {
+		if column == key {
+			keyIndex = i
+		}
+	}
+
+	var ids []string
+	for _, chunk := range insertChunks(records, len(columns)) {
+		query := multiRowInsertQuery(table, columns, len(chunk))
+		args, argColumns := batchArgs(columns, chunk)
+		logToFileAndScreen(fmt.Sprintf("Выполнение вставки: %s с параметрами %v", query, maskParams(argColumns, args)))
+		if key == "" || keyIndex >= 0 {
+			if _, err := txExec(tx, query, args...); err != nil {
+				return nil, err
+			}
+			if keyIndex >= 0 {
+				for _, record := range chunk {
+					ids = append(ids, record[keyIndex])
+				}
+			}
+			continue
+		}
+		chunkIDs, err := insertChunk(tx, table, key, query, args, len(chunk))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, chunkIDs...)
+	}
+	if err := writeAudit(tx, auditInsert, table, insertedAudit(columns, records, ids)); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Функция для выполнения одного многострочного INSERT с получением ключей добавленных записей
+func insertChunk(tx *sql.Tx, table, key, query string, args []interface{}, rowCount int) ([]string, error) {
+	if dialect.SupportsReturning() {
+		boundQuery, boundArgs := rebind(query+" RETURNING "+dialect.QuoteIdent(key), args)
+		rows, err := tx.Query(boundQuery, boundArgs...)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		var ids []string
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				return nil, err
+			}
+			ids = append(ids, id)
+		}
+		recordRowsAffected(int64(len(ids)))
+		return ids, rows.Err()
+	}
+
+	result, err := txExec(tx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	// Для нескольких записей LastInsertId однозначно определяет только первую из них,
+	// а ключ, который заполняет не счетчик (например, UUID по умолчанию), им не возвращается
+	info, _ := findTable(table)
+	if rowCount != 1 || (len(info.Details) > 0 && !autoGeneratedColumn(info, key)) {
+		return nil, nil
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, nil
+	}
+	return []string{strconv.FormatInt(id, 10)}, nil
+}
+
+// Функция для добавления записей через COPY (только PostgreSQL)
+func copyRecords(tx *sql.Tx, table string, columns []string, records [][]string) error {
+	schema, name := splitTableName(table)
+	if info, ok := findTable(table); ok {
+		schema, name = info.schemaAndName()
+	}
+	stmt, err := tx.Prepare(pq.CopyInSchema(schema, name, columns...))
+	if err != nil {
+		return err
+	}
+	for i, record := range records {
+		if _, err := stmt.Exec(stringArgs(record)...); err != nil {
+			stmt.Close()
+			return fmt.Errorf("запись %d: %w", i+1, err)
+		}
+	}
+	if _, err := stmt.Exec(); err != nil {
+		stmt.Close()
+		return err
+	}
+	return stmt.Close()
+}
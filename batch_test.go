@@ -0,0 +1,54 @@
+package osl
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+)
+
+// Сравнение добавления 1000 записей по одной (как раньше в insertData) и одним пакетом
+func BenchmarkInsert1000(b *testing.B) {
+	openSchema(b, []string{"components"},
+		"CREATE TABLE components (id INTEGER PRIMARY KEY, name TEXT NOT NULL, model TEXT, price NUMERIC)")
+	b.Setenv("OSL_AUDIT", "false")
+	columns := []string{"name", "model", "price"}
+	records := make([][]string, 1000)
+	for i := range records {
+		records[i] = []string{fmt.Sprintf("Компонент %d", i), fmt.Sprintf("M-%d", i), fmt.Sprintf("%d.50", 100+i)}
+	}
+
+	b.Run("per-row", func(b *testing.B) {
+		query := multiRowInsertQuery("components", columns, 1)
+		for i := 0; i < b.N; i++ {
+			for _, record := range records {
+				args, _ := batchArgs(columns, [][]string{record})
+				if _, err := dbExec(query, args...); err != nil {
+					b.Fatal(err)
+				}
+			}
+			clearBenchmarkTable(b)
+		}
+	})
+	b.Run("batched", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			err := dbTransaction(func(tx *sql.Tx) error {
+				_, err := insertRecords(tx, "components", columns, records)
+				return err
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+			clearBenchmarkTable(b)
+		}
+	})
+}
+
+// Функция для очистки таблицы между итерациями (время очистки не учитывается)
+func clearBenchmarkTable(b *testing.B) {
+	b.StopTimer()
+	if got := queryString(b, "SELECT COUNT(*) FROM components"); got != "1000" {
+		b.Fatalf("добавлено записей %s, ожидалось 1000", got)
+	}
+	mustExec(b, "DELETE FROM components")
+	b.StartTimer()
+}
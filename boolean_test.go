@@ -0,0 +1,57 @@
+package osl
+
+import "testing"
+
+// Логическая колонка принимает true/false, 1/0, да/нет и выводится словами
+func TestBooleanColumnInput(t *testing.T) {
+	openSchema(t, []string{"parts"}, "CREATE TABLE parts (id INTEGER PRIMARY KEY, name TEXT, active BOOLEAN)")
+	table := tables[0]
+
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"true", true}, {"TRUE", true}, {"t", true}, {"1", true}, {"да", true}, {"Да", true}, {"yes", true},
+		{"false", false}, {"False", false}, {"f", false}, {"0", false}, {"нет", false}, {"НЕТ", false}, {"no", false},
+	}
+	for _, tt := range tests {
+		if err := validateTableValue(table, "active", tt.input); err != nil {
+			t.Errorf("validateTableValue(%q): %v", tt.input, err)
+			continue
+		}
+		// SQLite хранит логические значения числами
+		want := "0"
+		if tt.want {
+			want = "1"
+		}
+		if got := normalizeTableValue(table, "active", tt.input); got != want {
+			t.Errorf("normalizeTableValue(%q) = %q, ожидалось %q", tt.input, got, want)
+		}
+	}
+	for _, input := range []string{"2", "-1", "maybe", "истина", "ложь", "tru"} {
+		if err := validateTableValue(table, "active", input); err == nil {
+			t.Errorf("validateTableValue(%q) принято", input)
+		}
+	}
+
+	// Для PostgreSQL параметр передается словом
+	saved := dialect
+	dialect = postgresDialect{}
+	if got := normalizeTableValue(table, "active", "да"); got != "true" {
+		t.Errorf("параметр PostgreSQL для «да»: %q", got)
+	}
+	dialect = saved
+
+	// Вывод: да/нет вместо значений драйвера
+	mustExec(t, "INSERT INTO parts (name, active) VALUES ('a', $1), ('b', $2), ('c', NULL)",
+		normalizeTableValue(table, "active", "yes"), normalizeTableValue(table, "active", "0"))
+	rs, err := queryRecords("SELECT name, active FROM parts ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for row, want := range []string{"да", "нет", ""} {
+		if got := rs.displayValue(row, 1); got != want {
+			t.Errorf("строка %d: выведено %q, ожидалось %q", row, got, want)
+		}
+	}
+}
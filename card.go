@@ -0,0 +1,124 @@
+package osl
+
+import (
+	"bufio"
+	"fmt"
+)
+
+// Карточка записи: запись выбранной таблицы по id, затем по каждой связи из внешних ключей —
+// записи, на которые она ссылается, и записи других таблиц, которые ссылаются на нее.
+// Пустые связи выводятся явно, чтобы было видно, что данных нет, а не что связь пропущена.
+
+// Максимальное количество дочерних записей одной связи в карточке
+const cardChildLimit = 50
+
+// Пункт 31: Карточка записи
+func showRecordCard(reader *bufio.Reader) {
+	tableIndex := selectTable(reader, msg("select_table.card"))
+	if tableIndex < 0 {
+		return
+	}
+	table := tables[tableIndex]
+
+	key := primaryKeyColumn(table)
+	if key == "" {
+		printError(msg("jump.no_key", table.Name))
+		return
+	}
+	id, ok := promptColumnValue(reader, msg("jump.key_prompt", key), table, key)
+	if !ok {
+		return
+	}
+
+	query := fmt.Sprintf("SELECT * FROM %s WHERE %s = $1", table.SQLName(), dialect.QuoteIdent(key))
+	logToFileAndScreen(fmt.Sprintf("Карточка записи: %s с параметрами [%s]", query, id))
+	rs, err := queryRecords(query, id)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка чтения карточки записи %s: %v", table.Name, err))
+		printError(msg("view.query_failed"))
+		return
+	}
+	if len(rs.Rows) == 0 {
+		fmt.Fprintln(stdout, msg("jump.not_found", table.Name, keyDescription([]string{key}, []interface{}{id})))
+		return
+	}
+
+	fmt.Fprintln(stdout, msg("card.title", table.Name, key, id))
+	printVertical(rs)
+	if recordArchived(table, rs) {
+		fmt.Fprintln(stdout, msg("jump.archived"))
+	}
+
+	for _, relation := range relatedTables {
+		if relation.Child == table.Name {
+			printCardParent(relation, resultValue(rs, relation.Column))
+		}
+	}
+	for _, relation := range relatedTables {
+		if relation.Parent == table.Name {
+			printCardChildren(relation, id)
+		}
+	}
+}
+
+// Функция для получения значения колонки первой записи результата (пусто — нет колонки или NULL)
+func resultValue(rs *ResultSet, column string) string {
+	for i, name := range rs.Columns {
+		if name == column && len(rs.Rows) > 0 {
+			return rs.Rows[0][i]
+		}
+	}
+	return ""
+}
+
+// Функция для вывода записи, на которую ссылается внешний ключ карточки
+func printCardParent(relation tableRelation, value string) {
+	fmt.Fprintln(stdout, msg("card.parent_title", relation.Column, relation.Parent))
+	if value == "" {
+		fmt.Fprintln(stdout, msg("card.parent_empty", relation.Column))
+		return
+	}
+	parent, _ := findTable(relation.Parent)
+	key := tableKeyColumn(relation.Parent)
+	if key == "" {
+		fmt.Fprintln(stdout, msg("jump.no_key", relation.Parent))
+		return
+	}
+
+	rs, err := queryRecords(fmt.Sprintf("SELECT * FROM %s WHERE %s = $1", parent.SQLName(), dialect.QuoteIdent(key)), value)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка чтения связанной записи %s: %v", relation.Parent, err))
+		printError(msg("view.query_failed"))
+		return
+	}
+	if len(rs.Rows) == 0 {
+		fmt.Fprintln(stdout, msg("card.parent_missing", relation.Parent, key, value))
+		return
+	}
+	printVertical(rs)
+}
+
+// Функция для вывода записей другой таблицы, ссылающихся на запись карточки
+func printCardChildren(relation tableRelation, id string) {
+	fmt.Fprintln(stdout, msg("card.children_title", relation.Child, relation.Column))
+	child, _ := findTable(relation.Child)
+	query := fmt.Sprintf("SELECT * FROM %s WHERE %s = $1 %s LIMIT %d", child.SQLName(), dialect.QuoteIdent(relation.Column), orderByClause(child), cardChildLimit+1)
+	rs, err := queryRecords(query, id)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка чтения связанных записей %s: %v", relation.Child, err))
+		printError(msg("view.query_failed"))
+		return
+	}
+	if len(rs.Rows) == 0 {
+		fmt.Fprintln(stdout, msg("card.children_empty", relation.Child))
+		return
+	}
+	more := len(rs.Rows) > cardChildLimit
+	if more {
+		rs.Rows = rs.Rows[:cardChildLimit]
+	}
+	printResult(rs)
+	if more {
+		fmt.Fprintln(stdout, msg("card.children_limit", cardChildLimit))
+	}
+}
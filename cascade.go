@@ -0,0 +1,162 @@
+package osl
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Каскадное удаление: перед физическим удалением по внешним ключам схемы ищутся записи других
+// таблиц, которые ссылаются на удаляемые (и записи, ссылающиеся на них). Пользователь видит их
+// количество и может удалить их вместе с исходными записями в одной транзакции — сначала
+// ссылающиеся, затем исходные. Без явного подтверждения каскадное удаление не выполняется.
+
+// Записи таблицы, ссылающиеся на удаляемые записи через колонку внешнего ключа
+type childReference struct {
+	Parent string // таблица, на записи которой ссылается колонка
+	Table  string
+	Column string
+	IDs    []string // ключи ссылающихся записей (пусто, если у таблицы нет ключа)
+	Where  string   // условие отбора ссылающихся записей для удаления
+	Args   []interface{}
+	Count  int
+}
+
+// Функция для получения колонок других таблиц, ссылающихся на таблицу (в порядке таблиц и колонок)
+func referencingColumns(tableName string) []childReference {
+	var refs []childReference
+	for _, table := range tables {
+		var columns []string
+		for column, refTable := range table.ForeignKeys {
+			if refTable == tableName {
+				columns = append(columns, column)
+			}
+		}
+		sort.Strings(columns)
+		for _, column := range columns {
+			refs = append(refs, childReference{Parent: tableName, Table: table.Name, Column: column})
+		}
+	}
+	return refs
+}
+
+// Функция для поиска записей, ссылающихся на записи ids таблицы, включая ссылки на них самих.
+// Ссылающиеся записи идут после записей, на которые они ссылаются; visited защищает от циклов.
+func collectReferences(tableName string, ids []string, visited map[string]bool) ([]childReference, error) {
+	visited[tableName] = true
+	defer delete(visited, tableName)
+
+	var result []childReference
+	for _, ref := range referencingColumns(tableName) {
+		if visited[ref.Table] {
+			continue
+		}
+		where, args := inListCondition(ref.Column, ids, 1)
+		ref.Where, ref.Args = where, args
+		if tableKeyColumn(ref.Table) == "" {
+			// На записи таблицы без ключа никто не ссылается, поэтому они удаляются по самой ссылке
+			query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", tableRef(ref.Table), where)
+			if err := dbScanRow(query, args, &ref.Count); err != nil {
+				return nil, err
+			}
+			if ref.Count > 0 {
+				result = append(result, ref)
+			}
+			continue
+		}
+
+		childIDs, err := matchingIDs(ref.Table, where, args)
+		if err != nil {
+			return nil, err
+		}
+		if len(childIDs) == 0 {
+			continue
+		}
+		ref.IDs, ref.Count = childIDs, len(childIDs)
+		ref.Where, ref.Args = inListCondition(tableKeyColumn(ref.Table), childIDs, 1)
+		result = append(result, ref)
+
+		nested, err := collectReferences(ref.Table, childIDs, visited)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, nested...)
+	}
+	return result, nil
+}
+
+// Функция для предупреждения о ссылающихся записях и подтверждения каскадного удаления
+func confirmCascade(reader *bufio.Reader, table TableInfo, refs []childReference) bool {
+	total := 0
+	fmt.Fprintln(stdout, msg("delete.referenced_title"))
+	for _, ref := range refs {
+		fmt.Fprintln(stdout, msg("delete.referenced", ref.Parent, ref.Table, ref.Count, ref.Column))
+		total += ref.Count
+	}
+	logToFileAndScreen(fmt.Sprintf("На удаляемые записи %s ссылаются записи других таблиц: %d", table.Name, total))
+
+	fmt.Fprintln(stdout, msg("delete.cascade_option"))
+	fmt.Fprintln(stdout, msg("delete.cancel_option"))
+	choice, ok := promptInt(reader, msg("delete.cascade_prompt"), 0, 1)
+	if !ok || choice == 0 {
+		return false
+	}
+	return promptConfirm(reader, msg("delete.cascade_confirm", total))
+}
+
+// Функция для удаления записей вместе со ссылающимися на них одной транзакцией.
+// Возвращает количество удаленных исходных записей.
+func deleteCascade(table TableInfo, ids []string, refs []childReference) (int64, error) {
+	type deleteStep struct {
+		table string
+		where string
+		query string
+		args  []interface{}
+	}
+	// Сначала удаляются самые дальние ссылающиеся записи, исходные — последними
+	steps := make([]deleteStep, 0, len(refs)+1)
+	for i := len(refs) - 1; i >= 0; i-- {
+		ref := refs[i]
+		steps = append(steps, deleteStep{ref.Table, ref.Where, fmt.Sprintf("DELETE FROM %s WHERE %s", tableRef(ref.Table), ref.Where), ref.Args})
+	}
+	where, args := inListCondition(tableKeyColumn(table.Name), ids, 1)
+	steps = append(steps, deleteStep{table.Name, where, fmt.Sprintf("DELETE FROM %s WHERE %s", table.SQLName(), where), args})
+
+	if dryRun {
+		for _, step := range steps {
+			printDryRun(step.query, nil, step.args)
+		}
+		return 0, nil
+	}
+
+	var deleted int64
+	err := dbTransaction(func(tx *sql.Tx) error {
+		for _, step := range steps {
+			logToFileAndScreen(fmt.Sprintf("Каскадное удаление: %s с параметрами %v", step.query, step.args))
+			removed, err := auditSnapshot(tx, step.table, step.where, step.args)
+			if err != nil {
+				return fmt.Errorf("%s: %w", step.table, err)
+			}
+			result, err := txExec(tx, step.query, step.args...)
+			if err != nil {
+				return fmt.Errorf("%s: %w", step.table, err)
+			}
+			deleted, _ = result.RowsAffected()
+			if err := writeAudit(tx, auditDelete, step.table, removed); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, ref := range refs {
+		fmt.Fprintln(stdout, msg("delete.cascade_done", ref.Table, ref.Count))
+		logToFileAndScreen(fmt.Sprintf("Каскадное удаление: из таблицы %s удалено %d записей (ключи: %s)", ref.Table, ref.Count, strings.Join(ref.IDs, ", ")))
+	}
+	return deleted, nil
+}
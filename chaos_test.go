@@ -0,0 +1,129 @@
+package osl
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Драйвер-обертка над SQLite, который отказывает в новых соединениях заданное число раз
+// с ошибкой PostgreSQL о перегрузке (SQLSTATE 53300)
+type chaosDriver struct {
+	sync.Mutex
+	inner    driver.Driver
+	failures int
+	refused  int
+}
+
+func (d *chaosDriver) Open(name string) (driver.Conn, error) {
+	d.Lock()
+	defer d.Unlock()
+	if d.failures > 0 {
+		d.failures--
+		d.refused++
+		return nil, &pq.Error{Code: "53300", Message: "sorry, too many clients already"}
+	}
+	return d.inner.Open(name)
+}
+
+// Функция для отказа в следующих count новых соединениях
+func (d *chaosDriver) refuse(count int) {
+	d.Lock()
+	d.failures, d.refused = count, 0
+	d.Unlock()
+}
+
+var chaos = func() *chaosDriver {
+	sqliteDB, _ := sql.Open("sqlite", "")
+	d := &chaosDriver{inner: sqliteDB.Driver()}
+	sql.Register("chaos", d)
+	return d
+}()
+
+// Функция для подключения к файлу SQLite через драйвер-обертку
+func openChaosDB(t *testing.T) {
+	t.Helper()
+	openTestDB(t)
+	db.Close()
+	var err error
+	db, err = sql.Open("chaos", filepath.Join(t.TempDir(), "chaos.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chaos.refuse(0)
+	savedOverload := overloadSeenAt
+	t.Cleanup(func() { overloadSeenAt = savedOverload })
+	t.Setenv("OSL_OVERLOAD_BACKOFF", "1ms")
+}
+
+// Перегрузка при запуске: ожидание с повтором вместо выхода с ошибкой подключения
+func TestOverloadAtStartup(t *testing.T) {
+	openChaosDB(t)
+	overloadSeenAt = time.Time{}
+
+	chaos.refuse(2)
+	var err error
+	output := captureOutput(t, func() { err = waitForDatabase(time.Second) })
+	if err != nil {
+		t.Fatalf("waitForDatabase: %v", err)
+	}
+	if count := strings.Count(output, msg("db.overloaded", time.Millisecond)); count != 2 || chaos.refused != 2 {
+		t.Errorf("сообщений о перегрузке %d, отказов %d:\n%s", count, chaos.refused, output)
+	}
+	if overloadSeenAt.IsZero() {
+		t.Error("перегрузка не учтена")
+	}
+
+	// Повторы ограничены OSL_OVERLOAD_RETRIES
+	t.Setenv("OSL_OVERLOAD_RETRIES", "1")
+	db.SetMaxIdleConns(0)
+	chaos.refuse(5)
+	captureOutput(t, func() { err = waitForDatabase(time.Second) })
+	if !isTooManyConnections(err) {
+		t.Errorf("после исчерпания повторов: %v", err)
+	}
+}
+
+// Перегрузка во время работы: операция ждет освобождения соединений, сеанс продолжается,
+// простаивающие соединения пула закрываются
+func TestOverloadInSession(t *testing.T) {
+	openChaosDB(t)
+	overloadSeenAt = time.Time{}
+	mustExec(t, "CREATE TABLE parts (id INTEGER PRIMARY KEY, name TEXT)")
+	mustExec(t, "INSERT INTO parts (name) VALUES ('Кулер')")
+
+	// Простаивающих соединений нет, поэтому запрос открывает новое соединение
+	db.SetMaxIdleConns(0)
+	db.SetMaxIdleConns(2)
+	chaos.refuse(3)
+	var rs *ResultSet
+	var err error
+	output := captureOutput(t, func() { rs, err = queryRecords("SELECT name FROM parts") })
+	if err != nil || len(rs.Rows) != 1 || rs.Rows[0][0] != "Кулер" {
+		t.Fatalf("queryRecords = %+v, %v:\n%s", rs, err, output)
+	}
+	if count := strings.Count(output, msg("db.overloaded", time.Millisecond)); count != 3 {
+		t.Errorf("сообщений о перегрузке %d, ожидалось 3:\n%s", count, output)
+	}
+	if strings.Contains(output, msg("db.connection_lost")) {
+		t.Errorf("перегрузка принята за потерю соединения:\n%s", output)
+	}
+
+	// Пока перегрузка была недавно, соединение после запроса не остается в пуле
+	if idle := db.Stats().Idle; idle != 0 {
+		t.Errorf("простаивающих соединений %d после перегрузки", idle)
+	}
+	// После паузы OSL_OVERLOAD_COOLDOWN пул возвращается к обычным настройкам
+	t.Setenv("OSL_OVERLOAD_COOLDOWN", "1ms")
+	time.Sleep(2 * time.Millisecond)
+	captureOutput(t, func() { queryRecords("SELECT name FROM parts") })
+	if !overloadSeenAt.IsZero() {
+		t.Error("режим перегрузки не снят после паузы")
+	}
+}
@@ -0,0 +1,95 @@
+package osl
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// Функция для выполнения команды командной строки вместо интерактивного меню.
+// Возвращает код завершения процесса.
+// Команду можно задать и в виде -action=<команда> (например, osl -action=status).
+func runCommand(args []string) int {
+	command := args[0]
+	for _, prefix := range []string{"-action=", "--action="} {
+		if strings.HasPrefix(command, prefix) {
+			command = strings.TrimPrefix(command, prefix)
+		}
+	}
+
+	// Команды, изменяющие данные, недоступны в режиме только для чтения
+	if readOnly && (command == "clone-db" || command == "import") {
+		warnReadOnly("команда " + command)
+		fmt.Fprintln(stderr, msg("readonly.blocked"))
+		return 1
+	}
+
+	switch command {
+	case "export":
+		return exportCommand(args[1:])
+	case "clone-db":
+		return cloneDBCommand(args[1:])
+	case "run":
+		return runSpecCommand(args[1:])
+	case "status":
+		return statusCommand(args[1:])
+	case "import":
+		return importCommand(args[1:])
+	default:
+		fmt.Fprintln(stderr, msg("cli.unknown_command", command))
+		fmt.Fprintln(stderr, msg("cli.commands", "export, clone-db, run, status, import"))
+		return 2
+	}
+}
+
+// Команда export: новая выгрузка таблицы или продолжение прерванной
+func exportCommand(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	resume := fs.String("resume", "", "путь к токену прерванного экспорта")
+	tableName := fs.String("table", "", "таблица для экспорта")
+	format := fs.String("format", "csv", "формат: csv или json")
+	out := fs.String("out", "", "путь к файлу результата")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	var token ExportToken
+	if *resume != "" {
+		var err error
+		token, err = loadExportToken(*resume)
+		if err != nil {
+			logToFileAndScreen(fmt.Sprintf("Ошибка чтения токена экспорта: %v", err))
+			return 1
+		}
+	} else {
+		table, ok := findTable(*tableName)
+		if !ok {
+			fmt.Fprintln(stderr, msg("common.table_not_found", *tableName))
+			return 2
+		}
+		if *format != "csv" && *format != "json" {
+			fmt.Fprintln(stderr, msg("cli.bad_format"))
+			return 2
+		}
+		path := *out
+		if path == "" {
+			path = fmt.Sprintf("%s.%s", table.Name, *format)
+		}
+		token.Spec = ExportSpec{Table: table.Name, Columns: table.Columns, Format: *format, Path: path}
+	}
+
+	if err := runExportWithSignals(token); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// Функция для поиска таблицы по имени
+func findTable(name string) (TableInfo, bool) {
+	for _, table := range tables {
+		if table.Name == name {
+			return table, true
+		}
+	}
+	return TableInfo{}, false
+}
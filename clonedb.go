@@ -0,0 +1,317 @@
+package osl
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Команда clone-db создает копию базы для тестов: новая база на том же сервере,
+// структура по обнаруженному каталогу и (по желанию) данные, скопированные через COPY.
+// Поддерживается только PostgreSQL.
+
+// Допустимое имя новой базы
+var databaseNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Команда clone-db: osl clone-db --source prod --target mydev --schema-only|--with-data [--tables a,b]
+func cloneDBCommand(args []string) int {
+	fs := flag.NewFlagSet("clone-db", flag.ContinueOnError)
+	source := fs.String("source", "", "профиль исходной базы (пусто — текущее подключение)")
+	target := fs.String("target", "", "имя создаваемой базы")
+	schemaOnly := fs.Bool("schema-only", false, "копировать только структуру")
+	withData := fs.Bool("with-data", false, "копировать структуру и данные")
+	tableList := fs.String("tables", "", "таблицы через запятую (связанные таблицы добавляются автоматически)")
+	keepPartial := fs.Bool("keep-partial", false, "не удалять базу при ошибке")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if !databaseNameRegex.MatchString(*target) {
+		fmt.Fprintln(stderr, msg("clone.target_required"))
+		return 2
+	}
+	if *schemaOnly == *withData {
+		fmt.Fprintln(stderr, msg("clone.mode_required"))
+		return 2
+	}
+	if dryRun {
+		fmt.Fprintln(stderr, msg("clone.dry_run", dryRunTag))
+		return 2
+	}
+	if _, exists := appConfig.Profiles[*target]; exists {
+		fmt.Fprintln(stderr, msg("clone.profile_exists", *target))
+		return 2
+	}
+
+	sourceConfig, err := profileConfig(*source)
+	if err != nil {
+		fmt.Fprintln(stderr, msg("common.error", err))
+		return 2
+	}
+	if sourceDialect, err := dialectFor(sourceConfig.Driver); err != nil {
+		fmt.Fprintln(stderr, msg("common.error", err))
+		return 2
+	} else if _, ok := sourceDialect.(postgresDialect); !ok {
+		fmt.Fprintln(stderr, msg("clone.postgres_only"))
+		return 2
+	}
+
+	// Структура исходной базы читается через общие функции, поэтому на время команды
+	// исходная база становится текущим подключением
+	if *source != "" {
+		sourceDB, err := openDatabase(sourceConfig)
+		if err != nil {
+			logToFileAndScreen(fmt.Sprintf("Ошибка подключения к исходной базе '%s': %v", *source, err))
+			return 1
+		}
+		previous, previousConfig := db, activeConfig
+		db, activeConfig = sourceDB, sourceConfig
+		defer func() {
+			db.Close()
+			db, activeConfig = previous, previousConfig
+		}()
+		loadColumnTypes()
+		loadForeignKeys()
+		loadColumnDetails()
+	}
+
+	selected, err := cloneTables(*tableList)
+	if err != nil {
+		fmt.Fprintln(stderr, msg("common.error", err))
+		return 2
+	}
+
+	start := time.Now()
+	var canCreate bool
+	if err := dbScanRow(`SELECT rolcreatedb OR rolsuper FROM pg_roles WHERE rolname = current_user`, nil, &canCreate); err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка проверки прав пользователя: %v", err))
+		return 1
+	}
+	if !canCreate {
+		logToFileAndScreen(fmt.Sprintf("Ошибка: у пользователя %s нет права CREATEDB, база '%s' не может быть создана",
+			sourceConfig.User, *target))
+		return 1
+	}
+
+	if _, err := dbExec("CREATE DATABASE " + dialect.QuoteIdent(*target)); err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка создания базы '%s': %v", *target, err))
+		return 1
+	}
+	fmt.Fprintln(stdout, msg("clone.created", *target))
+	logToFileAndScreen(fmt.Sprintf("Клонирование: создана база %s", *target))
+
+	targetConfig := sourceConfig
+	targetConfig.Name = *target
+	if err := cloneInto(targetConfig, selected, *withData); err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка клонирования в базу '%s': %v", *target, err))
+		if *keepPartial {
+			fmt.Fprintln(stdout, msg("clone.partial_kept", *target))
+		} else if _, dropErr := dbExec("DROP DATABASE " + dialect.QuoteIdent(*target)); dropErr != nil {
+			logToFileAndScreen(fmt.Sprintf("Ошибка удаления частично созданной базы '%s': %v", *target, dropErr))
+		} else {
+			fmt.Fprintln(stdout, msg("clone.partial_dropped", *target))
+		}
+		return 1
+	}
+
+	// Новый профиль позволяет сразу подключаться к копии
+	if appConfig.Profiles == nil {
+		appConfig.Profiles = make(map[string]ConnectionProfile)
+	}
+	appConfig.Profiles[*target] = ConnectionProfile{Driver: targetConfig.Driver, Host: targetConfig.Host,
+		Port: targetConfig.Port, Name: targetConfig.Name, SSLMode: targetConfig.SSLMode}
+	if err := saveAppConfig(); err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка сохранения профиля '%s': %v", *target, err))
+	} else {
+		fmt.Fprintln(stdout, msg("clone.profile_added", *target))
+	}
+
+	duration := time.Since(start).Round(time.Millisecond)
+	fmt.Fprintln(stdout, msg("clone.done", duration))
+	logToFileAndScreen(fmt.Sprintf("Клонирование в базу %s завершено за %s", *target, duration))
+	return 0
+}
+
+// Функция для открытия подключения с проверкой доступности
+func openDatabase(config DBConfig) (*sql.DB, error) {
+	configDialect, err := dialectFor(config.Driver)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := sql.Open(configDialect.DriverName(), connectionDSN(configDialect, config))
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Функция для получения строки подключения: пароль регистрируется как секрет до того,
+// как строка попадает в журнал, поэтому в журнале он заменяется на ***
+func connectionDSN(configDialect Dialect, config DBConfig) string {
+	registerSecret(config.Password)
+	dsn := configDialect.DSN(config)
+	logToFileAndScreen(fmt.Sprintf("Подключение к БД (%s): %s", configDialect.DriverName(), dsn))
+	return dsn
+}
+
+// Функция для выбора таблиц для клонирования: перечисленные и те, на которые они ссылаются,
+// в порядке внешних ключей (пустой список — все таблицы)
+func cloneTables(list string) ([]TableInfo, error) {
+	if strings.TrimSpace(list) == "" {
+		return tablesInDependencyOrder(tables), nil
+	}
+
+	requested := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			requested[name] = true
+		}
+	}
+
+	var selected []TableInfo
+	seen := make(map[string]bool)
+	var add func(name string) error
+	add = func(name string) error {
+		if seen[name] {
+			return nil
+		}
+		table, ok := findTable(name)
+		if !ok {
+			return fmt.Errorf("таблица '%s' не найдена", name)
+		}
+		seen[name] = true
+		selected = append(selected, table)
+		for _, column := range table.Columns {
+			if parent := foreignKeyTarget(table, column); parent != "" {
+				if !requested[parent] && !seen[parent] {
+					fmt.Fprintln(stdout, msg("clone.parent_added", parent, name))
+				}
+				if err := add(parent); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if err := add(name); err != nil {
+			return nil, err
+		}
+	}
+	return tablesInDependencyOrder(selected), nil
+}
+
+// Функция для создания структуры и копирования данных в новую базу
+func cloneInto(targetConfig DBConfig, selected []TableInfo, withData bool) error {
+	targetDB, err := openDatabase(targetConfig)
+	if err != nil {
+		return fmt.Errorf("подключение к новой базе: %w", err)
+	}
+	defer targetDB.Close()
+
+	for i, table := range selected {
+		ddl, err := createTableSQL(table)
+		if err != nil {
+			return err
+		}
+		if _, err := targetDB.Exec(ddl); err != nil {
+			return fmt.Errorf("создание таблицы %s: %w", table.Name, err)
+		}
+		fmt.Fprintln(stdout, msg("clone.table_created", i+1, len(selected), table.Name))
+	}
+	if !withData {
+		return nil
+	}
+
+	total := 0
+	for i, table := range selected {
+		count, err := copyTableData(targetDB, table)
+		if err != nil {
+			return fmt.Errorf("копирование данных %s: %w", table.Name, err)
+		}
+		total += count
+		fmt.Fprintln(stdout, msg("clone.table_copied", i+1, len(selected), table.Name, count))
+		logToFileAndScreen(fmt.Sprintf("Клонирование: %s — скопировано %d строк", table.Name, count))
+	}
+	fmt.Fprintln(stdout, msg("clone.total", total))
+	return nil
+}
+
+// Функция для копирования строк таблицы из текущей базы в targetDB через COPY
+func copyTableData(targetDB *sql.DB, table TableInfo) (int, error) {
+	columns := make([]string, len(table.Details))
+	for i, column := range table.Details {
+		columns[i] = column.Name
+	}
+
+	rows, err := dbQuery(fmt.Sprintf("SELECT %s FROM %s", columnList(columns), table.SQLName()))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	tx, err := targetDB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(pq.CopyIn(table.Name, columns...))
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	values := make([]interface{}, len(columns))
+	valuePtrs := make([]interface{}, len(columns))
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(valuePtrs...); err != nil {
+			stmt.Close()
+			return 0, err
+		}
+		if _, err := stmt.Exec(values...); err != nil {
+			stmt.Close()
+			return 0, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		stmt.Close()
+		return 0, err
+	}
+	if _, err := stmt.Exec(); err != nil {
+		stmt.Close()
+		return 0, err
+	}
+	if err := stmt.Close(); err != nil {
+		return 0, err
+	}
+
+	// Последовательности serial продолжают нумерацию после скопированных id
+	for _, column := range table.Details {
+		if !isSerialColumn(column) {
+			continue
+		}
+		_, err := txExec(tx, fmt.Sprintf("SELECT setval(pg_get_serial_sequence($1, $2), COALESCE(MAX(%s), 0) + 1, false) FROM %s",
+			dialect.QuoteIdent(column.Name), dialect.QuoteIdent(table.Name)), dialect.QuoteIdent(table.Name), column.Name)
+		if err != nil {
+			return 0, fmt.Errorf("не удалось обновить последовательность %s: %w", column.Name, err)
+		}
+	}
+	return count, tx.Commit()
+}
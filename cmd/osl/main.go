@@ -0,0 +1,25 @@
+// Программа osl: консольное приложение для работы с базой данных компонентов ПК.
+// Сеанс выполняется пакетом osl; здесь только параметры запуска и код завершения процесса.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"osl"
+)
+
+func main() {
+	err := osl.Run(context.Background(), osl.OptionsFromEnv(os.Args[1:]), os.Stdin, os.Stdout, os.Stderr)
+	var exitErr *osl.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		os.Exit(exitErr.Code)
+	default:
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,143 @@
+package osl
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// Цветной вывод в терминал (ANSI): заголовок таблицы жирным, разделитель тусклым, ошибки красным,
+// четные строки широких таблиц на затемненном фоне (OSL_ZEBRA_MIN_COLUMNS, 0 — без затенения).
+// Цвета отключаются при заданной NO_COLOR (https://no-color.org), TERM=dumb и выводе не в терминал
+// (перенаправление в файл или канал), чтобы в сохраненном выводе не было управляющих последовательностей.
+
+// ANSI-коды оформления
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiDim   = "\x1b[2m"
+	ansiRed   = "\x1b[31m"
+	ansiZebra = "\x1b[48;5;236m"
+)
+
+// Включен ли цветной вывод
+var colorEnabled = colorSupported(os.Stdout)
+
+// Функция для определения, можно ли выводить цвета: вывод должен быть терминалом
+func colorSupported(out io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	file, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Функция для оформления текста ANSI-кодом (без цветов текст не меняется)
+func colorize(code, text string) string {
+	if !colorEnabled || text == "" {
+		return text
+	}
+	return code + text + ansiReset
+}
+
+// Функция для выделения текста жирным
+func bold(text string) string {
+	return colorize(ansiBold, text)
+}
+
+// Функция для вывода текста тусклым
+func dim(text string) string {
+	return colorize(ansiDim, text)
+}
+
+// Функция для определения, выводится ли строка таблицы с номером number (с нуля) на затемненном фоне.
+// Затеняется каждая вторая строка таблиц, в которых колонок не меньше OSL_ZEBRA_MIN_COLUMNS (по умолчанию 5).
+func zebraRow(columnCount, number int) bool {
+	minColumns := envInt("OSL_ZEBRA_MIN_COLUMNS", 5)
+	return colorEnabled && minColumns > 0 && columnCount >= minColumns && number%2 == 1
+}
+
+// Функция для вывода сообщения об ошибке (красным, если цвета включены)
+func printError(text string) {
+	// Текст ошибки может содержать строку подключения
+	text = redactSecrets(text)
+	// Перевод строки в начале сообщения не окрашивается
+	trimmed := strings.TrimLeft(text, "\n")
+	fmt.Fprintln(stdout, text[:len(text)-len(trimmed)] + colorize(ansiRed, trimmed))
+}
+
+// Функция для получения видимой ширины строки: управляющие последовательности ANSI не учитываются
+func visibleWidth(str string) int {
+	width := 0
+	for i := 0; i < len(str); {
+		if n := ansiSequenceLength(str[i:]); n > 0 {
+			i += n
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(str[i:])
+		i += size
+		width++
+	}
+	return width
+}
+
+// Функция для обрезки строки длиннее width видимых символов с многоточием в конце
+func ellipsizeVisible(str string, width int) string {
+	if visibleWidth(str) <= width {
+		return str
+	}
+	if width < 1 {
+		return ""
+	}
+	return truncateVisible(str, width-1) + "…"
+}
+
+// Функция для обрезки строки до width видимых символов с сохранением управляющих последовательностей
+func truncateVisible(str string, width int) string {
+	var b strings.Builder
+	visible := 0
+	styled := false
+	for i := 0; i < len(str); {
+		if n := ansiSequenceLength(str[i:]); n > 0 {
+			b.WriteString(str[i : i+n])
+			styled = true
+			i += n
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(str[i:])
+		if visible == width {
+			// Оформление не должно «протечь» за пределы обрезанной ячейки
+			if styled {
+				b.WriteString(ansiReset)
+			}
+			break
+		}
+		b.WriteString(str[i : i+size])
+		i += size
+		visible++
+	}
+	return b.String()
+}
+
+// Функция для получения длины управляющей последовательности вида ESC [ ... m в начале строки (0 — ее нет)
+func ansiSequenceLength(str string) int {
+	if !strings.HasPrefix(str, "\x1b[") {
+		return 0
+	}
+	for i := 2; i < len(str); i++ {
+		c := str[i]
+		if c >= 0x40 && c <= 0x7e {
+			return i + 1
+		}
+	}
+	return 0
+}
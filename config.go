@@ -0,0 +1,175 @@
+package osl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Функция для получения пути к файлу логов
+func logFilePath() string {
+	return envString("LOG_FILE", "/logs/app.log")
+}
+
+// Функция для чтения строковой настройки из переменной окружения
+func envString(name, def string) string {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+// Функция для чтения целочисленной настройки из переменной окружения
+func envInt(name string, def int) int {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Некорректное значение %s=%q, используется %d", name, value, def)
+		return def
+	}
+	return n
+}
+
+// Функция для чтения дробной настройки из переменной окружения
+func envFloat(name string, def float64) float64 {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Printf("Некорректное значение %s=%q, используется %v", name, value, def)
+		return def
+	}
+	return f
+}
+
+// Функция для чтения логической настройки из переменной окружения
+func envBool(name string, def bool) bool {
+	value := strings.ToLower(strings.TrimSpace(os.Getenv(name)))
+	switch value {
+	case "":
+		return def
+	case "1", "true", "yes", "on", "да":
+		return true
+	case "0", "false", "no", "off", "нет":
+		return false
+	}
+	log.Printf("Некорректное значение %s=%q, используется %v", name, value, def)
+	return def
+}
+
+// Функция для чтения длительности из переменной окружения
+// (принимается формат Go, например "3s", или целое число секунд)
+func envDuration(name string, def time.Duration) time.Duration {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return def
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Некорректное значение %s=%q, используется %s", name, value, def)
+		return def
+	}
+	return d
+}
+
+// Структура файла конфигурации (путь задается OSL_CONFIG)
+type AppConfig struct {
+	// Порядок вывода колонок по таблицам: перечисленные колонки идут первыми,
+	// остальные — следом в порядке каталога БД
+	ColumnOrder map[string][]string `json:"column_order,omitempty"`
+	// Правила проверки строк перед вставкой и обновлением (см. ruleengine.go)
+	Rules []RowRule `json:"rules,omitempty"`
+	// Профили подключения по имени; логин и пароль берутся из текущего сеанса
+	Profiles map[string]ConnectionProfile `json:"profiles,omitempty"`
+	// Сохраненные фильтры для команды run (см. runspec.go)
+	SavedFilters map[string]SavedFilter `json:"saved_filters,omitempty"`
+	// Дополнительные таблицы ("таблица" или "схема.таблица"); колонки берутся из каталога БД
+	Tables []string `json:"tables,omitempty"`
+}
+
+// Профиль подключения к БД: незаданные поля берутся из текущего подключения
+type ConnectionProfile struct {
+	Driver  string `json:"driver,omitempty"`
+	Host    string `json:"host,omitempty"`
+	Port    string `json:"port,omitempty"`
+	Name    string `json:"name"`
+	SSLMode string `json:"sslmode,omitempty"`
+}
+
+// Текущая конфигурация из файла
+var appConfig AppConfig
+
+// Функция для загрузки файла конфигурации; без OSL_CONFIG используется пустая конфигурация
+func loadAppConfig() error {
+	path := envString("OSL_CONFIG", "")
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var config AppConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("некорректный файл конфигурации %s: %w", path, err)
+	}
+	appConfig = config
+	return nil
+}
+
+// Функция для сохранения конфигурации в файл OSL_CONFIG
+func saveAppConfig() error {
+	path := envString("OSL_CONFIG", "")
+	if path == "" {
+		return errors.New("файл конфигурации не задан (OSL_CONFIG)")
+	}
+	data, err := json.MarshalIndent(appConfig, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// Функция для получения параметров подключения по имени профиля (пусто — текущее подключение)
+func profileConfig(name string) (DBConfig, error) {
+	config := activeConfig
+	if name == "" {
+		return config, nil
+	}
+	profile, ok := appConfig.Profiles[name]
+	if !ok {
+		return DBConfig{}, fmt.Errorf("профиль '%s' не найден в файле конфигурации", name)
+	}
+	if profile.Driver != "" {
+		config.Driver = profile.Driver
+	}
+	if profile.Host != "" {
+		config.Host = profile.Host
+	}
+	if profile.Port != "" {
+		config.Port = profile.Port
+	}
+	if profile.SSLMode != "" {
+		config.SSLMode = profile.SSLMode
+	}
+	config.Name = profile.Name
+	return config, nil
+}
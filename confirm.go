@@ -0,0 +1,72 @@
+package osl
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+// Сводка перед выполнением изменения: таблица, добавляемые значения или SET и WHERE обновления
+// и подтверждение да/нет. Флаг запуска --yes (или OSL_ASSUME_YES) подтверждает изменения без вопроса
+// для неинтерактивного запуска. В пробном режиме сводка выводится без вопроса: запрос не выполняется.
+
+// Изменения подтверждаются автоматически (--yes или OSL_ASSUME_YES)
+var assumeYes bool
+
+// Максимальное количество записей, значения которых выводятся в сводке добавления
+const summaryMaxRecords = 10
+
+// Функция для вывода сводки изменения и запроса подтверждения; false — изменение отменено
+func confirmSummary(reader *bufio.Reader, lines []string) bool {
+	fmt.Fprintln(stdout, msg("summary.title"))
+	for _, line := range lines {
+		fmt.Fprintln(stdout, "  " + line)
+	}
+	if dryRun {
+		return true
+	}
+	if assumeYes {
+		logToFileAndScreen(fmt.Sprintf("Изменение подтверждено автоматически (--yes): %s", strings.Join(lines, "; ")))
+		return true
+	}
+	return promptConfirm(reader, msg("summary.confirm"))
+}
+
+// Функция для описания значений записи: "колонка = 'значение', ..." (чувствительные значения скрываются)
+func describeValues(columns []string, values []interface{}) string {
+	pairs := make([]string, len(columns))
+	for i, column := range columns {
+		value := ""
+		if i < len(values) && values[i] != nil {
+			value = fmt.Sprintf("%v", values[i])
+		}
+		pairs[i] = fmt.Sprintf("%s = '%s'", column, displayParam(column, value))
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// Функция для получения строк сводки добавления записей
+func insertSummary(table string, columns []string, records [][]string) []string {
+	lines := []string{msg("summary.insert", table, len(records))}
+	for i, record := range records {
+		if i == summaryMaxRecords {
+			lines = append(lines, msg("summary.more_records", len(records)-summaryMaxRecords))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%d. %s", i+1, describeValues(columns, stringArgs(record))))
+	}
+	return lines
+}
+
+// Функция для получения строк сводки обновления: SET, WHERE и количество записей
+func updateSummary(spec UpdateSpec, count int) []string {
+	where := describeConditions(spec.Conditions, spec.Operator)
+	if len(spec.Conditions) == 0 {
+		where = fmt.Sprintf("%s IN (%s)", tableKeyColumn(spec.Table), strings.Join(spec.IDs, ", "))
+	}
+	return []string{
+		msg("summary.update", spec.Table, count),
+		fmt.Sprintf("SET %s = '%s'", spec.Column, displayParam(spec.Column, spec.Value)),
+		"WHERE " + where,
+	}
+}
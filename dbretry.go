@@ -0,0 +1,387 @@
+package osl
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Функция для определения ошибок уровня соединения, после которых запрос имеет смысл повторить.
+// Ошибки SQL (синтаксис, ограничения и т.п.) повторять бессмысленно.
+func isConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// Класс 08 — ошибки соединения, 57P01-57P03 — остановка или перезапуск сервера
+		return pqErr.Code.Class() == "08" ||
+			pqErr.Code == "57P01" || pqErr.Code == "57P02" || pqErr.Code == "57P03"
+	}
+
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "connection refused") ||
+		strings.Contains(message, "connection reset") ||
+		strings.Contains(message, "broken pipe")
+}
+
+// Функция для определения ошибки входа: неверный логин или пароль
+// (SQLSTATE 28P01 и 28000 в PostgreSQL, ошибка 1045 в MySQL)
+func isAuthError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "28P01" || pqErr.Code == "28000"
+	}
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "password authentication failed") || strings.Contains(message, "access denied")
+}
+
+// Функция для определения перегрузки сервера по числу соединений
+// (SQLSTATE 53300 в PostgreSQL, ошибка 1040 в MySQL)
+func isTooManyConnections(err error) bool {
+	if err == nil {
+		return false
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "53300"
+	}
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "too many clients") || strings.Contains(message, "too many connections")
+}
+
+// Время последней перегрузки сервера (нулевое — перегрузки не было или она прошла)
+var overloadSeenAt time.Time
+
+// Функция для получения паузы перед повтором при перегрузке сервера
+func overloadBackoff() time.Duration {
+	return envDuration("OSL_OVERLOAD_BACKOFF", 15*time.Second)
+}
+
+// Функция для учета перегрузки: простаивающие соединения пула сразу закрываются,
+// чтобы программа сама не занимала лишние соединения
+func noteOverload() {
+	if overloadSeenAt.IsZero() && db != nil {
+		db.SetMaxIdleConns(0)
+	}
+	overloadSeenAt = time.Now()
+}
+
+// Функция для возврата обычных настроек пула, когда перегрузки давно не было (OSL_OVERLOAD_COOLDOWN)
+func relaxOverload() {
+	if overloadSeenAt.IsZero() || time.Since(overloadSeenAt) < envDuration("OSL_OVERLOAD_COOLDOWN", 5*time.Minute) {
+		return
+	}
+	overloadSeenAt = time.Time{}
+	if db != nil {
+		db.SetMaxIdleConns(poolSettings().MaxIdle)
+	}
+	logToFileAndScreen("Нагрузка на БД снизилась, пул соединений работает в обычном режиме")
+}
+
+// Функция для ожидания при перегрузке сервера.
+// Операция не завершается ошибкой, а ставится в очередь до освобождения соединений
+// (не более OSL_OVERLOAD_RETRIES повторов).
+func waitForOverload(attempt int, err error) bool {
+	if attempt > envInt("OSL_OVERLOAD_RETRIES", 5) {
+		return false
+	}
+	noteOverload()
+	backoff := overloadBackoff()
+	logToFileAndScreen(fmt.Sprintf("Сервер БД перегружен (%v), повтор %d", err, attempt))
+	fmt.Fprintln(stdout, msg("db.overloaded", backoff))
+	time.Sleep(backoff)
+	return true
+}
+
+// Интервал проверки готовности БД при запуске
+const readinessPollInterval = 500 * time.Millisecond
+
+// Функция для ожидания готовности БД при запуске: db.Ping повторяется с коротким интервалом,
+// пока БД не ответит или не истечет timeout. Ошибки, не связанные с соединением
+// (например, неверный пароль), возвращаются сразу. Пока идет ожидание, выводятся точки.
+func waitForDatabase(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	overloadAttempt := 0
+	waiting := false
+	defer func() {
+		if waiting {
+			fmt.Fprintln(stdout)
+		}
+	}()
+
+	for {
+		err := db.Ping()
+		if err == nil {
+			return nil
+		}
+		// При перегрузке сервера ждем дольше, срок ожидания при этом не расходуется
+		if isTooManyConnections(err) {
+			overloadAttempt++
+			if waitForOverload(overloadAttempt, err) {
+				deadline = deadline.Add(overloadBackoff())
+				continue
+			}
+			return err
+		}
+		if !isConnectionError(err) {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("БД не ответила за %s: %w", timeout, err)
+		}
+
+		if !waiting {
+			waiting = true
+			logToFileAndScreen(fmt.Sprintf("Ожидание запуска БД (%s): %v", activeConfig.Driver, err))
+			fmt.Fprint(stdout, msg("connect.waiting", timeout))
+		}
+		fmt.Fprint(stdout, ".")
+		time.Sleep(readinessPollInterval)
+	}
+}
+
+// Функция для подтверждения повтора изменения данных после восстановления соединения.
+// Задается в интерактивном режиме; без нее (команды командной строки) изменения не повторяются.
+var confirmWriteRetry func() bool
+
+// Функция для выполнения операции с восстановлением соединения.
+// При потере соединения выполняется ограниченное число попыток переподключения
+// (OSL_QUERY_RETRIES, начальная пауза OSL_QUERY_RETRY_BACKOFF удваивается с каждой попыткой),
+// после чего операция повторяется один раз: чтение — автоматически, изменение данных — только
+// с подтверждения пользователя, так как оно могло быть выполнено до обрыва.
+// При перегрузке сервера повторы идут отдельно с более длинной паузой (см. waitForOverload).
+func withRetry(idempotent bool, fn func() error) error {
+	overloadAttempt := 0
+	retried := false
+
+	for {
+		err := fn()
+		if err == nil {
+			relaxOverload()
+		}
+		if isTooManyConnections(err) {
+			overloadAttempt++
+			if waitForOverload(overloadAttempt, err) {
+				continue
+			}
+			return err
+		}
+		if !isConnectionError(err) || retried {
+			return err
+		}
+
+		logToFileAndScreen(fmt.Sprintf("Потеря соединения с БД: %v", err))
+		fmt.Fprintln(stdout, msg("db.connection_lost"))
+		if !reconnect() {
+			return err
+		}
+		if !idempotent && (confirmWriteRetry == nil || !confirmWriteRetry()) {
+			logToFileAndScreen("Изменение данных после восстановления соединения не повторялось")
+			return err
+		}
+		retried = true
+	}
+}
+
+// Функция для повторного запроса пароля, если при восстановлении соединения сервер его не принял.
+// Задается в интерактивном режиме; без нее попытки продолжаются с прежним паролем.
+var promptReconnectPassword func() (string, bool)
+
+// Функция для проверки соединения перед операцией меню: если соединение потеряно (например,
+// сервер перезапущен, пока меню простаивало), оно восстанавливается.
+// Возвращает false, если восстановить соединение не удалось, и признак восстановления соединения.
+func ensureConnection() (ok, restored bool) {
+	err := db.Ping()
+	if err == nil || !isConnectionError(err) {
+		return true, false
+	}
+	logToFileAndScreen(fmt.Sprintf("Потеря соединения с БД обнаружена перед операцией: %v", err))
+	fmt.Fprintln(stdout, msg("db.connection_lost"))
+	ok = reconnect()
+	return ok, ok
+}
+
+// Функция для подключения к БД по сохраненным параметрам. Если сервер не принял пароль,
+// пароль запрашивается повторно (в интерактивном режиме) и подключение повторяется.
+func reopenDatabase() (*sql.DB, error) {
+	newDB, err := openDatabase(activeConfig)
+	if !isAuthError(err) || promptReconnectPassword == nil {
+		return newDB, err
+	}
+	logToFileAndScreen(fmt.Sprintf("Сервер не принял пароль при восстановлении соединения: %v", err))
+	password, ok := promptReconnectPassword()
+	if !ok {
+		return nil, err
+	}
+	registerSecret(password)
+	config := activeConfig
+	config.Password = password
+	if newDB, err = openDatabase(config); err != nil {
+		return nil, err
+	}
+	activeConfig = config
+	return newDB, nil
+}
+
+// Функция для восстановления соединения с БД по сохраненным параметрам подключения.
+// Возвращает true, если соединение восстановлено.
+func reconnect() bool {
+	attempts := envInt("OSL_QUERY_RETRIES", 3)
+	backoff := envDuration("OSL_QUERY_RETRY_BACKOFF", 500*time.Millisecond)
+
+	for attempt := 1; attempt <= attempts; attempt++ {
+		logToFileAndScreen(fmt.Sprintf("Попытка восстановления соединения %d из %d", attempt, attempts))
+		time.Sleep(backoff)
+		backoff *= 2
+
+		// Пул сам заменяет разорванные соединения, поэтому сначала достаточно проверки
+		pingErr := db.Ping()
+		if pingErr != nil {
+			newDB, err := reopenDatabase()
+			if err != nil {
+				logToFileAndScreen(fmt.Sprintf("Соединение с БД не восстановлено: %v", err))
+				continue
+			}
+			applyPoolSettings(newDB, poolSettings())
+			oldDB := db
+			db = newDB
+			oldDB.Close()
+		}
+		logToFileAndScreen("Соединение с БД восстановлено")
+		fmt.Fprintln(stdout, msg("db.reconnected"))
+		return true
+	}
+	return false
+}
+
+// Функция для определения запросов, которые можно повторить без подтверждения
+func isReadQuery(query string) bool {
+	return firstKeyword(query) == "SELECT"
+}
+
+// Функция для выполнения запроса с повторами при потере соединения
+func dbQuery(query string, args ...interface{}) (*sql.Rows, error) {
+	var rows *sql.Rows
+	err := withRetry(isReadQuery(query), func() error {
+		return timedQuery("query", query, args, func(boundQuery string, boundArgs []interface{}) error {
+			var err error
+			rows, err = db.QueryContext(sessionCtx, boundQuery, boundArgs...)
+			return err
+		})
+	})
+	return rows, err
+}
+
+// Функция для выполнения команды с повторами при потере соединения
+func dbExec(query string, args ...interface{}) (sql.Result, error) {
+	var result sql.Result
+	err := withRetry(false, func() error {
+		return timedQuery("exec", query, args, func(boundQuery string, boundArgs []interface{}) error {
+			var err error
+			result, err = db.ExecContext(sessionCtx, boundQuery, boundArgs...)
+			return err
+		})
+	})
+	if err == nil {
+		n, _ := result.RowsAffected()
+		recordRowsAffected(n)
+	}
+	return result, err
+}
+
+// Функция для выполнения запроса, возвращающего одну строку, с повторами при потере соединения
+func dbScanRow(query string, args []interface{}, dest ...interface{}) error {
+	return withRetry(isReadQuery(query), func() error {
+		return timedQuery("scan_row", query, args, func(boundQuery string, boundArgs []interface{}) error {
+			return db.QueryRowContext(sessionCtx, boundQuery, boundArgs...).Scan(dest...)
+		})
+	})
+}
+
+// Функция для выполнения запроса с контекстом и повторами при потере соединения
+func dbQueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	var rows *sql.Rows
+	err := withRetry(isReadQuery(query), func() error {
+		return timedQuery("query_context", query, args, func(boundQuery string, boundArgs []interface{}) error {
+			var err error
+			rows, err = db.QueryContext(ctx, boundQuery, boundArgs...)
+			return err
+		})
+	})
+	return rows, err
+}
+
+// Функция для выполнения запроса одной строки с контекстом и повторами при потере соединения
+func dbScanRowContext(ctx context.Context, query string, args []interface{}, dest ...interface{}) error {
+	return withRetry(isReadQuery(query), func() error {
+		return timedQuery("scan_row_context", query, args, func(boundQuery string, boundArgs []interface{}) error {
+			return db.QueryRowContext(ctx, boundQuery, boundArgs...).Scan(dest...)
+		})
+	})
+}
+
+// Функция для выполнения операций в транзакции с повторами при потере соединения.
+// При ошибке транзакция откатывается целиком; при потере соединения повторяется с начала.
+// Отмена контекста сеанса откатывает транзакцию. В режиме только для чтения транзакции
+// изменения не открываются.
+func dbTransaction(fn func(tx *sql.Tx) error) error {
+	if readOnly {
+		warnReadOnly("транзакция изменения данных")
+		return errReadOnly
+	}
+	defer trackTiming("transaction", time.Now())
+	return withRetry(false, func() error {
+		tx, err := db.BeginTx(sessionCtx, nil)
+		if err != nil {
+			return err
+		}
+		if err := fn(tx); err != nil {
+			tx.Rollback()
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
+// Функция для чтения одной строки результата запроса внутри транзакции
+func txScanRow(tx *sql.Tx, query string, args []interface{}, dest ...interface{}) error {
+	return timedQuery("tx_query", query, args, func(boundQuery string, boundArgs []interface{}) error {
+		return tx.QueryRow(boundQuery, boundArgs...).Scan(dest...)
+	})
+}
+
+// Функция для выполнения запроса внутри транзакции
+func txExec(tx *sql.Tx, query string, args ...interface{}) (sql.Result, error) {
+	var result sql.Result
+	err := timedQuery("tx_exec", query, args, func(boundQuery string, boundArgs []interface{}) error {
+		var err error
+		result, err = tx.Exec(boundQuery, boundArgs...)
+		return err
+	})
+	if err == nil {
+		n, _ := result.RowsAffected()
+		recordRowsAffected(n)
+	}
+	return result, err
+}
@@ -0,0 +1,233 @@
+package osl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Отладочный HTTP-сервер включается только при заданном OSL_DEBUG_ADDR.
+// Он отдает стандартный net/http/pprof и /debug/osl с внутренними показателями программы.
+
+// Верхние границы интервалов гистограммы времени операций, в миллисекундах
+var timingBucketsMs = []float64{1, 5, 10, 50, 100, 500, 1000, 5000}
+
+// Статистика времени выполнения одного вида операций
+type TimingStats struct {
+	Count   int64            `json:"count"`
+	TotalMs float64          `json:"total_ms"`
+	MaxMs   float64          `json:"max_ms"`
+	Buckets map[string]int64 `json:"buckets"` // "le_<мс>" и "inf"
+}
+
+// Статистика обращений к кэшу
+type CacheStats struct {
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+// Статистика пула соединений
+type PoolStats struct {
+	OpenConnections int     `json:"open_connections"`
+	InUse           int     `json:"in_use"`
+	Idle            int     `json:"idle"`
+	WaitCount       int64   `json:"wait_count"`
+	WaitDurationMs  float64 `json:"wait_duration_ms"`
+}
+
+// Ответ /debug/osl
+type DebugReport struct {
+	SchemaDiscoveryMs float64                `json:"schema_discovery_ms"`
+	Operations        map[string]TimingStats `json:"operations"`
+	Caches            map[string]CacheStats  `json:"caches"`
+	Pool              PoolStats              `json:"pool"`
+	Goroutines        int                    `json:"goroutines"`
+}
+
+// Накопленные показатели для отладочного эндпоинта
+var debugStats = struct {
+	sync.Mutex
+	schemaDiscovery time.Duration
+	operations      map[string]*TimingStats
+	caches          map[string]*CacheStats
+}{
+	operations: map[string]*TimingStats{},
+	caches:     map[string]*CacheStats{},
+}
+
+// Функция для учета времени выполнения операции
+func recordTiming(operation string, duration time.Duration) {
+	ms := float64(duration) / float64(time.Millisecond)
+
+	debugStats.Lock()
+	defer debugStats.Unlock()
+
+	stats, ok := debugStats.operations[operation]
+	if !ok {
+		stats = &TimingStats{Buckets: map[string]int64{}}
+		debugStats.operations[operation] = stats
+	}
+	stats.Count++
+	stats.TotalMs += ms
+	if ms > stats.MaxMs {
+		stats.MaxMs = ms
+	}
+	bucket := "inf"
+	for _, limit := range timingBucketsMs {
+		if ms <= limit {
+			bucket = fmt.Sprintf("le_%g", limit)
+			break
+		}
+	}
+	stats.Buckets[bucket]++
+}
+
+// Функция для учета времени операции, начатой в start (вызывается через defer)
+func trackTiming(operation string, start time.Time) {
+	recordTiming(operation, time.Since(start))
+}
+
+// Функция для учета обращения к кэшу
+func recordCacheAccess(cache string, hit bool) {
+	debugStats.Lock()
+	defer debugStats.Unlock()
+
+	stats, ok := debugStats.caches[cache]
+	if !ok {
+		stats = &CacheStats{}
+		debugStats.caches[cache] = stats
+	}
+	if hit {
+		stats.Hits++
+	} else {
+		stats.Misses++
+	}
+}
+
+// Функция для учета длительности загрузки структуры БД
+func recordSchemaDiscovery(duration time.Duration) {
+	debugStats.Lock()
+	debugStats.schemaDiscovery = duration
+	debugStats.Unlock()
+}
+
+// Функция для формирования отчета /debug/osl
+func buildDebugReport() DebugReport {
+	debugStats.Lock()
+	report := DebugReport{
+		SchemaDiscoveryMs: float64(debugStats.schemaDiscovery) / float64(time.Millisecond),
+		Operations:        make(map[string]TimingStats, len(debugStats.operations)),
+		Caches:            make(map[string]CacheStats, len(debugStats.caches)),
+	}
+	for name, stats := range debugStats.operations {
+		copied := *stats
+		copied.Buckets = make(map[string]int64, len(stats.Buckets))
+		for bucket, count := range stats.Buckets {
+			copied.Buckets[bucket] = count
+		}
+		report.Operations[name] = copied
+	}
+	for name, stats := range debugStats.caches {
+		copied := *stats
+		if total := copied.Hits + copied.Misses; total > 0 {
+			copied.HitRate = float64(copied.Hits) / float64(total)
+		}
+		report.Caches[name] = copied
+	}
+	debugStats.Unlock()
+
+	if db != nil {
+		dbStats := db.Stats()
+		report.Pool = PoolStats{
+			OpenConnections: dbStats.OpenConnections,
+			InUse:           dbStats.InUse,
+			Idle:            dbStats.Idle,
+			WaitCount:       dbStats.WaitCount,
+			WaitDurationMs:  float64(dbStats.WaitDuration) / float64(time.Millisecond),
+		}
+	}
+	report.Goroutines = runtime.NumGoroutine()
+	return report
+}
+
+// Функция для создания обработчиков отладочного сервера
+func debugHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/osl", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(buildDebugReport())
+	})
+	return mux
+}
+
+// Функция для запуска отладочного сервера, если задан OSL_DEBUG_ADDR
+func startDebugServer() {
+	addr := envString("OSL_DEBUG_ADDR", "")
+	if addr == "" {
+		return
+	}
+	startHTTPListener("отладочный сервер", localAddr(addr), debugHandler())
+}
+
+// Функция для привязки адреса без хоста (":6060") к localhost.
+// Чтобы слушать другие интерфейсы, хост нужно указать явно (например 0.0.0.0:6060).
+func localAddr(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || host != "" {
+		return addr
+	}
+	return net.JoinHostPort("127.0.0.1", port)
+}
+
+// Запущенные служебные HTTP-серверы (отладка, метрики), останавливаются при выходе
+var httpListeners struct {
+	sync.Mutex
+	servers []*http.Server
+}
+
+// Функция для запуска служебного HTTP-сервера в фоне
+func startHTTPListener(name, addr string, handler http.Handler) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка запуска (%s) на %s: %v", name, addr, err))
+		return
+	}
+
+	server := &http.Server{Handler: handler, ReadHeaderTimeout: 5 * time.Second}
+	httpListeners.Lock()
+	httpListeners.servers = append(httpListeners.servers, server)
+	httpListeners.Unlock()
+
+	logToFileAndScreen(fmt.Sprintf("Запущен %s на %s", name, listener.Addr()))
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logToFileAndScreen(fmt.Sprintf("Ошибка работы (%s): %v", name, err))
+		}
+	}()
+}
+
+// Функция для остановки служебных HTTP-серверов
+func shutdownHTTPListeners() {
+	httpListeners.Lock()
+	servers := httpListeners.servers
+	httpListeners.servers = nil
+	httpListeners.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	for _, server := range servers {
+		server.Shutdown(ctx)
+	}
+}
@@ -0,0 +1,96 @@
+package osl
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Отладочный сервер запускается только с OSL_DEBUG_ADDR, слушает localhost
+// и отдает /debug/osl в формате DebugReport
+func TestDebugEndpoint(t *testing.T) {
+	openSchema(t, []string{"parts"}, "CREATE TABLE parts (id INTEGER PRIMARY KEY, name TEXT)")
+	t.Cleanup(shutdownHTTPListeners)
+
+	// Без OSL_DEBUG_ADDR сервер не запускается
+	t.Setenv("OSL_DEBUG_ADDR", "")
+	startDebugServer()
+	if count := len(httpListeners.servers); count != 0 {
+		t.Fatalf("запущено серверов без OSL_DEBUG_ADDR: %d", count)
+	}
+
+	// Показатели, которые должны попасть в отчет
+	recordSchemaDiscovery(12 * time.Millisecond)
+	queryString(t, "SELECT COUNT(*) FROM parts")
+	if _, err := queryRecords("SELECT * FROM parts"); err != nil {
+		t.Fatal(err)
+	}
+	// Шаблон white list компилируется один раз, повторная проверка берет его из кэша
+	t.Setenv("INPUT_WHITELIST", `^[\p{L}\d ]*$`)
+	validateTableValue(tables[0], "name", "Кулер")
+	validateTableValue(tables[0], "name", "Кулер")
+
+	// Свободный порт; адрес без хоста привязывается к localhost
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+	t.Setenv("OSL_DEBUG_ADDR", ":"+port)
+	if got := localAddr(":" + port); got != "127.0.0.1:"+port {
+		t.Fatalf("localAddr = %s", got)
+	}
+	startDebugServer()
+
+	response, err := http.Get("http://127.0.0.1:" + port + "/debug/osl")
+	if err != nil {
+		t.Fatalf("запрос /debug/osl: %v", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK || !strings.HasPrefix(response.Header.Get("Content-Type"), "application/json") {
+		t.Fatalf("ответ %s, Content-Type %q", response.Status, response.Header.Get("Content-Type"))
+	}
+
+	// Ответ содержит только поля DebugReport
+	decoder := json.NewDecoder(response.Body)
+	decoder.DisallowUnknownFields()
+	var report DebugReport
+	if err := decoder.Decode(&report); err != nil {
+		t.Fatalf("ответ не соответствует DebugReport: %v", err)
+	}
+	if report.SchemaDiscoveryMs != 12 {
+		t.Errorf("schema_discovery_ms = %v", report.SchemaDiscoveryMs)
+	}
+	if report.Goroutines < 1 || report.Pool.OpenConnections < 1 {
+		t.Errorf("goroutines = %d, open_connections = %d", report.Goroutines, report.Pool.OpenConnections)
+	}
+	query, ok := report.Operations["query"]
+	if !ok || query.Count < 1 || len(query.Buckets) == 0 {
+		t.Errorf("нет статистики запросов: %+v", report.Operations)
+	}
+	var bucketTotal int64
+	for bucket, count := range query.Buckets {
+		if bucket != "inf" && !strings.HasPrefix(bucket, "le_") {
+			t.Errorf("неизвестный интервал гистограммы %q", bucket)
+		}
+		bucketTotal += count
+	}
+	if bucketTotal != query.Count {
+		t.Errorf("сумма интервалов %d, операций %d", bucketTotal, query.Count)
+	}
+	cache := report.Caches["validation_patterns"]
+	if cache.Hits < 1 || cache.HitRate <= 0 || cache.HitRate > 1 {
+		t.Errorf("статистика кэша %+v", cache)
+	}
+
+	// pprof доступен на том же сервере
+	if response, err := http.Get("http://127.0.0.1:" + port + "/debug/pprof/"); err != nil || response.StatusCode != http.StatusOK {
+		t.Errorf("pprof: %v", err)
+	} else {
+		response.Body.Close()
+	}
+}
@@ -0,0 +1,45 @@
+package osl
+
+import (
+	"strings"
+	"testing"
+)
+
+// Колонка со значением по умолчанию пропускается при пустом вводе или DEFAULT,
+// обязательная колонка без значения по умолчанию требует ввода, введенное значение проверяется
+func TestInsertColumnDefaults(t *testing.T) {
+	openSchema(t, []string{"parts"},
+		`CREATE TABLE parts (id INTEGER PRIMARY KEY, name TEXT NOT NULL, qty INTEGER NOT NULL DEFAULT 5,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP)`)
+	assumeYes = true
+
+	output := captureOutput(t, func() {
+		insertData(scriptReader("2", "1",
+			// Первая запись: name пустой (повтор), qty "много" (повтор), затем Enter; created_at — DEFAULT
+			"", "Кулер", "много", "", "default",
+			// Вторая запись: qty и created_at не запрашиваются
+			"Корпус"))
+	})
+	if got := queryString(t, "SELECT group_concat(name || ':' || qty || ':' || (created_at IS NOT NULL), ',') FROM parts"); got != "Кулер:5:1,Корпус:5:1" {
+		t.Errorf("добавлены записи %q:\n%s", got, output)
+	}
+	for _, want := range []string{"значение не может быть пустым", msg("input.not_number", "qty")} {
+		if !strings.Contains(output, want) {
+			t.Errorf("нет ошибки ввода %q:\n%s", want, output)
+		}
+	}
+	if count := strings.Count(output, msg("insert.default_used", "qty")); count != 1 {
+		t.Errorf("сообщение о значении по умолчанию для qty выведено %d раз:\n%s", count, output)
+	}
+	if !strings.Contains(output, msg("insert.default_used", "created_at")) {
+		t.Errorf("нет сообщения о значении по умолчанию для created_at:\n%s", output)
+	}
+
+	// Введенное значение колонки со значением по умолчанию используется вместо него
+	captureOutput(t, func() {
+		insertData(scriptReader("1", "1", "Блок", "7", "2024-01-02 03:04:05"))
+	})
+	if got := queryString(t, "SELECT qty || ' ' || created_at FROM parts WHERE name = 'Блок'"); !strings.HasPrefix(got, "7 2024-01-02") {
+		t.Errorf("запись с введенными значениями: %q", got)
+	}
+}
@@ -0,0 +1,496 @@
+package osl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "modernc.org/sqlite"
+)
+
+// Запросы в программе пишутся с параметрами в стиле PostgreSQL ($1, $2, ...).
+// Перед выполнением они переводятся в синтаксис текущей СУБД функцией rebind,
+// поэтому построители запросов не зависят от выбранного драйвера.
+
+// Интерфейс особенностей конкретной СУБД
+type Dialect interface {
+	// Имя драйвера database/sql
+	DriverName() string
+	// Строка подключения из конфигурации
+	DSN(config DBConfig) string
+	// Параметр запроса с номером n (начиная с 1)
+	Placeholder(n int) string
+	// Поддерживает ли INSERT ... RETURNING id
+	SupportsReturning() bool
+	// Оператор сравнения с шаблоном без учета регистра
+	CaseInsensitiveLike() string
+	// Приведение значения выражения к тексту (для поиска подстроки в нетекстовых колонках)
+	TextCast(expr string) string
+	// Экранирование идентификатора (имени таблицы, колонки, правила сортировки)
+	QuoteIdent(name string) string
+	// Строковая константа SQL с экранированием
+	QuoteLiteral(value string) string
+	// Запрос внешних ключей всех схем: строки (схема, таблица, колонка, схема родителя,
+	// таблица-родитель, текущая схема)
+	ForeignKeysQuery() string
+	// Запрос существования таблицы по имени ($1): возвращает количество
+	TableExistsQuery() string
+	// Запрос имен таблиц, содержащих колонку с именем $1
+	TablesWithColumnQuery() string
+	// Запрос доступных правил сортировки
+	CollationsQuery() string
+	// Запрос структуры таблицы $1 в схеме $2 (пусто — текущая схема):
+	// имя, тип, допускает NULL, значение по умолчанию, входит в первичный ключ, заполняется СУБД
+	// (IDENTITY, AUTO_INCREMENT), максимальная длина строки, точность и масштаб десятичного числа (0 — не заданы)
+	ColumnsQuery() string
+	// Запрос схемы таблицы $1: схема и признак текущей схемы (пусто — СУБД без схем)
+	TableSchemaQuery() string
+	// Запрос версии сервера и имени текущей базы
+	ServerInfoQuery() string
+	// Префикс запроса плана выполнения (analyze — с фактическим выполнением, если СУБД это поддерживает)
+	ExplainPrefix(analyze bool) string
+	// Запрос создания индекса name по колонкам таблицы с условием where (пусто — индекс по всем строкам)
+	// без блокировки записи в таблицу, если СУБД это умеет. Пусто — СУБД не поддерживает такой индекс
+	CreateIndexQuery(table TableInfo, name string, columns []string, where string) string
+	// Запрос удаления индекса name таблицы
+	DropIndexQuery(table TableInfo, name string) string
+	// Запрос существования индекса $1 у таблицы $2 (имя без схемы): возвращает количество
+	IndexExistsQuery() string
+	// Запрос хода построения индекса таблицы $1: этап, обработано и всего блоков (пусто — не поддерживается)
+	IndexProgressQuery() string
+	// Запрос недействительных индексов с именем по шаблону LIKE $1: схема, таблица, индекс
+	// (пусто — после ошибки построения СУБД не оставляет недействительный индекс)
+	InvalidIndexesQuery() string
+}
+
+// Текущая СУБД (задается DB_DRIVER)
+var dialect Dialect = postgresDialect{}
+
+// Функция для выбора диалекта по имени драйвера
+func dialectFor(driver string) (Dialect, error) {
+	switch strings.ToLower(driver) {
+	case "", "postgres", "postgresql", "pg":
+		return postgresDialect{}, nil
+	case "sqlite", "sqlite3":
+		return sqliteDialect{}, nil
+	case "mysql":
+		return mysqlDialect{}, nil
+	}
+	return nil, fmt.Errorf("неизвестный драйвер БД: %s (поддерживаются postgres, sqlite, mysql)", driver)
+}
+
+// Функция для перевода параметров $n в синтаксис текущей СУБД.
+// Для СУБД с позиционными параметрами (?) аргументы переставляются в порядке появления,
+// поэтому один и тот же $n может встречаться в запросе несколько раз.
+// Строки, идентификаторы в кавычках и комментарии не меняются: $1 внутри них — не параметр.
+func rebind(query string, args []interface{}) (string, []interface{}) {
+	if _, ok := dialect.(postgresDialect); ok {
+		return query, args
+	}
+	// В MySQL обратная косая черта экранирует кавычку внутри строки ('it\'s')
+	_, backslashEscapes := dialect.(mysqlDialect)
+
+	var b strings.Builder
+	var newArgs []interface{}
+	for i := 0; i < len(query); i++ {
+		if end := skipQuotedSQL(query, i, backslashEscapes); end > i {
+			b.WriteString(query[i:end])
+			i = end - 1
+			continue
+		}
+		c := query[i]
+		if c != '$' {
+			b.WriteByte(c)
+			continue
+		}
+
+		j := i + 1
+		for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+			j++
+		}
+		n, err := strconv.Atoi(query[i+1 : j])
+		if err != nil || n < 1 || n > len(args) {
+			b.WriteByte(c)
+			continue
+		}
+		newArgs = append(newArgs, args[n-1])
+		b.WriteString(dialect.Placeholder(len(newArgs)))
+		i = j - 1
+	}
+	return b.String(), newArgs
+}
+
+// Функция для поиска конца строки ('...'), идентификатора в кавычках ("..." или `...`)
+// или комментария (-- и /* */), который начинается в позиции i запроса.
+// Возвращает i, если в этой позиции обычный текст; незакрытый фрагмент продолжается до конца запроса.
+func skipQuotedSQL(query string, i int, backslashEscapes bool) int {
+	rest := query[i:]
+	switch {
+	case strings.HasPrefix(rest, "--"):
+		if end := strings.IndexByte(rest, '\n'); end >= 0 {
+			return i + end + 1
+		}
+		return len(query)
+	case strings.HasPrefix(rest, "/*"):
+		if end := strings.Index(rest[2:], "*/"); end >= 0 {
+			return i + 2 + end + 2
+		}
+		return len(query)
+	}
+
+	quote := query[i]
+	if quote != '\'' && quote != '"' && quote != '`' {
+		return i
+	}
+	for j := i + 1; j < len(query); j++ {
+		switch {
+		case backslashEscapes && quote != '`' && query[j] == '\\':
+			j++
+		case query[j] == quote:
+			// Удвоенная кавычка внутри — сама кавычка, а не конец фрагмента
+			if j+1 < len(query) && query[j+1] == quote {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return len(query)
+}
+
+// PostgreSQL
+type postgresDialect struct{}
+
+func (postgresDialect) DriverName() string { return "postgres" }
+
+func (postgresDialect) DSN(config DBConfig) string {
+	dsn := fmt.Sprintf("host=%s port=%s dbname=%s user=%s password=%s sslmode=%s",
+		quoteDSNValue(config.Host), quoteDSNValue(config.Port), quoteDSNValue(config.Name),
+		quoteDSNValue(config.User), quoteDSNValue(config.Password), quoteDSNValue(config.SSLMode))
+	// Неизвестные драйверу параметры передаются серверу как настройки сеанса
+	if config.StatementTimeout > 0 {
+		dsn += fmt.Sprintf(" statement_timeout=%d", config.StatementTimeout.Milliseconds())
+	}
+	if config.SearchPath != "" {
+		dsn += " search_path=" + quoteDSNValue(config.SearchPath)
+	}
+	return dsn
+}
+
+// Функция для записи значения строки подключения libpq в кавычках: значения с пробелами,
+// запятыми и кавычками (пароль, search_path вида "a, b") иначе ломают разбор строки
+func quoteDSNValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return "'" + strings.ReplaceAll(value, "'", `\'`) + "'"
+}
+
+func (postgresDialect) Placeholder(n int) string { return fmt.Sprintf("$%d", n) }
+
+func (postgresDialect) SupportsReturning() bool { return true }
+
+func (postgresDialect) CaseInsensitiveLike() string { return "ILIKE" }
+
+func (postgresDialect) TextCast(expr string) string { return expr + "::text" }
+
+func (postgresDialect) QuoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (postgresDialect) QuoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func (postgresDialect) ForeignKeysQuery() string {
+	return `SELECT kcu.table_schema, kcu.table_name, kcu.column_name, ccu.table_schema, ccu.table_name, current_schema()
+		FROM information_schema.table_constraints tc
+		JOIN information_schema.key_column_usage kcu
+		  ON kcu.constraint_name = tc.constraint_name AND kcu.table_schema = tc.table_schema
+		JOIN information_schema.constraint_column_usage ccu
+		  ON ccu.constraint_name = tc.constraint_name AND ccu.constraint_schema = tc.constraint_schema
+		WHERE tc.constraint_type = 'FOREIGN KEY' AND tc.table_schema NOT IN ('pg_catalog', 'information_schema')`
+}
+
+func (postgresDialect) TableExistsQuery() string {
+	return `SELECT COUNT(*) FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_name = $1`
+}
+
+func (postgresDialect) TablesWithColumnQuery() string {
+	return `SELECT table_name FROM information_schema.columns
+		WHERE table_schema = current_schema() AND column_name = $1`
+}
+
+func (postgresDialect) CollationsQuery() string {
+	return `SELECT collname FROM pg_collation
+		WHERE collencoding IN (-1, (SELECT encoding FROM pg_database WHERE datname = current_database()))
+		ORDER BY collname`
+}
+
+func (postgresDialect) ColumnsQuery() string {
+	return `SELECT c.column_name, c.data_type, c.is_nullable = 'YES', COALESCE(c.column_default, ''),
+			EXISTS (SELECT 1 FROM information_schema.table_constraints tc
+				JOIN information_schema.key_column_usage kcu
+				  ON kcu.constraint_name = tc.constraint_name AND kcu.table_schema = tc.table_schema
+				WHERE tc.constraint_type = 'PRIMARY KEY' AND tc.table_schema = c.table_schema
+				  AND tc.table_name = c.table_name AND kcu.column_name = c.column_name),
+			c.is_identity = 'YES', COALESCE(c.character_maximum_length, 0),
+			COALESCE(CASE WHEN c.numeric_precision_radix = 10 THEN c.numeric_precision END, 0),
+			COALESCE(CASE WHEN c.numeric_precision_radix = 10 THEN c.numeric_scale END, 0)
+		FROM information_schema.columns c
+		WHERE c.table_schema = COALESCE(NULLIF($2, ''), current_schema()) AND c.table_name = $1
+		ORDER BY c.ordinal_position`
+}
+
+// Таблица ищется сначала в текущей схеме, затем в остальных схемах пути поиска и прочих схемах
+func (postgresDialect) TableSchemaQuery() string {
+	return `SELECT table_schema, table_schema = current_schema() FROM information_schema.tables
+		WHERE table_name = $1
+		ORDER BY table_schema = current_schema() DESC,
+			table_schema = ANY(current_schemas(false)) DESC, table_schema
+		LIMIT 1`
+}
+
+func (postgresDialect) ServerInfoQuery() string {
+	return `SELECT version(), current_database()`
+}
+
+func (postgresDialect) ExplainPrefix(analyze bool) string {
+	if analyze {
+		return "EXPLAIN ANALYZE"
+	}
+	return "EXPLAIN"
+}
+
+// CONCURRENTLY не блокирует запись, но выполняется только вне транзакции, а при ошибке
+// оставляет недействительный индекс, который нужно удалить (см. InvalidIndexesQuery)
+func (d postgresDialect) CreateIndexQuery(table TableInfo, name string, columns []string, where string) string {
+	query := fmt.Sprintf("CREATE INDEX CONCURRENTLY IF NOT EXISTS %s ON %s (%s)", d.QuoteIdent(name), table.SQLName(), columnList(columns))
+	if where != "" {
+		query += " WHERE " + where
+	}
+	return query
+}
+
+// Индекс находится в схеме своей таблицы
+func (d postgresDialect) DropIndexQuery(table TableInfo, name string) string {
+	schema, _ := table.schemaAndName()
+	if schema == "" {
+		return "DROP INDEX CONCURRENTLY IF EXISTS " + d.QuoteIdent(name)
+	}
+	return fmt.Sprintf("DROP INDEX CONCURRENTLY IF EXISTS %s.%s", d.QuoteIdent(schema), d.QuoteIdent(name))
+}
+
+func (postgresDialect) IndexExistsQuery() string {
+	return `SELECT COUNT(*) FROM pg_indexes WHERE indexname = $1 AND tablename = $2`
+}
+
+func (postgresDialect) IndexProgressQuery() string {
+	return `SELECT phase, blocks_done, blocks_total FROM pg_stat_progress_create_index WHERE relid = to_regclass($1)`
+}
+
+func (postgresDialect) InvalidIndexesQuery() string {
+	return `SELECT n.nspname, t.relname, c.relname
+		FROM pg_index i
+		JOIN pg_class c ON c.oid = i.indexrelid
+		JOIN pg_class t ON t.oid = i.indrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE NOT i.indisvalid AND c.relname LIKE $1
+		ORDER BY n.nspname, c.relname`
+}
+
+// SQLite (файл базы задается DB_NAME)
+type sqliteDialect struct{}
+
+func (sqliteDialect) DriverName() string { return "sqlite" }
+
+func (sqliteDialect) DSN(config DBConfig) string { return config.Name }
+
+func (sqliteDialect) Placeholder(n int) string { return "?" }
+
+func (sqliteDialect) SupportsReturning() bool { return false }
+
+// В SQLite LIKE не учитывает регистр (для латиницы)
+func (sqliteDialect) CaseInsensitiveLike() string { return "LIKE" }
+
+func (sqliteDialect) TextCast(expr string) string { return "CAST(" + expr + " AS TEXT)" }
+
+func (sqliteDialect) QuoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (sqliteDialect) QuoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func (sqliteDialect) ForeignKeysQuery() string {
+	// Схемы SQLite — основная и подключенные (ATTACH) базы; внешний ключ ссылается на таблицу той же базы
+	return `SELECT t.schema, t.name, p."from", t.schema, p."table", 'main'
+		FROM pragma_table_list t JOIN pragma_foreign_key_list(t.name, t.schema) p
+		WHERE t.type = 'table'`
+}
+
+func (sqliteDialect) TableExistsQuery() string {
+	return `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = $1`
+}
+
+func (sqliteDialect) TablesWithColumnQuery() string {
+	return `SELECT m.name FROM sqlite_master m JOIN pragma_table_info(m.name) p
+		WHERE m.type = 'table' AND p.name = $1`
+}
+
+func (sqliteDialect) CollationsQuery() string {
+	return `SELECT name FROM pragma_collation_list ORDER BY name`
+}
+
+func (sqliteDialect) ColumnsQuery() string {
+	// Длина и точность в SQLite не хранятся отдельно и берутся из имени типа, например VARCHAR(100)
+	return `SELECT name, type, "notnull" = 0, COALESCE(dflt_value, ''), pk > 0, 0, 0, 0, 0
+		FROM pragma_table_info($1, NULLIF($2, '')) ORDER BY cid`
+}
+
+func (sqliteDialect) TableSchemaQuery() string { return "" }
+
+func (sqliteDialect) ServerInfoQuery() string {
+	return `SELECT 'SQLite ' || sqlite_version(), file FROM pragma_database_list WHERE name = 'main'`
+}
+
+// SQLite не выполняет запрос при получении плана, поэтому analyze не учитывается
+func (sqliteDialect) ExplainPrefix(analyze bool) string { return "EXPLAIN QUERY PLAN" }
+
+// В SQLite схема указывается у имени индекса, а таблица — без схемы
+func (d sqliteDialect) CreateIndexQuery(table TableInfo, name string, columns []string, where string) string {
+	schema, tableName := table.schemaAndName()
+	if schema != "" {
+		name = schema + "." + name
+	}
+	query := fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (%s)", d.quoteQualified(name), d.QuoteIdent(tableName), columnList(columns))
+	if where != "" {
+		query += " WHERE " + where
+	}
+	return query
+}
+
+func (d sqliteDialect) DropIndexQuery(table TableInfo, name string) string {
+	if schema, _ := table.schemaAndName(); schema != "" {
+		name = schema + "." + name
+	}
+	return "DROP INDEX IF EXISTS " + d.quoteQualified(name)
+}
+
+// Функция для экранирования имени вида "схема.имя"
+func (d sqliteDialect) quoteQualified(name string) string {
+	if schema, rest, found := strings.Cut(name, "."); found {
+		return d.QuoteIdent(schema) + "." + d.QuoteIdent(rest)
+	}
+	return d.QuoteIdent(name)
+}
+
+func (sqliteDialect) IndexExistsQuery() string {
+	return `SELECT COUNT(*) FROM pragma_index_list($2) WHERE name = $1`
+}
+
+func (sqliteDialect) IndexProgressQuery() string { return "" }
+
+// Индекс создается в транзакции: при ошибке он не остается в базе
+func (sqliteDialect) InvalidIndexesQuery() string { return "" }
+
+// MySQL
+type mysqlDialect struct{}
+
+func (mysqlDialect) DriverName() string { return "mysql" }
+
+func (mysqlDialect) DSN(config DBConfig) string {
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
+		config.User, config.Password, config.Host, config.Port, config.Name)
+	// В MySQL ограничение действует только на SELECT
+	if config.StatementTimeout > 0 {
+		dsn += fmt.Sprintf("&max_execution_time=%d", config.StatementTimeout.Milliseconds())
+	}
+	return dsn
+}
+
+func (mysqlDialect) Placeholder(n int) string { return "?" }
+
+func (mysqlDialect) SupportsReturning() bool { return false }
+
+// В MySQL регистр не учитывается при сравнении строк в стандартных правилах сортировки (*_ci)
+func (mysqlDialect) CaseInsensitiveLike() string { return "LIKE" }
+
+func (mysqlDialect) TextCast(expr string) string { return "CAST(" + expr + " AS CHAR)" }
+
+func (mysqlDialect) QuoteIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// В MySQL обратная косая черта в строке тоже служит экранированием
+func (mysqlDialect) QuoteLiteral(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", "''").Replace(value) + "'"
+}
+
+func (mysqlDialect) ForeignKeysQuery() string {
+	return `SELECT table_schema, table_name, column_name, referenced_table_schema, referenced_table_name, DATABASE()
+		FROM information_schema.key_column_usage
+		WHERE referenced_table_name IS NOT NULL`
+}
+
+func (mysqlDialect) TableExistsQuery() string {
+	return `SELECT COUNT(*) FROM information_schema.tables
+		WHERE table_schema = DATABASE() AND table_name = $1`
+}
+
+func (mysqlDialect) TablesWithColumnQuery() string {
+	return `SELECT table_name FROM information_schema.columns
+		WHERE table_schema = DATABASE() AND column_name = $1`
+}
+
+func (mysqlDialect) CollationsQuery() string {
+	return `SELECT collation_name FROM information_schema.collations ORDER BY collation_name`
+}
+
+func (mysqlDialect) ColumnsQuery() string {
+	return `SELECT column_name, column_type, is_nullable = 'YES', COALESCE(column_default, ''), column_key = 'PRI',
+			extra LIKE '%auto_increment%', COALESCE(character_maximum_length, 0),
+			IF(data_type IN ('decimal', 'numeric'), numeric_precision, 0),
+			IF(data_type IN ('decimal', 'numeric'), numeric_scale, 0)
+		FROM information_schema.columns
+		WHERE table_schema = DATABASE() AND table_name = $1
+		ORDER BY ordinal_position`
+}
+
+// Схемы таблиц не поддерживаются (в MySQL схема — это сама база данных)
+func (mysqlDialect) TableSchemaQuery() string { return "" }
+
+func (mysqlDialect) ServerInfoQuery() string {
+	return `SELECT CONCAT('MySQL ', version()), DATABASE()`
+}
+
+func (mysqlDialect) ExplainPrefix(analyze bool) string {
+	if analyze {
+		return "EXPLAIN ANALYZE"
+	}
+	return "EXPLAIN"
+}
+
+// Частичных индексов в MySQL нет; ALGORITHM=INPLACE, LOCK=NONE строит индекс без блокировки записи
+func (d mysqlDialect) CreateIndexQuery(table TableInfo, name string, columns []string, where string) string {
+	if where != "" {
+		return ""
+	}
+	return fmt.Sprintf("CREATE INDEX %s ON %s (%s) ALGORITHM=INPLACE LOCK=NONE", d.QuoteIdent(name), table.SQLName(), columnList(columns))
+}
+
+func (d mysqlDialect) DropIndexQuery(table TableInfo, name string) string {
+	return fmt.Sprintf("DROP INDEX %s ON %s", d.QuoteIdent(name), table.SQLName())
+}
+
+func (mysqlDialect) IndexExistsQuery() string {
+	return `SELECT COUNT(DISTINCT index_name) FROM information_schema.statistics
+		WHERE table_schema = DATABASE() AND index_name = $1 AND table_name = $2`
+}
+
+// Ход построения индекса доступен только через performance_schema, которая часто отключена
+func (mysqlDialect) IndexProgressQuery() string { return "" }
+
+// Ошибка построения индекса откатывает его целиком
+func (mysqlDialect) InvalidIndexesQuery() string { return "" }
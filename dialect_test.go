@@ -0,0 +1,85 @@
+package osl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		query    string
+		args     []interface{}
+		want     string
+		wantArgs []interface{}
+	}{
+		{"postgres без изменений", postgresDialect{}, "SELECT * FROM t WHERE a = $2 AND b = $1",
+			[]interface{}{1, 2}, "SELECT * FROM t WHERE a = $2 AND b = $1", []interface{}{1, 2}},
+		{"порядок появления", sqliteDialect{}, "SELECT * FROM t WHERE a = $2 AND b = $1",
+			[]interface{}{1, 2}, "SELECT * FROM t WHERE a = ? AND b = ?", []interface{}{2, 1}},
+		{"повтор параметра", sqliteDialect{}, "UPDATE t SET a = a - $1 WHERE id = $2 AND a >= $1",
+			[]interface{}{5, 7}, "UPDATE t SET a = a - ? WHERE id = ? AND a >= ?", []interface{}{5, 7, 5}},
+		{"строка", sqliteDialect{}, "SELECT '$1' || name FROM t WHERE id = $1",
+			[]interface{}{3}, "SELECT '$1' || name FROM t WHERE id = ?", []interface{}{3}},
+		{"удвоенная кавычка в строке", sqliteDialect{}, "SELECT 'it''s $1' WHERE id = $1",
+			[]interface{}{3}, "SELECT 'it''s $1' WHERE id = ?", []interface{}{3}},
+		{"идентификатор в кавычках", sqliteDialect{}, `SELECT "cost$1" FROM t WHERE id = $1`,
+			[]interface{}{3}, `SELECT "cost$1" FROM t WHERE id = ?`, []interface{}{3}},
+		{"строчный комментарий", sqliteDialect{}, "SELECT a -- не $1\nFROM t WHERE id = $1",
+			[]interface{}{3}, "SELECT a -- не $1\nFROM t WHERE id = ?", []interface{}{3}},
+		{"блочный комментарий", sqliteDialect{}, "SELECT /* $2 */ a FROM t WHERE id = $1",
+			[]interface{}{3}, "SELECT /* $2 */ a FROM t WHERE id = ?", []interface{}{3}},
+		{"mysql: экранированная кавычка", mysqlDialect{}, `SELECT 'it\'s $1' FROM t WHERE id = $1`,
+			[]interface{}{3}, `SELECT 'it\'s $1' FROM t WHERE id = ?`, []interface{}{3}},
+		{"mysql: обратные кавычки", mysqlDialect{}, "SELECT `a$1` FROM t WHERE id = $1",
+			[]interface{}{3}, "SELECT `a$1` FROM t WHERE id = ?", []interface{}{3}},
+		{"sqlite: обратная косая черта не экранирует", sqliteDialect{}, `SELECT 'a\' WHERE id = $1`,
+			[]interface{}{3}, `SELECT 'a\' WHERE id = ?`, []interface{}{3}},
+		{"номер вне аргументов", sqliteDialect{}, "SELECT $3, $1",
+			[]interface{}{1}, "SELECT $3, ?", []interface{}{1}},
+	}
+	saved := dialect
+	defer func() { dialect = saved }()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dialect = tt.dialect
+			got, gotArgs := rebind(tt.query, tt.args)
+			if got != tt.want || !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("rebind(%q) = %q %v, ожидалось %q %v", tt.query, got, gotArgs, tt.want, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestPostgresDSNQuoting(t *testing.T) {
+	config := DBConfig{Host: "db", Port: "5432", Name: "pc components", User: "admin",
+		Password: `p@ss 'w\rd`, SSLMode: "disable", SearchPath: "sales, public"}
+	dsn := postgresDialect{}.DSN(config)
+	want := `host='db' port='5432' dbname='pc components' user='admin' password='p@ss \'w\\rd' sslmode='disable' search_path='sales, public'`
+	if dsn != want {
+		t.Fatalf("DSN = %s\nожидалось %s", dsn, want)
+	}
+	// Драйвер должен разобрать строку подключения без соединения с сервером
+	if _, err := pq.NewConnector(dsn); err != nil {
+		t.Fatalf("строка подключения не разобрана драйвером: %v", err)
+	}
+	if redacted := redactSecrets(dsn); redacted != `host='db' port='5432' dbname='pc components' user='admin' password=*** sslmode='disable' search_path='sales, public'` {
+		t.Errorf("пароль не скрыт: %s", redacted)
+	}
+}
+
+func TestSQLiteColumnTypesWithoutSize(t *testing.T) {
+	openSchema(t, []string{"parts"},
+		"CREATE TABLE parts (id INTEGER PRIMARY KEY, name varchar(100), price NUMERIC(10, 2), note TEXT)")
+	want := map[string]string{"id": "INTEGER", "name": "VARCHAR", "price": "NUMERIC", "note": "TEXT"}
+	if !reflect.DeepEqual(tables[0].Types, want) {
+		t.Errorf("типы колонок %v, ожидалось %v", tables[0].Types, want)
+	}
+	details := tables[0].Details
+	if len(details) != 4 || details[1].MaxLength != 100 || details[2].Precision != 10 || details[2].Scale != 2 {
+		t.Errorf("ограничения колонок из объявленного типа не прочитаны: %+v", details)
+	}
+}
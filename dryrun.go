@@ -0,0 +1,69 @@
+package osl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// В режиме проверки (dry-run) изменяющие запросы строятся и выводятся вместе с параметрами,
+// но не отправляются в БД; чтение выполняется как обычно. Записи журнала помечаются [DRY-RUN].
+
+// Режим проверки: начальное значение из OSL_DRY_RUN, переключается в меню
+var dryRun = envBool("OSL_DRY_RUN", false)
+
+// Метка режима проверки в выводе и журнале
+const dryRunTag = "[DRY-RUN]"
+
+// Функция для вывода изменяющего запроса вместо его выполнения.
+// Значения параметров показываются отдельно (чувствительные — замаскированными), в запрос они не подставляются.
+func printDryRun(query string, columns []string, args []interface{}) {
+	masked := maskParams(columns, args)
+	fmt.Fprintln(stdout, msg("dry_run.not_executed", dryRunTag, query))
+	if len(masked) > 0 {
+		params := make([]string, len(masked))
+		for i, value := range masked {
+			params[i] = fmt.Sprintf("$%d = '%v'", i+1, value)
+		}
+		fmt.Fprintln(stdout, msg("dry_run.params", strings.Join(params, ", ")))
+	}
+	logToFileAndScreen(fmt.Sprintf("%s Запрос не выполнен: %s с параметрами %v", dryRunTag, query, masked))
+}
+
+// Функция для вывода записей, которые затронуло бы обновление
+func printDryRunPreview(table string, ids []string) {
+	if len(ids) == 0 {
+		return
+	}
+	key, err := tableKeyRef(table)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("%s Ошибка предварительного просмотра: %v", dryRunTag, err))
+		return
+	}
+	where, args := inListCondition(tableKeyColumn(table), ids, 1)
+	query := fmt.Sprintf("SELECT * FROM %s WHERE %s ORDER BY %s", tableRef(table), where, key)
+
+	rows, err := dbQuery(query, args...)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("%s Ошибка предварительного просмотра: %v", dryRunTag, err))
+		return
+	}
+	rs, err := scanRows(rows)
+	rows.Close()
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("%s Ошибка предварительного просмотра: %v", dryRunTag, err))
+		return
+	}
+	fmt.Fprint(stdout, msg("dry_run.preview", dryRunTag, query))
+	printResult(rs)
+}
+
+// Функция для переключения режима проверки из меню
+func toggleDryRun() {
+	dryRun = !dryRun
+	if dryRun {
+		fmt.Fprintln(stdout, msg("dry_run.on"))
+	} else {
+		fmt.Fprintln(stdout, msg("dry_run.off"))
+	}
+	logToFileAndScreen(fmt.Sprintf("Режим проверки (dry-run): %s", yesNo(dryRun)))
+}
@@ -0,0 +1,231 @@
+package osl
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Генерация DDL по структуре, обнаруженной в каталоге БД (TableInfo.Details).
+// Используется при клонировании базы и для выгрузки схемы.
+// Здесь же выгрузка данных таблицы в SQL-файл из INSERT для быстрой резервной копии.
+
+// Функция для упорядочивания таблиц так, чтобы таблица шла после тех, на которые ссылается
+func tablesInDependencyOrder(list []TableInfo) []TableInfo {
+	byName := make(map[string]TableInfo, len(list))
+	for _, table := range list {
+		byName[table.Name] = table
+	}
+
+	var ordered []TableInfo
+	visited := make(map[string]bool)
+	var visit func(table TableInfo)
+	visit = func(table TableInfo) {
+		if visited[table.Name] {
+			return
+		}
+		visited[table.Name] = true
+		for _, column := range table.Columns {
+			if parent, ok := byName[foreignKeyTarget(table, column)]; ok {
+				visit(parent)
+			}
+		}
+		ordered = append(ordered, table)
+	}
+	for _, table := range list {
+		visit(table)
+	}
+	return ordered
+}
+
+// Функция для проверки, заполняется ли колонка последовательностью (serial)
+func isSerialColumn(column ColumnInfo) bool {
+	return strings.HasPrefix(strings.ToLower(column.Default), "nextval(")
+}
+
+// Функция для получения типа колонки в DDL
+func columnDDLType(column ColumnInfo) string {
+	if isSerialColumn(column) {
+		if strings.EqualFold(column.Type, "bigint") {
+			return "BIGSERIAL"
+		}
+		return "SERIAL"
+	}
+	return strings.ToUpper(column.Type)
+}
+
+// Функция для построения CREATE TABLE по структуре таблицы
+func createTableSQL(table TableInfo) (string, error) {
+	if len(table.Details) == 0 {
+		return "", fmt.Errorf("структура таблицы %s не загружена", table.Name)
+	}
+
+	var lines, primaryKey []string
+	for _, column := range table.Details {
+		line := fmt.Sprintf("    %s %s", dialect.QuoteIdent(column.Name), columnDDLType(column))
+		if !column.Nullable {
+			line += " NOT NULL"
+		}
+		if column.Default != "" && !isSerialColumn(column) {
+			line += " DEFAULT " + column.Default
+		}
+		lines = append(lines, line)
+		if column.IsPK {
+			primaryKey = append(primaryKey, dialect.QuoteIdent(column.Name))
+		}
+	}
+	if len(primaryKey) > 0 {
+		lines = append(lines, fmt.Sprintf("    PRIMARY KEY (%s)", strings.Join(primaryKey, ", ")))
+	}
+	for _, column := range table.Details {
+		if column.FKTarget != "" {
+			lines = append(lines, fmt.Sprintf("    FOREIGN KEY (%s) REFERENCES %s (id)",
+				dialect.QuoteIdent(column.Name), dialect.QuoteIdent(column.FKTarget)))
+		}
+	}
+	return fmt.Sprintf("CREATE TABLE %s (\n%s\n)", dialect.QuoteIdent(table.Name), strings.Join(lines, ",\n")), nil
+}
+
+// Пункт 22: Выгрузка таблицы в SQL-файл
+func dumpTable(reader *bufio.Reader) {
+	tableIndex := selectTable(reader, msg("select_table.dump"))
+	if tableIndex == -1 {
+		return
+	}
+	table := tables[tableIndex]
+
+	defaultPath := table.Name + ".sql"
+	path, ok := promptString(reader, msg("export.path_prompt", defaultPath))
+	if !ok {
+		return
+	}
+	if path == "" {
+		path = defaultPath
+	}
+	truncate := promptConfirm(reader, msg("dump.truncate_prompt", table.Name))
+
+	logToFileAndScreen(fmt.Sprintf("Выгрузка таблицы %s в SQL-файл %s (очистка перед вставкой: %v)", table.Name, path, truncate))
+	count, err := writeTableDump(table, path, truncate)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка выгрузки таблицы %s в %s: %v", table.Name, path, err))
+		printError(msg("dump.failed"))
+		return
+	}
+	fmt.Fprintln(stdout, msg("dump.done", count, path))
+	logToFileAndScreen(fmt.Sprintf("Выгрузка таблицы %s завершена: %d записей в %s", table.Name, count, path))
+}
+
+// Функция для записи данных таблицы в SQL-файл: строки читаются и записываются по одной,
+// без накопления в памяти. Файл пишется во временный и переименовывается после успешной выгрузки.
+func writeTableDump(table TableInfo, path string, truncate bool) (int, error) {
+	file, err := os.Create(path + ".partial")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(path + ".partial")
+	defer file.Close()
+	w := bufio.NewWriter(file)
+
+	fmt.Fprintf(w, "-- Выгрузка таблицы %s, %s\n", table.Name, time.Now().Format("2006-01-02 15:04:05"))
+	if truncate {
+		fmt.Fprintln(w, truncateStatement(table))
+	}
+
+	order := ""
+	if pk := primaryKeyColumn(table); pk != "" {
+		order = " ORDER BY " + dialect.QuoteIdent(pk)
+	}
+	rows, err := dbQuery(fmt.Sprintf("SELECT %s FROM %s%s", columnList(table.Columns), table.SQLName(), order))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	prefix := fmt.Sprintf("INSERT INTO %s (%s) VALUES (", table.SQLName(), columnList(table.Columns))
+	values := make([]interface{}, len(table.Columns))
+	valuePtrs := make([]interface{}, len(table.Columns))
+	literals := make([]string, len(table.Columns))
+	count := 0
+	for rows.Next() {
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return count, err
+		}
+		for i, value := range values {
+			literals[i] = sqlLiteral(value, table.Types[table.Columns[i]])
+		}
+		if _, err := fmt.Fprintf(w, "%s%s);\n", prefix, strings.Join(literals, ", ")); err != nil {
+			return count, err
+		}
+		count++
+		if count%1000 == 0 {
+			fmt.Fprint(stdout, msg("export.progress", count))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+
+	if err := w.Flush(); err != nil {
+		return count, err
+	}
+	if err := file.Close(); err != nil {
+		return count, err
+	}
+	return count, os.Rename(path+".partial", path)
+}
+
+// Функция для получения команды очистки таблицы (в SQLite нет TRUNCATE)
+func truncateStatement(table TableInfo) string {
+	if _, ok := dialect.(sqliteDialect); ok {
+		return fmt.Sprintf("DELETE FROM %s;", table.SQLName())
+	}
+	return fmt.Sprintf("TRUNCATE TABLE %s;", table.SQLName())
+}
+
+// Функция для записи значения колонки как константы SQL: NULL, число, логическое значение
+// или строка в кавычках с экранированием по правилам текущей СУБД
+func sqlLiteral(value interface{}, dbType string) string {
+	dbType = strings.ToUpper(dbType)
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		return strings.ToUpper(strconv.FormatBool(v))
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		switch dbType {
+		case "DATE":
+			return dialect.QuoteLiteral(v.Format("2006-01-02"))
+		case "TIMESTAMPTZ":
+			return dialect.QuoteLiteral(v.Format("2006-01-02 15:04:05.999999-07:00"))
+		}
+		return dialect.QuoteLiteral(v.Format("2006-01-02 15:04:05.999999"))
+	case []byte:
+		switch {
+		case dbType == "BYTEA":
+			return dialect.QuoteLiteral("\\x" + hex.EncodeToString(v))
+		case strings.HasSuffix(dbType, "BLOB"):
+			return "X'" + hex.EncodeToString(v) + "'"
+		case numericTypes[dbType] && isPlainNumber(string(v)):
+			return string(v)
+		}
+		return dialect.QuoteLiteral(string(v))
+	}
+	return dialect.QuoteLiteral(fmt.Sprintf("%v", value))
+}
+
+// Функция для проверки, что значение можно записать числом без кавычек
+func isPlainNumber(value string) bool {
+	_, err := strconv.ParseFloat(value, 64)
+	return err == nil && !strings.ContainsAny(value, "xXnN")
+}
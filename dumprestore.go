@@ -0,0 +1,474 @@
+package osl
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Восстановление таблиц из файлов выгрузки: SQL-файла из INSERT (пункт 22) или CSV (пункт 8).
+// Перед выполнением показываются первые запросы и имя текущей базы, чтобы не восстановить
+// выгрузку не туда. SQL-файл проверяется целиком до начала транзакции: допускаются только
+// INSERT в известные таблицы и очистка таблицы, которую выгрузка пишет в начало файла.
+
+// Количество запросов или строк, показываемых перед подтверждением
+const restorePreviewSize = 5
+
+// Запрос из SQL-файла выгрузки
+type dumpStatement struct {
+	Line  int    // номер строки файла, с которой начинается запрос
+	Text  string // запрос без завершающей точки с запятой
+	Table string // таблица, к которой относится запрос
+}
+
+// Пункт 23: Восстановление из файла выгрузки
+func restoreDump(reader *bufio.Reader) {
+	path, ok := promptValidated(reader, msg("dump_restore.path_prompt"), func(input string) error {
+		if input == "" {
+			return errors.New(msg("dump_restore.path_required"))
+		}
+		if _, err := os.Stat(input); err != nil {
+			return errors.New(msg("dump_restore.file_missing", input))
+		}
+		return nil
+	})
+	if !ok {
+		return
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".sql":
+		restoreSQLDump(reader, path)
+	case ".csv":
+		restoreCSVDump(reader, path)
+	default:
+		printError(msg("dump_restore.unknown_format"))
+	}
+}
+
+// Функция для восстановления SQL-выгрузки одной транзакцией
+func restoreSQLDump(reader *bufio.Reader, path string) {
+	// Проверка всего файла до подтверждения: ошибка сообщается с номером строки
+	var preview []dumpStatement
+	total := 0
+	err := readDumpStatements(path, func(statement dumpStatement) error {
+		if len(preview) < restorePreviewSize {
+			preview = append(preview, statement)
+		}
+		total++
+		return nil
+	})
+	if err != nil {
+		reportRestoreError(path, err)
+		return
+	}
+	if total == 0 {
+		printError(msg("dump_restore.no_statements"))
+		return
+	}
+
+	fmt.Fprintln(stdout, msg("dump_restore.preview_title", total, activeConfig.Name))
+	for _, statement := range preview {
+		fmt.Fprintln(stdout, msg("dump_restore.preview_line", statement.Line, truncateCell(statement.Text, 200)))
+	}
+	if !promptConfirm(reader, msg("dump_restore.confirm", total, activeConfig.Name)) {
+		fmt.Fprintln(stdout, msg("input.cancelled"))
+		return
+	}
+	if dryRun {
+		fmt.Fprintln(stdout, msg("dump_restore.dry_run", dryRunTag, total))
+		return
+	}
+
+	logToFileAndScreen(fmt.Sprintf("Восстановление из SQL-выгрузки %s в базу %s: %d запросов", path, activeConfig.Name, total))
+	start := time.Now()
+	var perTable map[string]int
+	executed := 0
+	err = dbTransaction(func(tx *sql.Tx) error {
+		// Файл читается внутри транзакции, чтобы при повторе после обрыва соединения начать сначала
+		perTable, executed = make(map[string]int), 0
+		return readDumpStatements(path, func(statement dumpStatement) error {
+			if _, err := txExec(tx, statement.Text); err != nil {
+				return &importLineError{Line: statement.Line, Err: err}
+			}
+			perTable[statement.Table]++
+			executed++
+			if executed%progressInterval() == 0 {
+				fmt.Fprint(stdout, msg("dump_restore.progress", executed, total))
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		reportRestoreError(path, err)
+		return
+	}
+
+	forgetUndo(fmt.Sprintf("выполнено восстановление из %s", path))
+	elapsed := time.Since(start).Round(time.Millisecond)
+	summary := describeTableCounts(perTable)
+	fmt.Fprintln(stdout, msg("dump_restore.done", executed, elapsed))
+	fmt.Fprintln(stdout, msg("dump_restore.summary", summary))
+	logToFileAndScreen(fmt.Sprintf("Восстановление из %s завершено: %d запросов за %s (%s)", path, executed, elapsed, summary))
+}
+
+// Функция для вывода ошибки восстановления (с номером строки, если он известен)
+func reportRestoreError(path string, err error) {
+	logToFileAndScreen(fmt.Sprintf("Ошибка восстановления из %s, изменения отменены: %v", path, err))
+	var lineErr *importLineError
+	if errors.As(err, &lineErr) {
+		printError(msg("dump_restore.failed_line", lineErr.Line, lineErr.Err))
+	} else {
+		printError(msg("common.error", err))
+	}
+	fmt.Fprintln(stdout, msg("dump_restore.rolled_back"))
+}
+
+// Функция для описания количества запросов по таблицам: "components: 10, stock: 5"
+func describeTableCounts(counts map[string]int) string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s: %d", name, counts[name])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Функция для последовательного чтения запросов SQL-выгрузки без загрузки файла в память.
+// Запрос может занимать несколько строк (перевод строки внутри значения); комментарии -- и пустые
+// строки между запросами пропускаются. Каждый запрос проверяется перед передачей в fn.
+func readDumpStatements(path string, fn func(dumpStatement) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	lineReader := bufio.NewReader(file)
+	var current strings.Builder
+	startLine := 0
+	for line := 1; ; line++ {
+		text, readErr := lineReader.ReadString('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return readErr
+		}
+		if text == "" && readErr != nil {
+			break
+		}
+
+		if current.Len() == 0 {
+			trimmed := strings.TrimSpace(text)
+			if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+				if readErr != nil {
+					break
+				}
+				continue
+			}
+			startLine = line
+		}
+		current.WriteString(text)
+
+		statement := strings.TrimSpace(current.String())
+		if _, unterminated := scanStatement(statement); strings.HasSuffix(statement, ";") && !unterminated {
+			current.Reset()
+			parsed, err := parseDumpStatement(strings.TrimSpace(strings.TrimSuffix(statement, ";")))
+			if err != nil {
+				return &importLineError{Line: startLine, Err: err}
+			}
+			parsed.Line = startLine
+			if err := fn(parsed); err != nil {
+				return err
+			}
+		}
+		if readErr != nil {
+			break
+		}
+	}
+	if current.Len() > 0 {
+		return &importLineError{Line: startLine, Err: errors.New(msg("dump_restore.unterminated"))}
+	}
+	return nil
+}
+
+// Функция для проверки запроса выгрузки: INSERT в известную таблицу с одними константами
+// в VALUES или очистка таблицы в том виде, в каком ее пишет выгрузка
+func parseDumpStatement(text string) (dumpStatement, error) {
+	if separators, _ := scanStatement(text); len(separators) > 0 {
+		return dumpStatement{}, errors.New(msg("sql.single_statement"))
+	}
+	for _, table := range tables {
+		if text+";" == truncateStatement(table) {
+			return dumpStatement{Text: text, Table: table.Name}, nil
+		}
+	}
+
+	keyword := firstKeyword(text)
+	if keyword != "INSERT" {
+		return dumpStatement{}, errors.New(msg("dump_restore.not_insert", keyword))
+	}
+	target, err := parseDumpInsert(text)
+	if err != nil {
+		return dumpStatement{}, err
+	}
+	for _, table := range tables {
+		if target == table.SQLName() || target == table.Name {
+			return dumpStatement{Text: text, Table: table.Name}, nil
+		}
+	}
+	return dumpStatement{}, errors.New(msg("dump_restore.unknown_table", target))
+}
+
+// Разбор запроса INSERT из выгрузки. Допускается только вид
+// INSERT INTO таблица [(колонки)] VALUES (...)[, (...)] без продолжения после списка строк:
+// подзапросы, ON CONFLICT, RETURNING и выражения в значениях отклоняются.
+type dumpInsertScanner struct {
+	text             string
+	pos              int
+	backslashEscapes bool
+}
+
+// Функция для проверки запроса INSERT; возвращает имя таблицы в том виде, в каком оно записано
+func parseDumpInsert(text string) (string, error) {
+	_, backslashEscapes := dialect.(mysqlDialect)
+	s := &dumpInsertScanner{text: text, backslashEscapes: backslashEscapes}
+	if !s.keyword("INSERT") {
+		return "", errors.New(msg("dump_restore.not_insert", firstKeyword(text)))
+	}
+	if !s.keyword("INTO") {
+		return "", s.malformed()
+	}
+
+	s.skipSpace()
+	start, end := s.pos, s.pos
+	for {
+		if !s.identifier() {
+			return "", s.malformed()
+		}
+		end = s.pos
+		if !s.symbol('.') {
+			break
+		}
+	}
+	target := text[start:end]
+
+	if s.symbol('(') {
+		for {
+			if !s.identifier() {
+				return "", s.malformed()
+			}
+			if s.symbol(')') {
+				break
+			}
+			if !s.symbol(',') {
+				return "", s.malformed()
+			}
+		}
+	}
+	if !s.keyword("VALUES") {
+		return "", s.malformed()
+	}
+	for {
+		if !s.symbol('(') {
+			return "", s.malformed()
+		}
+		for {
+			if !s.literal() {
+				return "", s.malformed()
+			}
+			if s.symbol(')') {
+				break
+			}
+			if !s.symbol(',') {
+				return "", s.malformed()
+			}
+		}
+		if !s.symbol(',') {
+			break
+		}
+	}
+	s.skipSpace()
+	if s.pos < len(text) {
+		return "", s.malformed()
+	}
+	return target, nil
+}
+
+// Функция для ошибки разбора с фрагментом запроса, на котором разбор остановился
+func (s *dumpInsertScanner) malformed() error {
+	s.skipSpace()
+	return errors.New(msg("dump_restore.bad_insert", truncateCell(s.text[s.pos:], 40)))
+}
+
+// Функция для пропуска пробелов и комментариев
+func (s *dumpInsertScanner) skipSpace() {
+	for s.pos < len(s.text) {
+		switch {
+		case s.text[s.pos] == ' ' || s.text[s.pos] == '\t' || s.text[s.pos] == '\n' || s.text[s.pos] == '\r':
+			s.pos++
+		case strings.HasPrefix(s.text[s.pos:], "--") || strings.HasPrefix(s.text[s.pos:], "/*"):
+			s.pos = skipQuotedSQL(s.text, s.pos, s.backslashEscapes)
+		default:
+			return
+		}
+	}
+}
+
+// Функция для чтения слова из букв, цифр и подчеркиваний
+func (s *dumpInsertScanner) word() string {
+	s.skipSpace()
+	end := s.pos
+	for end < len(s.text) && isWordByte(s.text[end]) {
+		end++
+	}
+	return s.text[s.pos:end]
+}
+
+// Функция для пропуска ключевого слова (без учета регистра), если оно следующее в запросе
+func (s *dumpInsertScanner) keyword(keyword string) bool {
+	if word := s.word(); strings.EqualFold(word, keyword) {
+		s.pos += len(word)
+		return true
+	}
+	return false
+}
+
+// Функция для пропуска символа, если он следующий в запросе
+func (s *dumpInsertScanner) symbol(c byte) bool {
+	s.skipSpace()
+	if s.pos < len(s.text) && s.text[s.pos] == c {
+		s.pos++
+		return true
+	}
+	return false
+}
+
+// Функция для пропуска имени: слова или идентификатора в кавычках
+func (s *dumpInsertScanner) identifier() bool {
+	s.skipSpace()
+	if s.pos < len(s.text) && (s.text[s.pos] == '"' || s.text[s.pos] == '`') {
+		s.pos = skipQuotedSQL(s.text, s.pos, s.backslashEscapes)
+		return true
+	}
+	word := s.word()
+	if word == "" || strings.EqualFold(word, "VALUES") || strings.EqualFold(word, "SELECT") {
+		return false
+	}
+	s.pos += len(word)
+	return true
+}
+
+// Функция для пропуска константы в том виде, в каком ее пишет выгрузка:
+// NULL, TRUE/FALSE, число, строка в кавычках или X'...' для двоичных данных
+func (s *dumpInsertScanner) literal() bool {
+	s.skipSpace()
+	if s.pos >= len(s.text) {
+		return false
+	}
+	switch c := s.text[s.pos]; {
+	case c == '\'':
+		s.pos = skipQuotedSQL(s.text, s.pos, s.backslashEscapes)
+		return true
+	case (c == 'X' || c == 'x') && strings.HasPrefix(s.text[s.pos+1:], "'"):
+		s.pos = skipQuotedSQL(s.text, s.pos+1, false)
+		return true
+	case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+		end := s.pos + 1
+		for end < len(s.text) && (isWordByte(s.text[end]) || s.text[end] == '.' ||
+			((s.text[end] == '-' || s.text[end] == '+') && (s.text[end-1] == 'e' || s.text[end-1] == 'E'))) {
+			end++
+		}
+		if !isPlainNumber(s.text[s.pos:end]) {
+			return false
+		}
+		s.pos = end
+		return true
+	}
+	for _, keyword := range []string{"NULL", "TRUE", "FALSE"} {
+		if s.keyword(keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// Функция для проверки символа слова SQL
+func isWordByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// Функция для восстановления CSV-выгрузки: предпросмотр первых строк и импорт с проверкой полей
+func restoreCSVDump(reader *bufio.Reader, path string) {
+	tableIndex := selectTable(reader, msg("select_table.import"))
+	if tableIndex == -1 {
+		return
+	}
+	table := tables[tableIndex]
+
+	if err := previewCSVDump(table, path); err != nil {
+		reportRestoreError(path, err)
+		return
+	}
+	if !promptConfirm(reader, msg("dump_restore.confirm_csv", table.Name, activeConfig.Name)) {
+		fmt.Fprintln(stdout, msg("input.cancelled"))
+		return
+	}
+	runImport(table, path)
+}
+
+// Функция для вывода первых строк CSV-файла в виде запросов INSERT (с проверкой, как при импорте)
+func previewCSVDump(table TableInfo, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	csvReader := csv.NewReader(file)
+	csvReader.TrimLeadingSpace = true
+	header, err := csvReader.Read()
+	if errors.Is(err, io.EOF) {
+		return errors.New(msg("import.empty_file"))
+	}
+	if err != nil {
+		return &importLineError{Line: 1, Err: err}
+	}
+	columns, positions, err := importColumns(table, header)
+	if err != nil {
+		return &importLineError{Line: 1, Err: err}
+	}
+
+	fmt.Fprintln(stdout, msg("dump_restore.preview_csv", restorePreviewSize, table.Name, activeConfig.Name))
+	for i := 0; i < restorePreviewSize; i++ {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := csvReader.FieldPos(0)
+		if err != nil {
+			return &importLineError{Line: line, Err: err}
+		}
+		values, err := importValues(table, columns, positions, record)
+		if err != nil {
+			return &importLineError{Line: line, Err: err}
+		}
+		literals := make([]string, len(values))
+		for j, value := range values {
+			literals[j] = sqlLiteral(value, table.Types[columns[j]])
+		}
+		text := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table.SQLName(), columnList(columns), strings.Join(literals, ", "))
+		fmt.Fprintln(stdout, msg("dump_restore.preview_line", line, truncateCell(text, 200)))
+	}
+	return nil
+}
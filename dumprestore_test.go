@@ -0,0 +1,92 @@
+package osl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Выгрузка таблицы восстанавливается из собственного файла
+func TestRestoreOwnDump(t *testing.T) {
+	openSchema(t, []string{"parts"},
+		`CREATE TABLE parts (id INTEGER PRIMARY KEY, name TEXT, price NUMERIC, photo BLOB, active BOOLEAN)`,
+		`INSERT INTO parts VALUES (1, 'Кулер; тихий -- 2 шт', 10.5, X'00ff', 1),
+			(2, 'Блок ''питания''', -3, NULL, 0), (3, NULL, 1e3, NULL, NULL)`)
+	assumeYes = true
+
+	path := filepath.Join(t.TempDir(), "parts.sql")
+	if _, err := writeTableDump(tables[0], path, true); err != nil {
+		t.Fatalf("writeTableDump: %v", err)
+	}
+	before := queryString(t, "SELECT group_concat(id || ':' || COALESCE(name, '') || ':' || COALESCE(price, '') || ':' || COALESCE(hex(photo), ''), '|') FROM parts")
+	mustExec(t, "UPDATE parts SET name = 'испорчено'")
+
+	output := captureOutput(t, func() { restoreSQLDump(scriptReader("да"), path) })
+	after := queryString(t, "SELECT group_concat(id || ':' || COALESCE(name, '') || ':' || COALESCE(price, '') || ':' || COALESCE(hex(photo), ''), '|') FROM parts")
+	if after != before {
+		t.Errorf("после восстановления %q, ожидалось %q:\n%s", after, before, output)
+	}
+}
+
+// В выгрузке допускаются только INSERT ... VALUES с константами
+func TestParseDumpStatement(t *testing.T) {
+	openSchema(t, []string{"parts"}, "CREATE TABLE parts (id INTEGER PRIMARY KEY, name TEXT)")
+
+	accepted := []string{
+		`INSERT INTO "parts" ("id", "name") VALUES (1, 'a')`,
+		`insert into parts values (1, 'a'), (2, NULL), (-3.5e2, TRUE)`,
+		`INSERT INTO parts (id, name) VALUES (1, 'x''); DROP TABLE parts; --')`,
+		"INSERT INTO parts /* комментарий */ (id, name)\nVALUES (1, X'00ff') -- конец",
+		`DELETE FROM "parts"`,
+	}
+	for _, text := range accepted {
+		statement, err := parseDumpStatement(text)
+		if err != nil || statement.Table != "parts" {
+			t.Errorf("parseDumpStatement(%q) = %+v, %v", text, statement, err)
+		}
+	}
+
+	rejected := []string{
+		`UPDATE parts SET name = 'a'`,
+		`DELETE FROM parts WHERE id = 1`,
+		`INSERT INTO parts SELECT * FROM parts`,
+		`INSERT INTO parts (id, name) SELECT id, name FROM parts`,
+		`INSERT INTO parts VALUES ((SELECT MAX(id) FROM parts), 'a')`,
+		`INSERT INTO parts VALUES (1, 'a') ON CONFLICT DO NOTHING`,
+		`INSERT INTO parts VALUES (1, 'a') RETURNING id`,
+		`INSERT INTO parts DEFAULT VALUES`,
+		`INSERT INTO parts VALUES (1, lower('A'))`,
+		`INSERT INTO parts VALUES (1, 'a'), (2, 'b') , `,
+		`INSERT INTO parts VALUES (0x10, 'a')`,
+		`INSERT OR REPLACE INTO parts VALUES (1, 'a')`,
+		`INSERT INTO other VALUES (1, 'a')`,
+		`INSERT INTO parts VALUES (1, 'a'); DELETE FROM parts`,
+	}
+	for _, text := range rejected {
+		if statement, err := parseDumpStatement(text); err == nil {
+			t.Errorf("parseDumpStatement(%q) принят: %+v", text, statement)
+		}
+	}
+}
+
+// Запрещенный запрос в середине файла останавливает восстановление до начала транзакции
+func TestRestoreRejectsSelect(t *testing.T) {
+	openSchema(t, []string{"parts"},
+		"CREATE TABLE parts (id INTEGER PRIMARY KEY, name TEXT)",
+		"INSERT INTO parts VALUES (1, 'Кулер')")
+	assumeYes = true
+
+	path := filepath.Join(t.TempDir(), "parts.sql")
+	dump := "DELETE FROM \"parts\";\nINSERT INTO parts VALUES (2, 'a');\nINSERT INTO parts SELECT id + 10, name FROM parts;\n"
+	if err := os.WriteFile(path, []byte(dump), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	output := captureOutput(t, func() { restoreSQLDump(scriptReader("да"), path) })
+	if !strings.Contains(output, "строка 3") {
+		t.Errorf("ошибка без номера строки:\n%s", output)
+	}
+	if got := queryString(t, "SELECT group_concat(name) FROM parts"); got != "Кулер" {
+		t.Errorf("данные изменены: %q", got)
+	}
+}
@@ -0,0 +1,142 @@
+package osl
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Перед добавлением записи проверяется, нет ли в таблице записи с тем же значением
+// колонки (например, name) без учета регистра. Проверка выполняется только
+// в интерактивном режиме: без пользователя решать, что делать с совпадением, некому.
+
+// Интерактивный сеанс (главное меню); команды командной строки проверку дубликатов не выполняют
+var interactive bool
+
+// Действия при найденных дубликатах
+const (
+	duplicateProceed = iota // добавить новую запись
+	duplicateReuse          // использовать существующую запись
+	duplicateCancel         // отменить добавление
+)
+
+// Функция для получения колонок проверки дубликатов по таблицам.
+// OSL_DUPLICATE_CHECK задает список вида "таблица.колонка" через запятую ("-" — не проверять);
+// по умолчанию проверяется колонка name во всех таблицах, где она есть.
+func duplicateCheckColumns() map[string]string {
+	columns := make(map[string]string)
+	value := envString("OSL_DUPLICATE_CHECK", "")
+	if value == "-" {
+		return columns
+	}
+	if value == "" {
+		for _, table := range tables {
+			if containsString(table.Columns, "name") {
+				columns[table.Name] = "name"
+			}
+		}
+		return columns
+	}
+	for _, part := range strings.Split(value, ",") {
+		table, column, found := strings.Cut(strings.TrimSpace(part), ".")
+		if !found || table == "" || column == "" {
+			log.Printf("Некорректное значение в OSL_DUPLICATE_CHECK: %q, ожидается таблица.колонка", part)
+			continue
+		}
+		columns[table] = column
+	}
+	return columns
+}
+
+// Функция для поиска записей с тем же значением колонки без учета регистра
+func findDuplicates(table TableInfo, column, value string) ([]lookupItem, error) {
+	key, err := tableKeyRef(table.Name)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf("SELECT %[1]s, %[2]s FROM %[3]s WHERE lower(%[2]s) = lower($1) ORDER BY %[1]s", key, dialect.QuoteIdent(column), table.SQLName())
+	rows, err := dbQuery(query, value)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []lookupItem
+	for rows.Next() {
+		var item lookupItem
+		if err := rows.Scan(&item.ID, &item.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// Функция для проверки записи на дубликаты перед добавлением.
+// При совпадении пользователь выбирает: добавить все равно, использовать существующую
+// запись (если allowReuse) или отменить. Возвращает действие и id выбранной существующей записи.
+func confirmDuplicates(reader *bufio.Reader, table TableInfo, columns []string, values []interface{}, allowReuse bool) (int, string) {
+	if !interactive {
+		return duplicateProceed, ""
+	}
+	column := duplicateCheckColumns()[table.Name]
+	if column == "" {
+		return duplicateProceed, ""
+	}
+	value := ""
+	for i, name := range columns {
+		if name == column && i < len(values) {
+			value = fmt.Sprint(values[i])
+		}
+	}
+	if value == "" {
+		return duplicateProceed, ""
+	}
+
+	duplicates, err := findDuplicates(table, column, value)
+	if err != nil {
+		// Проверка дубликатов не должна мешать добавлению
+		logToFileAndScreen(fmt.Sprintf("Ошибка проверки дубликатов в %s.%s: %v", table.Name, column, err))
+		return duplicateProceed, ""
+	}
+	if len(duplicates) == 0 {
+		return duplicateProceed, ""
+	}
+
+	fmt.Fprintln(stdout, msg("duplicates.found", table.Name, column, displayParam(column, value)))
+	for _, item := range duplicates {
+		fmt.Fprintln(stdout, msg("duplicates.item", item.ID, displayParam(column, item.Name)))
+	}
+	logToFileAndScreen(fmt.Sprintf("Найдены похожие записи в %s (%s): %d", table.Name, column, len(duplicates)))
+
+	fmt.Fprintln(stdout, msg("duplicates.proceed"))
+	maxChoice := 1
+	if allowReuse {
+		fmt.Fprintln(stdout, msg("duplicates.reuse"))
+		maxChoice = 2
+	}
+	fmt.Fprintln(stdout, msg("duplicates.cancel"))
+	choice, ok := promptInt(reader, msg("duplicates.prompt"), 0, maxChoice)
+	if !ok || choice == 0 {
+		return duplicateCancel, ""
+	}
+	if choice == 1 {
+		logToFileAndScreen(fmt.Sprintf("Добавление в %s несмотря на похожие записи", table.Name))
+		return duplicateProceed, ""
+	}
+
+	existing := duplicates[0]
+	if len(duplicates) > 1 {
+		for i, item := range duplicates {
+			fmt.Fprintf(stdout, "%d. id=%s: %s\n", i+1, item.ID, displayParam(column, item.Name))
+		}
+		fmt.Fprintln(stdout, msg("common.back"))
+		choice, ok := promptInt(reader, msg("duplicates.pick"), 0, len(duplicates))
+		if !ok || choice == 0 {
+			return duplicateCancel, ""
+		}
+		existing = duplicates[choice-1]
+	}
+	return duplicateReuse, existing.ID
+}
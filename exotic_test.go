@@ -0,0 +1,144 @@
+package osl
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// Драйвер, который на любой запрос возвращает строки с «экзотическими» типами PostgreSQL
+// (point, tsvector) — в SQLite таких типов в результате не бывает
+type exoticDriver struct{}
+
+type exoticConn struct{}
+
+type exoticStmt struct{}
+
+type exoticRows struct {
+	pos int
+}
+
+// Колонки и строки результата фиктивного драйвера
+var (
+	exoticColumns = []string{"id", "location", "doc"}
+	exoticTypes   = []string{"INT4", "POINT", "TSVECTOR"}
+	exoticData    = [][]driver.Value{
+		{int64(1), []byte("(1.5,2)"), []byte("'кулер':1 'тихий':2")},
+		{int64(2), nil, nil},
+	}
+)
+
+func init() {
+	sql.Register("exotic", exoticDriver{})
+}
+
+func (exoticDriver) Open(string) (driver.Conn, error) { return exoticConn{}, nil }
+
+func (exoticConn) Prepare(string) (driver.Stmt, error) { return exoticStmt{}, nil }
+func (exoticConn) Close() error                        { return nil }
+func (exoticConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("транзакции не поддерживаются")
+}
+
+func (exoticStmt) Close() error  { return nil }
+func (exoticStmt) NumInput() int { return -1 }
+func (exoticStmt) Exec([]driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+func (exoticStmt) Query([]driver.Value) (driver.Rows, error) { return &exoticRows{}, nil }
+
+func (r *exoticRows) Columns() []string { return exoticColumns }
+func (r *exoticRows) Close() error      { return nil }
+func (r *exoticRows) Next(dest []driver.Value) error {
+	if r.pos >= len(exoticData) {
+		return io.EOF
+	}
+	copy(dest, exoticData[r.pos])
+	r.pos++
+	return nil
+}
+func (r *exoticRows) ColumnTypeDatabaseTypeName(index int) string { return exoticTypes[index] }
+
+// Колонки типов, которые форматтер не умеет отображать, выводятся заглушкой, исходное
+// значение доступно при просмотре строки и в выгрузке, а ввод таких колонок пропускается
+func TestExoticTypesRenderAsPlaceholder(t *testing.T) {
+	openTestDB(t)
+	db.Close()
+	var err error
+	db, err = sql.Open("exotic", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rs, err := queryRecords("SELECT id, location, doc FROM shapes")
+	if err != nil {
+		t.Fatalf("queryRecords: %v", err)
+	}
+	if strings.Join(rs.Types, ",") != "INT4,POINT,TSVECTOR" {
+		t.Fatalf("типы колонок %v", rs.Types)
+	}
+	if !rs.hasUnrenderable() {
+		t.Error("hasUnrenderable = false для колонок point и tsvector")
+	}
+	for _, tt := range []struct {
+		row, col int
+		want     string
+	}{
+		{0, 0, "1"},
+		{0, 1, "<тип point>"},
+		{0, 2, "<тип tsvector>"},
+		// NULL не заменяется заглушкой
+		{1, 1, ""},
+		{1, 2, ""},
+	} {
+		if got := rs.displayValue(tt.row, tt.col); got != tt.want {
+			t.Errorf("displayValue(%d, %d) = %q, ожидалось %q", tt.row, tt.col, got, tt.want)
+		}
+	}
+	if rs.Rows[0][1] != "(1.5,2)" {
+		t.Errorf("исходное значение point %q", rs.Rows[0][1])
+	}
+
+	output := captureOutput(t, func() {
+		printResult(rs)
+	})
+	if !strings.Contains(output, "<тип point>") || strings.Contains(output, "(1.5,2)") {
+		t.Errorf("вывод результата:\n%s", output)
+	}
+	output = captureOutput(t, func() {
+		offerRawDetails(scriptReader("1"), rs)
+	})
+	for _, want := range []string{"location (point): (1.5,2)", "doc (tsvector): 'кулер':1 'тихий':2"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("при просмотре строки нет %q:\n%s", want, output)
+		}
+	}
+
+	// В выгрузку попадает исходное значение, а не заглушка
+	var buf strings.Builder
+	writer := &csvExportWriter{w: csv.NewWriter(&buf), columns: exoticColumns}
+	if err := writer.WriteRow([]interface{}{int64(1), []byte("(1.5,2)"), nil}); err != nil {
+		t.Fatal(err)
+	}
+	writer.Flush()
+	if got := buf.String(); got != "1,\"(1.5,2)\",\n" {
+		t.Errorf("строка выгрузки %q", got)
+	}
+
+	table := TableInfo{Name: "shapes", Columns: exoticColumns,
+		Types: map[string]string{"id": "INT4", "location": "POINT", "doc": "TSVECTOR"}}
+	var columns []string
+	output = captureOutput(t, func() {
+		columns = editableColumns(table, table.Columns)
+	})
+	if strings.Join(columns, ",") != "id" {
+		t.Errorf("колонки для ввода %v", columns)
+	}
+	if !strings.Contains(output, msg("schema.unsupported_type", "location", "point")) {
+		t.Errorf("нет пояснения о пропуске колонки point:\n%s", output)
+	}
+}
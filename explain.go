@@ -0,0 +1,52 @@
+package osl
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// План выполнения запроса просмотра или фильтрации. Запрос берется тот же, что был выполнен,
+// с теми же параметрами, поэтому план отражает реальные значения фильтра.
+// EXPLAIN ANALYZE (с фактическим выполнением запроса) включается OSL_EXPLAIN_ANALYZE=1.
+
+// Функция для предложения показать план выполненного запроса (Enter — не показывать)
+func offerExplain(reader *bufio.Reader, query string, args []interface{}, argColumns []string) {
+	input, ok := promptValidated(reader, msg("explain.prompt"), func(input string) error {
+		if input == "" {
+			return nil
+		}
+		if _, ok := parseYesNo(input); !ok {
+			return errors.New(msg("input.yes_no"))
+		}
+		return nil
+	})
+	if !ok {
+		return
+	}
+	if show, _ := parseYesNo(input); !show {
+		return
+	}
+	printExplain(query, args, argColumns)
+}
+
+// Функция для вывода плана выполнения запроса
+func printExplain(query string, args []interface{}, argColumns []string) {
+	analyze := envBool("OSL_EXPLAIN_ANALYZE", false)
+	ctx, cancel := context.WithTimeout(context.Background(), envDuration("OSL_SQL_TIMEOUT", 30*time.Second))
+	defer cancel()
+
+	prefix := dialect.ExplainPrefix(analyze)
+	logToFileAndScreen(fmt.Sprintf("Выполнение %s: %s с параметрами %v", prefix, query, maskParams(argColumns, args)))
+	boundQuery, boundArgs := rebind(query, args)
+	plan, err := explainQuery(ctx, boundQuery, boundArgs, analyze)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка получения плана запроса: %v", err))
+		printError(msg("explain.failed"))
+		return
+	}
+	fmt.Fprintln(stdout, msg("explain.title", prefix))
+	fmt.Fprintln(stdout, plan)
+}
@@ -0,0 +1,418 @@
+package osl
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+// Структура с описанием выгрузки
+type ExportSpec struct {
+	Table   string   `json:"table"`
+	Columns []string `json:"columns"`
+	Format  string   `json:"format"` // csv или json
+	Path    string   `json:"path"`
+}
+
+// Структура токена для продолжения прерванной выгрузки
+type ExportToken struct {
+	Spec     ExportSpec `json:"spec"`
+	LastKey  string     `json:"last_key"`  // ключ последней выгруженной записи
+	RowCount int        `json:"row_count"` // число строк в частичном файле
+}
+
+// Интерфейс записи строк выгрузки в файл
+type exportWriter interface {
+	WriteHeader() error
+	WriteRow(values []interface{}) error
+	Flush() error
+	Finish() error
+}
+
+// Функция для получения пути к частичному файлу выгрузки
+func partialPath(spec ExportSpec) string {
+	return spec.Path + ".partial"
+}
+
+// Функция для получения пути к токену продолжения выгрузки
+func tokenPath(spec ExportSpec) string {
+	return spec.Path + ".resume.json"
+}
+
+// Функция для загрузки токена продолжения выгрузки
+func loadExportToken(path string) (ExportToken, error) {
+	var token ExportToken
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return token, err
+	}
+	if err := json.Unmarshal(data, &token); err != nil {
+		return token, fmt.Errorf("некорректный токен %s: %w", path, err)
+	}
+	return token, nil
+}
+
+// Функция для сохранения токена продолжения выгрузки
+func saveExportToken(token ExportToken) error {
+	data, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(tokenPath(token.Spec), data, 0644)
+}
+
+// Пункт 8: Экспорт таблицы
+func exportTable(reader *bufio.Reader) {
+	tableIndex := selectTable(reader, msg("select_table.export"))
+	if tableIndex == -1 {
+		return
+	}
+	table := tables[tableIndex]
+
+	fmt.Fprintln(stdout, msg("export.format_title"))
+	fmt.Fprintln(stdout, "1. CSV")
+	fmt.Fprintln(stdout, "2. JSON")
+	fmt.Fprintln(stdout, msg("common.back"))
+	formatChoice, ok := promptInt(reader, msg("export.format_prompt"), 0, 2)
+	if !ok || formatChoice == 0 {
+		return
+	}
+	format := "csv"
+	if formatChoice == 2 {
+		format = "json"
+	}
+
+	defaultPath := fmt.Sprintf("%s.%s", table.Name, format)
+	path, ok := promptString(reader, msg("export.path_prompt", defaultPath))
+	if !ok {
+		return
+	}
+	if path == "" {
+		path = defaultPath
+	}
+
+	token := ExportToken{Spec: ExportSpec{Table: table.Name, Columns: table.Columns, Format: format, Path: path}}
+
+	// Если по этому пути осталась прерванная выгрузка, предлагаем продолжить
+	if saved, err := loadExportToken(tokenPath(token.Spec)); err == nil {
+		prompt := msg("export.resume_prompt", partialPath(saved.Spec), saved.RowCount)
+		if promptConfirm(reader, prompt) {
+			token = saved
+		} else {
+			os.Remove(partialPath(saved.Spec))
+			os.Remove(tokenPath(saved.Spec))
+		}
+	}
+
+	runExportWithSignals(token)
+}
+
+// Функция для выполнения выгрузки с отменой по Ctrl+C
+func runExportWithSignals(token ExportToken) error {
+	ctx, stop := signal.NotifyContext(sessionCtx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logToFileAndScreen(fmt.Sprintf("Экспорт таблицы %s в %s (%s), продолжение после ключа %q",
+		token.Spec.Table, token.Spec.Path, token.Spec.Format, token.LastKey))
+	fmt.Fprintln(stdout, msg("export.started"))
+
+	result, err := runExport(ctx, token)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка экспорта таблицы %s: %v", token.Spec.Table, err))
+		if result.RowCount > 0 {
+			fmt.Fprintln(stdout, msg("export.interrupted", result.RowCount, partialPath(result.Spec)))
+			fmt.Fprintln(stdout, msg("export.resume_hint", tokenPath(result.Spec)))
+		}
+		return err
+	}
+
+	fmt.Fprintln(stdout, msg("export.done", result.RowCount, result.Spec.Path))
+	logToFileAndScreen(fmt.Sprintf("Экспорт таблицы %s завершен: %d записей в %s",
+		result.Spec.Table, result.RowCount, result.Spec.Path))
+	return nil
+}
+
+// Функция для выгрузки таблицы постранично по первичному ключу с записью во временный файл.
+// При успехе файл атомарно переименовывается, при ошибке или отмене сохраняется токен продолжения.
+func runExport(ctx context.Context, token ExportToken) (ExportToken, error) {
+	spec := token.Spec
+	key := tableKeyColumn(spec.Table)
+	keyIndex := -1
+	for i, column := range spec.Columns {
+		if key != "" && column == key {
+			keyIndex = i
+		}
+	}
+	if keyIndex == -1 {
+		return token, errors.New("для постраничной выгрузки нужна колонка первичного ключа из одной колонки")
+	}
+
+	var file *os.File
+	var err error
+	resuming := token.RowCount > 0
+	if resuming && token.LastKey == "" {
+		return token, errors.New("в токене продолжения нет ключа последней записи")
+	}
+	if resuming {
+		// Перед дозаписью убеждаемся, что частичный файл соответствует токену
+		count, err := countPartialRows(spec)
+		if err != nil {
+			return token, fmt.Errorf("не удалось проверить частичный файл: %w", err)
+		}
+		if count != token.RowCount {
+			return token, fmt.Errorf("в частичном файле %d строк, а в токене %d", count, token.RowCount)
+		}
+		file, err = os.OpenFile(partialPath(spec), os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return token, err
+		}
+	} else {
+		file, err = os.Create(partialPath(spec))
+		if err != nil {
+			return token, err
+		}
+	}
+
+	writer := newExportWriter(spec, file, token.RowCount)
+	if !resuming {
+		if err := writer.WriteHeader(); err != nil {
+			file.Close()
+			return token, err
+		}
+	}
+
+	// При любой ошибке сохраняем записанное и токен для продолжения
+	fail := func(cause error) (ExportToken, error) {
+		if err := writer.Flush(); err != nil {
+			cause = fmt.Errorf("%v; ошибка записи: %w", cause, err)
+		}
+		file.Close()
+		if err := saveExportToken(token); err != nil {
+			cause = fmt.Errorf("%v; токен не сохранен: %w", cause, err)
+		}
+		return token, cause
+	}
+
+	pageSize := envInt("OSL_EXPORT_PAGE_SIZE", 1000)
+	// Первая страница выбирается без условия: пустая строка несравнима с числовым ключом
+	firstQuery := fmt.Sprintf("SELECT %[1]s FROM %[2]s ORDER BY %[3]s LIMIT $1",
+		columnList(spec.Columns), tableRef(spec.Table), dialect.QuoteIdent(key))
+	nextQuery := fmt.Sprintf("SELECT %[1]s FROM %[2]s WHERE %[3]s > $1 ORDER BY %[3]s LIMIT $2",
+		columnList(spec.Columns), tableRef(spec.Table), dialect.QuoteIdent(key))
+
+	for {
+		var rows *sql.Rows
+		if token.LastKey == "" {
+			rows, err = dbQueryContext(ctx, firstQuery, pageSize)
+		} else {
+			rows, err = dbQueryContext(ctx, nextQuery, token.LastKey, pageSize)
+		}
+		if err != nil {
+			return fail(err)
+		}
+
+		pageRows := 0
+		values := make([]interface{}, len(spec.Columns))
+		valuePtrs := make([]interface{}, len(spec.Columns))
+		for rows.Next() {
+			if ctx.Err() != nil {
+				break
+			}
+			for i := range values {
+				valuePtrs[i] = &values[i]
+			}
+			if err := rows.Scan(valuePtrs...); err != nil {
+				rows.Close()
+				return fail(err)
+			}
+			if err := writer.WriteRow(values); err != nil {
+				rows.Close()
+				return fail(err)
+			}
+			token.LastKey = formatRawValue(values[keyIndex], "")
+			token.RowCount++
+			pageRows++
+		}
+		rowsErr := rows.Err()
+		rows.Close()
+
+		if ctx.Err() != nil {
+			return fail(errors.New("экспорт прерван пользователем"))
+		}
+		if rowsErr != nil {
+			return fail(rowsErr)
+		}
+
+		// После каждой страницы файл и токен согласованы
+		if err := writer.Flush(); err != nil {
+			return fail(err)
+		}
+		fmt.Fprint(stdout, msg("export.progress", token.RowCount))
+
+		if pageRows < pageSize {
+			break
+		}
+	}
+
+	if err := writer.Finish(); err != nil {
+		return fail(err)
+	}
+	if err := file.Close(); err != nil {
+		return token, err
+	}
+	if err := os.Rename(partialPath(spec), spec.Path); err != nil {
+		return token, err
+	}
+	os.Remove(tokenPath(spec))
+	return token, nil
+}
+
+// Функция для подсчета строк данных в частичном файле выгрузки
+func countPartialRows(spec ExportSpec) (int, error) {
+	file, err := os.Open(partialPath(spec))
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	if spec.Format == "json" {
+		// Частичный файл не содержит закрывающей скобки массива
+		decoder := json.NewDecoder(io.MultiReader(file, strings.NewReader("\n]")))
+		if _, err := decoder.Token(); err != nil {
+			return 0, err
+		}
+		count := 0
+		for decoder.More() {
+			var element json.RawMessage
+			if err := decoder.Decode(&element); err != nil {
+				return count, err
+			}
+			count++
+		}
+		return count, nil
+	}
+
+	csvReader := csv.NewReader(file)
+	count := 0
+	for {
+		_, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	return count - 1, nil // без строки заголовка
+}
+
+// Функция для создания писателя выгрузки нужного формата
+func newExportWriter(spec ExportSpec, file *os.File, written int) exportWriter {
+	if spec.Format == "json" {
+		return &jsonExportWriter{w: bufio.NewWriter(file), columns: spec.Columns, count: written}
+	}
+	return &csvExportWriter{w: csv.NewWriter(file), columns: spec.Columns}
+}
+
+// Запись выгрузки в CSV
+type csvExportWriter struct {
+	w       *csv.Writer
+	columns []string
+}
+
+func (c *csvExportWriter) WriteHeader() error {
+	return c.w.Write(c.columns)
+}
+
+func (c *csvExportWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = formatRawValue(value, "")
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvExportWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvExportWriter) Finish() error {
+	return c.Flush()
+}
+
+// Запись выгрузки в JSON-массив объектов. Закрывающая скобка пишется только в Finish,
+// поэтому частичный файл можно продолжить дописывать.
+type jsonExportWriter struct {
+	w       *bufio.Writer
+	columns []string
+	count   int
+}
+
+func (j *jsonExportWriter) WriteHeader() error {
+	_, err := j.w.WriteString("[\n")
+	return err
+}
+
+func (j *jsonExportWriter) WriteRow(values []interface{}) error {
+	var b strings.Builder
+	if j.count > 0 {
+		b.WriteString(",\n")
+	}
+	b.WriteString("{")
+	for i, value := range values {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		key, _ := json.Marshal(j.columns[i])
+		b.Write(key)
+		b.WriteString(": ")
+		encoded, err := json.Marshal(jsonExportValue(value))
+		if err != nil {
+			return err
+		}
+		b.Write(encoded)
+	}
+	b.WriteString("}")
+	if _, err := j.w.WriteString(b.String()); err != nil {
+		return err
+	}
+	j.count++
+	return nil
+}
+
+func (j *jsonExportWriter) Flush() error {
+	return j.w.Flush()
+}
+
+func (j *jsonExportWriter) Finish() error {
+	if _, err := j.w.WriteString("\n]\n"); err != nil {
+		return err
+	}
+	return j.w.Flush()
+}
+
+// Функция для преобразования значения драйвера в значение JSON
+func jsonExportValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case int64, float64, bool:
+		return v
+	default:
+		return formatRawValue(v, "")
+	}
+}
@@ -0,0 +1,409 @@
+package osl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Формат ввода дат в фильтрах
+const dateInputLayout = "2006-01-02"
+
+// Условие фильтра по одной колонке
+type FilterCondition struct {
+	Column string
+	Type   int      // filterEquals, filterInList, filterRange, filterIsNull или filterNotNull
+	Values []string // значение; список значений; нижняя и верхняя граница; для NULL — пусто
+}
+
+// Описание фильтрации: по нему строится запрос (в том числе при повторе из истории)
+type FilterSpec struct {
+	Table           string
+	Conditions      []FilterCondition
+	Operator        string   // " AND " или " OR "
+	Columns         []string // колонки для вывода
+	IncludeArchived bool     // показывать архивные записи (для таблиц с мягким удалением)
+}
+
+// Функция для определения колонки с датой или временем по типу в БД
+func isDateColumn(table TableInfo, column string) bool {
+	switch strings.ToUpper(table.Types[column]) {
+	case "DATE", "TIMESTAMP", "TIMESTAMPTZ", "DATETIME":
+		return true
+	}
+	return false
+}
+
+// Функция для определения колонки с датой и временем суток (а не только датой)
+func isTimestampColumn(table TableInfo, column string) bool {
+	return isDateColumn(table, column) && !strings.EqualFold(table.Types[column], "DATE")
+}
+
+// Функция для проверки, поддерживает ли колонка фильтр по диапазону
+func supportsRangeFilter(table TableInfo, column string) bool {
+	return isNumericColumn(table, column) || isDateColumn(table, column)
+}
+
+// Функция для проверки границы диапазона (пустая граница означает отсутствие ограничения)
+func validateRangeBound(table TableInfo, column, value string) error {
+	if value == "" {
+		return nil
+	}
+	if isDateColumn(table, column) {
+		_, err := parseTimeInput(table, column, value)
+		return err
+	}
+	if _, err := strconv.ParseFloat(value, 64); err != nil {
+		return errors.New(msg("filter.bound_not_number", column))
+	}
+	return nil
+}
+
+// Функция для преобразования границы в параметр запроса
+func rangeBoundValue(table TableInfo, column, value string) interface{} {
+	if isDateColumn(table, column) {
+		date, _ := parseTimeInput(table, column, value)
+		return date
+	}
+	return value
+}
+
+// Функция для проверки, что нижняя граница не больше верхней
+func checkRangeOrder(table TableInfo, column, lower, upper string) error {
+	if lower == "" || upper == "" {
+		return nil
+	}
+	if isDateColumn(table, column) {
+		lowerDate, _ := parseTimeInput(table, column, lower)
+		upperDate, _ := parseTimeInput(table, column, upper)
+		if lowerDate.After(upperDate) {
+			return errors.New(msg("filter.dates_reversed"))
+		}
+		return nil
+	}
+	lowerNumber, _ := strconv.ParseFloat(lower, 64)
+	upperNumber, _ := strconv.ParseFloat(upper, 64)
+	if lowerNumber > upperNumber {
+		return errors.New(msg("filter.bounds_reversed"))
+	}
+	return nil
+}
+
+// Функция для построения условия по диапазону с параметрами начиная с $firstArg.
+// Для колонок с временем суток верхняя граница, заданная одной датой, включает весь день
+// (до начала следующего дня).
+func rangeCondition(table TableInfo, column, lower, upper string, firstArg int) (string, []interface{}) {
+	ref := dialect.QuoteIdent(column)
+	var args []interface{}
+	var upperValue interface{}
+	upperOp := "<="
+	if upper != "" {
+		upperValue = rangeBoundValue(table, column, upper)
+		if isTimestampColumn(table, column) && isDateOnlyInput(upper) {
+			upperValue = upperValue.(time.Time).AddDate(0, 0, 1)
+			upperOp = "<"
+		}
+	}
+
+	switch {
+	case lower != "" && upper != "" && upperOp == "<=":
+		args = append(args, rangeBoundValue(table, column, lower), upperValue)
+		return fmt.Sprintf("%s BETWEEN $%d AND $%d", ref, firstArg, firstArg+1), args
+	case lower != "" && upper != "":
+		args = append(args, rangeBoundValue(table, column, lower), upperValue)
+		return fmt.Sprintf("%s >= $%d AND %s %s $%d", ref, firstArg, ref, upperOp, firstArg+1), args
+	case lower != "":
+		args = append(args, rangeBoundValue(table, column, lower))
+		return fmt.Sprintf("%s >= $%d", ref, firstArg), args
+	default:
+		args = append(args, upperValue)
+		return fmt.Sprintf("%s %s $%d", ref, upperOp, firstArg), args
+	}
+}
+
+// Функция для ввода границ диапазона (пустая граница — без ограничения); false при отмене
+func promptRangeBounds(reader *bufio.Reader, table TableInfo, column string) ([]string, bool) {
+	hint := msg("filter.hint_number")
+	if isTimestampColumn(table, column) {
+		hint = msg("filter.hint_time", timeFormat())
+	} else if isDateColumn(table, column) {
+		hint = msg("filter.hint_date")
+	}
+
+	lower, ok := promptValidated(reader, msg("filter.lower_prompt", column, hint),
+		func(value string) error {
+			return validateRangeBound(table, column, value)
+		})
+	if !ok {
+		return nil, false
+	}
+
+	upper, ok := promptValidated(reader, msg("filter.upper_prompt", column, hint),
+		func(value string) error {
+			if err := validateRangeBound(table, column, value); err != nil {
+				return err
+			}
+			if lower == "" && value == "" {
+				return errors.New(msg("filter.bound_required"))
+			}
+			return checkRangeOrder(table, column, lower, value)
+		})
+	if !ok {
+		return nil, false
+	}
+	return []string{lower, upper}, true
+}
+
+// Типы фильтров по колонке
+const (
+	filterEquals  = iota + 1 // column = $n
+	filterInList             // column IN ($n, ...)
+	filterRange              // column BETWEEN $n AND $m
+	filterIsNull             // column IS NULL (без параметров)
+	filterNotNull            // column IS NOT NULL (без параметров)
+)
+
+// Функция для распознавания особых значений фильтра NULL и NOT NULL (без учета регистра).
+// Возвращает filterIsNull, filterNotNull или 0 для обычного значения.
+func nullFilterType(input string) int {
+	switch strings.ToUpper(strings.Join(strings.Fields(input), " ")) {
+	case "NULL":
+		return filterIsNull
+	case "NOT NULL":
+		return filterNotNull
+	}
+	return 0
+}
+
+// Функция для выбора типа фильтра по колонке; диапазон предлагается только для чисел и дат.
+// Для колонок со временем суток равенство бесполезно, поэтому сразу выбирается диапазон.
+func promptFilterType(reader *bufio.Reader, table TableInfo, column string) (int, bool) {
+	if isTimestampColumn(table, column) {
+		fmt.Fprintln(stdout, msg("filter.time_range", column))
+		return filterRange, true
+	}
+	fmt.Fprintln(stdout, msg("filter.type_title"))
+	fmt.Fprintln(stdout, msg("filter.type_equals"))
+	fmt.Fprintln(stdout, msg("filter.type_list"))
+	maxChoice := 2
+	if isDateColumn(table, column) {
+		fmt.Fprintln(stdout, msg("filter.type_date_range"))
+		maxChoice = 3
+	} else if supportsRangeFilter(table, column) {
+		fmt.Fprintln(stdout, msg("filter.type_range"))
+		maxChoice = 3
+	}
+	fmt.Fprintln(stdout, msg("common.back"))
+	choice, ok := promptInt(reader, msg("filter.type_prompt"), 0, maxChoice)
+	if !ok || choice == 0 {
+		return 0, false
+	}
+	return choice, true
+}
+
+// Функция для разбора списка значений через запятую: пустые элементы отбрасываются,
+// повторы удаляются, каждый элемент проверяется как значение колонки
+func parseValueList(table TableInfo, column, input string) ([]string, error) {
+	var values []string
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(input, ",") {
+		value := strings.TrimSpace(part)
+		if value == "" || seen[value] {
+			continue
+		}
+		if err := validateColumnValue(table, column, value); err != nil {
+			return nil, fmt.Errorf("'%s': %w", value, err)
+		}
+		seen[value] = true
+		values = append(values, value)
+	}
+	if len(values) == 0 {
+		return nil, errors.New(msg("filter.empty_list"))
+	}
+	return values, nil
+}
+
+// Функция для разбора списка значений ключа через запятую: каждое значение проверяется
+// по типу колонки ключа, повторы отбрасываются
+func parseKeyList(table TableInfo, key, input string) ([]string, error) {
+	values, err := parseValueList(table, key, input)
+	if err != nil {
+		return nil, err
+	}
+	for _, value := range values {
+		if err := validateTableValue(table, key, value); err != nil {
+			return nil, fmt.Errorf("'%s': %w", value, err)
+		}
+	}
+	return values, nil
+}
+
+// Функция для построения условия IN с параметрами начиная с $firstArg
+func inListCondition(column string, values []string, firstArg int) (string, []interface{}) {
+	placeholders := make([]string, len(values))
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		placeholders[i] = fmt.Sprintf("$%d", firstArg+i)
+		args[i] = value
+	}
+	return fmt.Sprintf("%s IN (%s)", dialect.QuoteIdent(column), strings.Join(placeholders, ", ")), args
+}
+
+// Функция для ввода списка значений через запятую; false при отмене
+func promptValueList(reader *bufio.Reader, table TableInfo, column string) ([]string, bool) {
+	input, ok := promptValidated(reader, msg("filter.list_prompt", column),
+		func(input string) error {
+			_, err := parseValueList(table, column, input)
+			return err
+		})
+	if !ok {
+		return nil, false
+	}
+	values, _ := parseValueList(table, column, input)
+	return values, true
+}
+
+// Функция для выбора способа объединения условий фильтра: " AND " или " OR "
+func promptFilterCombination(reader *bufio.Reader) (string, bool) {
+	fmt.Fprintln(stdout, msg("filter.combine_title"))
+	fmt.Fprintln(stdout, msg("filter.combine_and"))
+	fmt.Fprintln(stdout, msg("filter.combine_or"))
+	fmt.Fprintln(stdout, msg("common.back"))
+	choice, ok := promptInt(reader, msg("filter.combine_prompt"), 0, 2)
+	if !ok || choice == 0 {
+		return "", false
+	}
+	if choice == 2 {
+		return " OR ", true
+	}
+	return " AND ", true
+}
+
+// Функция для объединения условий; каждое условие берется в скобки,
+// чтобы составные условия (например диапазоны) не смешивались с OR
+func joinConditions(conditions []string, operator string) string {
+	if len(conditions) == 1 {
+		return conditions[0]
+	}
+	wrapped := make([]string, len(conditions))
+	for i, condition := range conditions {
+		wrapped[i] = "(" + condition + ")"
+	}
+	return strings.Join(wrapped, operator)
+}
+
+// Функция для построения условия WHERE с параметрами начиная с $firstArg.
+// Возвращает условие, параметры и колонки параметров (для маскирования в журнале).
+func buildWhereClause(table TableInfo, filters []FilterCondition, operator string, firstArg int) (string, []interface{}, []string) {
+	var conditions []string
+	var values []interface{}
+	var valueColumns []string
+	for _, filter := range filters {
+		var condition string
+		var args []interface{}
+		next := firstArg + len(values)
+		switch filter.Type {
+		case filterRange:
+			condition, args = rangeCondition(table, filter.Column, filter.Values[0], filter.Values[1], next)
+		case filterInList:
+			condition, args = inListCondition(filter.Column, filter.Values, next)
+		case filterIsNull:
+			// Условие без параметра не сдвигает нумерацию следующих параметров
+			condition = fmt.Sprintf("%s IS NULL", dialect.QuoteIdent(filter.Column))
+		case filterNotNull:
+			condition = fmt.Sprintf("%s IS NOT NULL", dialect.QuoteIdent(filter.Column))
+		default:
+			condition = fmt.Sprintf("%s = $%d", dialect.QuoteIdent(filter.Column), next)
+			args = []interface{}{filter.Values[0]}
+		}
+		conditions = append(conditions, condition)
+		values = append(values, args...)
+		for range args {
+			valueColumns = append(valueColumns, filter.Column)
+		}
+	}
+	return joinConditions(conditions, operator), values, valueColumns
+}
+
+// Функция для построения условия WHERE фильтрации (архивные записи скрываются, если не запрошены)
+func filterWhereClause(table TableInfo, spec FilterSpec) (string, []interface{}, []string) {
+	where, values, valueColumns := buildWhereClause(table, spec.Conditions, spec.Operator, 1)
+	if !spec.IncludeArchived {
+		where = withActiveRows(table, where)
+	}
+	return where, values, valueColumns
+}
+
+// Функция для построения запроса фильтрации.
+// Возвращает запрос, параметры и колонки параметров (для маскирования в журнале).
+func buildFilterQuery(table TableInfo, spec FilterSpec) (string, []interface{}, []string) {
+	where, values, valueColumns := filterWhereClause(table, spec)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s %s",
+		columnList(spec.Columns), table.SQLName(), where, orderByClause(table))
+	return query, values, valueColumns
+}
+
+// Функция для ввода условий фильтра: способ объединения и filterCount условий.
+// Возвращает условия, оператор объединения и false при отмене.
+func promptFilterConditions(reader *bufio.Reader, table TableInfo, filterCount int) ([]FilterCondition, string, bool) {
+	// При нескольких фильтрах пользователь выбирает, как их объединить
+	operator := " AND "
+	if filterCount > 1 {
+		var ok bool
+		operator, ok = promptFilterCombination(reader)
+		if !ok {
+			return nil, "", false
+		}
+	}
+
+	var conditions []FilterCondition
+	for i := 0; i < filterCount; i++ {
+		fmt.Fprintln(stdout, msg("filter.condition_title", i+1, filterCount))
+
+		// Выбор колонки
+		columnIndex := selectColumn(reader, table)
+		if columnIndex == -1 {
+			return nil, "", false
+		}
+
+		columnName := table.Columns[columnIndex]
+
+		// Кроме равенства доступны список значений и (для чисел и дат) диапазон
+		filterType, ok := promptFilterType(reader, table, columnName)
+		if !ok {
+			return nil, "", false
+		}
+
+		var values []string
+		switch filterType {
+		case filterRange:
+			values, ok = promptRangeBounds(reader, table, columnName)
+		case filterInList:
+			values, ok = promptValueList(reader, table, columnName)
+		default:
+			// Ввод значения для фильтрации с проверкой допустимых символов;
+			// NULL и NOT NULL превращаются в проверку на пустое значение
+			var value string
+			value, ok = promptValidated(reader, msg("filter.value_prompt", columnName),
+				func(value string) error {
+					if nullFilterType(value) != 0 {
+						return nil
+					}
+					return checkAllowedChars(table, columnName, value)
+				})
+			values = []string{value}
+			if nullType := nullFilterType(value); nullType != 0 {
+				filterType, values = nullType, nil
+			}
+		}
+		if !ok {
+			return nil, "", false
+		}
+
+		conditions = append(conditions, FilterCondition{Column: columnName, Type: filterType, Values: values})
+	}
+	return conditions, operator, true
+}
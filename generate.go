@@ -0,0 +1,306 @@
+package osl
+
+import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Генерация тестовых данных для демонстраций и нагрузочных проверок. Значения подбираются
+// по типу колонки из метаданных БД, внешние ключи ссылаются на существующие записи.
+// Колонки, которые могут остаться пустыми или имеют значение по умолчанию, заполняются,
+// только если для их типа есть генератор. OSL_GENERATE_SEED задает воспроизводимую последовательность.
+
+// Количество записей в одном пакете вставки
+const generateBatchSize = 500
+
+// Количество id связанной таблицы, из которых выбираются значения внешнего ключа
+const generateFKSample = 1000
+
+// Слоги для правдоподобных строковых значений
+var generateSyllables = []string{
+	"ka", "ro", "mi", "te", "lo", "va", "ni", "sa", "to", "re", "da", "pe", "ku", "li", "mo", "zer", "tan", "vor",
+}
+
+// Генератор значений одной колонки
+type columnGenerator struct {
+	Column string
+	Next   func(rnd *rand.Rand) string
+}
+
+// Функция для подготовки генераторов значений колонок таблицы.
+// Возвращает ошибку, если обязательную колонку заполнить нечем.
+func columnGenerators(table TableInfo) ([]columnGenerator, error) {
+	details := make(map[string]ColumnInfo, len(table.Details))
+	for _, column := range table.Details {
+		details[column.Name] = column
+	}
+
+	var generators []columnGenerator
+	for _, column := range table.Columns {
+		info, known := details[column]
+		// Автоматический ключ заполняет СУБД, колонка мягкого удаления получает значение по умолчанию
+		if autoGeneratedColumn(table, column) || column == softDeleteColumn(table) {
+			continue
+		}
+		optional := !known || info.Nullable || info.Default != ""
+
+		if refTable := foreignKeyTarget(table, column); refTable != "" {
+			ids, err := sampleIDs(refTable)
+			if err != nil {
+				return nil, err
+			}
+			if len(ids) == 0 {
+				if optional {
+					continue
+				}
+				return nil, errors.New(msg("generate.no_parent_rows", column, refTable))
+			}
+			generators = append(generators, columnGenerator{Column: column, Next: func(rnd *rand.Rand) string {
+				return ids[rnd.Intn(len(ids))]
+			}})
+			continue
+		}
+
+		next := valueGenerator(column, table.Types[column], info.Type)
+		if next == nil {
+			if optional {
+				continue
+			}
+			return nil, errors.New(msg("generate.unsupported", column, strings.ToLower(table.Types[column])))
+		}
+		generators = append(generators, columnGenerator{Column: column, Next: next})
+	}
+	if len(generators) == 0 {
+		return nil, errors.New(msg("generate.no_columns", table.Name))
+	}
+	return generators, nil
+}
+
+// Функция для получения id существующих неархивных записей таблицы
+func sampleIDs(table string) ([]string, error) {
+	key, err := tableKeyRef(table)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s", key, tableRef(table))
+	if info, ok := findTable(table); ok {
+		if condition := activeRowsCondition(info); condition != "" {
+			query += " WHERE " + condition
+		}
+	}
+	query += fmt.Sprintf(" ORDER BY %s LIMIT %d", key, generateFKSample)
+
+	rows, err := dbQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Функция для выбора генератора по типу колонки (nil — тип не поддерживается).
+// columnType — тип из каталога БД, из него берется длина CHAR(n).
+func valueGenerator(column, dbType, columnType string) func(rnd *rand.Rand) string {
+	lower := strings.ToLower(column)
+	// Размер вида CHAR(2) в имени типа (SQLite) не учитывается при выборе генератора
+	baseType := strings.ToUpper(dbType)
+	if i := strings.Index(baseType, "("); i >= 0 {
+		if columnType == "" {
+			columnType = baseType
+		}
+		baseType = strings.TrimSpace(baseType[:i])
+	}
+	switch baseType {
+	case "INT2", "INT4", "INT8", "INTEGER", "INT", "BIGINT", "SMALLINT", "MEDIUMINT":
+		switch {
+		case strings.Contains(lower, "year"):
+			return func(rnd *rand.Rand) string { return strconv.Itoa(1950 + rnd.Intn(time.Now().Year()-1949)) }
+		case strings.Contains(lower, "quantity"):
+			return func(rnd *rand.Rand) string { return strconv.Itoa(rnd.Intn(501)) }
+		}
+		return func(rnd *rand.Rand) string { return strconv.Itoa(1 + rnd.Intn(1000)) }
+	case "TINYINT":
+		return func(rnd *rand.Rand) string { return strconv.Itoa(rnd.Intn(2)) }
+	case "YEAR":
+		return func(rnd *rand.Rand) string { return strconv.Itoa(1990 + rnd.Intn(time.Now().Year()-1989)) }
+	case "NUMERIC", "DECIMAL", "REAL", "DOUBLE", "FLOAT", "FLOAT4", "FLOAT8", "MONEY":
+		// До 999.99, чтобы значение помещалось и в NUMERIC(5,2)
+		return func(rnd *rand.Rand) string { return fmt.Sprintf("%.2f", 1+rnd.Float64()*998.99) }
+	case "BOOL", "BOOLEAN":
+		return func(rnd *rand.Rand) string { return boolParam(rnd.Intn(2) == 1) }
+	case "DATE":
+		return func(rnd *rand.Rand) string { return randomTime(rnd).Format("2006-01-02") }
+	case "TIMESTAMP", "TIMESTAMPTZ", "DATETIME":
+		return func(rnd *rand.Rand) string { return randomTime(rnd).Format("2006-01-02 15:04:05") }
+	case "UUID":
+		return randomUUID
+	case "BPCHAR", "CHAR", "CHARACTER":
+		size := typeLength(columnType, 1)
+		return func(rnd *rand.Rand) string { return randomLetters(rnd, size) }
+	case "TEXT", "VARCHAR", "NAME", "CITEXT", "NVARCHAR", "CHARACTER VARYING", "":
+		if strings.Contains(lower, "name") || strings.Contains(lower, "model") {
+			return func(rnd *rand.Rand) string { return fmt.Sprintf("%s %d", randomWord(rnd), 100+rnd.Intn(900)) }
+		}
+		return func(rnd *rand.Rand) string { return randomWord(rnd) }
+	}
+	return nil
+}
+
+// Функция для получения длины из типа вида "character(2)" или "char(2)" (def — если длины нет)
+func typeLength(columnType string, def int) int {
+	start, end := strings.Index(columnType, "("), strings.Index(columnType, ")")
+	if start < 0 || end <= start {
+		return def
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(columnType[start+1 : end]))
+	if err != nil || n <= 0 {
+		return def
+	}
+	return n
+}
+
+// Функция для получения случайного момента за последние три года
+func randomTime(rnd *rand.Rand) time.Time {
+	return time.Now().Add(-time.Duration(rnd.Int63n(int64(3 * 365 * 24 * time.Hour)))).Truncate(time.Second)
+}
+
+// Функция для получения случайного слова из слогов с заглавной буквы (до 9 символов)
+func randomWord(rnd *rand.Rand) string {
+	var b strings.Builder
+	for i := 2 + rnd.Intn(2); i > 0; i-- {
+		b.WriteString(generateSyllables[rnd.Intn(len(generateSyllables))])
+	}
+	word := b.String()
+	return strings.ToUpper(word[:1]) + word[1:]
+}
+
+// Функция для получения строки из n заглавных латинских букв
+func randomLetters(rnd *rand.Rand, n int) string {
+	letters := make([]byte, n)
+	for i := range letters {
+		letters[i] = byte('A' + rnd.Intn(26))
+	}
+	return string(letters)
+}
+
+// Функция для получения случайного UUID версии 4
+func randomUUID(rnd *rand.Rand) string {
+	b := make([]byte, 16)
+	rnd.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// Функция для генерации записей: по одному значению каждой колонки
+func generateRecords(rnd *rand.Rand, generators []columnGenerator, count int) [][]string {
+	records := make([][]string, count)
+	for i := range records {
+		record := make([]string, len(generators))
+		for j, generator := range generators {
+			record[j] = generator.Next(rnd)
+		}
+		records[i] = record
+	}
+	return records
+}
+
+// Пункт 26: Генерация тестовых данных
+func generateTestData(reader *bufio.Reader) {
+	tableIndex := selectTable(reader, msg("select_table.generate"))
+	if tableIndex == -1 {
+		return
+	}
+	table := tables[tableIndex]
+
+	generators, err := columnGenerators(table)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Генерация тестовых данных для %s невозможна: %v", table.Name, err))
+		printError(msg("common.error", err))
+		return
+	}
+	columns := make([]string, len(generators))
+	for i, generator := range generators {
+		columns[i] = generator.Column
+	}
+	fmt.Fprintln(stdout, msg("generate.columns", strings.Join(columns, ", ")))
+
+	count, ok := promptInt(reader, msg("generate.count_prompt"), 1, envInt("OSL_GENERATE_MAX", 100000))
+	if !ok {
+		return
+	}
+
+	seed := int64(envInt("OSL_GENERATE_SEED", 0))
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rnd := rand.New(rand.NewSource(seed))
+	records := generateRecords(rnd, generators, count)
+
+	sample := records
+	if len(sample) > restorePreviewSize {
+		sample = sample[:restorePreviewSize]
+	}
+	fmt.Fprintln(stdout, msg("generate.sample"))
+	printTable(&ResultSet{Columns: columns, Types: make([]string, len(columns)), Rows: sample})
+	if !promptConfirm(reader, msg("generate.confirm", count, table.Name, activeConfig.Name)) {
+		fmt.Fprintln(stdout, msg("input.cancelled"))
+		return
+	}
+	if dryRun {
+		printDryRunInsert(table.Name, columns, sample)
+		fmt.Fprintln(stdout, msg("generate.dry_run", dryRunTag, count))
+		return
+	}
+
+	logToFileAndScreen(fmt.Sprintf("Генерация тестовых данных: %d записей в %s (seed %d)", count, table.Name, seed))
+	start := time.Now()
+	var ids []string
+	err = dbTransaction(func(tx *sql.Tx) error {
+		ids = nil
+		for offset := 0; offset < len(records); offset += generateBatchSize {
+			end := offset + generateBatchSize
+			if end > len(records) {
+				end = len(records)
+			}
+			batch := records[offset:end]
+			batchIDs, err := insertRecords(tx, table.Name, columns, batch)
+			if err != nil {
+				return err
+			}
+			ids = append(ids, batchIDs...)
+			fmt.Fprint(stdout, msg("generate.progress", offset+len(batch), count))
+		}
+		return nil
+	})
+	fmt.Fprintln(stdout)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка генерации тестовых данных в %s, изменения отменены: %v", table.Name, err))
+		printError(msg("generate.failed"))
+		return
+	}
+
+	// Отменить можно, только если известны id всех добавленных записей
+	if len(ids) == count {
+		rememberUndo(msg("undo.kind_generate", table.Name), []undoStep{{Table: table.Name, IDs: ids}})
+	} else {
+		forgetUndo(fmt.Sprintf("сгенерированы записи в таблице %s", table.Name))
+	}
+	elapsed := time.Since(start).Round(time.Millisecond)
+	fmt.Fprintln(stdout, msg("generate.done", count, table.Name, elapsed))
+	logToFileAndScreen(fmt.Sprintf("Генерация тестовых данных: добавлено %d записей в %s за %s", count, table.Name, elapsed))
+}
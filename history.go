@@ -0,0 +1,304 @@
+package osl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// История выполненных операций хранится только в памяти на время сеанса,
+// поэтому введенные значения (в том числе чувствительные) не попадают на диск.
+// Повтор операции заново строит запрос по сохраненным параметрам.
+
+// Количество операций, которое хранится в истории
+const historyLimit = 20
+
+// Виды операций в истории
+const (
+	historyFilter = "фильтрация"
+	historyUpdate = "обновление"
+	historyInsert = "добавление"
+)
+
+// Ключи сообщений с названиями видов операций
+var historyKindKeys = map[string]string{
+	historyFilter: "history.kind_filter",
+	historyUpdate: "history.kind_update",
+	historyInsert: "history.kind_insert",
+}
+
+// Описание обновления одной колонки в записях с указанными id
+// (или во всех записях, удовлетворяющих условиям, если они заданы)
+type UpdateSpec struct {
+	Table      string
+	Column     string
+	IDs        []string
+	Conditions []FilterCondition
+	Operator   string
+	Value      string
+}
+
+// Описание добавления записей
+type InsertSpec struct {
+	Table   string
+	Columns []string
+	Records [][]string
+}
+
+// Запись истории операций
+type HistoryEntry struct {
+	Kind   string
+	At     time.Time
+	Filter *FilterSpec
+	Update *UpdateSpec
+	Insert *InsertSpec
+}
+
+// Параметр операции, который можно изменить перед повтором
+type historyParam struct {
+	Label    string
+	Column   string
+	Value    *string
+	Validate func(string) error
+}
+
+// История операций текущего сеанса (последняя — в конце)
+var history []HistoryEntry
+
+// Функция для добавления операции в историю
+func recordHistory(entry HistoryEntry) {
+	entry.At = time.Now()
+	history = append(history, entry.clone())
+	if len(history) > historyLimit {
+		history = history[len(history)-historyLimit:]
+	}
+}
+
+// Функция для копирования записи истории, чтобы правка перед повтором не меняла историю
+func (e HistoryEntry) clone() HistoryEntry {
+	copied := e
+	if e.Filter != nil {
+		filter := *e.Filter
+		filter.Columns = append([]string(nil), e.Filter.Columns...)
+		filter.Conditions = cloneConditions(e.Filter.Conditions)
+		copied.Filter = &filter
+	}
+	if e.Update != nil {
+		update := *e.Update
+		update.IDs = append([]string(nil), e.Update.IDs...)
+		update.Conditions = cloneConditions(e.Update.Conditions)
+		copied.Update = &update
+	}
+	if e.Insert != nil {
+		insert := *e.Insert
+		insert.Columns = append([]string(nil), e.Insert.Columns...)
+		insert.Records = make([][]string, len(e.Insert.Records))
+		for i, record := range e.Insert.Records {
+			insert.Records[i] = append([]string(nil), record...)
+		}
+		copied.Insert = &insert
+	}
+	return copied
+}
+
+// Функция для копирования условий фильтра
+func cloneConditions(conditions []FilterCondition) []FilterCondition {
+	if conditions == nil {
+		return nil
+	}
+	copied := make([]FilterCondition, len(conditions))
+	for i, condition := range conditions {
+		condition.Values = append([]string(nil), condition.Values...)
+		copied[i] = condition
+	}
+	return copied
+}
+
+// Функция для описания условий фильтра
+func describeConditions(conditions []FilterCondition, operator string) string {
+	parts := make([]string, len(conditions))
+	for i, condition := range conditions {
+		values := make([]string, len(condition.Values))
+		for j, value := range condition.Values {
+			values[j] = displayParam(condition.Column, value)
+		}
+		switch condition.Type {
+		case filterRange:
+			parts[i] = msg("history.range", condition.Column, values[0], values[1])
+		case filterInList:
+			parts[i] = msg("history.in_list", condition.Column, strings.Join(values, ", "))
+		case filterIsNull:
+			parts[i] = condition.Column + " IS NULL"
+		case filterNotNull:
+			parts[i] = condition.Column + " IS NOT NULL"
+		default:
+			parts[i] = fmt.Sprintf("%s = '%s'", condition.Column, values[0])
+		}
+	}
+	return strings.Join(parts, strings.ToLower(operator))
+}
+
+// Функция для получения изменяемых параметров условий фильтра
+func conditionParams(table TableInfo, conditions []FilterCondition) []historyParam {
+	var params []historyParam
+	for i := range conditions {
+		condition := &conditions[i]
+		column := condition.Column
+		for j := range condition.Values {
+			param := historyParam{Label: column, Column: column, Value: &condition.Values[j]}
+			switch condition.Type {
+			case filterRange:
+				param.Label = msg("history.lower_bound", column)
+				if j == 1 {
+					param.Label = msg("history.upper_bound", column)
+				}
+				other := &condition.Values[1-j]
+				param.Validate = func(value string) error {
+					if value == "" && *other == "" {
+						return errors.New(msg("filter.bound_required"))
+					}
+					return validateRangeBound(table, column, value)
+				}
+			case filterInList:
+				param.Validate = func(value string) error { return validateColumnValue(table, column, value) }
+			default:
+				param.Validate = func(value string) error { return checkAllowedChars(table, column, value) }
+			}
+			params = append(params, param)
+		}
+	}
+	return params
+}
+
+// Функция для отображения значения с маскированием чувствительных колонок
+func displayParam(column, value string) string {
+	if sensitiveColumns()[strings.ToLower(column)] {
+		return redactedValue
+	}
+	return value
+}
+
+// Функция для краткого описания операции
+func (e HistoryEntry) describe() string {
+	switch {
+	case e.Filter != nil:
+		return fmt.Sprintf("%s: %s", e.Filter.Table, describeConditions(e.Filter.Conditions, e.Filter.Operator))
+	case e.Update != nil:
+		target := "id " + strings.Join(e.Update.IDs, ", ")
+		if len(e.Update.Conditions) > 0 {
+			target = describeConditions(e.Update.Conditions, e.Update.Operator)
+		}
+		return msg("history.describe_update", e.Update.Table, e.Update.Column,
+			displayParam(e.Update.Column, e.Update.Value), target)
+	case e.Insert != nil:
+		return msg("history.describe_insert", e.Insert.Table, len(e.Insert.Records), strings.Join(e.Insert.Columns, ", "))
+	}
+	return ""
+}
+
+// Функция для получения списка параметров, которые можно изменить перед повтором
+func (e HistoryEntry) params() []historyParam {
+	var params []historyParam
+	switch {
+	case e.Filter != nil:
+		table, _ := findTable(e.Filter.Table)
+		params = conditionParams(table, e.Filter.Conditions)
+	case e.Update != nil:
+		table, _ := findTable(e.Update.Table)
+		params = append(params, historyParam{Label: e.Update.Column, Column: e.Update.Column, Value: &e.Update.Value,
+			Validate: recordValueValidator(table, e.Update.Column)})
+		key := tableKeyColumn(e.Update.Table)
+		for i := range e.Update.IDs {
+			params = append(params, historyParam{Label: key, Column: key, Value: &e.Update.IDs[i],
+				Validate: func(value string) error { return validateTableValue(table, key, value) }})
+		}
+		params = append(params, conditionParams(table, e.Update.Conditions)...)
+	case e.Insert != nil:
+		table, _ := findTable(e.Insert.Table)
+		for i, record := range e.Insert.Records {
+			for j, column := range e.Insert.Columns {
+				params = append(params, historyParam{Label: msg("history.record_param", i+1, column), Column: column,
+					Value: &record[j], Validate: recordValueValidator(table, column)})
+			}
+		}
+	}
+	return params
+}
+
+// Функция для проверки значения колонки записи: внешний ключ должен существовать
+func recordValueValidator(table TableInfo, column string) func(string) error {
+	if refTable := foreignKeyTarget(table, column); refTable != "" {
+		return func(value string) error { return validateManualID(refTable, value) }
+	}
+	return func(value string) error { return validateTableValue(table, column, value) }
+}
+
+// Пункт 10: История операций
+func showHistory(reader *bufio.Reader) {
+	if len(history) == 0 {
+		fmt.Fprintln(stdout, msg("history.empty"))
+		return
+	}
+
+	fmt.Fprintln(stdout, msg("history.title"))
+	for i := len(history) - 1; i >= 0; i-- {
+		entry := history[i]
+		fmt.Fprintf(stdout, "%d. [%s] %s — %s\n", i+1, entry.At.Format("15:04:05"), msg(historyKindKeys[entry.Kind]), entry.describe())
+	}
+	fmt.Fprintln(stdout, msg("common.back"))
+
+	choice, ok := promptInt(reader, msg("history.prompt"), 0, len(history))
+	if !ok || choice == 0 {
+		return
+	}
+	entry := history[choice-1].clone()
+
+	// Перед повтором можно изменить одно значение
+	params := entry.params()
+	fmt.Fprintln(stdout, msg("history.params_title"))
+	for i, param := range params {
+		fmt.Fprintf(stdout, "%d. %s = %s\n", i+1, param.Label, displayParam(param.Column, *param.Value))
+	}
+	input, ok := promptValidated(reader, msg("history.param_prompt"),
+		func(input string) error {
+			if input == "" {
+				return nil
+			}
+			if n, err := strconv.Atoi(input); err != nil || n < 1 || n > len(params) {
+				return errors.New(msg("input.choose_range", 1, len(params)))
+			}
+			return nil
+		})
+	if !ok {
+		return
+	}
+	if input != "" {
+		n, _ := strconv.Atoi(input)
+		param := params[n-1]
+		value, ok := promptValidated(reader, msg("history.new_value", param.Label), param.Validate)
+		if !ok {
+			return
+		}
+		*param.Value = value
+	}
+
+	logToFileAndScreen(fmt.Sprintf("Повтор операции из истории: %s — %s", entry.Kind, entry.describe()))
+	switch {
+	case entry.Filter != nil:
+		executeFilter(reader, *entry.Filter)
+	case entry.Update != nil:
+		if len(entry.Update.Conditions) == 0 {
+			ids, ok := confirmExistingIDs(reader, entry.Update.Table, entry.Update.IDs)
+			if !ok {
+				return
+			}
+			entry.Update.IDs = ids
+		}
+		executeUpdate(reader, *entry.Update)
+	case entry.Insert != nil:
+		executeInsert(reader, *entry.Insert)
+	}
+}
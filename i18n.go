@@ -0,0 +1,1411 @@
+package osl
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Все, что выводится на экран, берется из словаря сообщений активного языка.
+// Язык задается OSL_LANG или системной переменной LANG (например en_US.UTF-8; по умолчанию ru)
+// и переключается в меню.
+// Если в словаре нет сообщения, используется русский вариант. Журнал ведется на русском.
+
+// Язык по умолчанию и запасной словарь
+const defaultLanguage = "ru"
+
+// Названия языков для меню выбора
+var languageNames = map[string]string{
+	"ru": "Русский",
+	"en": "English",
+}
+
+// Словари сообщений: язык -> ключ -> текст (для fmt.Sprintf, если передаются аргументы)
+var catalogs = map[string]map[string]string{
+	"ru": messagesRU,
+	"en": messagesEN,
+}
+
+// Активный язык интерфейса
+var language = languageFromEnv()
+
+// Функция для получения языка из OSL_LANG, затем из LANG; неизвестный язык заменяется русским
+func languageFromEnv() string {
+	for _, name := range []string{"OSL_LANG", "LANG"} {
+		if lang := localeLanguage(os.Getenv(name)); lang != "" {
+			return lang
+		}
+	}
+	return defaultLanguage
+}
+
+// Функция для получения языка интерфейса из значения вида ru, en_US.UTF-8 или en-GB
+// (пусто — язык не задан или для него нет словаря)
+func localeLanguage(value string) string {
+	lang := strings.ToLower(strings.TrimSpace(value))
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	if _, ok := catalogs[lang]; !ok {
+		return ""
+	}
+	return lang
+}
+
+// Функция для получения сообщения на активном языке.
+// Нет перевода — берется русский вариант, нет и его — выводится сам ключ.
+func msg(key string, args ...interface{}) string {
+	text, ok := catalogs[language][key]
+	if !ok {
+		text, ok = catalogs[defaultLanguage][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+// Пункт 15: Язык интерфейса
+func chooseLanguage(reader *bufio.Reader) {
+	codes := make([]string, 0, len(catalogs))
+	for code := range catalogs {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	fmt.Fprintln(stdout, msg("language.title"))
+	for i, code := range codes {
+		fmt.Fprintf(stdout, "%d. %s (%s)\n", i+1, languageNames[code], code)
+	}
+	fmt.Fprintln(stdout, msg("common.back"))
+	choice, ok := promptInt(reader, msg("language.prompt"), 0, len(codes))
+	if !ok || choice == 0 {
+		return
+	}
+	language = codes[choice-1]
+	fmt.Fprintln(stdout, msg("language.changed", languageNames[language]))
+	logToFileAndScreen(fmt.Sprintf("Язык интерфейса: %s", language))
+}
+
+// Русский словарь (основной)
+var messagesRU = map[string]string{
+	"common.back":            "0. Вернуться в меню",
+	"common.error":           "Ошибка: %v",
+	"common.found_rows":      "\nНайдено записей: %d (за %s)",
+	"common.table_not_found": "Ошибка: таблица '%s' не найдена",
+	"common.choose_table":    "Выберите таблицу: ",
+	"common.choose_column":   "Выберите колонку: ",
+	"common.no_key":          "В таблице '%s' нет первичного ключа из одной колонки — записи нельзя изменить или удалить по ключу",
+
+	"connect.title":       "=== Подключение к базе данных ===",
+	"connect.login":       "Введите логин: ",
+	"connect.password":    "Введите пароль: ",
+	"connect.open_failed": "Ошибка: Не удалось подключиться к базе данных. Проверьте учетные данные.",
+	"connect.ping_failed": "Ошибка: Не удалось подключиться к базе данных. Проверьте учетные данные и доступность БД.",
+	"connect.ok":          "✓ Подключение к базе данных успешно установлено",
+	"connect.waiting":     "Ожидание запуска БД (не более %s)",
+	"connect.retry_write": "Изменение могло быть выполнено до обрыва соединения. Повторить его? (да/нет): ",
+
+	"menu.title":          "\n=== МЕНЮ (база: %s) ===",
+	"menu.dry_run_banner": "%s Включен режим проверки: изменения не отправляются в БД",
+	"menu.view":           "1. Просмотр таблицы",
+	"menu.filter":         "2. Фильтрация",
+	"menu.update":         "3. Обновить запись",
+	"menu.insert":         "4. Добавить запись",
+	"menu.insert_related": "5. Добавить запись в связанные таблицы",
+	"menu.sort":           "6. Параметры сортировки",
+	"menu.reports":        "7. Отчёты",
+	"menu.export":         "8. Экспорт таблицы",
+	"menu.describe":       "9. Структура таблицы",
+	"menu.history":        "10. История операций",
+	"menu.sql":            "11. SQL-запрос",
+	"menu.status":         "12. Состояние подключения",
+	"menu.dry_run_off":    "13. Режим проверки (dry-run): выключить",
+	"menu.dry_run_on":     "13. Режим проверки (dry-run): включить",
+	"menu.stock":          "14. Корректировка остатков",
+	"menu.language":       "15. Язык интерфейса (Language)",
+	"menu.import":         "16. Импорт из CSV",
+	"menu.undo":           "17. Отменить последнюю операцию",
+	"menu.search":         "18. Поиск",
+	"menu.delete":         "19. Удаление записей",
+	"menu.restore":        "20. Восстановить запись из архива",
+	"menu.transfer":       "21. Перемещение между складами",
+	"menu.dump":           "22. Выгрузка таблицы в SQL-файл",
+	"menu.restore_dump":   "23. Восстановление из выгрузки (SQL или CSV)",
+	"menu.presets":        "24. Сохраненные фильтры",
+	"menu.switch_db":      "25. Сменить базу данных",
+	"menu.generate":       "26. Генерация тестовых данных",
+	"menu.product":        "27. Полный ввод нового товара",
+	"menu.show_log":       "28. Показать журнал",
+	"menu.exit":           "0. Выход",
+	"menu.prompt":         "Выберите пункт меню: ",
+	"menu.invalid":        "Ошибка: выберите цифру от 0 до %d",
+	"menu.bye":            "Завершение программы...",
+
+	"language.title":   "\n=== ЯЗЫК ИНТЕРФЕЙСА ===",
+	"language.prompt":  "Выберите язык: ",
+	"language.changed": "✓ Язык интерфейса: %s",
+
+	"input.too_long":          "ввод слишком длинный: %d символов (максимум %d)",
+	"input.interrupted":       "\nВвод прерван, операция отменена",
+	"input.cancelled":         "Операция отменена",
+	"input.retry":             "Повторите ввод (или введите 'отмена' для выхода)",
+	"input.too_many_attempts": "Ошибка: превышено число попыток ввода, операция отменена",
+	"input.min_number":        "введите число не меньше %d",
+	"input.choose_range":      "выберите цифру от %d до %d",
+	"input.not_number":        "поле '%s' должно быть числом",
+	"input.yes_no":            "ответьте 'да' или 'нет'",
+
+	"select_table.view":   "\n=== ВЫБОР ТАБЛИЦЫ ДЛЯ ПРОСМОТРА ===",
+	"select_table.filter": "ВЫБОР ТАБЛИЦЫ ДЛЯ ФИЛЬТРАЦИИ",
+	"select_table.update": "ВЫБОР ТАБЛИЦЫ ДЛЯ ОБНОВЛЕНИЯ",
+	"select_table.insert": "ВЫБОР ТАБЛИЦЫ ДЛЯ ДОБАВЛЕНИЯ",
+	"select_table.import": "ВЫБОР ТАБЛИЦЫ ДЛЯ ИМПОРТА",
+	"select_table.search": "ВЫБОР ТАБЛИЦЫ ДЛЯ ПОИСКА",
+	"select_table.delete": "ВЫБОР ТАБЛИЦЫ ДЛЯ УДАЛЕНИЯ",
+	"select_column.title": "\n=== ВЫБОР КОЛОНКИ В ТАБЛИЦЕ '%s' ===",
+
+	"view.count":            "В таблице %d записей",
+	"view.include_archived": "В таблице есть архивные записи (%d). Показать их? (да/нет): ",
+	"view.confirm_all":      "Вывести все записи сразу? (да/нет, нет — просмотр по страницам): ",
+	"view.query_failed":     "Ошибка: Не удалось выполнить запрос к таблице",
+	"stream.notice":         "Записей много: они выводятся по мере чтения, ширина колонок подобрана по первым строкам",
+
+	"filter.count":            "\nВведите количество фильтров (минимум 1): ",
+	"filter.failed":           "Ошибка: Не удалось выполнить фильтрацию",
+	"filter.none":             "По заданным фильтрам записей не найдено",
+	"filter.include_archived": "Искать, включая архивные записи (%d)? (да/нет): ",
+
+	"update.mode_title":       "\n=== СПОСОБ ВЫБОРА ЗАПИСЕЙ ===",
+	"update.mode_ids":         "1. По ID",
+	"update.mode_conditions":  "2. По условию",
+	"update.mode_prompt":      "Выберите способ: ",
+	"update.count_ids":        "\nВведите количество данных для обновления (минимум 1): ",
+	"update.count_conditions": "\nВведите количество условий (минимум 1): ",
+	"update.no_columns":       "В таблице нет колонок для обновления",
+	"update.id_prompt":        "Введите ID записи %d для обновления: ",
+	"update.id_duplicate":     "этот ID уже введен",
+	"update.column_title":     "\n=== ВЫБОР КОЛОНКИ ДЛЯ ОБНОВЛЕНИЯ В '%s' ===",
+	"update.column_prompt":    "Выберите колонку для обновления: ",
+	"update.value_prompt":     "Введите новое значение для '%s' в таблице '%s': ",
+	"update.check_failed":     "Ошибка: Не удалось проверить существование записей",
+	"update.ids_not_found":    "Записи с указанными ID в таблице '%s' не найдены",
+	"update.missing_ids":      "Не найдены записи с ID: %s",
+	"update.confirm_partial":  "Продолжить обновление только найденных записей (%d)? (да/нет): ",
+	"update.cancelled":        "Обновление отменено",
+	"update.find_failed":      "Ошибка: Не удалось найти записи для обновления",
+	"update.none_matched":     "По заданным условиям записей не найдено",
+	"update.rules_failed":     "Ошибка: Не удалось проверить правила, обновление отменено",
+	"update.failed":           "Ошибка: Не удалось обновить данные",
+	"update.done":             "Обновлено записей: %d",
+	"update.partial": "Из %d найденных записей обновлено %d: остальные были удалены или изменены другим пользователем " +
+		"после проверки или уже содержали это значение",
+
+	"insert.count":        "\nВведите количество создаваемых записей (минимум 1): ",
+	"insert.record_title": "\n=== Ввод данных для записи %d из %d ===",
+	"insert.pick_value":   "Выбор значения для '%s':",
+	"insert.value_prompt": "Введите значение для '%s': ",
+	"insert.failed":       "Ошибка: Не удалось добавить записи, ни одна запись не добавлена",
+	"insert.done":         "\nВсего добавлено записей: %d (за %s)",
+	"insert.cancelled":    "Добавление отменено",
+
+	"related.title":        "\n=== ВЫБОР СВЯЗАННЫХ ТАБЛИЦ ===",
+	"related.prompt":       "Выберите связанные таблицы: ",
+	"related.record_title": "\n=== Ввод данных для связанных таблиц %d из %d ===",
+	"related.table_data":   "\n--- Данные для таблицы '%s' ---",
+	"related.dry_run_done": "\n%s Связанные записи не добавлены",
+	"related.done":         "\nВсего добавлено связанных записей: %d",
+
+	"lookup.manual_id":    "Введите ID вручную: ",
+	"lookup.empty":        "В таблице '%s' нет записей",
+	"lookup.search":       "В таблице '%s' %d записей. Введите часть названия для поиска (Enter — показать все, #<id> — ввести ID): ",
+	"lookup.no_match":     "записей с '%s' в названии не найдено",
+	"lookup.title":        "\n=== ВЫБОР ЗАПИСИ ИЗ '%s' ===",
+	"lookup.prompt":       "Выберите запись или введите #<id>: ",
+	"lookup.check_failed": "не удалось проверить существование записи",
+	"lookup.not_found":    "такой записи нет в таблице '%s'",
+
+	"dry_run.not_executed": "\n%s Запрос не выполнен:\n%s",
+	"dry_run.params":       "Параметры: %s",
+	"dry_run.preview":      "\n%s Записи, которые будут обновлены (%s):",
+	"dry_run.on":           "✓ Режим проверки включен: изменения не будут отправляться в БД",
+	"dry_run.off":          "✓ Режим проверки выключен: изменения выполняются",
+
+	"status.title":       "\n=== СОСТОЯНИЕ ПОДКЛЮЧЕНИЯ ===",
+	"status.driver":      "СУБД:          %s",
+	"status.host":        "Хост:          %s",
+	"status.port":        "Порт:          %s",
+	"status.ssl":         "SSL:           %s",
+	"status.user":        "Пользователь:  %s",
+	"status.ping_failed": "Соединение:    ошибка (%v)",
+	"status.ping_ok":     "Соединение:    работает (ответ за %s)",
+	"status.info_failed": "Сервер:        не удалось получить сведения (%v)",
+	"status.server":      "Сервер:        %s",
+	"status.database":    "База данных:   %s",
+	"status.pool":        "\n--- Пул соединений ---",
+	"status.pool_open":   "Открыто:       %d (занято %d, свободно %d)",
+	"status.pool_wait":   "Ожиданий:      %d (всего %s)",
+	"status.pool_closed": "Закрыто:       по простою %d, по времени жизни %d",
+
+	"stock.title":           "\n=== КОРРЕКТИРОВКА ОСТАТКОВ ===",
+	"stock.no_rows":         "Ошибка: для компонента '%s' нет записей в таблице stock",
+	"stock.locations":       "\n=== СКЛАДЫ КОМПОНЕНТА '%s' ===",
+	"stock.location":        "%d. %s — %d шт. (id=%s)",
+	"stock.location_prompt": "Выберите склад: ",
+	"stock.current":         "Текущий остаток: %d шт.",
+	"stock.negative":        "остаток не может стать меньше нуля",
+	"stock.rejected":        "Ошибка: %v (остаток %d, изменение %+d)",
+	"stock.row_deleted":     "Ошибка: запись склада была удалена, корректировка не выполнена",
+	"stock.done":            "✓ Остаток '%s' изменен: %d → %d шт. (%+d)",
+	"stock.delta_prompt":    "Введите изменение остатка (например +10 или -3): ",
+	"stock.delta_packages":  "Введите изменение остатка (например +10 или -3; в упаковке %d шт., можно ввести N%s): ",
+	"stock.delta_zero":      "изменение не может быть нулевым",
+	"stock.packages":        "%d %s × %d шт. = %d шт.",
+
+	"packages.fractional":   "дробное количество упаковок недопустимо, введите целое число или количество в штуках",
+	"packages.not_integer":  "количество упаковок должно быть целым числом, например 5%s",
+	"packages.unknown_size": "для компонента не указано количество в упаковке (units_per_package), введите количество в штуках",
+	"packages.prompt_hint":  " (в упаковке %d шт., можно ввести N%s): ",
+	"packages.confirm":      "%d %s × %d шт. = %d шт. Подтвердить? (да/нет): ",
+
+	"duplicates.found":   "В таблице '%s' уже есть записи с %s = '%s' (без учета регистра):",
+	"duplicates.item":    "  id=%s: %s",
+	"duplicates.proceed": "1. Все равно добавить новую запись",
+	"duplicates.reuse":   "2. Использовать существующую запись",
+	"duplicates.cancel":  "0. Отменить добавление",
+	"duplicates.prompt":  "Выберите действие: ",
+	"duplicates.pick":    "Выберите существующую запись: ",
+
+	"import.path_prompt":      "Введите путь к CSV-файлу с заголовком (Enter — %s): ",
+	"import.failed_line":      "Ошибка импорта, строка %d: %v",
+	"import.rolled_back":      "Импорт отменен, ни одна запись не добавлена",
+	"import.done":             "\n✓ Импортировано записей: %d в таблицу '%s' (за %s)",
+	"import.dry_run":          "%s Проверено строк: %d, записи не добавлены",
+	"import.empty_file":       "файл пуст",
+	"import.unknown_column":   "колонка '%s' не найдена в таблице '%s'",
+	"import.duplicate_column": "колонка '%s' указана в заголовке дважды",
+	"import.no_columns":       "в заголовке нет колонок для импорта",
+	"import.missing_value":    "нет значения для колонки '%s'",
+	"import.rule_blocked":     "нарушено правило '%s': %s",
+	"import.rule_warning":     "Предупреждение (%s, строка %d): %s",
+
+	"quality.title":         "\n=== КОНТРОЛЬ КАЧЕСТВА: таблица '%s', добавлено строк: %d ===",
+	"quality.numeric":       "%s: мин %s, макс %s, среднее %s, пустых %d",
+	"quality.numeric_old":   "(до импорта: мин %s, макс %s, среднее %s)",
+	"quality.numeric_empty": "%s: все значения пустые (%d)",
+	"quality.no_old_data":   "(до импорта данных не было)",
+	"quality.text":          "%s: пустых %d из %d (до импорта: пустых строк %d, NULL %d из %d)",
+	"quality.outliers":      "    значений с сильным отклонением: %d, примеры:",
+	"quality.example":       "    строка %d: %s",
+	"quality.clean":         "Сильных отклонений от прежних данных не найдено",
+	"quality.flagged":       "Отмечено колонок: %d — проверьте строки файла, импорт не отменен",
+	"quality.saved":         "Отчет записан в %s",
+	"quality.write_failed":  "Не удалось записать отчет %s: %v",
+
+	"undo.nothing":       "Нечего отменять: после запуска программы не было изменений, которые можно отменить",
+	"undo.title":         "\n=== ОТМЕНА ПОСЛЕДНЕЙ ОПЕРАЦИИ ===",
+	"undo.operation":     "Операция в %s: %s",
+	"undo.restore_step":  "вернуть прежние значения %s.%s в записях: %d",
+	"undo.delta_step":    "изменить %s.%s на %+d в записях: %d",
+	"undo.delete_step":   "удалить добавленные записи из %s: %d",
+	"undo.confirm":       "Отменить операцию? (да/нет): ",
+	"undo.cancelled":     "Отмена не выполнена",
+	"undo.failed":        "Ошибка отмены операции, изменения не внесены",
+	"undo.done":          "\n✓ Операция отменена, затронуто записей: %d",
+	"undo.partial":       "Внимание: %d записей уже были изменены или удалены другим способом",
+	"undo.kind_update":   "обновление %s.%s",
+	"undo.kind_insert":   "добавление записей в %s",
+	"undo.kind_related":  "добавление в связанные таблицы %s",
+	"undo.kind_stock":    "корректировка остатка '%s'",
+	"undo.kind_archive":  "архивирование записей в %s",
+	"undo.kind_restore":  "восстановление записи из архива в %s",
+	"undo.kind_transfer": "перемещение '%s' между складами",
+
+	"search.columns":         "Поиск выполняется в колонках: %s",
+	"search.no_text_columns": "В таблице '%s' нет текстовых колонок для поиска",
+	"search.prompt":          "Введите текст для поиска: ",
+	"search.empty":           "текст для поиска не может быть пустым",
+	"search.failed":          "Ошибка: Не удалось выполнить поиск",
+	"search.none":            "Записей, содержащих '%s', не найдено",
+	"search.matched_column":  "совпадение",
+
+	"delete.soft_notice":   "В таблице '%s' записи не удаляются, а помечаются архивными (колонка %s)",
+	"delete.hard_notice":   "Внимание: записи таблицы '%s' будут удалены безвозвратно",
+	"delete.ids_prompt":    "Введите ID записей через запятую: ",
+	"delete.none_found":    "В таблице '%s' не найдено записей с указанными ID (или они уже в архиве)",
+	"delete.skipped":       "Не найдены или уже в архиве: %s",
+	"delete.confirm_soft":  "Переместить в архив записей: %d из таблицы '%s'? (да/нет): ",
+	"delete.confirm_hard":  "Удалить безвозвратно записей: %d из таблицы '%s'? (да/нет): ",
+	"delete.cancelled":     "Удаление отменено",
+	"delete.failed":        "Ошибка: Не удалось удалить записи (возможно, на них ссылаются другие таблицы)",
+	"delete.done":          "\n✓ Удалено записей: %d",
+	"delete.archived":      "\n✓ Перемещено в архив записей: %d",
+	"restore.no_tables":    "Ни в одной таблице нет мягкого удаления (колонки archived или deleted_at)",
+	"restore.title":        "\n=== ВОССТАНОВЛЕНИЕ ИЗ АРХИВА ===",
+	"restore.id_prompt":    "Введите ID записи: ",
+	"restore.not_archived": "В таблице '%s' нет архивной записи с ID %s",
+	"restore.done":         "\n✓ Запись восстановлена из архива: %s, ID %s",
+
+	"history.kind_filter":     "фильтрация",
+	"history.kind_update":     "обновление",
+	"history.kind_insert":     "добавление",
+	"history.range":           "%s от '%s' до '%s'",
+	"history.in_list":         "%s в (%s)",
+	"history.lower_bound":     "%s (нижняя граница)",
+	"history.upper_bound":     "%s (верхняя граница)",
+	"history.describe_update": "%s: %s = '%s' для %s",
+	"history.describe_insert": "%s: %d записей (%s)",
+	"history.record_param":    "запись %d, %s",
+	"history.empty":           "\nИстория операций пуста",
+	"history.title":           "\n=== ИСТОРИЯ ОПЕРАЦИЙ ===",
+	"history.prompt":          "Выберите операцию для повтора: ",
+	"history.params_title":    "\n=== ПАРАМЕТРЫ ОПЕРАЦИИ ===",
+	"history.param_prompt":    "Номер значения для изменения (Enter — повторить без изменений): ",
+	"history.new_value":       "Новое значение для '%s': ",
+
+	"filter.bound_not_number": "поле '%s' должно содержать только число",
+	"filter.dates_reversed":   "начальная дата позже конечной",
+	"filter.bounds_reversed":  "нижняя граница больше верхней",
+	"filter.bound_required":   "укажите хотя бы одну границу",
+	"filter.hint_number":      "число",
+	"filter.hint_date":        "ГГГГ-ММ-ДД или RFC3339",
+	"filter.lower_prompt":     "Нижняя граница для '%s' (%s, Enter — без ограничения): ",
+	"filter.upper_prompt":     "Верхняя граница для '%s' (%s, Enter — без ограничения): ",
+	"filter.type_title":       "\n=== ТИП ФИЛЬТРА ===",
+	"filter.type_equals":      "1. Равно",
+	"filter.type_list":        "2. Одно из списка значений",
+	"filter.type_range":       "3. Диапазон (от и до)",
+	"filter.type_prompt":      "Выберите тип фильтра: ",
+	"filter.empty_list":       "список значений пуст",
+	"filter.list_prompt":      "Введите значения для '%s' через запятую: ",
+	"filter.combine_title":    "\n=== ОБЪЕДИНЕНИЕ УСЛОВИЙ ===",
+	"filter.combine_and":      "1. Все условия (И / AND)",
+	"filter.combine_or":       "2. Любое из условий (ИЛИ / OR)",
+	"filter.combine_prompt":   "Выберите способ объединения: ",
+	"filter.condition_title":  "\n=== Фильтр %d из %d ===",
+	"filter.value_prompt":     "Введите значение для фильтрации по '%s' (NULL — значение не задано, NOT NULL — задано): ",
+
+	"select_table.export":  "ВЫБОР ТАБЛИЦЫ ДЛЯ ЭКСПОРТА",
+	"export.format_title":  "\n=== ФОРМАТ ЭКСПОРТА ===",
+	"export.format_prompt": "Выберите формат: ",
+	"export.path_prompt":   "Введите путь к файлу (Enter — %s): ",
+	"export.resume_prompt": "Найден незавершенный экспорт в %s (%d строк). Продолжить? (да/нет): ",
+	"export.started":       "Экспорт запущен (Ctrl+C — прервать с возможностью продолжения)",
+	"export.interrupted":   "\nЭкспорт прерван после %d строк. Частичный файл: %s",
+	"export.resume_hint":   "Для продолжения: osl export --resume %s",
+	"export.done":          "\n✓ Экспортировано записей: %d в файл %s",
+	"export.progress":      "\rВыгружено записей: %d",
+
+	"common.yes":                 "да",
+	"common.no":                  "нет",
+	"render.type_placeholder":    "<тип %s>",
+	"render.mode_table":          "таблица",
+	"render.mode_vertical":       "по записям",
+	"render.mode_auto":           "авто",
+	"render.money_raw":           "цены как в БД",
+	"render.money_formatted":     "цены с форматированием",
+	"render.mode_prompt":         "Режим вывода: 1 — таблица, 2 — по записям, 3 — %s, 4 — авто по ширине терминала, 5 — ширина колонок (сейчас %d) (Enter — %s): ",
+	"render.mode_invalid":        "выберите 1, 2, 3 или 4",
+	"render.record_title":        "\n--- Запись %d ---",
+	"render.raw_prompt":          "\nВ результате есть значения нестандартных типов. Номер строки для просмотра (1-%d, Enter — пропустить): ",
+	"render.columns_title":       "\n=== ВЫБОР КОЛОНОК ДЛЯ ВЫВОДА ИЗ '%s' ===",
+	"render.columns_prompt":      "Введите номера колонок через запятую (например 1,3,5; Enter — все колонки): ",
+	"render.columns_invalid":     "номера колонок должны быть цифрами от 1 до %d",
+	"reports.title":              "\n=== ОТЧЁТЫ ===",
+	"reports.stock_by_component": "Остатки и стоимость по компонентам",
+	"reports.stock_by_warehouse": "Остатки по складам",
+	"reports.low_stock":          "Компоненты с остатком ниже порога",
+	"reports.low_stock_title":    "Компоненты с остатком ниже %d",
+	"reports.prompt":             "Выберите отчёт: ",
+	"reports.threshold_prompt":   "Введите порог количества (Enter — %d): ",
+	"reports.threshold_invalid":  "порог должен быть неотрицательным числом",
+	"reports.failed":             "Ошибка: Не удалось сформировать отчёт",
+	"reports.none":               "Записей не найдено",
+	"select_table.sort":          "ВЫБОР ТАБЛИЦЫ ДЛЯ НАСТРОЙКИ СОРТИРОВКИ",
+	"sort.done":                  "✓ Таблица '%s' будет сортироваться: %s",
+	"sort.collations_failed":     "Ошибка: Не удалось получить список правил сортировки",
+	"sort.collation_prompt":      "Введите правило сортировки или часть его имени (например C, ru_RU, icu; Enter — по умолчанию БД): ",
+	"sort.collation_unknown":     "правило сортировки '%s' недоступно в этой базе данных",
+	"sort.collations_title":      "\n=== ДОСТУПНЫЕ ПРАВИЛА СОРТИРОВКИ ===",
+	"sort.collation_choice":      "Выберите правило сортировки: ",
+	"select_table.describe":      "ВЫБОР ТАБЛИЦЫ ДЛЯ ПРОСМОТРА СТРУКТУРЫ",
+	"schema.unsupported_type":    "Колонка '%s' имеет тип %s, ввод которого не поддерживается, и будет пропущена",
+	"schema.failed":              "Ошибка: Не удалось получить структуру таблицы",
+	"schema.col_name":            "колонка",
+	"schema.col_type":            "тип",
+	"schema.col_default":         "по умолчанию",
+	"schema.col_key":             "ключ",
+	"schema.title":               "\n=== СТРУКТУРА ТАБЛИЦЫ '%s' ===",
+	"schema.matches":             "\nМетаданные программы совпадают с каталогом БД",
+	"schema.mismatches":          "\nРасхождения с метаданными программы:",
+	"schema.missing_in_db":       "колонка '%s' используется программой, но отсутствует в БД",
+	"schema.unknown_column":      "колонка '%s' есть в БД, но не известна программе",
+
+	"rules.check_failed":    "Ошибка: Не удалось проверить правила, операция отменена",
+	"rules.warning":         "Предупреждение",
+	"rules.blocked":         "Запрещено",
+	"rules.violation":       "%s (%s, запись %d): %s",
+	"rules.not_executed":    "Операция не выполнена",
+	"rules.confirm":         "Выполнить операцию, несмотря на предупреждения? (да/нет): ",
+	"rules.change_title":    "\n⚠ Подозрительно большое изменение значения:",
+	"rules.change_confirm":  "Подтвердите изменение (да/нет): ",
+	"rules.change_refused":  "Изменение отклонено: --yes не подтверждает подозрительные изменения (запустите программу с флагом --allow-large-price-changes)",
+	"runspec.line_done":     "Строка %d: найдено записей: %d -> %s",
+	"runspec.summary":       "Выполнено запусков: %d, пропущено строк с ошибками: %d",
+	"sql.write_allowed":     "\nВнимание: разрешены запросы, изменяющие данные (--allow-write)",
+	"sql.select_only":       "\nРазрешены только запросы SELECT",
+	"sql.rejected":          "Ошибка: разрешены только запросы SELECT (для остальных запустите программу с флагом --allow-write)",
+	"sql.executed":          "Запрос выполнен, затронуто записей: %d",
+	"sql.prompt":            "Введите запрос, завершив его символом ';' (пустой ввод или 'отмена' — вернуться в меню):",
+	"sql.input_cancelled":   "Ошибка: %v, запрос отменен",
+	"sql.single_statement":  "Ошибка: за один раз можно выполнить только один запрос",
+	"sql.timeout":           "Ошибка: превышено время выполнения запроса (OSL_SQL_TIMEOUT)",
+	"sql.error_position":    "Строка %d, позиция %d:",
+	"sql.hint":              "Подсказка: %s",
+	"clone.created":         "База '%s' создана",
+	"clone.partial_kept":    "Частично созданная база '%s' сохранена (--keep-partial)",
+	"clone.partial_dropped": "Частично созданная база '%s' удалена",
+	"clone.profile_added":   "Добавлен профиль подключения '%s'",
+	"clone.done":            "Клонирование завершено за %s",
+	"clone.parent_added":    "Таблица '%s' добавлена: на нее ссылается '%s'",
+	"clone.table_created":   "[%d/%d] %s: структура создана",
+	"clone.table_copied":    "[%d/%d] %s: скопировано строк: %d",
+	"clone.total":           "Всего скопировано строк: %d",
+
+	"cli.unknown_command":      "Неизвестная команда: %s",
+	"cli.commands":             "Доступные команды: %s",
+	"cli.bad_format":           "Ошибка: формат должен быть csv или json",
+	"cli.import_file_required": "Ошибка: укажите CSV-файл в --file",
+	"cli.spec_not_found":       "Ошибка: сохраненный фильтр '%s' не найден в файле конфигурации",
+	"cli.spec_invalid":         "Ошибка: фильтр '%s': %v",
+	"cli.params_file_required": "Ошибка: укажите файл параметров в --params-file",
+	"clone.target_required":    "Ошибка: укажите имя новой базы в --target (латинские буквы, цифры и _)",
+	"clone.mode_required":      "Ошибка: укажите ровно один из флагов --schema-only или --with-data",
+	"clone.dry_run":            "Ошибка: %s клонирование не выполняется в режиме проверки (OSL_DRY_RUN)",
+	"clone.profile_exists":     "Ошибка: профиль '%s' уже существует",
+	"clone.postgres_only":      "Ошибка: клонирование поддерживается только для PostgreSQL",
+	"validation.empty":         "значение не может быть пустым",
+	"validation.bad_chars":     "значение содержит недопустимые символы",
+	"validation.control_char":  "значение содержит недопустимый управляющий символ %U",
+	"validation.bad_sequence":  "значение содержит недопустимую последовательность '%s'",
+	"db.overloaded":            "База перегружена, повтор через %s...",
+	"db.connection_lost":       "Соединение с БД потеряно, переподключение...",
+	"db.reconnected":           "✓ Соединение восстановлено",
+	"rules.change_warning":     "%s.%s у записи %s: было %s, станет %s (%+.1f%%, порог %.0f%%)",
+
+	"transfer.title":              "\n=== ПЕРЕМЕЩЕНИЕ МЕЖДУ СКЛАДАМИ ===",
+	"transfer.source_prompt":      "Выберите склад, с которого перемещать: ",
+	"transfer.source":             "Склад-источник: %s, остаток %d шт.",
+	"transfer.source_empty":       "Ошибка: на складе '%s' нет остатка для перемещения",
+	"transfer.targets":            "\n=== ДРУГИЕ СКЛАДЫ КОМПОНЕНТА ===",
+	"transfer.target_prompt":      "Введите номер склада назначения или название нового склада: ",
+	"transfer.same_location":      "склад назначения совпадает с источником",
+	"transfer.quantity_prompt":    "Введите количество для перемещения (доступно %d шт.): ",
+	"transfer.quantity_packages":  "Введите количество для перемещения (доступно %d шт.; в упаковке %d шт., можно ввести N%s): ",
+	"transfer.quantity_positive":  "количество должно быть больше нуля",
+	"transfer.quantity_too_large": "на складе только %d шт.",
+	"transfer.confirm":            "Переместить %d шт. '%s' со склада '%s' на склад '%s'? (да/нет): ",
+	"transfer.insufficient":       "Ошибка: недостаточно остатка на складе-источнике (остаток %d, требуется %d), перемещение отменено",
+	"transfer.failed":             "Ошибка: Не удалось выполнить перемещение, изменения отменены",
+	"transfer.done":               "✓ Перемещено %d шт. '%s'",
+	"transfer.balance":            "  %s: %d → %d шт.",
+	"transfer.created":            "Для склада '%s' создана запись stock (id=%s)",
+
+	"select_table.dump":    "ВЫБОР ТАБЛИЦЫ ДЛЯ ВЫГРУЗКИ В SQL",
+	"dump.truncate_prompt": "Добавить в начало файла очистку таблицы '%s' (TRUNCATE)? (да/нет): ",
+	"dump.failed":          "Ошибка: Не удалось выгрузить таблицу",
+	"dump.done":            "\n✓ Выгружено записей: %d в файл %s",
+
+	"import.progress":             "\rОбработано строк: %d",
+	"dump_restore.path_prompt":    "Введите путь к файлу выгрузки (.sql или .csv): ",
+	"dump_restore.path_required":  "укажите путь к файлу",
+	"dump_restore.file_missing":   "файл '%s' не найден",
+	"dump_restore.unknown_format": "Ошибка: поддерживаются только файлы .sql и .csv",
+	"dump_restore.no_statements":  "Ошибка: в файле нет запросов",
+	"dump_restore.preview_title":  "\n=== ВОССТАНОВЛЕНИЕ: %d запросов в базу '%s', первые из них ===",
+	"dump_restore.preview_csv":    "\n=== ВОССТАНОВЛЕНИЕ: первые %d строк в таблицу '%s' базы '%s' ===",
+	"dump_restore.preview_line":   "  строка %d: %s",
+	"dump_restore.confirm":        "Выполнить %d запросов в базе '%s'? (да/нет): ",
+	"dump_restore.confirm_csv":    "Импортировать файл в таблицу '%s' базы '%s'? (да/нет): ",
+	"dump_restore.dry_run":        "%s Проверено запросов: %d, изменения не выполнены",
+	"dump_restore.progress":       "\rВыполнено запросов: %d из %d",
+	"dump_restore.done":           "\n✓ Выполнено запросов: %d (за %s)",
+	"dump_restore.summary":        "По таблицам: %s",
+	"dump_restore.failed_line":    "Ошибка восстановления, строка %d: %v",
+	"dump_restore.rolled_back":    "Восстановление отменено, изменения не сохранены",
+	"dump_restore.unterminated":   "запрос не завершен точкой с запятой",
+	"dump_restore.not_insert":     "допускаются только запросы INSERT, а не %s",
+	"dump_restore.unknown_table":  "таблица %s не известна программе",
+	"dump_restore.bad_insert":     "ожидается INSERT INTO таблица (колонки) VALUES (...) только с константами, ошибка у '%s'",
+
+	"presets.save_prompt":    "\nИмя для сохранения условий фильтра (Enter — не сохранять): ",
+	"presets.name_required":  "имя не может быть пустым",
+	"presets.overwrite":      "Фильтр '%s' уже сохранен. Заменить? (да/нет): ",
+	"presets.masked":         "Значения чувствительных колонок не сохраняются: они будут запрошены при применении фильтра",
+	"presets.sensitive_list": "список значений чувствительной колонки '%s' нельзя сохранить",
+	"presets.save_failed":    "Ошибка: не удалось сохранить файл конфигурации (задан ли OSL_CONFIG?)",
+	"presets.saved":          "Фильтр '%s' сохранен",
+	"presets.empty":          "Сохраненных фильтров нет. Условия можно сохранить после фильтрации (пункт 2)",
+	"presets.title":          "\n=== СОХРАНЕННЫЕ ФИЛЬТРЫ ===",
+	"presets.item":           "%d. %s — %s: %s",
+	"presets.item_invalid":   "%d. %s — %s: не применим (%v)",
+	"presets.action_apply":   "1. Применить",
+	"presets.action_delete":  "2. Удалить",
+	"presets.action_prompt":  "Выберите действие: ",
+	"presets.choose":         "Номер или имя фильтра: ",
+	"presets.not_found":      "фильтр '%s' не найден",
+	"presets.table_prompt":   "Таблица (Enter — %s): ",
+	"presets.invalid":        "Ошибка: фильтр '%s' не применим: %v",
+	"presets.param_prompt":   "Значение параметра %s: ",
+	"presets.delete_confirm": "Удалить фильтр '%s'? (да/нет): ",
+	"presets.deleted":        "Фильтр '%s' удален",
+
+	"switch_db.current":       "\nТекущая база: %s",
+	"switch_db.name_prompt":   "Имя базы данных (DB_NAME): ",
+	"switch_db.name_required": "имя базы не может быть пустым",
+	"switch_db.bad_name":      "имя базы может содержать только латинские буквы, цифры и _",
+	"switch_db.schema_prompt": "Схема (search_path, Enter — по умолчанию): ",
+	"switch_db.bad_schema":    "имя схемы может содержать только латинские буквы, цифры и _",
+	"switch_db.failed":        "Ошибка: не удалось подключиться к базе %s",
+	"switch_db.kept":          "Работа продолжается с базой %s",
+	"switch_db.done":          "Подключено к базе %s, таблиц: %d",
+
+	"explain.prompt": "Показать план выполнения запроса? (да/нет, Enter — нет): ",
+	"explain.title":  "\n=== ПЛАН ЗАПРОСА (%s) ===",
+	"explain.failed": "Ошибка: не удалось получить план запроса",
+
+	"select_table.generate":   "ВЫБОР ТАБЛИЦЫ ДЛЯ ТЕСТОВЫХ ДАННЫХ",
+	"undo.kind_generate":      "генерация тестовых данных в %s",
+	"generate.no_parent_rows": "колонка %s ссылается на таблицу %s, в которой нет записей",
+	"generate.unsupported":    "для обязательной колонки %s (%s) нет генератора значений",
+	"generate.no_columns":     "в таблице %s нет колонок, которые можно заполнить",
+	"generate.columns":        "Заполняемые колонки: %s",
+	"generate.count_prompt":   "Количество записей: ",
+	"generate.sample":         "\nПример сгенерированных записей:",
+	"generate.confirm":        "Добавить %d записей в таблицу %s базы %s? (да/нет): ",
+	"generate.dry_run":        "%s Записи не добавлены (сгенерировано %d)",
+	"generate.progress":       "\rДобавлено %d из %d",
+	"generate.failed":         "Ошибка: не удалось добавить тестовые данные, изменения отменены",
+	"generate.done":           "Добавлено %d записей в таблицу %s за %s",
+
+	"undo.kind_product":     "полный ввод нового товара",
+	"product.level":         "\n--- Таблица '%s' ---",
+	"product.pick_existing": "1. Выбрать существующую запись",
+	"product.create_new":    "2. Создать новую запись",
+	"product.choice_prompt": "Выберите действие: ",
+	"product.auto_existing": "  Автоматически установлено: %s = %s",
+	"product.auto_new":      "  Автоматически установлено: %s = id новой записи в '%s'",
+	"product.reused":        "✓ Используется существующая запись в '%s' с ID: %s",
+	"product.plan_title":    "\n=== БУДУТ ДОБАВЛЕНЫ ЗАПИСИ ===",
+	"product.plan_existing": "  %s: существующая запись с ID %s",
+	"product.plan_new":      "  %s: новая запись (%s)",
+	"product.plan_parent":   "%s=<id новой записи в '%s'>",
+	"product.confirm":       "Добавить записи в базу %s? (да/нет): ",
+	"product.dry_run":       "\n%s Записи не добавлены",
+	"product.no_id":         "не удалось получить id новой записи в %s",
+	"product.failed":        "Ошибка: не удалось добавить товар, все записи этого ввода отменены",
+	"product.inserted":      "✓ В таблицу '%s' добавлена запись с ID: %s",
+	"product.unavailable":   "Ошибка: для полного ввода товара нужны таблицы %s и %s со ссылкой %s",
+
+	"delete.referenced_title": "\nВнимание: на удаляемые записи ссылаются записи других таблиц:",
+	"delete.referenced":       "  на записи '%s' ссылается записей из '%s': %d (колонка %s)",
+	"delete.cascade_option":   "1. Удалить вместе со ссылающимися записями (каскадно)",
+	"delete.cancel_option":    "0. Отменить удаление",
+	"delete.cascade_prompt":   "Выберите действие: ",
+	"delete.cascade_confirm":  "Безвозвратно удалить также %d ссылающихся записей из других таблиц? (да/нет): ",
+	"delete.cascade_done":     "✓ Из таблицы '%s' удалено ссылающихся записей: %d",
+
+	"validation.too_long":     "максимум %d символов, введено %d",
+	"validation.out_of_range": "значение должно быть от %s до %s",
+	"validation.below_min":    "значение должно быть не меньше %s, введено %s",
+	"validation.scale":        "максимум %d цифр после запятой, введено %d",
+	"validation.precision":    "максимум %d цифр до запятой, введено %d",
+
+	"validation.not_bool": "поле '%s' принимает значения да/нет, true/false или 1/0",
+	"input.bool_hint":     " (да/нет): ",
+
+	"log_view.lines_prompt":  "Количество последних строк журнала (Enter — %d): ",
+	"log_view.errors_prompt": "Показать только ошибки? (да/нет, Enter — нет): ",
+	"log_view.sync_failed":   "Не удалось сбросить журнал на диск: %v",
+	"log_view.rotated":       "Файл журнала %s был заменен при ротации, показан новый файл",
+	"log_view.read_failed":   "Ошибка: не удалось прочитать журнал %s: %v",
+	"log_view.empty":         "В журнале %s нет подходящих записей",
+	"log_view.title":         "\n=== ЖУРНАЛ %s (строк: %d) ===",
+
+	"paging.footer":     "\nСтраница %d из %d (записи %d–%d из %d)",
+	"paging.prompt":     "Enter или 1 — следующая страница, 2 — предыдущая, 0 — выход: ",
+	"paging.first_page": "это первая страница",
+	"paging.no_more":    "Больше записей нет",
+
+	"validation.bad_date": "поле '%s' ожидает дату в формате ГГГГ-ММ-ДД или RFC3339, например %s",
+	"validation.bad_time": "поле '%s' ожидает дату и время в формате TIME_FORMAT (например %s), ГГГГ-ММ-ДД или RFC3339",
+	"input.time_hint":     " (формат %s, ГГГГ-ММ-ДД или RFC3339): ",
+	"input.date_hint":     " (ГГГГ-ММ-ДД): ",
+
+	"log_view.stdout_only": "Журнал выводится только в stdout (LOG_TARGET=stdout или файл логов недоступен) — просмотр в программе невозможен.",
+
+	"menu.lookup":       "29. Найти запись по ключу",
+	"select_table.jump": "ВЫБОР ТАБЛИЦЫ ДЛЯ ПОИСКА ЗАПИСИ ПО КЛЮЧУ",
+	"jump.no_key":       "В таблице %s нет первичного ключа — найти запись по ключу нельзя",
+	"jump.key_prompt":   "Введите значение %s: ",
+	"jump.not_found":    "В таблице %s нет записи с %s",
+	"jump.archived":     "Запись помечена как удаленная (архивная).",
+
+	"reports.audit":     "Журнал изменений (аудит)",
+	"audit.unavailable": "Журнал аудита %s не найден: изменения еще не записывались или журнал отключен (OSL_AUDIT=false)",
+	"audit.table_title": "\n=== ОТБОР ЖУРНАЛА АУДИТА ПО ТАБЛИЦЕ ===",
+	"audit.all_tables":  "0. Все таблицы",
+	"audit.from_prompt": "Дата начала ГГГГ-ММ-ДД (Enter — без ограничения): ",
+	"audit.to_prompt":   "Дата окончания ГГГГ-ММ-ДД включительно (Enter — без ограничения): ",
+	"audit.bad_date":    "ожидается дата в формате ГГГГ-ММ-ДД",
+
+	"filter.type_date_range": "3. Раньше, позже или между датами",
+	"filter.time_range":      "Колонка '%s' содержит время: задайте границы (только нижняя — позже, только верхняя — раньше, обе — между)",
+	"filter.hint_time":       "ГГГГ-ММ-ДД, %s или RFC3339",
+
+	"summary.title":        "\n=== ПРОВЕРЬТЕ ИЗМЕНЕНИЕ ===",
+	"summary.insert":       "Добавление в таблицу %s, записей: %d",
+	"summary.update":       "Обновление таблицы %s, записей: %d",
+	"summary.more_records": "... и еще записей: %d",
+	"summary.confirm":      "Выполнить? (да/нет): ",
+
+	"review.execute":      "1. Добавить записи",
+	"review.edit":         "2. Исправить поле",
+	"review.cancel":       "0. Отменить",
+	"review.prompt":       "Выберите действие: ",
+	"review.fields_title": "\n=== ВЫБОР ПОЛЯ ДЛЯ ИСПРАВЛЕНИЯ ===",
+	"review.field_prompt": "Выберите поле: ",
+	"related.failed":      "Ошибка: не удалось добавить связанные записи, изменения этой записи отменены",
+
+	"menu.reload":           "30. Обновить структуру БД",
+	"reload.changes_title":  "\n=== ИЗМЕНЕНИЯ СТРУКТУРЫ БД ===",
+	"reload.table_added":    "  + таблица %s (%s)",
+	"reload.table_removed":  "  - таблица %s",
+	"reload.column_added":   "  + колонка %s.%s",
+	"reload.column_removed": "  - колонка %s.%s",
+	"reload.no_changes":     "Структура БД не изменилась",
+	"reload.done":           "✓ Структура БД загружена заново, таблиц: %d",
+
+	"render.width_prompt":     "Максимальная ширина колонки в символах (сейчас %d): ",
+	"render.truncated_prompt": "\nДлинные значения обрезаны. Номер строки для просмотра целиком (1-%d, Enter — пропустить): ",
+
+	"related.none": "Ошибка: в базе нет связанных таблиц (внешних ключей между таблицами из списка)",
+
+	"menu.card":           "31. Карточка записи",
+	"select_table.card":   "ВЫБОР ТАБЛИЦЫ ДЛЯ КАРТОЧКИ ЗАПИСИ",
+	"card.title":          "\n=== КАРТОЧКА ЗАПИСИ: %s, %s = %s ===",
+	"card.parent_title":   "\n--- %s → %s ---",
+	"card.parent_empty":   "Ссылка %s не заполнена",
+	"card.parent_missing": "В таблице %s нет записи с %s=%s, на которую ссылается запись",
+	"card.children_title": "\n--- Связанные записи %s (по %s) ---",
+	"card.children_empty": "В таблице %s нет записей, ссылающихся на эту запись",
+	"card.children_limit": "Показаны первые %d связанных записей",
+
+	"menu.indexes": "32. Обслуживание индексов",
+
+	"indexes.title":              "\n=== ОБСЛУЖИВАНИЕ ИНДЕКСОВ ===",
+	"indexes.none":               "В запросах сеанса нет условий, которым не хватает индекса (условие должно встретиться не менее %d раз)",
+	"indexes.reason_soft_delete": "Отбор неархивных записей '%s' (запросов в сеансе: %d), частичный индекс:",
+	"indexes.reason_low_stock":   "Отчет о заканчивающихся товарах по '%s' (запросов в сеансе: %d), покрывающий индекс:",
+	"indexes.unsupported":        "такой индекс не поддерживается текущей СУБД",
+	"indexes.prompt":             "Выберите индекс: ",
+	"indexes.plan_before":        "\nПлан запроса без индекса:",
+	"indexes.plan_after":         "\nПлан запроса с индексом:",
+	"indexes.plan_failed":        "Ошибка: не удалось получить план запроса",
+	"indexes.cost":               "Оценка стоимости запроса: %s → %s",
+	"indexes.ddl_required":       "Индексы создаются и удаляются только в режиме DDL: запустите программу с флагом --allow-ddl",
+	"indexes.confirm":            "Создать индекс %s? (да/нет): ",
+	"indexes.progress":           "  построение индекса: %s, %d%%",
+	"indexes.created":            "✓ Индекс %s создан за %s",
+	"indexes.create_failed":      "Ошибка: индекс %s не создан: %v",
+	"indexes.invalid_found":      "Недействительные индексы после прерванного построения: %d",
+	"indexes.invalid_confirm":    "Удалить их? (да/нет): ",
+	"indexes.invalid_dropped":    "Недействительный индекс %s удален",
+	"indexes.drop_failed":        "Ошибка: не удалось удалить недействительный индекс %s: %v",
+
+	"search.scope_text":   "1. Искать в текстовых колонках",
+	"search.scope_all":    "2. Искать по всем колонкам (числа, даты и другие значения как текст)",
+	"search.scope_prompt": "Где искать: ",
+
+	"schema_check.missing": "В базе данных нет таблиц программы: %s",
+	"schema_check.confirm": "Создать недостающие таблицы? (да/нет): ",
+	"schema_check.skipped": "Таблицы не созданы, действия с ними будут завершаться ошибкой",
+	"schema_check.failed":  "Ошибка: не удалось создать таблицу %s",
+	"schema_check.created": "✓ Создана таблица %s",
+
+	"menu.readonly_tag":     " (только чтение)",
+	"readonly.blocked":      "Ошибка: включен режим только для чтения, изменение данных запрещено",
+	"schema_check.readonly": "Режим только для чтения: недостающие таблицы не создаются",
+
+	"connect.password_again": "Сервер не принял пароль. Введите пароль еще раз: ",
+	"db.resume_menu":         "Работа продолжается с главного меню",
+	"db.gave_up":             "Ошибка: не удалось восстановить соединение с БД, программа завершается",
+
+	"input.default_hint":  " (Enter или DEFAULT — по умолчанию: %s): ",
+	"insert.default_used": "  Колонка %s будет заполнена значением по умолчанию",
+	"insert.all_default":  "Ошибка: для всех колонок таблицы %s выбраны значения по умолчанию, добавлять нечего",
+}
+
+// Английский словарь
+var messagesEN = map[string]string{
+	"common.back":            "0. Back to menu",
+	"common.error":           "Error: %v",
+	"common.found_rows":      "\nRecords found: %d (in %s)",
+	"common.table_not_found": "Error: table '%s' not found",
+	"common.choose_table":    "Choose a table: ",
+	"common.choose_column":   "Choose a column: ",
+	"common.no_key":          "Table '%s' has no single-column primary key, its records cannot be updated or deleted by key",
+
+	"connect.title":       "=== Connecting to the database ===",
+	"connect.login":       "Login: ",
+	"connect.password":    "Password: ",
+	"connect.open_failed": "Error: could not connect to the database. Check your credentials.",
+	"connect.ping_failed": "Error: could not connect to the database. Check your credentials and that the database is available.",
+	"connect.ok":          "✓ Connected to the database",
+	"connect.waiting":     "Waiting for the database to start (up to %s)",
+	"connect.retry_write": "The change may have been applied before the connection was lost. Retry it? (yes/no): ",
+
+	"menu.title":          "\n=== MENU (database: %s) ===",
+	"menu.dry_run_banner": "%s Dry-run mode is on: changes are not sent to the database",
+	"menu.view":           "1. View table",
+	"menu.filter":         "2. Filter",
+	"menu.update":         "3. Update records",
+	"menu.insert":         "4. Add records",
+	"menu.insert_related": "5. Add records to related tables",
+	"menu.sort":           "6. Sort settings",
+	"menu.reports":        "7. Reports",
+	"menu.export":         "8. Export table",
+	"menu.describe":       "9. Table structure",
+	"menu.history":        "10. Operation history",
+	"menu.sql":            "11. SQL query",
+	"menu.status":         "12. Connection status",
+	"menu.dry_run_off":    "13. Dry-run mode: turn off",
+	"menu.dry_run_on":     "13. Dry-run mode: turn on",
+	"menu.stock":          "14. Stock adjustment",
+	"menu.language":       "15. Language (Язык интерфейса)",
+	"menu.import":         "16. Import from CSV",
+	"menu.undo":           "17. Undo last operation",
+	"menu.search":         "18. Search",
+	"menu.delete":         "19. Delete records",
+	"menu.restore":        "20. Restore an archived record",
+	"menu.transfer":       "21. Transfer between warehouses",
+	"menu.dump":           "22. Dump table to an SQL file",
+	"menu.restore_dump":   "23. Restore from a dump (SQL or CSV)",
+	"menu.presets":        "24. Saved filters",
+	"menu.switch_db":      "25. Switch database",
+	"menu.generate":       "26. Generate test data",
+	"menu.product":        "27. Full entry of a new product",
+	"menu.show_log":       "28. Show the log",
+	"menu.exit":           "0. Exit",
+	"menu.prompt":         "Choose a menu item: ",
+	"menu.invalid":        "Error: choose a number from 0 to %d",
+	"menu.bye":            "Exiting...",
+
+	"language.title":   "\n=== LANGUAGE ===",
+	"language.prompt":  "Choose a language: ",
+	"language.changed": "✓ Interface language: %s",
+
+	"input.too_long":          "input too long: %d characters (maximum %d)",
+	"input.interrupted":       "\nInput closed, operation cancelled",
+	"input.cancelled":         "Operation cancelled",
+	"input.retry":             "Try again (or type 'cancel' to exit)",
+	"input.too_many_attempts": "Error: too many invalid attempts, operation cancelled",
+	"input.min_number":        "enter a number not less than %d",
+	"input.choose_range":      "choose a number from %d to %d",
+	"input.not_number":        "field '%s' must be a number",
+	"input.yes_no":            "answer 'yes' or 'no'",
+
+	"select_table.view":   "\n=== CHOOSE A TABLE TO VIEW ===",
+	"select_table.filter": "CHOOSE A TABLE TO FILTER",
+	"select_table.update": "CHOOSE A TABLE TO UPDATE",
+	"select_table.insert": "CHOOSE A TABLE TO ADD TO",
+	"select_table.import": "CHOOSE A TABLE TO IMPORT INTO",
+	"select_table.search": "CHOOSE A TABLE TO SEARCH",
+	"select_table.delete": "CHOOSE A TABLE TO DELETE FROM",
+	"select_column.title": "\n=== CHOOSE A COLUMN IN '%s' ===",
+
+	"view.count":            "The table has %d records",
+	"view.include_archived": "The table has archived records (%d). Show them? (yes/no): ",
+	"view.confirm_all":      "Show all records at once? (yes/no, no — browse by pages): ",
+	"view.query_failed":     "Error: could not query the table",
+	"stream.notice":         "Many records: they are printed as they are read, column widths are based on the first rows",
+
+	"filter.count":            "\nNumber of filters (at least 1): ",
+	"filter.failed":           "Error: could not run the filter",
+	"filter.none":             "No records match the filters",
+	"filter.include_archived": "Include archived records (%d)? (yes/no): ",
+
+	"update.mode_title":       "\n=== HOW TO SELECT RECORDS ===",
+	"update.mode_ids":         "1. By ID",
+	"update.mode_conditions":  "2. By condition",
+	"update.mode_prompt":      "Choose a method: ",
+	"update.count_ids":        "\nNumber of records to update (at least 1): ",
+	"update.count_conditions": "\nNumber of conditions (at least 1): ",
+	"update.no_columns":       "The table has no columns that can be updated",
+	"update.id_prompt":        "ID of record %d to update: ",
+	"update.id_duplicate":     "this ID has already been entered",
+	"update.column_title":     "\n=== CHOOSE A COLUMN TO UPDATE IN '%s' ===",
+	"update.column_prompt":    "Choose a column to update: ",
+	"update.value_prompt":     "New value for '%s' in table '%s': ",
+	"update.check_failed":     "Error: could not check that the records exist",
+	"update.ids_not_found":    "No records with the given IDs in table '%s'",
+	"update.missing_ids":      "Records not found for IDs: %s",
+	"update.confirm_partial":  "Update only the records that were found (%d)? (yes/no): ",
+	"update.cancelled":        "Update cancelled",
+	"update.find_failed":      "Error: could not find the records to update",
+	"update.none_matched":     "No records match the conditions",
+	"update.rules_failed":     "Error: could not check the rules, update cancelled",
+	"update.failed":           "Error: could not update the data",
+	"update.done":             "Records updated: %d",
+	"update.partial": "%[2]d of %[1]d records found were updated: the rest were deleted or changed by another user " +
+		"after the check, or already had this value",
+
+	"insert.count":        "\nNumber of records to create (at least 1): ",
+	"insert.record_title": "\n=== Record %d of %d ===",
+	"insert.pick_value":   "Choose a value for '%s':",
+	"insert.value_prompt": "Value for '%s': ",
+	"insert.failed":       "Error: could not add the records, none were added",
+	"insert.done":         "\nRecords added: %d (in %s)",
+	"insert.cancelled":    "Adding cancelled",
+
+	"related.title":        "\n=== CHOOSE RELATED TABLES ===",
+	"related.prompt":       "Choose related tables: ",
+	"related.record_title": "\n=== Related records %d of %d ===",
+	"related.table_data":   "\n--- Data for table '%s' ---",
+	"related.dry_run_done": "\n%s Related records were not added",
+	"related.done":         "\nRelated records added: %d",
+
+	"lookup.manual_id":    "Enter the ID manually: ",
+	"lookup.empty":        "Table '%s' has no records",
+	"lookup.search":       "Table '%s' has %d records. Enter part of a name to search (Enter shows all, #<id> enters an ID): ",
+	"lookup.no_match":     "no records with '%s' in the name",
+	"lookup.title":        "\n=== CHOOSE A RECORD FROM '%s' ===",
+	"lookup.prompt":       "Choose a record or enter #<id>: ",
+	"lookup.check_failed": "could not check that the record exists",
+	"lookup.not_found":    "no such record in table '%s'",
+
+	"dry_run.not_executed": "\n%s Query not executed:\n%s",
+	"dry_run.params":       "Parameters: %s",
+	"dry_run.preview":      "\n%s Records that would be updated (%s):",
+	"dry_run.on":           "✓ Dry-run mode on: changes will not be sent to the database",
+	"dry_run.off":          "✓ Dry-run mode off: changes are executed",
+
+	"status.title":       "\n=== CONNECTION STATUS ===",
+	"status.driver":      "Driver:        %s",
+	"status.host":        "Host:          %s",
+	"status.port":        "Port:          %s",
+	"status.ssl":         "SSL:           %s",
+	"status.user":        "User:          %s",
+	"status.ping_failed": "Connection:    error (%v)",
+	"status.ping_ok":     "Connection:    OK (responded in %s)",
+	"status.info_failed": "Server:        could not get server details (%v)",
+	"status.server":      "Server:        %s",
+	"status.database":    "Database:      %s",
+	"status.pool":        "\n--- Connection pool ---",
+	"status.pool_open":   "Open:          %d (in use %d, idle %d)",
+	"status.pool_wait":   "Waits:         %d (total %s)",
+	"status.pool_closed": "Closed:        idle %d, lifetime %d",
+
+	"stock.title":           "\n=== STOCK ADJUSTMENT ===",
+	"stock.no_rows":         "Error: component '%s' has no records in table stock",
+	"stock.locations":       "\n=== WAREHOUSES FOR '%s' ===",
+	"stock.location":        "%d. %s — %d pcs (id=%s)",
+	"stock.location_prompt": "Choose a warehouse: ",
+	"stock.current":         "Current quantity: %d pcs",
+	"stock.negative":        "quantity cannot go below zero",
+	"stock.rejected":        "Error: %v (quantity %d, change %+d)",
+	"stock.row_deleted":     "Error: the stock record was deleted, nothing was adjusted",
+	"stock.done":            "✓ Quantity of '%s' changed: %d → %d pcs (%+d)",
+	"stock.delta_prompt":    "Change in quantity (e.g. +10 or -3): ",
+	"stock.delta_packages":  "Change in quantity (e.g. +10 or -3; %d pcs per package, N%s is accepted): ",
+	"stock.delta_zero":      "the change cannot be zero",
+	"stock.packages":        "%d %s × %d pcs = %d pcs",
+
+	"packages.fractional":   "a fractional number of packages is not allowed, enter a whole number or the quantity in pieces",
+	"packages.not_integer":  "the number of packages must be a whole number, e.g. 5%s",
+	"packages.unknown_size": "the component has no package size (units_per_package), enter the quantity in pieces",
+	"packages.prompt_hint":  " (%d pcs per package, N%s is accepted): ",
+	"packages.confirm":      "%d %s × %d pcs = %d pcs. Confirm? (yes/no): ",
+
+	"duplicates.found":   "Table '%s' already has records with %s = '%s' (case-insensitive):",
+	"duplicates.item":    "  id=%s: %s",
+	"duplicates.proceed": "1. Add a new record anyway",
+	"duplicates.reuse":   "2. Use the existing record",
+	"duplicates.cancel":  "0. Cancel",
+	"duplicates.prompt":  "Choose an action: ",
+	"duplicates.pick":    "Choose the existing record: ",
+
+	"import.path_prompt":      "Path to a CSV file with a header row (Enter for %s): ",
+	"import.failed_line":      "Import error, line %d: %v",
+	"import.rolled_back":      "Import cancelled, no records were added",
+	"import.done":             "\n✓ Records imported: %d into '%s' (in %s)",
+	"import.dry_run":          "%s Rows checked: %d, no records were added",
+	"import.empty_file":       "the file is empty",
+	"import.unknown_column":   "column '%s' not found in table '%s'",
+	"import.duplicate_column": "column '%s' appears twice in the header",
+	"import.no_columns":       "the header has no columns to import",
+	"import.missing_value":    "no value for column '%s'",
+	"import.rule_blocked":     "rule '%s' violated: %s",
+	"import.rule_warning":     "Warning (%s, line %d): %s",
+
+	"quality.title":         "\n=== QUALITY CHECK: table '%s', rows added: %d ===",
+	"quality.numeric":       "%s: min %s, max %s, average %s, empty %d",
+	"quality.numeric_old":   "(before import: min %s, max %s, average %s)",
+	"quality.numeric_empty": "%s: all values are empty (%d)",
+	"quality.no_old_data":   "(no data before import)",
+	"quality.text":          "%s: empty %d of %d (before import: empty strings %d, NULL %d of %d)",
+	"quality.outliers":      "    values far from existing data: %d, examples:",
+	"quality.example":       "    line %d: %s",
+	"quality.clean":         "No strong deviations from existing data found",
+	"quality.flagged":       "Columns flagged: %d — review these file lines, the import was not rolled back",
+	"quality.saved":         "Report written to %s",
+	"quality.write_failed":  "Could not write report %s: %v",
+
+	"undo.nothing":       "Nothing to undo: no undoable changes have been made since the program started",
+	"undo.title":         "\n=== UNDO LAST OPERATION ===",
+	"undo.operation":     "Operation at %s: %s",
+	"undo.restore_step":  "restore previous values of %s.%s in records: %d",
+	"undo.delta_step":    "change %s.%s by %+d in records: %d",
+	"undo.delete_step":   "delete records added to %s: %d",
+	"undo.confirm":       "Undo the operation? (yes/no): ",
+	"undo.cancelled":     "Undo cancelled",
+	"undo.failed":        "Undo failed, no changes were made",
+	"undo.done":          "\n✓ Operation undone, records affected: %d",
+	"undo.partial":       "Warning: %d records had already been changed or deleted by other means",
+	"undo.kind_update":   "update of %s.%s",
+	"undo.kind_insert":   "insert into %s",
+	"undo.kind_related":  "insert into related tables %s",
+	"undo.kind_stock":    "stock adjustment of '%s'",
+	"undo.kind_archive":  "archiving records in %s",
+	"undo.kind_restore":  "restoring an archived record in %s",
+	"undo.kind_transfer": "transfer of '%s' between warehouses",
+
+	"search.columns":         "Searching columns: %s",
+	"search.no_text_columns": "Table '%s' has no text columns to search",
+	"search.prompt":          "Text to search for: ",
+	"search.empty":           "the search text cannot be empty",
+	"search.failed":          "Error: could not run the search",
+	"search.none":            "No records contain '%s'",
+	"search.matched_column":  "matched",
+
+	"delete.soft_notice":   "Records in '%s' are not deleted but marked as archived (column %s)",
+	"delete.hard_notice":   "Warning: records in '%s' will be deleted permanently",
+	"delete.ids_prompt":    "Record IDs, separated by commas: ",
+	"delete.none_found":    "No records with these IDs in '%s' (or they are already archived)",
+	"delete.skipped":       "Not found or already archived: %s",
+	"delete.confirm_soft":  "Archive %d records from '%s'? (yes/no): ",
+	"delete.confirm_hard":  "Permanently delete %d records from '%s'? (yes/no): ",
+	"delete.cancelled":     "Deletion cancelled",
+	"delete.failed":        "Error: could not delete the records (other tables may reference them)",
+	"delete.done":          "\n✓ Records deleted: %d",
+	"delete.archived":      "\n✓ Records archived: %d",
+	"restore.no_tables":    "No table supports soft delete (an archived or deleted_at column)",
+	"restore.title":        "\n=== RESTORE FROM ARCHIVE ===",
+	"restore.id_prompt":    "Record ID: ",
+	"restore.not_archived": "Table '%s' has no archived record with ID %s",
+	"restore.done":         "\n✓ Record restored from the archive: %s, ID %s",
+
+	"history.kind_filter":     "filter",
+	"history.kind_update":     "update",
+	"history.kind_insert":     "insert",
+	"history.range":           "%s from '%s' to '%s'",
+	"history.in_list":         "%s in (%s)",
+	"history.lower_bound":     "%s (lower bound)",
+	"history.upper_bound":     "%s (upper bound)",
+	"history.describe_update": "%s: %s = '%s' for %s",
+	"history.describe_insert": "%s: %d records (%s)",
+	"history.record_param":    "record %d, %s",
+	"history.empty":           "\nThe operation history is empty",
+	"history.title":           "\n=== OPERATION HISTORY ===",
+	"history.prompt":          "Choose an operation to repeat: ",
+	"history.params_title":    "\n=== OPERATION PARAMETERS ===",
+	"history.param_prompt":    "Number of the value to change (Enter to repeat unchanged): ",
+	"history.new_value":       "New value for '%s': ",
+
+	"filter.bound_not_number": "field '%s' must contain a number only",
+	"filter.dates_reversed":   "the start date is after the end date",
+	"filter.bounds_reversed":  "the lower bound is greater than the upper bound",
+	"filter.bound_required":   "enter at least one bound",
+	"filter.hint_number":      "number",
+	"filter.hint_date":        "YYYY-MM-DD or RFC3339",
+	"filter.lower_prompt":     "Lower bound for '%s' (%s, Enter for none): ",
+	"filter.upper_prompt":     "Upper bound for '%s' (%s, Enter for none): ",
+	"filter.type_title":       "\n=== FILTER TYPE ===",
+	"filter.type_equals":      "1. Equals",
+	"filter.type_list":        "2. One of a list of values",
+	"filter.type_range":       "3. Range (from and to)",
+	"filter.type_prompt":      "Choose a filter type: ",
+	"filter.empty_list":       "the list of values is empty",
+	"filter.list_prompt":      "Values for '%s', separated by commas: ",
+	"filter.combine_title":    "\n=== COMBINING CONDITIONS ===",
+	"filter.combine_and":      "1. All conditions (AND)",
+	"filter.combine_or":       "2. Any condition (OR)",
+	"filter.combine_prompt":   "Choose how to combine: ",
+	"filter.condition_title":  "\n=== Filter %d of %d ===",
+	"filter.value_prompt":     "Value to filter '%s' by (NULL — not set, NOT NULL — set): ",
+
+	"select_table.export":  "CHOOSE A TABLE TO EXPORT",
+	"export.format_title":  "\n=== EXPORT FORMAT ===",
+	"export.format_prompt": "Choose a format: ",
+	"export.path_prompt":   "File path (Enter for %s): ",
+	"export.resume_prompt": "Found an unfinished export in %s (%d rows). Resume? (yes/no): ",
+	"export.started":       "Export started (Ctrl+C to stop; it can be resumed later)",
+	"export.interrupted":   "\nExport stopped after %d rows. Partial file: %s",
+	"export.resume_hint":   "To resume: osl export --resume %s",
+	"export.done":          "\n✓ Records exported: %d to %s",
+	"export.progress":      "\rRecords exported: %d",
+
+	"common.yes":                 "yes",
+	"common.no":                  "no",
+	"render.type_placeholder":    "<type %s>",
+	"render.mode_table":          "table",
+	"render.mode_vertical":       "by record",
+	"render.mode_auto":           "auto",
+	"render.money_raw":           "prices as stored",
+	"render.money_formatted":     "formatted prices",
+	"render.mode_prompt":         "Display mode: 1 — table, 2 — by record, 3 — %s, 4 — auto by terminal width, 5 — column width (now %d) (Enter for %s): ",
+	"render.mode_invalid":        "choose 1, 2, 3 or 4",
+	"render.record_title":        "\n--- Record %d ---",
+	"render.raw_prompt":          "\nThe result has values of non-standard types. Row number to inspect (1-%d, Enter to skip): ",
+	"render.columns_title":       "\n=== CHOOSE COLUMNS TO SHOW FROM '%s' ===",
+	"render.columns_prompt":      "Column numbers separated by commas (e.g. 1,3,5; Enter for all columns): ",
+	"render.columns_invalid":     "column numbers must be from 1 to %d",
+	"reports.title":              "\n=== REPORTS ===",
+	"reports.stock_by_component": "Stock and value by component",
+	"reports.stock_by_warehouse": "Stock by warehouse",
+	"reports.low_stock":          "Components below the stock threshold",
+	"reports.low_stock_title":    "Components with stock below %d",
+	"reports.prompt":             "Choose a report: ",
+	"reports.threshold_prompt":   "Quantity threshold (Enter for %d): ",
+	"reports.threshold_invalid":  "the threshold must be a non-negative number",
+	"reports.failed":             "Error: could not build the report",
+	"reports.none":               "No records found",
+	"select_table.sort":          "CHOOSE A TABLE TO CONFIGURE SORTING",
+	"sort.done":                  "✓ Table '%s' will be sorted: %s",
+	"sort.collations_failed":     "Error: could not load the list of collations",
+	"sort.collation_prompt":      "Collation or part of its name (e.g. C, ru_RU, icu; Enter for the database default): ",
+	"sort.collation_unknown":     "collation '%s' is not available in this database",
+	"sort.collations_title":      "\n=== AVAILABLE COLLATIONS ===",
+	"sort.collation_choice":      "Choose a collation: ",
+	"select_table.describe":      "CHOOSE A TABLE TO DESCRIBE",
+	"schema.unsupported_type":    "Column '%s' has type %s, which cannot be entered, and will be skipped",
+	"schema.failed":              "Error: could not load the table structure",
+	"schema.col_name":            "column",
+	"schema.col_type":            "type",
+	"schema.col_default":         "default",
+	"schema.col_key":             "key",
+	"schema.title":               "\n=== STRUCTURE OF TABLE '%s' ===",
+	"schema.matches":             "\nThe program metadata matches the database catalog",
+	"schema.mismatches":          "\nDifferences from the program metadata:",
+	"schema.missing_in_db":       "column '%s' is used by the program but missing in the database",
+	"schema.unknown_column":      "column '%s' exists in the database but is unknown to the program",
+
+	"rules.check_failed":    "Error: could not check the rules, operation cancelled",
+	"rules.warning":         "Warning",
+	"rules.blocked":         "Blocked",
+	"rules.violation":       "%s (%s, record %d): %s",
+	"rules.not_executed":    "The operation was not executed",
+	"rules.confirm":         "Run the operation despite the warnings? (yes/no): ",
+	"rules.change_title":    "\n⚠ Suspiciously large change of value:",
+	"rules.change_confirm":  "Confirm the change (yes/no): ",
+	"rules.change_refused":  "The change was rejected: --yes does not confirm suspicious changes (start the program with --allow-large-price-changes)",
+	"runspec.line_done":     "Line %d: records found: %d -> %s",
+	"runspec.summary":       "Runs completed: %d, lines skipped with errors: %d",
+	"sql.write_allowed":     "\nWarning: statements that modify data are allowed (--allow-write)",
+	"sql.select_only":       "\nOnly SELECT statements are allowed",
+	"sql.rejected":          "Error: only SELECT statements are allowed (start the program with --allow-write for others)",
+	"sql.executed":          "Statement executed, records affected: %d",
+	"sql.prompt":            "Enter a statement ending with ';' (empty input or 'cancel' returns to the menu):",
+	"sql.input_cancelled":   "Error: %v, statement cancelled",
+	"sql.single_statement":  "Error: only one statement can be run at a time",
+	"sql.timeout":           "Error: the statement timed out (OSL_SQL_TIMEOUT)",
+	"sql.error_position":    "Line %d, position %d:",
+	"sql.hint":              "Hint: %s",
+	"clone.created":         "Database '%s' created",
+	"clone.partial_kept":    "Partially created database '%s' kept (--keep-partial)",
+	"clone.partial_dropped": "Partially created database '%s' dropped",
+	"clone.profile_added":   "Connection profile '%s' added",
+	"clone.done":            "Cloning finished in %s",
+	"clone.parent_added":    "Table '%s' added: '%s' references it",
+	"clone.table_created":   "[%d/%d] %s: structure created",
+	"clone.table_copied":    "[%d/%d] %s: rows copied: %d",
+	"clone.total":           "Total rows copied: %d",
+
+	"cli.unknown_command":      "Unknown command: %s",
+	"cli.commands":             "Available commands: %s",
+	"cli.bad_format":           "Error: the format must be csv or json",
+	"cli.import_file_required": "Error: specify a CSV file with --file",
+	"cli.spec_not_found":       "Error: saved filter '%s' not found in the configuration file",
+	"cli.spec_invalid":         "Error: filter '%s': %v",
+	"cli.params_file_required": "Error: specify a parameters file with --params-file",
+	"clone.target_required":    "Error: specify the new database name with --target (latin letters, digits and _)",
+	"clone.mode_required":      "Error: specify exactly one of --schema-only or --with-data",
+	"clone.dry_run":            "Error: %s cloning is not performed in dry-run mode (OSL_DRY_RUN)",
+	"clone.profile_exists":     "Error: profile '%s' already exists",
+	"clone.postgres_only":      "Error: cloning is supported only for PostgreSQL",
+	"validation.empty":         "the value cannot be empty",
+	"validation.bad_chars":     "the value contains invalid characters",
+	"validation.control_char":  "the value contains an invalid control character %U",
+	"validation.bad_sequence":  "the value contains an invalid sequence '%s'",
+	"db.overloaded":            "The database is overloaded, retrying in %s...",
+	"db.connection_lost":       "Lost the database connection, reconnecting...",
+	"db.reconnected":           "✓ Connection restored",
+	"rules.change_warning":     "%s.%s of record %s: was %s, will be %s (%+.1f%%, threshold %.0f%%)",
+
+	"transfer.title":              "\n=== TRANSFER BETWEEN WAREHOUSES ===",
+	"transfer.source_prompt":      "Choose the warehouse to move from: ",
+	"transfer.source":             "Source warehouse: %s, quantity %d pcs",
+	"transfer.source_empty":       "Error: warehouse '%s' has nothing to transfer",
+	"transfer.targets":            "\n=== OTHER WAREHOUSES OF THE COMPONENT ===",
+	"transfer.target_prompt":      "Destination warehouse number or the name of a new warehouse: ",
+	"transfer.same_location":      "the destination is the same as the source",
+	"transfer.quantity_prompt":    "Quantity to transfer (%d pcs available): ",
+	"transfer.quantity_packages":  "Quantity to transfer (%d pcs available; %d pcs per package, N%s is accepted): ",
+	"transfer.quantity_positive":  "the quantity must be greater than zero",
+	"transfer.quantity_too_large": "only %d pcs are in stock",
+	"transfer.confirm":            "Move %d pcs of '%s' from '%s' to '%s'? (yes/no): ",
+	"transfer.insufficient":       "Error: not enough stock at the source (quantity %d, required %d), transfer cancelled",
+	"transfer.failed":             "Error: the transfer failed, no changes were made",
+	"transfer.done":               "✓ Moved %d pcs of '%s'",
+	"transfer.balance":            "  %s: %d → %d pcs",
+	"transfer.created":            "A stock record was created for warehouse '%s' (id=%s)",
+
+	"select_table.dump":    "CHOOSE A TABLE TO DUMP AS SQL",
+	"dump.truncate_prompt": "Start the file by emptying table '%s' (TRUNCATE)? (yes/no): ",
+	"dump.failed":          "Error: could not dump the table",
+	"dump.done":            "\n✓ Records dumped: %d to %s",
+
+	"import.progress":             "\rRows processed: %d",
+	"dump_restore.path_prompt":    "Path to the dump file (.sql or .csv): ",
+	"dump_restore.path_required":  "enter a file path",
+	"dump_restore.file_missing":   "file '%s' not found",
+	"dump_restore.unknown_format": "Error: only .sql and .csv files are supported",
+	"dump_restore.no_statements":  "Error: the file has no statements",
+	"dump_restore.preview_title":  "\n=== RESTORE: %d statements into database '%s', the first ones ===",
+	"dump_restore.preview_csv":    "\n=== RESTORE: first %d rows into table '%s' of database '%s' ===",
+	"dump_restore.preview_line":   "  line %d: %s",
+	"dump_restore.confirm":        "Run %d statements in database '%s'? (yes/no): ",
+	"dump_restore.confirm_csv":    "Import the file into table '%s' of database '%s'? (yes/no): ",
+	"dump_restore.dry_run":        "%s Statements checked: %d, nothing was changed",
+	"dump_restore.progress":       "\rStatements executed: %d of %d",
+	"dump_restore.done":           "\n✓ Statements executed: %d (in %s)",
+	"dump_restore.summary":        "By table: %s",
+	"dump_restore.failed_line":    "Restore error, line %d: %v",
+	"dump_restore.rolled_back":    "Restore cancelled, no changes were saved",
+	"dump_restore.unterminated":   "the statement is not terminated by a semicolon",
+	"dump_restore.not_insert":     "only INSERT statements are allowed, not %s",
+	"dump_restore.unknown_table":  "table %s is unknown to the program",
+	"dump_restore.bad_insert":     "expected INSERT INTO table (columns) VALUES (...) with constants only, error at '%s'",
+
+	"presets.save_prompt":    "\nName to save these filter conditions under (Enter to skip): ",
+	"presets.name_required":  "the name cannot be empty",
+	"presets.overwrite":      "Filter '%s' already exists. Replace it? (yes/no): ",
+	"presets.masked":         "Values of sensitive columns are not saved: you will be asked for them when the filter is applied",
+	"presets.sensitive_list": "a value list on the sensitive column '%s' cannot be saved",
+	"presets.save_failed":    "Error: could not save the configuration file (is OSL_CONFIG set?)",
+	"presets.saved":          "Filter '%s' saved",
+	"presets.empty":          "No saved filters. Conditions can be saved after filtering (item 2)",
+	"presets.title":          "\n=== SAVED FILTERS ===",
+	"presets.item":           "%d. %s — %s: %s",
+	"presets.item_invalid":   "%d. %s — %s: not applicable (%v)",
+	"presets.action_apply":   "1. Apply",
+	"presets.action_delete":  "2. Delete",
+	"presets.action_prompt":  "Choose an action: ",
+	"presets.choose":         "Filter number or name: ",
+	"presets.not_found":      "filter '%s' not found",
+	"presets.table_prompt":   "Table (Enter for %s): ",
+	"presets.invalid":        "Error: filter '%s' cannot be applied: %v",
+	"presets.param_prompt":   "Value of parameter %s: ",
+	"presets.delete_confirm": "Delete filter '%s'? (yes/no): ",
+	"presets.deleted":        "Filter '%s' deleted",
+
+	"switch_db.current":       "\nCurrent database: %s",
+	"switch_db.name_prompt":   "Database name (DB_NAME): ",
+	"switch_db.name_required": "the database name cannot be empty",
+	"switch_db.bad_name":      "the database name may contain only Latin letters, digits and _",
+	"switch_db.schema_prompt": "Schema (search_path, Enter for the default): ",
+	"switch_db.bad_schema":    "the schema name may contain only Latin letters, digits and _",
+	"switch_db.failed":        "Error: could not connect to database %s",
+	"switch_db.kept":          "Still working with database %s",
+	"switch_db.done":          "Connected to database %s, tables: %d",
+
+	"explain.prompt": "Show the query plan? (yes/no, Enter for no): ",
+	"explain.title":  "\n=== QUERY PLAN (%s) ===",
+	"explain.failed": "Error: could not get the query plan",
+
+	"select_table.generate":   "CHOOSE A TABLE FOR TEST DATA",
+	"undo.kind_generate":      "test data generation in %s",
+	"generate.no_parent_rows": "column %s references table %s, which has no records",
+	"generate.unsupported":    "there is no value generator for the required column %s (%s)",
+	"generate.no_columns":     "table %s has no columns that can be filled",
+	"generate.columns":        "Columns to fill: %s",
+	"generate.count_prompt":   "Number of records: ",
+	"generate.sample":         "\nSample of the generated records:",
+	"generate.confirm":        "Add %d records to table %s in database %s? (yes/no): ",
+	"generate.dry_run":        "%s No records added (%d generated)",
+	"generate.progress":       "\rAdded %d of %d",
+	"generate.failed":         "Error: could not add the test data, changes rolled back",
+	"generate.done":           "Added %d records to table %s in %s",
+
+	"undo.kind_product":     "full entry of a new product",
+	"product.level":         "\n--- Table '%s' ---",
+	"product.pick_existing": "1. Choose an existing record",
+	"product.create_new":    "2. Create a new record",
+	"product.choice_prompt": "Choose an action: ",
+	"product.auto_existing": "  Set automatically: %s = %s",
+	"product.auto_new":      "  Set automatically: %s = id of the new record in '%s'",
+	"product.reused":        "✓ Using the existing record in '%s' with ID: %s",
+	"product.plan_title":    "\n=== RECORDS TO BE ADDED ===",
+	"product.plan_existing": "  %s: existing record with ID %s",
+	"product.plan_new":      "  %s: new record (%s)",
+	"product.plan_parent":   "%s=<id of the new record in '%s'>",
+	"product.confirm":       "Add the records to database %s? (yes/no): ",
+	"product.dry_run":       "\n%s Records were not added",
+	"product.no_id":         "could not get the id of the new record in %s",
+	"product.failed":        "Error: could not add the product, all records of this entry were rolled back",
+	"product.inserted":      "✓ Added a record to '%s' with ID: %s",
+	"product.unavailable":   "Error: full product entry requires tables %s and %s with the %s reference",
+
+	"delete.referenced_title": "\nWarning: records of other tables reference the records being deleted:",
+	"delete.referenced":       "  records of '%s' referenced from '%s': %d (column %s)",
+	"delete.cascade_option":   "1. Delete together with the referencing records (cascade)",
+	"delete.cancel_option":    "0. Cancel the deletion",
+	"delete.cascade_prompt":   "Choose an action: ",
+	"delete.cascade_confirm":  "Also permanently delete %d referencing records from other tables? (yes/no): ",
+	"delete.cascade_done":     "✓ Referencing records deleted from '%s': %d",
+
+	"validation.too_long":     "at most %d characters, %d entered",
+	"validation.out_of_range": "the value must be between %s and %s",
+	"validation.below_min":    "the value must be at least %s, %s entered",
+	"validation.scale":        "at most %d digits after the decimal point, %d entered",
+	"validation.precision":    "at most %d digits before the decimal point, %d entered",
+
+	"validation.not_bool": "field '%s' accepts yes/no, true/false or 1/0",
+	"input.bool_hint":     " (yes/no): ",
+
+	"log_view.lines_prompt":  "Number of last log lines (Enter — %d): ",
+	"log_view.errors_prompt": "Show errors only? (yes/no, Enter — no): ",
+	"log_view.sync_failed":   "Could not flush the log to disk: %v",
+	"log_view.rotated":       "The log file %s was replaced by rotation, showing the new file",
+	"log_view.read_failed":   "Error: could not read the log %s: %v",
+	"log_view.empty":         "No matching entries in the log %s",
+	"log_view.title":         "\n=== LOG %s (%d lines) ===",
+
+	"paging.footer":     "\nPage %d of %d (records %d–%d of %d)",
+	"paging.prompt":     "Enter or 1 — next page, 2 — previous, 0 — exit: ",
+	"paging.first_page": "this is the first page",
+	"paging.no_more":    "No more records",
+
+	"validation.bad_date": "field '%s' expects a date in the YYYY-MM-DD or RFC3339 format, e.g. %s",
+	"validation.bad_time": "field '%s' expects a date and time in the TIME_FORMAT layout (e.g. %s), YYYY-MM-DD or RFC3339",
+	"input.time_hint":     " (format %s, YYYY-MM-DD or RFC3339): ",
+	"input.date_hint":     " (YYYY-MM-DD): ",
+
+	"log_view.stdout_only": "The log is written to stdout only (LOG_TARGET=stdout or the log file is not writable), so it cannot be shown here.",
+
+	"menu.lookup":       "29. Find a record by key",
+	"select_table.jump": "SELECT A TABLE TO FIND A RECORD BY KEY",
+	"jump.no_key":       "Table %s has no primary key, records cannot be looked up by key",
+	"jump.key_prompt":   "Enter the %s value: ",
+	"jump.not_found":    "Table %s has no record with %s",
+	"jump.archived":     "The record is marked as deleted (archived).",
+
+	"reports.audit":     "Change log (audit)",
+	"audit.unavailable": "Audit table %s not found: no changes recorded yet or the audit is disabled (OSL_AUDIT=false)",
+	"audit.table_title": "\n=== FILTER THE AUDIT LOG BY TABLE ===",
+	"audit.all_tables":  "0. All tables",
+	"audit.from_prompt": "Start date YYYY-MM-DD (Enter for no limit): ",
+	"audit.to_prompt":   "End date YYYY-MM-DD, inclusive (Enter for no limit): ",
+	"audit.bad_date":    "expected a date in the YYYY-MM-DD format",
+
+	"filter.type_date_range": "3. Before, after or between dates",
+	"filter.time_range":      "Column '%s' holds a time: enter the bounds (lower only for after, upper only for before, both for between)",
+	"filter.hint_time":       "YYYY-MM-DD, %s or RFC3339",
+
+	"summary.title":        "\n=== REVIEW THE CHANGE ===",
+	"summary.insert":       "Insert into table %s, records: %d",
+	"summary.update":       "Update of table %s, records: %d",
+	"summary.more_records": "... and %d more records",
+	"summary.confirm":      "Execute? (yes/no): ",
+
+	"review.execute":      "1. Add the records",
+	"review.edit":         "2. Edit a field",
+	"review.cancel":       "0. Cancel",
+	"review.prompt":       "Choose an action: ",
+	"review.fields_title": "\n=== CHOOSE A FIELD TO EDIT ===",
+	"review.field_prompt": "Choose a field: ",
+	"related.failed":      "Error: could not add the related records, changes for this record were rolled back",
+
+	"menu.reload":           "30. Reload database metadata",
+	"reload.changes_title":  "\n=== DATABASE STRUCTURE CHANGES ===",
+	"reload.table_added":    "  + table %s (%s)",
+	"reload.table_removed":  "  - table %s",
+	"reload.column_added":   "  + column %s.%s",
+	"reload.column_removed": "  - column %s.%s",
+	"reload.no_changes":     "The database structure has not changed",
+	"reload.done":           "✓ Database metadata reloaded, tables: %d",
+
+	"render.width_prompt":     "Maximum column width in characters (now %d): ",
+	"render.truncated_prompt": "\nLong values are truncated. Row number to show in full (1-%d, Enter to skip): ",
+
+	"related.none": "Error: the database has no related tables (foreign keys between the listed tables)",
+
+	"menu.card":           "31. Record card",
+	"select_table.card":   "SELECT A TABLE FOR THE RECORD CARD",
+	"card.title":          "\n=== RECORD CARD: %s, %s = %s ===",
+	"card.parent_title":   "\n--- %s → %s ---",
+	"card.parent_empty":   "Reference %s is not set",
+	"card.parent_missing": "Table %s has no record with %s=%s referenced by this record",
+	"card.children_title": "\n--- Related records in %s (by %s) ---",
+	"card.children_empty": "Table %s has no records referencing this record",
+	"card.children_limit": "Showing the first %d related records",
+
+	"menu.indexes": "32. Index maintenance",
+
+	"indexes.title":              "\n=== INDEX MAINTENANCE ===",
+	"indexes.none":               "No conditions in this session's queries lack an index (a condition must occur at least %d times)",
+	"indexes.reason_soft_delete": "Selecting non-archived records of '%s' (queries in this session: %d), partial index:",
+	"indexes.reason_low_stock":   "Low stock report on '%s' (queries in this session: %d), covering index:",
+	"indexes.unsupported":        "the current DBMS does not support this index",
+	"indexes.prompt":             "Choose an index: ",
+	"indexes.plan_before":        "\nQuery plan without the index:",
+	"indexes.plan_after":         "\nQuery plan with the index:",
+	"indexes.plan_failed":        "Error: could not get the query plan",
+	"indexes.cost":               "Estimated query cost: %s → %s",
+	"indexes.ddl_required":       "Indexes are created and dropped only in DDL mode: start the program with --allow-ddl",
+	"indexes.confirm":            "Create index %s? (yes/no): ",
+	"indexes.progress":           "  building the index: %s, %d%%",
+	"indexes.created":            "✓ Index %s created in %s",
+	"indexes.create_failed":      "Error: index %s was not created: %v",
+	"indexes.invalid_found":      "Invalid indexes left by an interrupted build: %d",
+	"indexes.invalid_confirm":    "Drop them? (yes/no): ",
+	"indexes.invalid_dropped":    "Invalid index %s dropped",
+	"indexes.drop_failed":        "Error: could not drop invalid index %s: %v",
+
+	"search.scope_text":   "1. Search text columns",
+	"search.scope_all":    "2. Search all columns (numbers, dates and other values as text)",
+	"search.scope_prompt": "Where to search: ",
+
+	"schema_check.missing": "The database is missing the program tables: %s",
+	"schema_check.confirm": "Create the missing tables? (yes/no): ",
+	"schema_check.skipped": "Tables were not created, actions on them will fail",
+	"schema_check.failed":  "Error: could not create table %s",
+	"schema_check.created": "✓ Table %s created",
+
+	"menu.readonly_tag":     " (read-only)",
+	"readonly.blocked":      "Error: read-only mode is on, data changes are not allowed",
+	"schema_check.readonly": "Read-only mode: missing tables are not created",
+
+	"connect.password_again": "The server rejected the password. Enter the password again: ",
+	"db.resume_menu":         "Resuming at the main menu",
+	"db.gave_up":             "Error: could not restore the database connection, exiting",
+
+	"input.default_hint":  " (Enter or DEFAULT for the default: %s): ",
+	"insert.default_used": "  Column %s will be filled with its default value",
+	"insert.all_default":  "Error: all columns of table %s use default values, there is nothing to insert",
+}
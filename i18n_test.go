@@ -0,0 +1,73 @@
+package osl
+
+import (
+	"strings"
+	"testing"
+)
+
+// Переключение языка в меню меняет вывод
+func TestSwitchLanguage(t *testing.T) {
+	openSchema(t, []string{"parts"}, "CREATE TABLE parts (id INTEGER PRIMARY KEY, name TEXT)")
+	t.Cleanup(func() { language = "ru" })
+
+	render := func() string {
+		return captureOutput(t, func() { selectTable(scriptReader("0"), msg("select_table.insert")) })
+	}
+	russian := render()
+	if !strings.Contains(russian, "Вернуться в меню") {
+		t.Fatalf("вывод на русском:\n%s", russian)
+	}
+
+	// Языки в меню упорядочены по коду: 1 — en, 2 — ru
+	output := captureOutput(t, func() { chooseLanguage(scriptReader("1")) })
+	if language != "en" || !strings.Contains(output, msg("language.changed", "English")) {
+		t.Fatalf("язык %q после выбора:\n%s", language, output)
+	}
+	english := render()
+	if english == russian || !strings.Contains(english, "Back to menu") || strings.Contains(english, "Вернуться") {
+		t.Errorf("вывод на английском:\n%s", english)
+	}
+
+	captureOutput(t, func() { chooseLanguage(scriptReader("2")) })
+	if got := render(); got != russian {
+		t.Errorf("после возврата к русскому:\n%s", got)
+	}
+}
+
+// Язык из переменных окружения: OSL_LANG важнее LANG, неизвестный язык — русский
+func TestLanguageFromEnv(t *testing.T) {
+	tests := []struct {
+		oslLang, lang, want string
+	}{
+		{"", "", "ru"},
+		{"", "en_US.UTF-8", "en"},
+		{"EN", "ru_RU.UTF-8", "en"},
+		{"ru", "en_GB", "ru"},
+		{"de", "en-GB", "en"},
+		{"de", "fr_FR", "ru"},
+	}
+	for _, tt := range tests {
+		t.Setenv("OSL_LANG", tt.oslLang)
+		t.Setenv("LANG", tt.lang)
+		if got := languageFromEnv(); got != tt.want {
+			t.Errorf("OSL_LANG=%q LANG=%q: язык %q, ожидался %q", tt.oslLang, tt.lang, got, tt.want)
+		}
+	}
+}
+
+// Сообщения без перевода берутся из русского словаря, неизвестный ключ выводится как есть
+func TestMessageFallback(t *testing.T) {
+	t.Cleanup(func() { language = "ru" })
+	language = "en"
+	if got := msg("common.error", "x"); got != "Error: x" {
+		t.Errorf("msg(common.error) = %q", got)
+	}
+	if got := msg("no.such.key"); got != "no.such.key" {
+		t.Errorf("неизвестный ключ: %q", got)
+	}
+	for key := range messagesRU {
+		if _, ok := messagesEN[key]; !ok {
+			t.Errorf("нет перевода сообщения %s", key)
+		}
+	}
+}
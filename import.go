@@ -0,0 +1,268 @@
+package osl
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Импорт CSV: первая строка файла — заголовок с именами колонок таблицы.
+// Колонка id пропускается (значение генерирует БД), пустое поле записывается как NULL.
+// Все строки добавляются одной транзакцией: ошибка в любой строке отменяет весь импорт.
+// Каждая строка проверяется правилами таблицы для вставки, как при добавлении из меню.
+// После импорта больших файлов выводится отчет контроля качества (quality.go).
+
+// Ошибка импорта с номером строки файла
+type importLineError struct {
+	Line int
+	Err  error
+}
+
+func (e *importLineError) Error() string {
+	return fmt.Sprintf("строка %d: %v", e.Line, e.Err)
+}
+
+func (e *importLineError) Unwrap() error {
+	return e.Err
+}
+
+// Функция для получения интервала вывода прогресса импорта и восстановления (в строках)
+func progressInterval() int {
+	return envInt("OSL_PROGRESS_ROWS", 1000)
+}
+
+// Пункт 16: Импорт из CSV
+func importTable(reader *bufio.Reader) {
+	tableIndex := selectTable(reader, msg("select_table.import"))
+	if tableIndex == -1 {
+		return
+	}
+	table := tables[tableIndex]
+
+	defaultPath := table.Name + ".csv"
+	path, ok := promptString(reader, msg("import.path_prompt", defaultPath))
+	if !ok {
+		return
+	}
+	if path == "" {
+		path = defaultPath
+	}
+	runImport(table, path)
+}
+
+// Команда import: osl import --table <таблица> --file <путь к CSV>
+func importCommand(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	tableName := fs.String("table", "", "таблица для импорта")
+	path := fs.String("file", "", "путь к CSV-файлу с заголовком")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	table, ok := findTable(*tableName)
+	if !ok {
+		fmt.Fprintln(stderr, msg("common.table_not_found", *tableName))
+		return 2
+	}
+	if *path == "" {
+		fmt.Fprintln(stderr, msg("cli.import_file_required"))
+		return 2
+	}
+	if err := runImport(table, *path); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// Функция для импорта файла с выводом результата
+func runImport(table TableInfo, path string) error {
+	start := time.Now()
+	count, report, err := importCSV(table, path)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка импорта %s в таблицу %s, изменения отменены: %v", path, table.Name, err))
+		var lineErr *importLineError
+		if errors.As(err, &lineErr) {
+			printError(msg("import.failed_line", lineErr.Line, lineErr.Err))
+		} else {
+			printError(msg("common.error", err))
+		}
+		fmt.Fprintln(stdout, msg("import.rolled_back"))
+		return err
+	}
+
+	elapsed := time.Since(start)
+	if dryRun {
+		fmt.Fprintln(stdout, msg("import.dry_run", dryRunTag, count))
+		return nil
+	}
+	forgetUndo(fmt.Sprintf("выполнен импорт в таблицу %s", table.Name))
+	fmt.Fprintln(stdout, msg("import.done", count, table.Name, elapsed.Round(time.Millisecond)))
+	logToFileAndScreen(fmt.Sprintf("Импорт %s: добавлено %d записей в таблицу %s за %s", path, count, table.Name, elapsed))
+	if report != nil && count >= qualityMinRows() {
+		showQualityReport(report, path)
+	}
+	return nil
+}
+
+// Функция для импорта CSV-файла в таблицу одной транзакцией. Возвращает количество добавленных строк
+// (в режиме проверки — количество проверенных строк, запросы не выполняются)
+// и статистику для контроля качества (nil, если контроль отключен).
+func importCSV(table TableInfo, path string) (int, *qualityReport, error) {
+	count := 0
+	var report *qualityReport
+	err := dbTransaction(func(tx *sql.Tx) error {
+		// Файл читается внутри транзакции, чтобы при повторе после обрыва соединения начать сначала
+		count = 0
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		csvReader := csv.NewReader(file)
+		csvReader.TrimLeadingSpace = true
+		header, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			return errors.New(msg("import.empty_file"))
+		}
+		if err != nil {
+			return &importLineError{Line: 1, Err: err}
+		}
+		columns, positions, err := importColumns(table, header)
+		if err != nil {
+			return &importLineError{Line: 1, Err: err}
+		}
+
+		placeholders := make([]string, len(columns))
+		for i := range placeholders {
+			placeholders[i] = fmt.Sprintf("$%d", i+1)
+		}
+		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+			table.SQLName(), columnList(columns), strings.Join(placeholders, ", "))
+		if dryRun {
+			printDryRun(query, nil, nil)
+		}
+		withRules := hasRowRules(table.Name, "insert")
+		// Статистика данных до импорта читается до первой вставки
+		report, err = newQualityReport(tx, table, columns)
+		if err != nil {
+			return err
+		}
+		// Каждый $n встречается один раз по порядку, поэтому значения можно передавать без перестановки
+		boundQuery, _ := rebind(query, make([]interface{}, len(columns)))
+		stmt, err := tx.Prepare(boundQuery)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for {
+			record, err := csvReader.Read()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			line, _ := csvReader.FieldPos(0)
+			if err != nil {
+				var parseErr *csv.ParseError
+				if errors.As(err, &parseErr) {
+					line = parseErr.Line
+				}
+				return &importLineError{Line: line, Err: err}
+			}
+
+			values, err := importValues(table, columns, positions, record)
+			if err != nil {
+				return &importLineError{Line: line, Err: err}
+			}
+			if withRules {
+				if err := checkImportRules(tx, table, columns, values, line); err != nil {
+					return err
+				}
+			}
+			if !dryRun {
+				if _, err := stmt.Exec(values...); err != nil {
+					return &importLineError{Line: line, Err: err}
+				}
+			}
+			if report != nil {
+				report.add(line, values)
+			}
+			count++
+			if count%progressInterval() == 0 {
+				fmt.Fprint(stdout, msg("import.progress", count))
+			}
+		}
+	})
+	return count, report, err
+}
+
+// Функция для проверки строки файла по правилам таблицы: блокирующее правило прерывает импорт
+// с номером строки, предупреждения выводятся и записываются в журнал
+func checkImportRules(tx *sql.Tx, table TableInfo, columns []string, values []interface{}, line int) error {
+	violations, err := evaluateRowRules(table.Name, "insert", []ruleRow{newRuleRow(columns, values)}, txRuleLookup(tx))
+	if err != nil {
+		return &importLineError{Line: line, Err: err}
+	}
+	for _, violation := range violations {
+		if violation.Rule.Severity == "block" {
+			return &importLineError{Line: line, Err: errors.New(msg("import.rule_blocked", violation.Rule.Name, violation.Rule.Message))}
+		}
+		fmt.Fprintln(stdout, "⚠ " + msg("import.rule_warning", violation.Rule.Name, line, violation.Rule.Message))
+		logToFileAndScreen(fmt.Sprintf("Правило нарушено (warn, %s, строка %d): %s", violation.Rule.Name, line, violation.Rule.Message))
+	}
+	return nil
+}
+
+// Функция для сопоставления заголовка CSV с колонками таблицы.
+// Возвращает колонки для вставки и номера соответствующих полей в строке файла.
+func importColumns(table TableInfo, header []string) ([]string, []int, error) {
+	var columns []string
+	var positions []int
+	seen := make(map[string]bool)
+	for i, name := range header {
+		// Excel сохраняет UTF-8 с BOM в начале файла
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if autoGeneratedColumn(table, name) {
+			continue
+		}
+		if !containsString(table.Columns, name) {
+			return nil, nil, errors.New(msg("import.unknown_column", name, table.Name))
+		}
+		if seen[name] {
+			return nil, nil, errors.New(msg("import.duplicate_column", name))
+		}
+		seen[name] = true
+		columns = append(columns, name)
+		positions = append(positions, i)
+	}
+	if len(columns) == 0 {
+		return nil, nil, errors.New(msg("import.no_columns"))
+	}
+	return columns, positions, nil
+}
+
+// Функция для проверки полей строки CSV и получения параметров запроса (пустое поле — NULL)
+func importValues(table TableInfo, columns []string, positions []int, record []string) ([]interface{}, error) {
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		if positions[i] >= len(record) {
+			return nil, errors.New(msg("import.missing_value", column))
+		}
+		value := strings.TrimSpace(record[positions[i]])
+		if value == "" {
+			continue
+		}
+		if err := validateTableValue(table, column, value); err != nil {
+			return nil, fmt.Errorf("'%s': %w", column, err)
+		}
+		values[i] = normalizeTableValue(table, column, value)
+	}
+	return values, nil
+}
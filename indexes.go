@@ -0,0 +1,386 @@
+package osl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Обслуживание индексов: среди запросов текущего сеанса находятся повторяющиеся условия,
+// которым не хватает индекса, — отбор неархивных записей (deleted_at IS NULL или
+// archived IS NOT TRUE, см. softdelete.go) и отчет о заканчивающихся товарах. Для мягкого
+// удаления предлагается частичный индекс только по неархивным строкам. Отчет о заканчивающихся
+// товарах суммирует остатки по всем строкам склада, поэтому частичный индекс по quantity
+// ему не поможет: предлагается покрывающий индекс (component_id, quantity).
+// Польза показывается планом запроса сеанса до и после создания индекса. Индексы создаются
+// только в режиме DDL (флаг --allow-ddl или OSL_ALLOW_DDL): в PostgreSQL — CONCURRENTLY
+// с выводом хода построения, а недействительный индекс, оставшийся после ошибки, удаляется.
+
+// Разрешено создание и удаление индексов (--allow-ddl)
+var allowDDL bool
+
+// Префикс имен индексов, которые создает программа
+const indexNamePrefix = "osl_"
+
+// Условие отчета о заканчивающихся товарах (reports.go): по нему отчет узнается среди запросов сеанса
+const lowStockCondition = "HAVING COALESCE(SUM(s.quantity), 0) < $1"
+
+// Максимальная длина имени индекса (ограничение PostgreSQL)
+const maxIndexNameLength = 63
+
+// Предлагаемый индекс
+type indexSuggestion struct {
+	Table   TableInfo
+	Name    string
+	Columns []string
+	Where   string   // условие частичного индекса (пусто — индекс по всем строкам)
+	Markers []string // фрагменты, по которым узнается запрос, которому поможет индекс
+	Reason  string   // ключ сообщения с пояснением
+}
+
+// Запрос сеанса с условием предлагаемого индекса: сколько раз встретился и последний текст
+type indexObservation struct {
+	Count int
+	Query string
+	Args  []interface{}
+}
+
+// Запросы сеанса по именам предлагаемых индексов (запросы выполняются и из фоновых горутин)
+var (
+	indexObservationsMu sync.Mutex
+	indexObservations   = make(map[string]*indexObservation)
+)
+
+// Оценка стоимости в плане PostgreSQL: cost=начальная..полная
+var planCostRegex = regexp.MustCompile(`cost=[\d.]+\.\.([\d.]+)`)
+
+// Функция для получения имени индекса программы: osl_<таблица>_<назначение>
+func indexName(table TableInfo, purpose string) string {
+	_, name := table.schemaAndName()
+	result := strings.ToLower(indexNamePrefix + name + "_" + purpose)
+	if len(result) > maxIndexNameLength {
+		result = result[:maxIndexNameLength]
+	}
+	return result
+}
+
+// Функция для получения индексов, которые могут понадобиться при текущей структуре БД
+func indexSuggestions() []indexSuggestion {
+	var suggestions []indexSuggestion
+	for _, table := range tables {
+		condition := activeRowsCondition(table)
+		key := tableKeyColumn(table.Name)
+		if condition == "" || key == "" {
+			continue
+		}
+		suggestions = append(suggestions, indexSuggestion{
+			Table:   table,
+			Name:    indexName(table, "active"),
+			Columns: []string{key},
+			Where:   condition,
+			Markers: []string{table.SQLName(), condition},
+			Reason:  "indexes.reason_soft_delete",
+		})
+	}
+	if stock, ok := findTable("stock"); ok && containsString(stock.Columns, "component_id") && containsString(stock.Columns, "quantity") {
+		suggestions = append(suggestions, indexSuggestion{
+			Table:   stock,
+			Name:    indexName(stock, "component_quantity"),
+			Columns: []string{"component_id", "quantity"},
+			Markers: []string{lowStockCondition},
+			Reason:  "indexes.reason_low_stock",
+		})
+	}
+	return suggestions
+}
+
+// Функция для проверки, поможет ли индекс запросу
+func (s indexSuggestion) matches(query string) bool {
+	for _, marker := range s.Markers {
+		if !strings.Contains(query, marker) {
+			return false
+		}
+	}
+	return true
+}
+
+// Функция для учета запроса чтения сеанса: запоминаются запросы с условиями предлагаемых индексов
+func observeIndexQuery(query string, args []interface{}) {
+	suggestions := indexSuggestions()
+	indexObservationsMu.Lock()
+	defer indexObservationsMu.Unlock()
+	for _, suggestion := range suggestions {
+		if !suggestion.matches(query) {
+			continue
+		}
+		observation := indexObservations[suggestion.Name]
+		if observation == nil {
+			observation = &indexObservation{}
+			indexObservations[suggestion.Name] = observation
+		}
+		observation.Count++
+		observation.Query = query
+		observation.Args = append([]interface{}(nil), args...)
+	}
+}
+
+// Функция для получения копии учета запросов для индекса (false — запросов не было)
+func observedIndexQuery(name string) (indexObservation, bool) {
+	indexObservationsMu.Lock()
+	defer indexObservationsMu.Unlock()
+	observation, ok := indexObservations[name]
+	if !ok {
+		return indexObservation{}, false
+	}
+	return *observation, true
+}
+
+// Функция для сброса учета запросов сеанса (запросы относились к прежней базе)
+func forgetIndexQueries() {
+	indexObservationsMu.Lock()
+	defer indexObservationsMu.Unlock()
+	indexObservations = make(map[string]*indexObservation)
+}
+
+// Функция для проверки, есть ли у таблицы индекс с таким именем
+func indexExists(table TableInfo, name string) (bool, error) {
+	_, tableName := table.schemaAndName()
+	var count int
+	err := dbScanRow(dialect.IndexExistsQuery(), []interface{}{name, tableName}, &count)
+	return count > 0, err
+}
+
+// Пункт 32: Обслуживание индексов
+func indexMaintenance(reader *bufio.Reader) {
+	fmt.Fprintln(stdout, msg("indexes.title"))
+	dropInvalidIndexes(reader)
+
+	minUses := envInt("OSL_INDEX_MIN_USES", 2)
+	var found []indexSuggestion
+	var observations []indexObservation
+	for _, suggestion := range indexSuggestions() {
+		observation, ok := observedIndexQuery(suggestion.Name)
+		if !ok || observation.Count < minUses {
+			continue
+		}
+		exists, err := indexExists(suggestion.Table, suggestion.Name)
+		if err != nil {
+			logToFileAndScreen(fmt.Sprintf("Ошибка проверки индекса %s: %v", suggestion.Name, err))
+			continue
+		}
+		if exists {
+			continue
+		}
+		found = append(found, suggestion)
+		observations = append(observations, observation)
+	}
+	if len(found) == 0 {
+		fmt.Fprintln(stdout, msg("indexes.none", minUses))
+		return
+	}
+
+	for i, suggestion := range found {
+		fmt.Fprintf(stdout, "%d. %s\n", i+1, msg(suggestion.Reason, suggestion.Table.Name, observations[i].Count))
+		if query := dialect.CreateIndexQuery(suggestion.Table, suggestion.Name, suggestion.Columns, suggestion.Where); query != "" {
+			fmt.Fprintln(stdout, "   " + query)
+		} else {
+			fmt.Fprintln(stdout, "   " + msg("indexes.unsupported"))
+		}
+	}
+	fmt.Fprintln(stdout, msg("common.back"))
+	choice, ok := promptInt(reader, msg("indexes.prompt"), 0, len(found))
+	if !ok || choice == 0 {
+		return
+	}
+	createSuggestedIndex(reader, found[choice-1], observations[choice-1])
+}
+
+// Функция для создания предложенного индекса с планом запроса сеанса до и после создания
+func createSuggestedIndex(reader *bufio.Reader, suggestion indexSuggestion, observation indexObservation) {
+	before, beforeErr := observedQueryPlan(observation)
+	if beforeErr == nil {
+		fmt.Fprintln(stdout, msg("indexes.plan_before"))
+		fmt.Fprintln(stdout, before)
+	}
+
+	query := dialect.CreateIndexQuery(suggestion.Table, suggestion.Name, suggestion.Columns, suggestion.Where)
+	if query == "" {
+		printError(msg("indexes.unsupported"))
+		return
+	}
+	if !allowDDL {
+		fmt.Fprintln(stdout, msg("indexes.ddl_required"))
+		return
+	}
+	if !promptConfirm(reader, msg("indexes.confirm", suggestion.Name)) {
+		return
+	}
+
+	start := time.Now()
+	if err := buildIndex(suggestion, query); err != nil {
+		printError(msg("indexes.create_failed", suggestion.Name, err))
+		return
+	}
+	elapsed := time.Since(start)
+	fmt.Fprintln(stdout, msg("indexes.created", suggestion.Name, elapsed.Round(time.Millisecond)))
+	logToFileAndScreen(fmt.Sprintf("Создан индекс %s за %s: %s", suggestion.Name, elapsed, query))
+
+	after, err := observedQueryPlan(observation)
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(stdout, msg("indexes.plan_after"))
+	fmt.Fprintln(stdout, after)
+	if beforeErr != nil {
+		return
+	}
+	beforeCost, ok1 := planCost(before)
+	afterCost, ok2 := planCost(after)
+	if ok1 && ok2 {
+		fmt.Fprintln(stdout, msg("indexes.cost", beforeCost, afterCost))
+		logToFileAndScreen(fmt.Sprintf("Оценка стоимости запроса с индексом %s: %s -> %s", suggestion.Name, beforeCost, afterCost))
+	}
+}
+
+// Функция для получения плана запроса сеанса с теми же параметрами
+func observedQueryPlan(observation indexObservation) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), envDuration("OSL_SQL_TIMEOUT", 30*time.Second))
+	defer cancel()
+	boundQuery, boundArgs := rebind(observation.Query, observation.Args)
+	plan, err := explainQuery(ctx, boundQuery, boundArgs, false)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка получения плана запроса: %v", err))
+		printError(msg("indexes.plan_failed"))
+	}
+	return plan, err
+}
+
+// Функция для получения полной оценки стоимости из первой строки плана (false — СУБД ее не выводит)
+func planCost(plan string) (string, bool) {
+	firstLine, _, _ := strings.Cut(plan, "\n")
+	match := planCostRegex.FindStringSubmatch(firstLine)
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
+
+// Функция для построения индекса с выводом хода построения. Если построение не удалось,
+// а индекс остался (CONCURRENTLY оставляет недействительный индекс), он удаляется.
+func buildIndex(suggestion indexSuggestion, query string) error {
+	logToFileAndScreen(fmt.Sprintf("Создание индекса %s: %s", suggestion.Name, query))
+	stop := watchIndexProgress(suggestion.Table)
+	_, err := dbExec(query)
+	stop()
+	if err == nil {
+		return nil
+	}
+	logToFileAndScreen(fmt.Sprintf("Ошибка создания индекса %s: %v", suggestion.Name, err))
+
+	exists, checkErr := indexExists(suggestion.Table, suggestion.Name)
+	if checkErr != nil || !exists {
+		return err
+	}
+	if _, dropErr := dbExec(dialect.DropIndexQuery(suggestion.Table, suggestion.Name)); dropErr != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка удаления недействительного индекса %s: %v", suggestion.Name, dropErr))
+		printError(msg("indexes.drop_failed", suggestion.Name, dropErr))
+		return err
+	}
+	logToFileAndScreen(fmt.Sprintf("Недействительный индекс %s удален", suggestion.Name))
+	fmt.Fprintln(stdout, msg("indexes.invalid_dropped", suggestion.Name))
+	return err
+}
+
+// Функция для периодического вывода хода построения индекса таблицы (OSL_INDEX_PROGRESS_INTERVAL).
+// Возвращает функцию остановки вывода.
+func watchIndexProgress(table TableInfo) func() {
+	query := dialect.IndexProgressQuery()
+	if query == "" {
+		return func() {}
+	}
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(envDuration("OSL_INDEX_PROGRESS_INTERVAL", time.Second))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			var phase string
+			var blocksDone, blocksTotal int64
+			// Опрос выполняется напрямую через db, чтобы не попадать в статистику запросов
+			if err := db.QueryRow(query, table.SQLName()).Scan(&phase, &blocksDone, &blocksTotal); err != nil {
+				continue
+			}
+			percent := int64(0)
+			if blocksTotal > 0 {
+				percent = blocksDone * 100 / blocksTotal
+			}
+			fmt.Fprintln(stdout, msg("indexes.progress", phase, percent))
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+// Функция для поиска и удаления недействительных индексов программы, оставшихся
+// после прерванного построения (только в СУБД, где они бывают)
+func dropInvalidIndexes(reader *bufio.Reader) {
+	query := dialect.InvalidIndexesQuery()
+	if query == "" {
+		return
+	}
+	pattern := strings.ReplaceAll(indexNamePrefix, "_", `\_`) + "%"
+	rows, err := dbQuery(query, pattern)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка поиска недействительных индексов: %v", err))
+		return
+	}
+	var invalid []TableInfo
+	var names []string
+	for rows.Next() {
+		var schema, table, name string
+		if err := rows.Scan(&schema, &table, &name); err != nil {
+			logToFileAndScreen(fmt.Sprintf("Ошибка поиска недействительных индексов: %v", err))
+			rows.Close()
+			return
+		}
+		invalid = append(invalid, TableInfo{Name: table, Schema: schema})
+		names = append(names, name)
+	}
+	rows.Close()
+	if len(names) == 0 {
+		return
+	}
+
+	fmt.Fprintln(stdout, msg("indexes.invalid_found", len(names)))
+	for i, name := range names {
+		fmt.Fprintf(stdout, "  %s (%s)\n", name, invalid[i].Name)
+	}
+	if !allowDDL {
+		fmt.Fprintln(stdout, msg("indexes.ddl_required"))
+		return
+	}
+	if !promptConfirm(reader, msg("indexes.invalid_confirm")) {
+		return
+	}
+	for i, name := range names {
+		if _, err := dbExec(dialect.DropIndexQuery(invalid[i], name)); err != nil {
+			logToFileAndScreen(fmt.Sprintf("Ошибка удаления недействительного индекса %s: %v", name, err))
+			printError(msg("indexes.drop_failed", name, err))
+			continue
+		}
+		logToFileAndScreen(fmt.Sprintf("Недействительный индекс %s удален", name))
+		fmt.Fprintln(stdout, msg("indexes.invalid_dropped", name))
+	}
+}
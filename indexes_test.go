@@ -0,0 +1,109 @@
+package osl
+
+import (
+	"strings"
+	"testing"
+)
+
+// Повторяющиеся условия сеанса (мягкое удаление и отчет о заканчивающихся товарах) дают
+// предложения индексов; индекс создается только в режиме DDL, план показывается до и после
+func TestIndexMaintenance(t *testing.T) {
+	openBaseSchema(t,
+		"ALTER TABLE components ADD COLUMN deleted_at TIMESTAMP",
+		"INSERT INTO categories (name, description) VALUES ('Процессоры', 'CPU')",
+		"INSERT INTO manufacturers (name) VALUES ('Intel')",
+		"INSERT INTO components (name, category_id, manufacturer_id, model, price) VALUES ('Core i5', 1, 1, '12400F', 15990)",
+		"INSERT INTO stock (component_id, quantity, warehouse_location) VALUES (1, 3, 'A-1')")
+	forgetIndexQueries()
+	t.Cleanup(func() {
+		forgetIndexQueries()
+		allowDDL = false
+	})
+
+	// Одного запроса мало: условие должно повториться
+	captureOutput(t, func() { reportsMenu(scriptReader("3", "")) })
+	output := captureOutput(t, func() { indexMaintenance(scriptReader()) })
+	if !strings.Contains(output, msg("indexes.none", 2)) {
+		t.Fatalf("предложение после одного запроса:\n%s", output)
+	}
+
+	captureOutput(t, func() {
+		viewTable(scriptReader("3", "", "", ""))
+		reportsMenu(scriptReader("3", ""))
+	})
+
+	// Без --allow-ddl предложения и план выводятся, индекс не создается
+	output = captureOutput(t, func() { indexMaintenance(scriptReader("1")) })
+	for _, want := range []string{
+		`CREATE INDEX IF NOT EXISTS "osl_components_active" ON "components" ("id") WHERE "deleted_at" IS NULL`,
+		`CREATE INDEX IF NOT EXISTS "osl_stock_component_quantity" ON "stock" ("component_id", "quantity")`,
+		msg("indexes.plan_before"),
+		msg("indexes.ddl_required"),
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("нет %q:\n%s", want, output)
+		}
+	}
+	if got := queryString(t, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name LIKE 'osl%'"); got != "0" {
+		t.Fatalf("индексов создано без --allow-ddl: %s", got)
+	}
+
+	allowDDL = true
+	output = captureOutput(t, func() { indexMaintenance(scriptReader("1", "да")) })
+	if !strings.Contains(output, msg("indexes.plan_after")) {
+		t.Errorf("нет плана после создания:\n%s", output)
+	}
+	after := output[strings.Index(output, msg("indexes.plan_after")):]
+	if !strings.Contains(after, "osl_components_active") {
+		t.Errorf("план после создания не использует индекс:\n%s", after)
+	}
+	if got := queryString(t, "SELECT sql FROM sqlite_master WHERE name = 'osl_components_active'"); !strings.HasSuffix(got, `WHERE "deleted_at" IS NULL`) {
+		t.Errorf("частичный индекс: %s", got)
+	}
+
+	// Созданный индекс больше не предлагается
+	output = captureOutput(t, func() { indexMaintenance(scriptReader("0")) })
+	if strings.Contains(output, "osl_components_active") || !strings.Contains(output, "osl_stock_component_quantity") {
+		t.Errorf("предложения после создания индекса:\n%s", output)
+	}
+}
+
+// Запросы создания и удаления индексов для СУБД со схемами и без частичных индексов
+func TestIndexQueries(t *testing.T) {
+	table := TableInfo{Name: "components", Schema: "shop"}
+	columns := []string{"id"}
+	tests := []struct {
+		dialect      Dialect
+		create, drop string
+	}{
+		{postgresDialect{},
+			`CREATE INDEX CONCURRENTLY IF NOT EXISTS "osl_components_active" ON "shop"."components" ("id") WHERE "deleted_at" IS NULL`,
+			`DROP INDEX CONCURRENTLY IF EXISTS "shop"."osl_components_active"`},
+		{sqliteDialect{},
+			`CREATE INDEX IF NOT EXISTS "shop"."osl_components_active" ON "components" ("id") WHERE "deleted_at" IS NULL`,
+			`DROP INDEX IF EXISTS "shop"."osl_components_active"`},
+		// Частичных индексов в MySQL нет
+		{mysqlDialect{}, "", "DROP INDEX `osl_components_active` ON `shop`.`components`"},
+	}
+	saved := dialect
+	t.Cleanup(func() { dialect = saved })
+	for _, tt := range tests {
+		dialect = tt.dialect
+		where := dialect.QuoteIdent("deleted_at") + " IS NULL"
+		if got := dialect.CreateIndexQuery(table, "osl_components_active", columns, where); got != tt.create {
+			t.Errorf("%T: создание %s, ожидалось %s", tt.dialect, got, tt.create)
+		}
+		if got := dialect.DropIndexQuery(table, "osl_components_active"); got != tt.drop {
+			t.Errorf("%T: удаление %s, ожидалось %s", tt.dialect, got, tt.drop)
+		}
+	}
+
+	dialect = mysqlDialect{}
+	if got := dialect.CreateIndexQuery(table, "osl_stock_component_quantity", []string{"component_id", "quantity"}, ""); got !=
+		"CREATE INDEX `osl_stock_component_quantity` ON `shop`.`components` (`component_id`, `quantity`) ALGORITHM=INPLACE LOCK=NONE" {
+		t.Errorf("mysql: %s", got)
+	}
+	if cost, ok := planCost("Seq Scan on components  (cost=0.00..35.50 rows=10 width=4)\n  Filter: (deleted_at IS NULL)"); !ok || cost != "35.50" {
+		t.Errorf("planCost = %q, %v", cost, ok)
+	}
+}
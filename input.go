@@ -0,0 +1,252 @@
+package osl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Ключевые слова для отмены текущей операции
+var cancelKeywords = map[string]bool{
+	"отмена": true,
+	"назад":  true,
+	"cancel": true,
+}
+
+// Ошибка ввода строки длиннее допустимой
+type inputTooLongError struct {
+	Length int
+	Limit  int
+}
+
+func (e *inputTooLongError) Error() string {
+	return msg("input.too_long", e.Length, e.Limit)
+}
+
+// Функция для получения максимальной длины строки ввода в символах (OSL_MAX_INPUT_LENGTH)
+func maxInputLength() int {
+	limit := envInt("OSL_MAX_INPUT_LENGTH", 4096)
+	if limit < 1 {
+		return 4096
+	}
+	return limit
+}
+
+// Функция для чтения строки ввода; конец ввода (Ctrl+D) возвращается как io.EOF.
+// Строка длиннее maxInputLength дочитывается до конца (чтобы ее остаток не попал
+// в ответы на следующие вопросы), но не сохраняется: возвращается *inputTooLongError.
+func readLine(reader *bufio.Reader) (string, error) {
+	limit := maxInputLength()
+	var line strings.Builder
+	length := 0
+	for {
+		r, _, err := reader.ReadRune()
+		if err != nil {
+			if errors.Is(err, io.EOF) && length > 0 {
+				// Последняя строка без перевода строки — обычный ввод
+				break
+			}
+			return "", err
+		}
+		if r == '\n' {
+			break
+		}
+		if r != '\r' {
+			length++
+		}
+		if length <= limit {
+			line.WriteRune(r)
+		}
+	}
+	if length > limit {
+		return "", &inputTooLongError{Length: length, Limit: limit}
+	}
+	return strings.TrimSpace(line.String()), nil
+}
+
+// Функция для проверки, является ли ввод командой отмены
+func isCancelInput(input string) bool {
+	return cancelKeywords[strings.ToLower(input)]
+}
+
+// Функция для запроса значения с проверкой и повтором вопроса при ошибке.
+// Возвращает false, если пользователь отменил ввод, ввод закончился или исчерпаны попытки.
+func promptValidated(reader *bufio.Reader, prompt string, validate func(string) error) (string, bool) {
+	attempts := envInt("OSL_PROMPT_ATTEMPTS", 3)
+	for attempt := 1; attempt <= attempts; attempt++ {
+		fmt.Fprint(stdout, prompt)
+		input, err := readLine(reader)
+		var tooLong *inputTooLongError
+		if errors.As(err, &tooLong) {
+			printError(msg("common.error", err))
+			continue
+		}
+		if err != nil {
+			fmt.Fprintln(stdout, msg("input.interrupted"))
+			return "", false
+		}
+
+		if isCancelInput(input) {
+			fmt.Fprintln(stdout, msg("input.cancelled"))
+			return "", false
+		}
+
+		if validate != nil {
+			if err := validate(input); err != nil {
+				printError(msg("common.error", err))
+				if attempt < attempts {
+					fmt.Fprintln(stdout, msg("input.retry"))
+				}
+				continue
+			}
+		}
+		return input, true
+	}
+
+	printError(msg("input.too_many_attempts"))
+	return "", false
+}
+
+// Функция для запроса произвольной строки
+func promptString(reader *bufio.Reader, prompt string) (string, bool) {
+	return promptValidated(reader, prompt, nil)
+}
+
+// Функция для запроса целого числа в диапазоне [min, max]
+func promptInt(reader *bufio.Reader, prompt string, min, max int) (int, bool) {
+	input, ok := promptValidated(reader, prompt, func(input string) error {
+		n, err := strconv.Atoi(input)
+		if err != nil || n < min || n > max {
+			if max == maxPromptInt {
+				return errors.New(msg("input.min_number", min))
+			}
+			return errors.New(msg("input.choose_range", min, max))
+		}
+		return nil
+	})
+	if !ok {
+		return 0, false
+	}
+	n, _ := strconv.Atoi(input)
+	return n, true
+}
+
+// Верхняя граница для promptInt, когда она не ограничена
+const maxPromptInt = int(^uint(0) >> 1)
+
+// Функция для проверки значения колонки: допустимые символы и числовой формат по типу колонки
+// (целое число для целочисленных типов, десятичное — для NUMERIC, REAL и подобных)
+func validateColumnValue(table TableInfo, column, value string) error {
+	if err := checkAllowedChars(table, column, value); err != nil {
+		return err
+	}
+	switch {
+	case isIntegerColumn(table, column):
+		if !integerRegex.MatchString(value) {
+			return errors.New(msg("input.not_number", column))
+		}
+	case isNumericColumn(table, column):
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+			return errors.New(msg("input.not_number", column))
+		}
+	}
+	return nil
+}
+
+// Дробные числовые типы (имена типов драйвера PostgreSQL, SQLite и MySQL)
+var decimalTypes = map[string]bool{
+	"NUMERIC": true, "DECIMAL": true, "REAL": true, "FLOAT": true, "FLOAT4": true, "FLOAT8": true,
+	"DOUBLE": true, "DOUBLE PRECISION": true,
+}
+
+// Функция для проверки, что колонка целочисленная (логический TINYINT(1) в MySQL — нет)
+func isIntegerColumn(table TableInfo, column string) bool {
+	dbType := strings.TrimPrefix(strings.ToUpper(table.Types[column]), "UNSIGNED ")
+	_, ok := integerTypeRanges[dbType]
+	return ok && !isBooleanColumn(table, column)
+}
+
+// Функция для проверки, должно ли значение колонки быть числом (по типу колонки в БД)
+func isNumericColumn(table TableInfo, column string) bool {
+	return isIntegerColumn(table, column) || decimalTypes[strings.ToUpper(table.Types[column])]
+}
+
+// Функция для запроса значения колонки таблицы с проверкой по ее определению.
+// Возвращает значение в том виде, в котором оно передается в запрос.
+func promptColumnValue(reader *bufio.Reader, prompt string, table TableInfo, column string) (string, bool) {
+	value, ok := promptValidated(reader, columnPrompt(prompt, table, column), func(value string) error {
+		return validateTableValue(table, column, value)
+	})
+	if !ok {
+		return "", false
+	}
+	return normalizeTableValue(table, column, value), true
+}
+
+// Функция для запроса значения колонки со значением по умолчанию в БД: пустой ввод или DEFAULT
+// означают, что колонка не указывается в INSERT и ее заполняет СУБД.
+// Возвращает значение, признак значения по умолчанию и false при отмене.
+func promptColumnValueOrDefault(reader *bufio.Reader, prompt string, table TableInfo, column, defaultExpr string) (string, bool, bool) {
+	prompt = strings.TrimSuffix(columnPrompt(prompt, table, column), ": ") + msg("input.default_hint", defaultExpr)
+	value, ok := promptValidated(reader, prompt, func(value string) error {
+		if isDefaultInput(value) {
+			return nil
+		}
+		return validateTableValue(table, column, value)
+	})
+	if !ok {
+		return "", false, false
+	}
+	if isDefaultInput(value) {
+		return "", true, true
+	}
+	return normalizeTableValue(table, column, value), false, true
+}
+
+// Функция для проверки, выбрано ли значение по умолчанию (пустой ввод или DEFAULT)
+func isDefaultInput(value string) bool {
+	return value == "" || strings.EqualFold(value, "DEFAULT")
+}
+
+// Функция для добавления к вопросу подсказки о формате значения колонки
+func columnPrompt(prompt string, table TableInfo, column string) string {
+	if isBooleanColumn(table, column) {
+		return strings.TrimSuffix(prompt, ": ") + msg("input.bool_hint")
+	} else if isTimestampColumn(table, column) {
+		return strings.TrimSuffix(prompt, ": ") + msg("input.time_hint", timeFormat())
+	} else if isDateColumn(table, column) {
+		return strings.TrimSuffix(prompt, ": ") + msg("input.date_hint")
+	}
+	return prompt
+}
+
+// Функция для запроса подтверждения; возвращает true только при ответе «да»
+func promptConfirm(reader *bufio.Reader, prompt string) bool {
+	input, ok := promptValidated(reader, prompt, func(input string) error {
+		if _, ok := parseYesNo(input); !ok {
+			return errors.New(msg("input.yes_no"))
+		}
+		return nil
+	})
+	if !ok {
+		return false
+	}
+	answer, _ := parseYesNo(input)
+	return answer
+}
+
+// Функция для разбора ответа да/нет
+func parseYesNo(input string) (bool, bool) {
+	switch strings.ToLower(input) {
+	case "да", "д", "yes", "y":
+		return true, true
+	case "нет", "н", "no", "n":
+		return false, true
+	}
+	return false, false
+}
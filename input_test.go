@@ -0,0 +1,49 @@
+package osl
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"strings"
+	"testing"
+)
+
+// Вставка 1 МБ текста в меню и в поле ввода: строка отклоняется целиком с указанием длины,
+// ее остаток не становится ответами на следующие вопросы, и сеанс продолжается
+func TestHugePasteKeepsSessionCoherent(t *testing.T) {
+	openBaseSchema(t)
+	assumeYes = true
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	t.Cleanup(func() { log.SetOutput(io.Discard) })
+
+	paste := strings.Repeat("Вставленный документ; ", 1<<20/len("Вставленный документ; ")+1)
+	runes := len([]rune(paste))
+	tooLong := msg("input.too_long", runes, maxInputLength())
+
+	var code int
+	output := captureOutput(t, func() {
+		code = mainMenu(scriptReader(
+			paste,
+			// Пункт 4 — добавление: одна запись в categories, вставка вместо названия, затем обычный ввод
+			"4", "1", "1", paste, "Процессоры", "ЦП",
+			"0"))
+	})
+	if code != 0 {
+		t.Errorf("mainMenu = %d, ожидался выход по пункту 0", code)
+	}
+	if count := strings.Count(output, tooLong); count != 2 {
+		t.Errorf("отказ по длине выведен %d раз, ожидалось 2", count)
+	}
+	if got := queryString(t, "SELECT group_concat(name || '/' || description) FROM categories"); got != "Процессоры/ЦП" {
+		t.Errorf("добавлено %q", got)
+	}
+	if !strings.Contains(output, msg("menu.bye")) {
+		t.Error("сеанс не дошел до выхода")
+	}
+	for name, text := range map[string]string{"экран": output, "журнал": logged.String()} {
+		if strings.Contains(text, "Вставленный документ") {
+			t.Errorf("вставленный текст выведен (%s)", name)
+		}
+	}
+}
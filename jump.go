@@ -0,0 +1,91 @@
+package osl
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+// Быстрый переход к записи: пользователь выбирает таблицу и вводит значения первичного ключа,
+// запись выбирается параметризованным запросом и выводится блоком «колонка: значение».
+// Архивные записи тоже показываются, но с пометкой.
+
+// Пункт 29: Найти запись по ключу
+func showRecordByKey(reader *bufio.Reader) {
+	tableIndex := selectTable(reader, msg("select_table.jump"))
+	if tableIndex < 0 {
+		return
+	}
+	table := tables[tableIndex]
+
+	keys := primaryKeyColumns(table)
+	if len(keys) == 0 {
+		printError(msg("jump.no_key", table.Name))
+		return
+	}
+
+	conditions := make([]string, len(keys))
+	args := make([]interface{}, len(keys))
+	for i, key := range keys {
+		value, ok := promptColumnValue(reader, msg("jump.key_prompt", key), table, key)
+		if !ok {
+			return
+		}
+		conditions[i] = fmt.Sprintf("%s = $%d", dialect.QuoteIdent(key), i+1)
+		args[i] = value
+	}
+
+	query := fmt.Sprintf("SELECT * FROM %s WHERE %s", table.SQLName(), strings.Join(conditions, " AND "))
+	logToFileAndScreen(fmt.Sprintf("Поиск записи по ключу: %s с параметрами %v", query, args))
+	rs, err := queryRecords(query, args...)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка поиска записи по ключу: %v", err))
+		printError(msg("view.query_failed"))
+		return
+	}
+
+	if len(rs.Rows) == 0 {
+		fmt.Fprintln(stdout, msg("jump.not_found", table.Name, keyDescription(keys, args)))
+		return
+	}
+	printVertical(rs)
+	if recordArchived(table, rs) {
+		fmt.Fprintln(stdout, msg("jump.archived"))
+	}
+}
+
+// Функция для выполнения запроса и чтения всех записей результата
+func queryRecords(query string, args ...interface{}) (*ResultSet, error) {
+	rows, err := dbQuery(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanRows(rows)
+}
+
+// Функция для описания значений ключа записи: "колонка=значение, ..."
+func keyDescription(keys []string, values []interface{}) string {
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = fmt.Sprintf("%s=%v", key, values[i])
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// Функция для проверки, помечена ли первая запись результата как удаленная (мягкое удаление)
+func recordArchived(table TableInfo, rs *ResultSet) bool {
+	column := softDeleteColumn(table)
+	for i, name := range rs.Columns {
+		if name != column || len(rs.Rows) == 0 {
+			continue
+		}
+		value := rs.Rows[0][i]
+		if column == "archived" {
+			archived, _ := parseBoolValue(value)
+			return archived
+		}
+		return value != ""
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package osl
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Таблица без первичного ключа: добавлять записи можно, изменять и удалять по ключу — нет
+func TestKeylessTable(t *testing.T) {
+	openSchema(t, []string{"notes"},
+		"CREATE TABLE notes (body TEXT NOT NULL, tag TEXT)",
+		"INSERT INTO notes (body, tag) VALUES ('первая', 'a')")
+
+	if key := tableKeyColumn("notes"); key != "" {
+		t.Fatalf("ключ таблицы без первичного ключа: %q", key)
+	}
+
+	output := captureOutput(t, func() {
+		// Обновление по ID, выбор таблицы notes
+		updateData(scriptReader("1", "1", "1"))
+		executeUpdate(scriptReader(), UpdateSpec{Table: "notes", Operator: " AND ", Column: "tag", Value: "b",
+			Conditions: []FilterCondition{{Column: "body", Type: filterEquals, Values: []string{"первая"}}}})
+		deleteRecords(scriptReader("1"))
+	})
+	if count := strings.Count(output, msg("common.no_key", "notes")); count != 3 {
+		t.Errorf("отказ для таблицы без ключа выведен %d раз, ожидалось 3:\n%s", count, output)
+	}
+	if got := queryString(t, "SELECT tag FROM notes"); got != "a" {
+		t.Errorf("запись таблицы без ключа изменена: tag = %q", got)
+	}
+
+	// Добавление работает, но ключи добавленных записей не возвращаются
+	var ids []string
+	err := dbTransaction(func(tx *sql.Tx) error {
+		var err error
+		ids, err = insertRecords(tx, "notes", []string{"body", "tag"}, [][]string{{"вторая", "c"}})
+		return err
+	})
+	if err != nil || ids != nil {
+		t.Fatalf("insertRecords = %v, %v", ids, err)
+	}
+	if got := queryString(t, "SELECT COUNT(*) FROM notes"); got != "2" {
+		t.Errorf("в таблице %s записей, ожидалось 2", got)
+	}
+}
+
+// Таблица с текстовым ключом uuid, на которую ссылаются таблица с ключом и таблица без ключа
+func TestUUIDKeyTable(t *testing.T) {
+	openSchema(t, []string{"parts", "orders", "remarks"},
+		"CREATE TABLE parts (uuid TEXT PRIMARY KEY, name TEXT NOT NULL, price NUMERIC)",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, part_uuid TEXT REFERENCES parts(uuid), qty INTEGER)",
+		"CREATE TABLE remarks (part_uuid TEXT REFERENCES parts(uuid), body TEXT)",
+		"INSERT INTO parts VALUES ('a-1', 'Кулер', 10), ('b-2', 'Блок питания', 30)",
+		"INSERT INTO orders (part_uuid, qty) VALUES ('a-1', 2)",
+		"INSERT INTO remarks VALUES ('a-1', 'тихий')")
+
+	if key := tableKeyColumn("parts"); key != "uuid" {
+		t.Fatalf("ключ таблицы parts: %q", key)
+	}
+
+	// Обновление цены по uuid: режим по ID, одна запись, таблица parts, колонка price
+	captureOutput(t, func() {
+		updateData(scriptReader("1", "1", "1", "a-1", "2", "12", "да"))
+	})
+	if got := queryString(t, "SELECT price FROM parts WHERE uuid = 'a-1'"); got != "12" {
+		t.Fatalf("цена после обновления %q, ожидалось 12", got)
+	}
+	if lastUndo == nil || len(lastUndo.Steps) != 1 {
+		t.Fatalf("отмена обновления не сохранена: %+v", lastUndo)
+	}
+	statements := lastUndo.Steps[0].statements()
+	if len(statements) != 1 || !strings.Contains(statements[0].Query, `WHERE "uuid" = $2`) || statements[0].Args[1] != "a-1" {
+		t.Errorf("запрос отмены %+v", statements)
+	}
+
+	// Ключ, введенный вместе с записью, возвращается для отмены
+	var ids []string
+	err := dbTransaction(func(tx *sql.Tx) error {
+		var err error
+		ids, err = insertRecords(tx, "parts", []string{"uuid", "name"}, [][]string{{"c-3", "Корпус"}})
+		return err
+	})
+	if err != nil || strings.Join(ids, ",") != "c-3" {
+		t.Fatalf("insertRecords = %v, %v", ids, err)
+	}
+
+	// Выбор внешнего ключа из списка (по названию) и ручной ввод
+	var picked string
+	captureOutput(t, func() {
+		picked, _ = pickForeignKey(scriptReader("3"), "parts")
+	})
+	if picked != "a-1" {
+		t.Errorf("выбран ключ %q, ожидался a-1 (Кулер — третий по названию)", picked)
+	}
+	if err := validateManualID("parts", "#b-2"); err != nil {
+		t.Errorf("ручной ввод существующего uuid: %v", err)
+	}
+	if err := validateManualID("parts", "#z-9"); err == nil {
+		t.Error("ручной ввод несуществующего uuid принят")
+	}
+
+	// Удаление с каскадом в таблицу с ключом и таблицу без ключа
+	output := captureOutput(t, func() {
+		deleteRecords(scriptReader("1", "a-1", "1", "да"))
+	})
+	for _, query := range []string{"SELECT COUNT(*) FROM parts WHERE uuid = 'a-1'", "SELECT COUNT(*) FROM orders", "SELECT COUNT(*) FROM remarks"} {
+		if got := queryString(t, query); got != "0" {
+			t.Errorf("%s = %s после каскадного удаления:\n%s", query, got, output)
+		}
+	}
+}
+
+// Постраничная выгрузка по текстовому ключу
+func TestExportByUUIDKey(t *testing.T) {
+	openSchema(t, []string{"parts"},
+		"CREATE TABLE parts (uuid TEXT PRIMARY KEY, name TEXT NOT NULL)",
+		"INSERT INTO parts VALUES ('c-3', 'Корпус'), ('a-1', 'Кулер'), ('b-2', 'Блок питания')")
+	t.Setenv("OSL_EXPORT_PAGE_SIZE", "2")
+
+	path := filepath.Join(t.TempDir(), "parts.csv")
+	token := ExportToken{Spec: ExportSpec{Table: "parts", Columns: tables[0].Columns, Format: "csv", Path: path}}
+	var err error
+	captureOutput(t, func() {
+		token, err = runExport(context.Background(), token)
+	})
+	if err != nil {
+		t.Fatalf("runExport: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "uuid,name\na-1,Кулер\nb-2,Блок питания\nc-3,Корпус\n"
+	if string(data) != want {
+		t.Errorf("выгрузка:\n%s\nожидалось:\n%s", data, want)
+	}
+	if token.RowCount != 3 || token.LastKey != "c-3" {
+		t.Errorf("токен после выгрузки %+v", token)
+	}
+}
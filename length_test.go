@@ -0,0 +1,51 @@
+package osl
+
+import (
+	"strings"
+	"testing"
+)
+
+// Длина строки проверяется в символах, а не в байтах: кириллица занимает два байта на символ
+func TestMaxLengthBoundaries(t *testing.T) {
+	openSchema(t, []string{"parts"}, "CREATE TABLE parts (id INTEGER PRIMARY KEY, code VARCHAR(5), name VARCHAR(1), note TEXT)")
+	table := tables[0]
+	assumeYes = true
+
+	if got := columnDetails(table, "code").MaxLength; got != 5 {
+		t.Fatalf("максимальная длина code = %d, ожидалось 5", got)
+	}
+	tests := []struct {
+		column, value string
+		ok            bool
+	}{
+		{"code", "abcd", true},
+		{"code", "abcde", true},
+		{"code", "abcdef", false},
+		{"code", "Кулер", true},
+		{"code", "Кулеры", false},
+		{"code", "ёЁ-1", true},
+		{"name", "Ж", true},
+		{"name", "Жа", false},
+		{"note", strings.Repeat("длинный текст ", 100), true},
+	}
+	for _, tt := range tests {
+		err := validateTableValue(table, tt.column, tt.value)
+		if (err == nil) != tt.ok {
+			t.Errorf("validateTableValue(%s, %q) = %v, ожидалось допустимо=%v", tt.column, tt.value, err, tt.ok)
+		}
+	}
+	if err := validateTableValue(table, "code", "Кулеры"); err == nil || err.Error() != msg("validation.too_long", 5, 6) {
+		t.Errorf("сообщение о длине: %v", err)
+	}
+
+	// Слишком длинное значение запрашивается повторно и не доходит до БД
+	output := captureOutput(t, func() {
+		insertData(scriptReader("1", "1", "Кулеры", "Кулер", "Жа", "Ж", "заметка"))
+	})
+	if got := queryString(t, "SELECT code || '/' || name FROM parts"); got != "Кулер/Ж" {
+		t.Errorf("добавлено %q:\n%s", got, output)
+	}
+	if strings.Count(output, "максимум") != 2 {
+		t.Errorf("ожидались два отказа по длине:\n%s", output)
+	}
+}
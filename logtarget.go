@@ -0,0 +1,61 @@
+package osl
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Назначение журнала (LOG_TARGET): файл LOG_FILE, stdout (для docker logs) или оба сразу.
+// Записи журнала идут в стандартный логгер через io.MultiWriter; сообщения для пользователя
+// выводятся на экран отдельно и в журнал не попадают.
+
+// Допустимые значения LOG_TARGET
+const (
+	logTargetFile   = "file"
+	logTargetStdout = "stdout"
+	logTargetBoth   = "both"
+)
+
+// Журнал выводится в stdout: ошибки уже видны на экране и не выводятся повторно
+var logToStdout bool
+
+// Функция для получения назначения журнала (LOG_TARGET, по умолчанию file)
+func logTarget() string {
+	target := strings.ToLower(envString("LOG_TARGET", logTargetFile))
+	switch target {
+	case logTargetFile, logTargetStdout, logTargetBoth:
+		return target
+	}
+	log.Printf("Некорректное значение LOG_TARGET=%q, используется %s", target, logTargetFile)
+	return logTargetFile
+}
+
+// Функция для настройки вывода журнала. Если файл журнала недоступен для записи,
+// выводится предупреждение и журнал пишется в stdout.
+func setupLogOutput(target, path string) {
+	var writers []io.Writer
+	var openErr error
+	if target != logTargetStdout {
+		// Каталог журнала берется из LOG_FILE
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			openErr = err
+		} else if logFile, openErr = os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); openErr == nil {
+			writers = append(writers, logFile)
+		} else {
+			logFile = nil
+		}
+	}
+	if target != logTargetFile || openErr != nil {
+		writers = append(writers, stdout)
+		logToStdout = true
+	}
+	log.SetOutput(io.MultiWriter(writers...))
+
+	if openErr != nil {
+		logToFileAndScreen(fmt.Sprintf("Предупреждение: файл логов %s недоступен (%v), журнал выводится в stdout", path, openErr))
+	}
+}
@@ -0,0 +1,170 @@
+package osl
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Просмотр журнала из приложения: последние строки активного файла логов читаются блоками
+// с конца файла, поэтому размер журнала не ограничен памятью. Если файл заменили при ротации,
+// показывается новый файл по тому же пути с предупреждением.
+
+// Размер блока, которым файл журнала читается с конца
+const logTailBlockSize = 64 * 1024
+
+// Максимальное количество строк журнала за один просмотр
+const logTailMaxLines = 10000
+
+// Функция для проверки, является ли сообщение журнала ошибкой (такие сообщения выводятся и на экран)
+func isErrorMessage(message string) bool {
+	return strings.Contains(strings.ToLower(message), "ошибка")
+}
+
+// Функция для чтения последних n строк файла, удовлетворяющих условию match (nil — все строки).
+// Строки возвращаются в порядке записи в файл.
+func tailLines(path string, n int, match func(string) bool) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	offset, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	var partial []byte // начало строки, которая продолжается в уже прочитанном блоке
+	block := make([]byte, logTailBlockSize)
+	for offset > 0 && len(lines) < n {
+		size := int64(logTailBlockSize)
+		if offset < size {
+			size = offset
+		}
+		offset -= size
+		if _, err := file.ReadAt(block[:size], offset); err != nil {
+			return nil, err
+		}
+
+		data := append(append([]byte(nil), block[:size]...), partial...)
+		parts := bytes.Split(data, []byte("\n"))
+		// Первая часть может быть продолжением строки из предыдущего блока файла
+		partial = parts[0]
+		for i := len(parts) - 1; i >= 1 && len(lines) < n; i-- {
+			lines = appendTailLine(lines, string(parts[i]), match)
+		}
+	}
+	if offset == 0 && len(lines) < n {
+		lines = appendTailLine(lines, string(partial), match)
+	}
+
+	// Строки собраны с конца файла
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+	return lines, nil
+}
+
+// Функция для добавления непустой строки журнала, если она подходит под условие
+func appendTailLine(lines []string, line string, match func(string) bool) []string {
+	line = strings.TrimRight(line, "\r")
+	if line == "" || (match != nil && !match(line)) {
+		return lines
+	}
+	return append(lines, line)
+}
+
+// Функция для проверки, заменен ли файл журнала по пути path при ротации
+func logFileRotated(path string) bool {
+	if logFile == nil {
+		return false
+	}
+	current, err := logFile.Stat()
+	if err != nil {
+		return false
+	}
+	onDisk, err := os.Stat(path)
+	if err != nil {
+		return true
+	}
+	return !os.SameFile(current, onDisk)
+}
+
+// Пункт 28: Показать журнал
+func showLog(reader *bufio.Reader) {
+	if logFile == nil && logToStdout {
+		fmt.Fprintln(stdout, msg("log_view.stdout_only"))
+		return
+	}
+	path := logFilePath()
+	if logFile != nil {
+		path = logFile.Name()
+		// Записанное в журнал должно попасть на диск до чтения файла
+		if err := logFile.Sync(); err != nil {
+			fmt.Fprintln(stdout, msg("log_view.sync_failed", err))
+		}
+	}
+
+	defaultLines := envInt("OSL_LOG_TAIL_LINES", 50)
+	input, ok := promptValidated(reader, msg("log_view.lines_prompt", defaultLines), func(input string) error {
+		if input == "" {
+			return nil
+		}
+		if n, err := strconv.Atoi(input); err != nil || n < 1 || n > logTailMaxLines {
+			return errors.New(msg("input.choose_range", 1, logTailMaxLines))
+		}
+		return nil
+	})
+	if !ok {
+		return
+	}
+	count := defaultLines
+	if input != "" {
+		count, _ = strconv.Atoi(input)
+	}
+
+	errorsInput, ok := promptValidated(reader, msg("log_view.errors_prompt"), func(input string) error {
+		if input == "" {
+			return nil
+		}
+		if _, ok := parseYesNo(input); !ok {
+			return errors.New(msg("input.yes_no"))
+		}
+		return nil
+	})
+	if !ok {
+		return
+	}
+	var match func(string) bool
+	if onlyErrors, _ := parseYesNo(errorsInput); onlyErrors {
+		match = isErrorMessage
+	}
+
+	if logFileRotated(path) {
+		fmt.Fprintln(stdout, msg("log_view.rotated", path))
+	}
+	lines, err := tailLines(path, count, match)
+	if err != nil {
+		printError(msg("log_view.read_failed", path, err))
+		return
+	}
+	if len(lines) == 0 {
+		fmt.Fprintln(stdout, msg("log_view.empty", path))
+		return
+	}
+	fmt.Fprintln(stdout, msg("log_view.title", path, len(lines)))
+	for _, line := range lines {
+		if isErrorMessage(line) {
+			printError(line)
+			continue
+		}
+		fmt.Fprintln(stdout, line)
+	}
+}
@@ -0,0 +1,171 @@
+package osl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Максимальное количество записей, которое показывается без предварительного поиска
+const lookupListLimit = 20
+
+// Структура для записи из справочной таблицы
+type lookupItem struct {
+	ID   string
+	Name string
+}
+
+// Функция для определения таблицы, на которую ссылается колонка (пустая строка — не внешний ключ)
+func foreignKeyTarget(table TableInfo, column string) string {
+	return table.ForeignKeys[column]
+}
+
+// Функция для выбора значения внешнего ключа из списка записей связанной таблицы.
+// Возвращает выбранный ключ записи и false, если выбор отменен или ввод некорректен.
+func pickForeignKey(reader *bufio.Reader, refTable string) (string, bool) {
+	key, err := tableKeyRef(refTable)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка загрузки списка из %s: %v", refTable, err))
+		printError(msg("lookup.check_failed"))
+		return "", false
+	}
+	// Записи показываются по названию, а если колонки name нет — по самому ключу
+	label := tableKeyColumn(refTable)
+	// Архивные записи не предлагаются для новых ссылок
+	where := ""
+	if table, ok := findTable(refTable); ok {
+		if condition := activeRowsCondition(table); condition != "" {
+			where = " WHERE " + condition
+		}
+		if containsString(table.Columns, "name") {
+			label = "name"
+		}
+	}
+	query := fmt.Sprintf("SELECT %s, %s FROM %s%s ORDER BY %s%s", key, dialect.QuoteIdent(label),
+		tableRef(refTable), where, dialect.QuoteIdent(label), collateSuffix(refTable, label))
+	logToFileAndScreen(fmt.Sprintf("Выполнение запроса: %s", query))
+
+	rows, err := dbQuery(query)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка загрузки списка из %s: %v", refTable, err))
+		input, ok := promptValidated(reader, msg("lookup.manual_id"), func(input string) error {
+			return validateManualID(refTable, input)
+		})
+		if !ok {
+			return "", false
+		}
+		return parseManualID(input)
+	}
+	defer rows.Close()
+
+	var items []lookupItem
+	for rows.Next() {
+		var item lookupItem
+		if err := rows.Scan(&item.ID, &item.Name); err != nil {
+			logToFileAndScreen(fmt.Sprintf("Ошибка чтения строки: %v", err))
+			continue
+		}
+		items = append(items, item)
+	}
+
+	if len(items) == 0 {
+		fmt.Fprintln(stdout, msg("lookup.empty", refTable))
+		return "", false
+	}
+
+	// Для длинных списков сначала предлагаем сузить выбор по части названия
+	if len(items) > lookupListLimit {
+		term, ok := promptValidated(reader,
+			msg("lookup.search", refTable, len(items)),
+			func(term string) error {
+				if strings.HasPrefix(term, "#") {
+					return validateManualID(refTable, term)
+				}
+				if len(filterLookupItems(items, term)) == 0 {
+					return errors.New(msg("lookup.no_match", term))
+				}
+				return nil
+			})
+		if !ok {
+			return "", false
+		}
+		if strings.HasPrefix(term, "#") {
+			return parseManualID(term)
+		}
+		items = filterLookupItems(items, term)
+	}
+
+	fmt.Fprintln(stdout, msg("lookup.title", refTable))
+	for i, item := range items {
+		fmt.Fprintf(stdout, "%d. %s (id=%s)\n", i+1, item.Name, item.ID)
+	}
+	fmt.Fprintln(stdout, msg("common.back"))
+
+	input, ok := promptValidated(reader, msg("lookup.prompt"), func(input string) error {
+		if strings.HasPrefix(input, "#") {
+			return validateManualID(refTable, input)
+		}
+		choice, err := strconv.Atoi(input)
+		if err != nil || choice < 0 || choice > len(items) {
+			return errors.New(msg("input.choose_range", 0, len(items)))
+		}
+		return nil
+	})
+	if !ok {
+		return "", false
+	}
+
+	if strings.HasPrefix(input, "#") {
+		return parseManualID(input)
+	}
+
+	choice, _ := strconv.Atoi(input)
+	if choice == 0 {
+		return "", false
+	}
+
+	return items[choice-1].ID, true
+}
+
+// Функция для отбора записей по подстроке в названии без учета регистра
+func filterLookupItems(items []lookupItem, term string) []lookupItem {
+	term = strings.ToLower(term)
+	var result []lookupItem
+	for _, item := range items {
+		if strings.Contains(strings.ToLower(item.Name), term) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// Функция для разбора ручного ввода ID в формате #<id> (решетка необязательна)
+func parseManualID(input string) (string, bool) {
+	id := strings.TrimSpace(strings.TrimPrefix(input, "#"))
+	return id, id != ""
+}
+
+// Функция для проверки ручного ввода ID по типу ключа связанной таблицы,
+// включая существование записи в ней
+func validateManualID(refTable, input string) error {
+	id, ok := parseManualID(input)
+	if !ok {
+		return errors.New(msg("lookup.not_found", refTable))
+	}
+	if table, found := findTable(refTable); found && tableKeyColumn(refTable) != "" {
+		if err := validateTableValue(table, tableKeyColumn(refTable), id); err != nil {
+			return err
+		}
+	}
+	exists, err := foreignKeyExists(refTable, id)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка проверки записи %s в %s: %v", id, refTable, err))
+		return errors.New(msg("lookup.check_failed"))
+	}
+	if !exists {
+		return errors.New(msg("lookup.not_found", refTable))
+	}
+	return nil
+}
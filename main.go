@@ -1,14 +1,20 @@
-package main
+// Пакет osl — консольное приложение для работы с базой данных компонентов ПК. Сеанс запускается
+// функцией Run с заданными вводом и выводом; программа командной строки находится в cmd/osl.
+package osl
 
 import (
 	"bufio"
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	_ "github.com/lib/pq"
@@ -16,8 +22,12 @@ import (
 
 // Структура для хранения информации о таблице
 type TableInfo struct {
-	Name    string
-	Columns []string
+	Name        string            // имя таблицы, возможно со схемой: "схема.таблица"
+	Schema      string            // схема из каталога БД (пусто — схема по умолчанию)
+	Columns     []string
+	ForeignKeys map[string]string // колонка -> таблица, на которую она ссылается
+	Types       map[string]string // колонка -> тип в БД
+	Details     []ColumnInfo      // структура колонок из каталога БД
 }
 
 // Структура для конфигурации БД
@@ -28,109 +38,332 @@ type DBConfig struct {
 	User     string
 	Password string
 	SSLMode  string
+	Driver   string // postgres, sqlite или mysql
+	// Ограничение времени выполнения запроса на сервере (0 — без ограничения)
+	StatementTimeout time.Duration
+	// Схема поиска таблиц (search_path в PostgreSQL; пусто — по умолчанию)
+	SearchPath string
 }
 
 // Глобальные переменные
 var (
 	db             *sql.DB
+	activeConfig   DBConfig // параметры текущего подключения (для профилей и клонирования)
 	tables         []TableInfo
-	relatedTables  []string
+	relatedTables  []tableRelation // связи между таблицами по внешним ключам (см. loadRelations)
 	logFile        *os.File
-	whiteListRegex = regexp.MustCompile(`^[a-zA-Zа-яА-ЯёЁ0-9\s\-\.]+$`)
+	whiteListRegex = regexp.MustCompile(`^[a-zA-Zа-яА-ЯёЁ0-9\s\-\.]+$`) // строгий режим, см. validation.go
 )
 
-func main() {
-	// Получение пути к файлу логов из переменной окружения
-	logPath := os.Getenv("LOG_FILE")
-	if logPath == "" {
-		logPath = "/logs/app.log"
+// Параметры запуска сеанса программы
+type Options struct {
+	DB      DBConfig // пустые логин и пароль запрашиваются у пользователя
+	LogFile string
+	// Назначение журнала: file, stdout или both (LOG_TARGET)
+	LogTarget string
+	Args      []string // команда командной строки; пусто — интерактивное меню
+	// Разрешить в режиме SQL-запросов изменение данных (--allow-write или OSL_ALLOW_WRITE_SQL)
+	AllowWriteSQL bool
+	// Выполнять изменения без запроса подтверждения (--yes или OSL_ASSUME_YES)
+	AssumeYes bool
+	// Режим только для чтения (--readonly или OSL_READONLY)
+	ReadOnly bool
+	// Принимать подозрительно большие изменения без подтверждения (--allow-large-price-changes
+	// или OSL_ALLOW_LARGE_CHANGES)
+	AllowLargeChanges bool
+	// Разрешить создание и удаление индексов (--allow-ddl или OSL_ALLOW_DDL)
+	AllowDDL bool
+}
+
+// Функция для получения параметров запуска из переменных окружения и аргументов командной строки
+func OptionsFromEnv(args []string) Options {
+	flags, args := globalFlags(args)
+	return Options{
+		DB: DBConfig{
+			Host:    os.Getenv("DB_HOST"),
+			Port:    os.Getenv("DB_PORT"),
+			Name:    os.Getenv("DB_NAME"),
+			SSLMode: os.Getenv("DB_SSLMODE"),
+			Driver:  envString("DB_DRIVER", "postgres"),
+
+			StatementTimeout: envDuration("DB_STATEMENT_TIMEOUT", 0),
+		},
+		LogFile:   logFilePath(),
+		LogTarget: logTarget(),
+		Args:      args,
+
+		AllowWriteSQL: flags.AllowWrite || envBool("OSL_ALLOW_WRITE_SQL", false),
+		AssumeYes:     flags.AssumeYes || envBool("OSL_ASSUME_YES", false),
+		ReadOnly:      flags.ReadOnly || envBool("OSL_READONLY", false),
+
+		AllowLargeChanges: flags.AllowLargeChanges || envBool("OSL_ALLOW_LARGE_CHANGES", false),
+		AllowDDL:          flags.AllowDDL || envBool("OSL_ALLOW_DDL", false),
 	}
+}
 
-	// Создание директории для логов если не существует
-	os.MkdirAll("/logs", 0755)
+// Общие флаги, заданные перед командой
+type launchFlags struct {
+	AllowWrite bool // --allow-write: изменение данных в режиме SQL-запросов
+	AssumeYes  bool // --yes: изменения выполняются без запроса подтверждения
+	ReadOnly   bool // --readonly: изменение данных запрещено
+	// --allow-large-price-changes: подозрительно большие изменения принимаются без подтверждения
+	AllowLargeChanges bool
+	AllowDDL          bool // --allow-ddl: создание и удаление индексов
+}
 
-	// Открытие файла логов
-	var err error
-	logFile, err = os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		log.Printf("Ошибка открытия файла логов: %v", err)
-		os.Exit(1)
+// Функция для отделения общих флагов, заданных перед командой (osl --allow-write --yes --readonly --allow-large-price-changes --allow-ddl [команда ...]).
+// Возвращает флаги и оставшиеся аргументы.
+func globalFlags(args []string) (launchFlags, []string) {
+	var flags launchFlags
+	for len(args) > 0 {
+		switch args[0] {
+		case "--allow-write":
+			flags.AllowWrite = true
+		case "--yes", "-y":
+			flags.AssumeYes = true
+		case "--readonly":
+			flags.ReadOnly = true
+		case "--allow-large-price-changes":
+			flags.AllowLargeChanges = true
+		case "--allow-ddl":
+			flags.AllowDDL = true
+		default:
+			return flags, args
+		}
+		args = args[1:]
+	}
+	return flags, args
+}
+
+// Ненулевой код завершения сеанса (например, 1 — ошибка подключения, 2 — неверные аргументы команды)
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("сеанс завершен с кодом %d", e.Code)
+}
+
+// Ввод и вывод текущего сеанса (задаются в Run)
+var (
+	stdin      io.Reader = os.Stdin
+	stdout     io.Writer = os.Stdout
+	stderr     io.Writer = os.Stderr
+	sessionCtx           = context.Background()
+)
+
+// Состояние программы хранится в глобальных переменных, поэтому сеансы Run и вызовы Store
+// выполняются по одному
+var sessionMu sync.Mutex
+
+// Состояние сеанса: подключение, структура БД и ввод-вывод
+type sessionState struct {
+	db            *sql.DB
+	dialect       Dialect
+	config        DBConfig
+	appConfig     AppConfig
+	tables        []TableInfo
+	relatedTables []tableRelation
+	rowRules      []*RowRule
+	auditReady    bool
+	readOnly      bool
+	dryRun        bool
+	interactive   bool
+	stdin         io.Reader
+	stdout        io.Writer
+	stderr        io.Writer
+	ctx           context.Context
+}
+
+// Функция для сохранения текущего состояния сеанса
+func captureSession() sessionState {
+	return sessionState{db, dialect, activeConfig, appConfig, tables, relatedTables, rowRules,
+		auditReady, readOnly, dryRun, interactive, stdin, stdout, stderr, sessionCtx}
+}
+
+// Функция для восстановления сохраненного состояния сеанса
+func (s sessionState) restore() {
+	db, dialect, activeConfig, appConfig = s.db, s.dialect, s.config, s.appConfig
+	tables, relatedTables, rowRules, auditReady = s.tables, s.relatedTables, s.rowRules, s.auditReady
+	readOnly, dryRun, interactive = s.readOnly, s.dryRun, s.interactive
+	stdin, stdout, stderr, sessionCtx = s.stdin, s.stdout, s.stderr, s.ctx
+}
+
+// Функция для выполнения сеанса программы со своими вводом и выводом: подключение, загрузка
+// структуры БД, затем команда из opts.Args или интерактивное меню. Отмена ctx завершает меню
+// перед следующим пунктом. Ненулевой код завершения возвращается как *ExitError.
+// Одновременно выполняется только один сеанс: остальные вызовы ждут его завершения.
+func Run(ctx context.Context, opts Options, in io.Reader, out, errOut io.Writer) error {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+
+	saved, savedColor := captureSession(), colorEnabled
+	defer func() {
+		saved.restore()
+		colorEnabled = savedColor
+	}()
+	// Буфер ввода общий для меню и команд, читающих stdin (run - параметры из stdin)
+	reader := bufio.NewReader(in)
+	stdin, stdout, stderr, sessionCtx = reader, out, errOut, ctx
+	colorEnabled = colorSupported(out)
+
+	code := run(opts, reader)
+	if err := ctx.Err(); err != nil {
+		return err
 	}
-	defer logFile.Close()
+	if code != 0 {
+		return &ExitError{Code: code}
+	}
+	return nil
+}
 
-	// Настройка логгера для записи в файл
-	log.SetOutput(logFile)
+// Функция для проверки, отменен ли сеанс (контекст Run)
+func sessionCancelled() bool {
+	if err := sessionCtx.Err(); err != nil {
+		logToFileAndScreen(fmt.Sprintf("Сеанс прерван: %v", err))
+		return true
+	}
+	return false
+}
+
+// Функция для выполнения сеанса: подключение, загрузка структуры БД, затем команда
+// или интерактивное меню. Возвращает код завершения процесса.
+func run(opts Options, reader *bufio.Reader) int {
+	// Настройка журнала: файл, stdout или оба (LOG_TARGET)
+	setupLogOutput(opts.LogTarget, opts.LogFile)
+	if logFile != nil {
+		defer logFile.Close()
+	}
 
-	fmt.Println("=== Подключение к базе данных ===")
+	fmt.Fprintln(stdout, msg("connect.title"))
 
 	// Запрос учетных данных у пользователя
-	reader := bufio.NewReader(os.Stdin)
+	config := opts.DB
 
-	fmt.Print("Введите логин: ")
-	username, _ := reader.ReadString('\n')
-	username = strings.TrimSpace(username)
+	if config.User == "" {
+		fmt.Fprint(stdout, msg("connect.login"))
+		config.User, _ = readLine(reader)
+	}
 
-	fmt.Print("Введите пароль: ")
-	password, _ := reader.ReadString('\n')
-	password = strings.TrimSpace(password)
+	if config.Password == "" {
+		fmt.Fprint(stdout, msg("connect.password"))
+		config.Password, _ = readLine(reader)
+	}
 
-	// Чтение конфигурации из переменных окружения
-	config := DBConfig{
-		Host:     os.Getenv("DB_HOST"),
-		Port:     os.Getenv("DB_PORT"),
-		Name:     os.Getenv("DB_NAME"),
-		User:     username,
-		Password: password,
-		SSLMode:  os.Getenv("DB_SSLMODE"),
+	// Пароль никогда не должен попадать в лог
+	registerSecret(config.Password)
+
+	// Загрузка файла конфигурации
+	if err := loadAppConfig(); err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка чтения конфигурации: %v", err))
 	}
 
-	// Подключение к базе данных
-	connectionString := fmt.Sprintf("host=%s port=%s dbname=%s user=%s password=%s sslmode=%s",
-		config.Host, config.Port, config.Name, config.User, config.Password, config.SSLMode)
+	activeConfig = config
+	allowWriteSQL = opts.AllowWriteSQL
+	assumeYes = opts.AssumeYes
+	readOnly = opts.ReadOnly
+	allowLargeChanges = opts.AllowLargeChanges
+	allowDDL = opts.AllowDDL
+	if readOnly {
+		logToFileAndScreen("Включен режим только для чтения: изменение данных запрещено")
+		if allowWriteSQL {
+			logToFileAndScreen("[WARN] Режим только для чтения: разрешение изменений в режиме SQL-запросов (--allow-write) не действует")
+			allowWriteSQL = false
+		}
+		if allowDDL {
+			logToFileAndScreen("[WARN] Режим только для чтения: создание индексов (--allow-ddl) не действует")
+			allowDDL = false
+		}
+	}
 
+	// Выбор СУБД
+	var err error
+	dialect, err = dialectFor(config.Driver)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка конфигурации: %v", err))
+		return 1
+	}
+
+	// Подключение к базе данных
 	var connectErr error
-	db, connectErr = sql.Open("postgres", connectionString)
+	db, connectErr = sql.Open(dialect.DriverName(), connectionDSN(dialect, config))
 	if connectErr != nil {
 		logToFileAndScreen(fmt.Sprintf("Ошибка подключения к БД: %v", connectErr))
-		fmt.Println("Ошибка: Не удалось подключиться к базе данных. Проверьте учетные данные.")
-		os.Exit(1)
+		printError(msg("connect.open_failed"))
+		return 1
+	}
+	defer func() {
+		// db может быть заменено при восстановлении соединения, поэтому закрывается текущее
+		shutdownHTTPListeners()
+		db.Close()
+	}()
+
+	// Параметры пула соединений
+	settings := poolSettings()
+	applyPoolSettings(db, settings)
+	logToFileAndScreen(fmt.Sprintf("Пул соединений: максимум %d, простаивающих %d, время жизни %s",
+		settings.MaxOpen, settings.MaxIdle, settings.MaxLifetime))
+	logToFileAndScreen(fmt.Sprintf("Ограничение времени запроса на сервере: %s", describeStatementTimeout(config)))
+
+	// Ждем готовности СУБД (DB_WAIT_TIMEOUT)
+	if err := waitForDatabase(envDuration("DB_WAIT_TIMEOUT", 30*time.Second)); err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка: Не удалось подключиться к базе данных: %v", err))
+		printError(msg("connect.ping_failed"))
+		return 1
 	}
 
-	// Ждем запуска PostgreSQL
-	logToFileAndScreen("Ожидание запуска PostgreSQL...")
-	time.Sleep(5 * time.Second)
-
-	// Проверка подключения с повторными попытками
-	maxRetries := 3
-	for i := 0; i < maxRetries; i++ {
-		if err := db.Ping(); err != nil {
-			logToFileAndScreen(fmt.Sprintf("Попытка %d: Ошибка проверки подключения: %v", i+1, err))
-			if i < maxRetries-1 {
-				time.Sleep(2 * time.Second)
-				continue
-			}
-			logToFileAndScreen("Ошибка: Не удалось подключиться к базе данных")
-			fmt.Println("Ошибка: Не удалось подключиться к базе данных. Проверьте учетные данные и доступность БД.")
-			os.Exit(1)
-		}
-		break
+	// После восстановления соединения изменение данных повторяется только с подтверждения
+	confirmWriteRetry = func() bool {
+		return promptConfirm(reader, msg("connect.retry_write"))
+	}
+	// Если при восстановлении соединения сервер не принял пароль, он запрашивается снова
+	promptReconnectPassword = func() (string, bool) {
+		fmt.Fprint(stdout, msg("connect.password_again"))
+		password, err := readLine(reader)
+		return password, err == nil && password != ""
 	}
 
 	logToFileAndScreen("Успешное подключение к базе данных")
-	fmt.Println("✓ Подключение к базе данных успешно установлено")
+	fmt.Fprintln(stdout, msg("connect.ok"))
+
+	// Отладочный HTTP-сервер (только при заданном OSL_DEBUG_ADDR)
+	startDebugServer()
+
+	// Сервер метрик Prometheus (только при заданном METRICS_ADDR)
+	startMetricsServer()
+
+	// Проверка наличия основных таблиц (OSL_SKIP_SCHEMA_CHECK отключает)
+	checkBaseSchema(reader)
 
 	// Загрузка информации о таблицах
-	loadTableInfo()
+	loadSchema()
+
+	// Ежедневная сводка при первом запуске за день
+	runDailySummary()
 
-	// Определение связанных таблиц
-	relatedTables = []string{
-		"components и stock",
-		"categories и components",
-		"manufacturers и components",
+	// Запуск команды командной строки вместо меню (например, osl export --resume <токен>)
+	if len(opts.Args) > 0 {
+		return runCommand(opts.Args)
 	}
 
 	// Запуск главного меню
-	mainMenu(reader)
+	interactive = true
+	return mainMenu(reader)
+}
+
+// Функция для загрузки структуры БД: таблиц, схем, типов колонок, внешних ключей и правил
+func loadSchema() {
+	discoveryStart := time.Now()
+	loadTableInfo()
+	loadTableSchemas()
+	loadColumnTypes()
+	loadForeignKeys()
+	loadColumnDetails()
+	loadRelations()
+	applyColumnOrder()
+	compileRowRules()
+	prepareAuditTable()
+	recordSchemaDiscovery(time.Since(discoveryStart))
 }
 
 // Функция для загрузки информации о таблицах
@@ -138,51 +371,127 @@ func loadTableInfo() {
 	tables = []TableInfo{
 		{Name: "categories", Columns: []string{"id", "name", "description"}},
 		{Name: "manufacturers", Columns: []string{"id", "name", "country", "founded_year"}},
-		{Name: "components", Columns: []string{"id", "name", "category_id", "manufacturer_id", "model", "price"}},
-		{Name: "stock", Columns: []string{"id", "component_id", "quantity", "warehouse_location"}},
+		{Name: "components", Columns: []string{"id", "name", "category_id", "manufacturer_id", "model", "price"},
+			ForeignKeys: map[string]string{"category_id": "categories", "manufacturer_id": "manufacturers"}},
+		{Name: "stock", Columns: []string{"id", "component_id", "quantity", "warehouse_location"},
+			ForeignKeys: map[string]string{"component_id": "components"}},
+	}
+	for _, name := range appConfig.Tables {
+		if _, ok := findTable(name); ok {
+			continue
+		}
+		tables = append(tables, TableInfo{Name: name})
 	}
 }
 
 // Функция для логирования в файл и на экран
 func logToFileAndScreen(message string) {
+	message = redactSecrets(message)
 	timestamp := time.Now().Format("2006-01-02 15:04:05")
 	logMessage := fmt.Sprintf("[%s] %s", timestamp, message)
 	
 	// Запись в файл
 	log.Println(message)
 	
-	// Вывод на экран только если это не обычное сообщение
-	if strings.Contains(strings.ToLower(message), "ошибка") {
-		fmt.Println(logMessage)
+	// Вывод на экран только если это не обычное сообщение и журнал не выводится в stdout
+	if isErrorMessage(message) && !logToStdout {
+		printError(logMessage)
 	}
 }
 
-// Главное меню
-func mainMenu(reader *bufio.Reader) {
+// Главное меню. Возвращает код завершения: 1, если соединение с БД не удалось восстановить.
+func mainMenu(reader *bufio.Reader) int {
 	for {
-		fmt.Println("\n=== МЕНЮ ===")
-		fmt.Println("1. Просмотр таблицы")
-		fmt.Println("2. Фильтрация")
-		fmt.Println("3. Обновить запись")
-		fmt.Println("4. Добавить запись")
-		fmt.Println("5. Добавить запись в связанные таблицы")
-		fmt.Println("0. Выход")
-
-		fmt.Print("Выберите пункт меню: ")
-		input, _ := reader.ReadString('\n')
-		input = strings.TrimSpace(input)
+		if sessionCancelled() {
+			return 1
+		}
+		label := databaseLabel()
+		if readOnly {
+			label += msg("menu.readonly_tag")
+		}
+		fmt.Fprintln(stdout, msg("menu.title", label))
+		if dryRun {
+			fmt.Fprintln(stdout, msg("menu.dry_run_banner", dryRunTag))
+		}
+		fmt.Fprintln(stdout, msg("menu.view"))
+		fmt.Fprintln(stdout, msg("menu.filter"))
+		printMenuItem(3, "menu.update")
+		printMenuItem(4, "menu.insert")
+		printMenuItem(5, "menu.insert_related")
+		fmt.Fprintln(stdout, msg("menu.sort"))
+		fmt.Fprintln(stdout, msg("menu.reports"))
+		fmt.Fprintln(stdout, msg("menu.export"))
+		fmt.Fprintln(stdout, msg("menu.describe"))
+		fmt.Fprintln(stdout, msg("menu.history"))
+		fmt.Fprintln(stdout, msg("menu.sql"))
+		fmt.Fprintln(stdout, msg("menu.status"))
+		if dryRun {
+			fmt.Fprintln(stdout, msg("menu.dry_run_off"))
+		} else {
+			fmt.Fprintln(stdout, msg("menu.dry_run_on"))
+		}
+		printMenuItem(14, "menu.stock")
+		fmt.Fprintln(stdout, msg("menu.language"))
+		printMenuItem(16, "menu.import")
+		printMenuItem(17, "menu.undo")
+		fmt.Fprintln(stdout, msg("menu.search"))
+		printMenuItem(19, "menu.delete")
+		printMenuItem(20, "menu.restore")
+		printMenuItem(21, "menu.transfer")
+		fmt.Fprintln(stdout, msg("menu.dump"))
+		printMenuItem(23, "menu.restore_dump")
+		fmt.Fprintln(stdout, msg("menu.presets"))
+		fmt.Fprintln(stdout, msg("menu.switch_db"))
+		printMenuItem(26, "menu.generate")
+		printMenuItem(27, "menu.product")
+		fmt.Fprintln(stdout, msg("menu.show_log"))
+		fmt.Fprintln(stdout, msg("menu.lookup"))
+		fmt.Fprintln(stdout, msg("menu.reload"))
+		fmt.Fprintln(stdout, msg("menu.card"))
+		printMenuItem(32, "menu.indexes")
+		fmt.Fprintln(stdout, msg("menu.exit"))
+
+		fmt.Fprint(stdout, msg("menu.prompt"))
+		input, err := readLine(reader)
+		var tooLong *inputTooLongError
+		if errors.As(err, &tooLong) {
+			printError(msg("common.error", err))
+			continue
+		}
+		if err != nil {
+			// Конец ввода (Ctrl+D) в главном меню — выход из программы
+			input = "0"
+		}
+		if sessionCancelled() {
+			return 1
+		}
 
 		choice, err := strconv.Atoi(input)
 		if err != nil {
-			fmt.Println("Ошибка: введите цифру от 0 до 5")
+			printError(msg("menu.invalid", 32))
 			continue
 		}
+		if !menuItemAllowed(choice) {
+			rejectMenuItem(choice)
+			continue
+		}
+
+		// Перед операцией проверяем соединение: после восстановления работа продолжается с меню
+		if choice != 0 {
+			if ok, restored := ensureConnection(); !ok {
+				printError(msg("db.gave_up"))
+				logToFileAndScreen("Соединение с БД не восстановлено, завершение программы")
+				return 1
+			} else if restored {
+				fmt.Fprintln(stdout, msg("db.resume_menu"))
+				continue
+			}
+		}
 
 		switch choice {
 		case 0:
-			fmt.Println("Завершение программы...")
-			db.Close()
-			os.Exit(0)
+			fmt.Fprintln(stdout, msg("menu.bye"))
+			return 0
 		case 1:
 			viewTable(reader)
 		case 2:
@@ -193,685 +502,843 @@ func mainMenu(reader *bufio.Reader) {
 			insertData(reader)
 		case 5:
 			insertRelatedData(reader)
+		case 6:
+			sortSettings(reader)
+		case 7:
+			reportsMenu(reader)
+		case 8:
+			exportTable(reader)
+		case 9:
+			describeTable(reader)
+		case 10:
+			showHistory(reader)
+		case 11:
+			rawSQLMode(reader)
+		case 12:
+			printConnectionStatus()
+		case 13:
+			toggleDryRun()
+		case 14:
+			adjustStock(reader)
+		case 15:
+			chooseLanguage(reader)
+		case 16:
+			importTable(reader)
+		case 17:
+			undoLastOperation(reader)
+		case 18:
+			searchTable(reader)
+		case 19:
+			deleteRecords(reader)
+		case 20:
+			restoreRecord(reader)
+		case 21:
+			transferStock(reader)
+		case 22:
+			dumpTable(reader)
+		case 23:
+			restoreDump(reader)
+		case 24:
+			filterPresets(reader)
+		case 25:
+			switchDatabase(reader)
+		case 26:
+			generateTestData(reader)
+		case 27:
+			insertProduct(reader)
+		case 28:
+			showLog(reader)
+		case 29:
+			showRecordByKey(reader)
+		case 30:
+			reloadSchema()
+		case 31:
+			showRecordCard(reader)
+		case 32:
+			indexMaintenance(reader)
 		default:
-			fmt.Println("Ошибка: выберите цифру от 0 до 5")
+			printError(msg("menu.invalid", 32))
 		}
 	}
 }
 
-// Функция для выравнивания строк до заданной длины
+// Функция для выравнивания строк до заданной длины (по видимым символам, без кодов цвета)
 func padRight(str string, length int) string {
-	if len(str) >= length {
-		return str[:length]
+	width := visibleWidth(str)
+	if width >= length {
+		return ellipsizeVisible(str, length)
 	}
-	return str + strings.Repeat(" ", length-len(str))
+	return str + strings.Repeat(" ", length-width)
+}
+
+// Функция для выравнивания строки по правому краю
+func padLeft(str string, length int) string {
+	width := visibleWidth(str)
+	if width >= length {
+		return ellipsizeVisible(str, length)
+	}
+	return strings.Repeat(" ", length-width) + str
 }
 
 // Пункт 1: Просмотр таблицы
 func viewTable(reader *bufio.Reader) {
 	for {
-		fmt.Println("\n=== ВЫБОР ТАБЛИЦЫ ДЛЯ ПРОСМОТРА ===")
+		fmt.Fprintln(stdout, msg("select_table.view"))
 		for i, table := range tables {
-			fmt.Printf("%d. %s\n", i+1, table.Name)
+			fmt.Fprintf(stdout, "%d. %s\n", i+1, table.Name)
+		}
+		fmt.Fprintln(stdout, msg("common.back"))
+
+		choice, ok := promptInt(reader, msg("common.choose_table"), 0, len(tables))
+		if !ok || choice == 0 {
+			return
 		}
-		fmt.Println("0. Вернуться в меню")
 
-		fmt.Print("Выберите таблицу: ")
-		input, _ := reader.ReadString('\n')
-		input = strings.TrimSpace(input)
+		table := tables[choice-1]
+		tableName := table.Name
 
-		choice, err := strconv.Atoi(input)
-		if err != nil || choice < 0 || choice > len(tables) {
-			fmt.Println("Ошибка: выберите цифру от 0 до", len(tables))
-			continue
+		// Архивные записи скрыты, если пользователь не попросил их показать
+		where, condition := "", ""
+		if !promptIncludeArchived(reader, table, "view.include_archived") {
+			if condition = activeRowsCondition(table); condition != "" {
+				where = " WHERE " + condition
+			}
 		}
 
-		if choice == 0 {
+		// Сначала показываем количество записей, большие таблицы можно просматривать по страницам
+		var total int
+		paged := false
+		if err := dbScanRow(fmt.Sprintf("SELECT COUNT(*) FROM %s%s", table.SQLName(), where), nil, &total); err != nil {
+			logToFileAndScreen(fmt.Sprintf("Ошибка подсчета записей в %s: %v", tableName, err))
+		} else {
+			fmt.Fprintln(stdout, msg("view.count", total))
+			paged = total > envInt("LIST_WARN_ROWS", 1000) && !promptConfirm(reader, msg("view.confirm_all"))
+		}
+
+		// Выбор колонок для вывода
+		selectedColumns, ok := selectColumnSubset(reader, table)
+		if !ok {
+			continue
+		}
+		if !promptDisplayMode(reader) {
+			continue
+		}
+		if paged {
+			browsePages(reader, table, selectedColumns, condition, total)
 			return
 		}
 
-		tableName := tables[choice-1].Name
-		query := fmt.Sprintf("SELECT * FROM %s ORDER BY id", tableName)
+		query := viewQuery(table, selectedColumns, condition)
 		
 		logToFileAndScreen(fmt.Sprintf("Выполнение запроса: %s", query))
 		
-		rows, err := db.Query(query)
+		rows, err := dbQuery(query)
 		if err != nil {
 			logToFileAndScreen(fmt.Sprintf("Ошибка выполнения запроса: %v", err))
-			fmt.Println("Ошибка: Не удалось выполнить запрос к таблице")
+			printError(msg("view.query_failed"))
 			continue
 		}
-		defer rows.Close()
 
-		// Получение названий колонок
-		columns, err := rows.Columns()
-		if err != nil {
-			logToFileAndScreen(fmt.Sprintf("Ошибка получения колонок: %v", err))
-			continue
-		}
-
-		// Определяем максимальную ширину для каждой колонки
-		columnWidths := make([]int, len(columns))
-		for i, col := range columns {
-			if len(col) > columnWidths[i] {
-				columnWidths[i] = len(col)
-			}
-		}
-
-		// Считываем данные для определения ширины
-		values := make([]interface{}, len(columns))
-		valuePtrs := make([]interface{}, len(columns))
-		allRows := [][]string{}
-		
-		for rows.Next() {
-			for i := range values {
-				valuePtrs[i] = &values[i]
-			}
-			
-			if err := rows.Scan(valuePtrs...); err != nil {
-				logToFileAndScreen(fmt.Sprintf("Ошибка чтения строки: %v", err))
-				continue
-			}
-
-			rowData := make([]string, len(columns))
-			for i, val := range values {
-				str := ""
-				if val != nil {
-					str = fmt.Sprintf("%v", val)
-				}
-				rowData[i] = str
-				if len(str) > columnWidths[i] {
-					columnWidths[i] = len(str)
-				}
-			}
-			allRows = append(allRows, rowData)
+		// Большие таблицы выводятся потоком, без накопления строк в памяти
+		if total > streamThreshold() {
+			printStreamed(rows, fmt.Sprintf("Просмотр таблицы %s", tableName))
+			offerExplain(reader, query, nil, nil)
+			return
 		}
 
-		// Если нужно переоткрыть курсор
+		rs, err := scanRows(rows)
 		rows.Close()
-		rows, _ = db.Query(query)
-		defer rows.Close()
-
-		// Вывод заголовков с выравниванием
-		headerParts := make([]string, len(columns))
-		for i, col := range columns {
-			headerParts[i] = padRight(col, columnWidths[i])
+		if err != nil {
+			logToFileAndScreen(fmt.Sprintf("Ошибка чтения результата: %v", err))
+			continue
 		}
-		fmt.Println("\n" + strings.Join(headerParts, " | "))
 
-		// Вывод разделительной линии
-		dividerParts := make([]string, len(columns))
-		for i, width := range columnWidths {
-			dividerParts[i] = strings.Repeat("-", width)
-		}
-		fmt.Println(strings.Join(dividerParts, "-+-"))
+		printResult(rs)
+		rowCount := len(rs.Rows)
 
-		// Вывод данных с выравниванием
-		rowCount := 0
-		for _, rowData := range allRows {
-			rowParts := make([]string, len(rowData))
-			for i, cell := range rowData {
-				rowParts[i] = padRight(cell, columnWidths[i])
-			}
-			fmt.Println(strings.Join(rowParts, " | "))
-			rowCount++
-		}
-
-		fmt.Printf("\nНайдено записей: %d\n", rowCount)
+		printFoundRows(rowCount)
 		logToFileAndScreen(fmt.Sprintf("Просмотр таблицы %s: найдено %d записей", tableName, rowCount))
+		offerRawDetails(reader, rs)
+		offerExplain(reader, query, nil, nil)
 		
 		// Возвращаемся в главное меню после успешного выполнения
 		return
 	}
 }
 
+// Функция для построения запроса просмотра таблицы; condition — условие отбора
+// (например, скрытие архивных записей), пусто — все записи
+func viewQuery(table TableInfo, columns []string, condition string) string {
+	where := ""
+	if condition != "" {
+		where = " WHERE " + condition
+	}
+	return fmt.Sprintf("SELECT %s FROM %s%s %s", columnList(columns), table.SQLName(), where, orderByClause(table))
+}
+
 // Пункт 2: Фильтрация
 func filterData(reader *bufio.Reader) {
-	fmt.Print("\nВведите количество фильтров (минимум 1): ")
-	input, _ := reader.ReadString('\n')
-	input = strings.TrimSpace(input)
-
-	filterCount, err := strconv.Atoi(input)
-	if err != nil || filterCount < 1 {
-		fmt.Println("Ошибка: введите число больше 0")
+	filterCount, ok := promptInt(reader, msg("filter.count"), 1, maxPromptInt)
+	if !ok {
 		return
 	}
 
 	// Выбор таблицы
-	tableIndex := selectTable(reader, "ВЫБОР ТАБЛИЦЫ ДЛЯ ФИЛЬТРАЦИИ")
+	tableIndex := selectTable(reader, msg("select_table.filter"))
 	if tableIndex == -1 {
 		return
 	}
 
 	table := tables[tableIndex]
-	var conditions []string
-	var values []interface{}
-
-	for i := 0; i < filterCount; i++ {
-		fmt.Printf("\n=== Фильтр %d из %d ===\n", i+1, filterCount)
-		
-		// Выбор колонки
-		columnIndex := selectColumn(reader, table)
-		if columnIndex == -1 {
-			return
-		}
-
-		columnName := table.Columns[columnIndex]
+	spec := FilterSpec{Table: table.Name}
+	spec.Conditions, spec.Operator, ok = promptFilterConditions(reader, table, filterCount)
+	if !ok {
+		return
+	}
+	spec.IncludeArchived = promptIncludeArchived(reader, table, "filter.include_archived")
 
-		// Ввод значения для фильтрации
-		fmt.Printf("Введите значение для фильтрации по '%s': ", columnName)
-		value, _ := reader.ReadString('\n')
-		value = strings.TrimSpace(value)
+	// Выбор колонок для вывода результата
+	spec.Columns, ok = selectColumnSubset(reader, table)
+	if !ok {
+		return
+	}
+	if !promptDisplayMode(reader) {
+		return
+	}
 
-		// Проверка white list
-		if !whiteListRegex.MatchString(value) {
-			fmt.Println("Ошибка: значение содержит недопустимые символы")
-			return
-		}
+	executeFilter(reader, spec)
+	offerSavePreset(reader, spec)
+}
 
-		conditions = append(conditions, fmt.Sprintf("%s = $%d", columnName, i+1))
-		values = append(values, value)
+// Функция для выполнения фильтрации по описанию и вывода результата
+func executeFilter(reader *bufio.Reader, spec FilterSpec) {
+	table, ok := findTable(spec.Table)
+	if !ok {
+		printError(msg("common.table_not_found", spec.Table))
+		return
 	}
 
 	// Формирование и выполнение запроса
-	query := fmt.Sprintf("SELECT * FROM %s WHERE %s ORDER BY id", 
-		table.Name, strings.Join(conditions, " AND "))
+	query, values, valueColumns := buildFilterQuery(table, spec)
 	
-	logToFileAndScreen(fmt.Sprintf("Выполнение фильтрации: %s с параметрами %v", query, values))
+	// Предварительный подсчет определяет, выводить ли результат потоком
+	where, _, _ := filterWhereClause(table, spec)
+	var total int
+	if err := dbScanRow(fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", table.SQLName(), where), values, &total); err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка подсчета записей фильтрации: %v", err))
+	}
+
+	logToFileAndScreen(fmt.Sprintf("Выполнение фильтрации: %s с параметрами %v", query, maskParams(valueColumns, values)))
 	
-	rows, err := db.Query(query, values...)
+	rows, err := dbQuery(query, values...)
 	if err != nil {
 		logToFileAndScreen(fmt.Sprintf("Ошибка выполнения фильтрации: %v", err))
-		fmt.Println("Ошибка: Не удалось выполнить фильтрацию")
+		printError(msg("filter.failed"))
 		return
 	}
-	defer rows.Close()
 
-	// Вывод результатов
-	columns, _ := rows.Columns()
-	
-	// Определяем ширину колонок
-	columnWidths := make([]int, len(columns))
-	for i, col := range columns {
-		if len(col) > columnWidths[i] {
-			columnWidths[i] = len(col)
-		}
+	if total > streamThreshold() {
+		recordHistory(HistoryEntry{Kind: historyFilter, Filter: &spec})
+		printStreamed(rows, fmt.Sprintf("Фильтрация таблицы %s", table.Name))
+		offerExplain(reader, query, values, valueColumns)
+		return
 	}
 
-	allRows := [][]string{}
-	for rows.Next() {
-		values := make([]interface{}, len(columns))
-		valuePtrs := make([]interface{}, len(columns))
-		for i := range values {
-			valuePtrs[i] = &values[i]
-		}
-
-		if err := rows.Scan(valuePtrs...); err != nil {
-			continue
-		}
-
-		rowData := make([]string, len(columns))
-		for i, val := range values {
-			str := ""
-			if val != nil {
-				str = fmt.Sprintf("%v", val)
-			}
-			rowData[i] = str
-			if len(str) > columnWidths[i] {
-				columnWidths[i] = len(str)
-			}
-		}
-		allRows = append(allRows, rowData)
+	rs, err := scanRows(rows)
+	rows.Close()
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка чтения результата: %v", err))
+		return
 	}
+	recordHistory(HistoryEntry{Kind: historyFilter, Filter: &spec})
 
-	if len(allRows) == 0 {
-		fmt.Println("По заданным фильтрам записей не найдено")
+	if len(rs.Rows) == 0 {
+		fmt.Fprintln(stdout, msg("filter.none"))
 		logToFileAndScreen("Фильтрация: записей не найдено")
+		offerExplain(reader, query, values, valueColumns)
 		return
 	}
 
-	// Вывод заголовков
-	headerParts := make([]string, len(columns))
-	for i, col := range columns {
-		headerParts[i] = padRight(col, columnWidths[i])
-	}
-	fmt.Println("\n" + strings.Join(headerParts, " | "))
+	printResult(rs)
 
-	// Вывод разделительной линии
-	dividerParts := make([]string, len(columns))
-	for i, width := range columnWidths {
-		dividerParts[i] = strings.Repeat("-", width)
-	}
-	fmt.Println(strings.Join(dividerParts, "-+-"))
+	printFoundRows(len(rs.Rows))
+	logToFileAndScreen(fmt.Sprintf("Фильтрация таблицы %s: найдено %d записей", table.Name, len(rs.Rows)))
+	offerRawDetails(reader, rs)
+	offerExplain(reader, query, values, valueColumns)
+}
 
-	// Вывод данных
-	for _, rowData := range allRows {
-		rowParts := make([]string, len(rowData))
-		for i, cell := range rowData {
-			rowParts[i] = padRight(cell, columnWidths[i])
-		}
-		fmt.Println(strings.Join(rowParts, " | "))
+// Функция для потокового вывода результата с итоговым количеством записей
+func printStreamed(rows *sql.Rows, operation string) {
+	defer rows.Close()
+	fmt.Fprintln(stdout, msg("stream.notice"))
+	count, err := streamResult(rows)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка чтения результата: %v", err))
 	}
-
-	fmt.Printf("\nНайдено записей: %d\n", len(allRows))
-	logToFileAndScreen(fmt.Sprintf("Фильтрация таблицы %s: найдено %d записей", table.Name, len(allRows)))
+	printFoundRows(count)
+	logToFileAndScreen(fmt.Sprintf("%s: найдено %d записей (потоковый вывод)", operation, count))
 }
 
 // Пункт 3: Обновление данных
 func updateData(reader *bufio.Reader) {
-	fmt.Print("\nВведите количество данных для обновления (минимум 1): ")
-	input, _ := reader.ReadString('\n')
-	input = strings.TrimSpace(input)
+	// Записи выбираются по ID или по условию, как в фильтрации
+	fmt.Fprintln(stdout, msg("update.mode_title"))
+	fmt.Fprintln(stdout, msg("update.mode_ids"))
+	fmt.Fprintln(stdout, msg("update.mode_conditions"))
+	fmt.Fprintln(stdout, msg("common.back"))
+	mode, ok := promptInt(reader, msg("update.mode_prompt"), 0, 2)
+	if !ok || mode == 0 {
+		return
+	}
+	byCondition := mode == 2
 
-	updateCount, err := strconv.Atoi(input)
-	if err != nil || updateCount < 1 {
-		fmt.Println("Ошибка: введите число больше 0")
+	countPrompt := msg("update.count_ids")
+	if byCondition {
+		countPrompt = msg("update.count_conditions")
+	}
+	updateCount, ok := promptInt(reader, countPrompt, 1, maxPromptInt)
+	if !ok {
 		return
 	}
 
 	// Выбор таблицы
-	tableIndex := selectTable(reader, "ВЫБОР ТАБЛИЦЫ ДЛЯ ОБНОВЛЕНИЯ")
+	tableIndex := selectTable(reader, msg("select_table.update"))
 	if tableIndex == -1 {
 		return
 	}
 
 	table := tables[tableIndex]
 
-	// Создаем список колонок без id (id нельзя обновлять!)
+	// Без ключа нельзя ни найти обновленные записи для отмены, ни записать их в аудит
+	key := tableKeyColumn(table.Name)
+	if key == "" {
+		printError(msg("common.no_key", table.Name))
+		return
+	}
+
+	// Создаем список колонок без первичного ключа (ключ нельзя обновлять!)
 	updatableColumns := make([]string, 0)
-	for _, column := range table.Columns {
-		if column != "id" {
+	for _, column := range editableColumns(table, table.Columns) {
+		if !containsString(primaryKeyColumns(table), column) {
 			updatableColumns = append(updatableColumns, column)
 		}
 	}
 
 	if len(updatableColumns) == 0 {
-		fmt.Println("В таблице нет колонок для обновления")
+		fmt.Fprintln(stdout, msg("update.no_columns"))
 		return
 	}
 
-	// Ввод ID для обновления
+	spec := UpdateSpec{Table: table.Name}
+	if byCondition {
+		spec.Conditions, spec.Operator, ok = promptFilterConditions(reader, table, updateCount)
+		if !ok {
+			return
+		}
+	}
+
+	// Ввод ID для обновления: значение проверяется по типу колонки ключа, повторы не принимаются
 	var ids []string
-	for i := 0; i < updateCount; i++ {
-		fmt.Printf("Введите ID записи %d для обновления: ", i+1)
-		idInput, _ := reader.ReadString('\n')
-		idInput = strings.TrimSpace(idInput)
-		
-		if _, err := strconv.Atoi(idInput); err != nil {
-			fmt.Println("Ошибка: ID должен быть числом")
+	for i := 0; i < updateCount && !byCondition; i++ {
+		idInput, ok := promptValidated(reader, msg("update.id_prompt", i+1),
+			func(input string) error {
+				if err := validateTableValue(table, key, input); err != nil {
+					return err
+				}
+				if containsString(ids, input) {
+					return errors.New(msg("update.id_duplicate"))
+				}
+				return nil
+			})
+		if !ok {
 			return
 		}
 		ids = append(ids, idInput)
 	}
 
-	// Выбор колонки для обновления (исключая id)
-	fmt.Printf("\n=== ВЫБОР КОЛОНКИ ДЛЯ ОБНОВЛЕНИЯ В '%s' ===\n", table.Name)
-	for i, column := range updatableColumns {
-		fmt.Printf("%d. %s\n", i+1, column)
+	// Проверка существования записей до ввода нового значения
+	if !byCondition {
+		ids, ok = confirmExistingIDs(reader, table.Name, ids)
+		if !ok {
+			return
+		}
+		spec.IDs = ids
 	}
-	fmt.Println("0. Вернуться в меню")
-
-	fmt.Print("Выберите колонку для обновления: ")
-	columnInput, _ := reader.ReadString('\n')
-	columnInput = strings.TrimSpace(columnInput)
 
-	columnChoice, err := strconv.Atoi(columnInput)
-	if err != nil || columnChoice < 0 || columnChoice > len(updatableColumns) {
-		fmt.Println("Ошибка: выберите цифру от 0 до", len(updatableColumns))
-		return
+	// Выбор колонки для обновления (исключая ключ)
+	fmt.Fprintln(stdout, msg("update.column_title", table.Name))
+	for i, column := range updatableColumns {
+		fmt.Fprintf(stdout, "%d. %s\n", i+1, column)
 	}
+	fmt.Fprintln(stdout, msg("common.back"))
 
-	if columnChoice == 0 {
+	columnChoice, ok := promptInt(reader, msg("update.column_prompt"), 0, len(updatableColumns))
+	if !ok || columnChoice == 0 {
 		return
 	}
 
 	columnName := updatableColumns[columnChoice-1]
 
-	// Ввод нового значения
-	fmt.Printf("Введите новое значение для '%s' в таблице '%s': ", columnName, table.Name)
-	newValue, _ := reader.ReadString('\n')
-	newValue = strings.TrimSpace(newValue)
+	var newValue string
+	if refTable := foreignKeyTarget(table, columnName); refTable != "" {
+		// Для внешнего ключа значение выбирается из связанной таблицы
+		id, ok := pickForeignKey(reader, refTable)
+		if !ok {
+			return
+		}
+		newValue = id
+	} else {
+		// Ввод нового значения с проверкой допустимых символов, формата и ограничений колонки
+		prompt := msg("update.value_prompt", columnName, table.Name)
+		if table.Name == "stock" && columnName == "quantity" {
+			// Пересчет упаковок возможен, только когда известен единственный компонент
+			componentID := ""
+			if len(ids) == 1 {
+				componentID = stockRowComponentID(ids[0])
+			}
+			newValue, ok = promptQuantity(reader, prompt, componentID)
+		} else {
+			newValue, ok = promptColumnValue(reader, prompt, table, columnName)
+		}
+		if !ok {
+			return
+		}
+	}
+
+	spec.Column, spec.Value = columnName, newValue
+	executeUpdate(reader, spec)
+}
+
+// Функция для проверки существования записей перед обновлением.
+// Если часть записей не найдена, пользователь решает, продолжать ли с найденными.
+func confirmExistingIDs(reader *bufio.Reader, tableName string, ids []string) ([]string, bool) {
+	existing, err := existingIDs(tableName, ids)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка проверки существования записей: %v", err))
+		printError(msg("update.check_failed"))
+		return nil, false
+	}
+	if len(existing) == 0 {
+		fmt.Fprintln(stdout, msg("update.ids_not_found", tableName))
+		return nil, false
+	}
+	if len(existing) < len(ids) {
+		fmt.Fprintln(stdout, msg("update.missing_ids", strings.Join(missingIDs(ids, existing), ", ")))
+		if !promptConfirm(reader, msg("update.confirm_partial", len(existing))) {
+			fmt.Fprintln(stdout, msg("update.cancelled"))
+			return nil, false
+		}
+	}
+	return existing, true
+}
 
-	// Проверка white list
-	if !whiteListRegex.MatchString(newValue) {
-		fmt.Println("Ошибка: значение содержит недопустимые символы")
+// Функция для выполнения обновления по описанию с проверкой правил
+func executeUpdate(reader *bufio.Reader, spec UpdateSpec) {
+	table, ok := findTable(spec.Table)
+	if !ok {
+		printError(msg("common.table_not_found", spec.Table))
+		return
+	}
+	key := tableKeyColumn(table.Name)
+	if key == "" {
+		printError(msg("common.no_key", table.Name))
 		return
 	}
 
-	// Проверка для числовых полей
-	if columnName == "price" || columnName == "quantity" || columnName == "founded_year" || 
-	   columnName == "category_id" || columnName == "manufacturer_id" || columnName == "component_id" {
-		if _, err := strconv.Atoi(newValue); err != nil {
-			fmt.Printf("Ошибка: поле '%s' должно быть числом\n", columnName)
+	// При обновлении по условию сначала показываем, сколько записей будет затронуто
+	ids := spec.IDs
+	if len(spec.Conditions) > 0 {
+		where, whereArgs, _ := buildWhereClause(table, spec.Conditions, spec.Operator, 1)
+		var err error
+		ids, err = matchingIDs(spec.Table, where, whereArgs)
+		if err != nil {
+			logToFileAndScreen(fmt.Sprintf("Ошибка поиска записей для обновления: %v", err))
+			printError(msg("update.find_failed"))
+			return
+		}
+		if len(ids) == 0 {
+			fmt.Fprintln(stdout, msg("update.none_matched"))
 			return
 		}
 	}
 
-	// Формирование и выполнение запроса
-	var query string
-	var args []interface{}
-	
-	if updateCount == 1 {
-		query = fmt.Sprintf("UPDATE %s SET %s = $1 WHERE id = $2", table.Name, columnName)
-		args = []interface{}{newValue, ids[0]}
-	} else {
-		placeholders := make([]string, len(ids))
-		args = []interface{}{newValue}
-		for i, id := range ids {
-			placeholders[i] = fmt.Sprintf("$%d", i+2)
-			args = append(args, id)
-		}
-		query = fmt.Sprintf("UPDATE %s SET %s = $1 WHERE id IN (%s)", 
-			table.Name, columnName, strings.Join(placeholders, ", "))
+	// Проверка правил из конфигурации для строк с новым значением
+	ruleRows, err := updatedRuleRows(spec.Table, ids, spec.Column, spec.Value)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка чтения записей для проверки правил: %v", err))
+		printError(msg("update.rules_failed"))
+		return
+	}
+	if !confirmRowRules(reader, spec.Table, "update", ruleRows) {
+		return
 	}
 
-	logToFileAndScreen(fmt.Sprintf("Выполнение обновления: %s с параметрами %v", query, args))
-	
-	result, err := db.Exec(query, args...)
+	// Проверка подозрительно больших изменений (например, цены)
+	warnings, err := checkChangeRules(spec.Table, spec.Column, ids, spec.Value)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка проверки текущих значений: %v", err))
+	}
+	if !confirmChangeWarnings(reader, warnings) {
+		logToFileAndScreen(fmt.Sprintf("Обновление %s.%s отменено пользователем после предупреждения", spec.Table, spec.Column))
+		fmt.Fprintln(stdout, msg("update.cancelled"))
+		return
+	}
+
+	// Сводка изменения и подтверждение перед выполнением
+	if !confirmSummary(reader, updateSummary(spec, len(ids))) {
+		fmt.Fprintln(stdout, msg("update.cancelled"))
+		return
+	}
+
+	// Формирование и выполнение запроса
+	query, args, argColumns := updateQuery(table, key, spec)
+	if dryRun {
+		printDryRun(query, argColumns, args)
+		printDryRunPreview(spec.Table, ids)
+		return
+	}
+	undo, rowsAffected, err := applyUpdate(table, key, spec, ids)
 	if err != nil {
 		logToFileAndScreen(fmt.Sprintf("Ошибка обновления: %v", err))
-		fmt.Println("Ошибка: Не удалось обновить данные")
+		printError(msg("update.failed"))
 		return
 	}
+	recordHistory(HistoryEntry{Kind: historyUpdate, Update: &spec})
+	rememberUndo(msg("undo.kind_update", spec.Table, spec.Column), []undoStep{undo})
 
-	rowsAffected, _ := result.RowsAffected()
-	fmt.Printf("Обновлено записей: %d\n", rowsAffected)
-	logToFileAndScreen(fmt.Sprintf("Обновление таблица %s: обновлено %d записей", table.Name, rowsAffected))
+	fmt.Fprintln(stdout, msg("update.done", rowsAffected))
+	if rowsAffected < int64(len(ids)) {
+		fmt.Fprintln(stdout, msg("update.partial", len(ids), rowsAffected))
+	}
+	logToFileAndScreen(fmt.Sprintf("Обновление таблица %s: обновлено %d записей", spec.Table, rowsAffected))
+}
+
+// Функция для построения запроса обновления по ключам или по условиям.
+// Возвращает запрос, параметры и колонки параметров (для маскирования в журнале).
+func updateQuery(table TableInfo, key string, spec UpdateSpec) (string, []interface{}, []string) {
+	column := dialect.QuoteIdent(spec.Column)
+	if len(spec.Conditions) > 0 {
+		// $1 — новое значение, условия нумеруются с $2
+		where, whereArgs, whereColumns := buildWhereClause(table, spec.Conditions, spec.Operator, 2)
+		query := fmt.Sprintf("UPDATE %s SET %s = $1 WHERE %s", table.SQLName(), column, where)
+		return query, append([]interface{}{spec.Value}, whereArgs...), append([]string{spec.Column}, whereColumns...)
+	}
+	if len(spec.IDs) == 1 {
+		query := fmt.Sprintf("UPDATE %s SET %s = $1 WHERE %s = $2", table.SQLName(), column, dialect.QuoteIdent(key))
+		return query, []interface{}{spec.Value, spec.IDs[0]}, []string{spec.Column}
+	}
+	where, whereArgs := inListCondition(key, spec.IDs, 2)
+	query := fmt.Sprintf("UPDATE %s SET %s = $1 WHERE %s", table.SQLName(), column, where)
+	return query, append([]interface{}{spec.Value}, whereArgs...), []string{spec.Column}
+}
+
+// Функция для выполнения обновления одной транзакцией: прежние значения записей ids
+// запоминаются для отмены и записываются в журнал аудита вместе с новыми.
+// Возвращает шаг отмены и количество обновленных записей.
+func applyUpdate(table TableInfo, key string, spec UpdateSpec, ids []string) (undoStep, int64, error) {
+	query, args, argColumns := updateQuery(table, key, spec)
+	logToFileAndScreen(fmt.Sprintf("Выполнение обновления: %s с параметрами %v", query, maskParams(argColumns, args)))
+
+	var undo undoStep
+	var rowsAffected int64
+	err := dbTransaction(func(tx *sql.Tx) error {
+		where, whereArgs := inListCondition(key, ids, 1)
+		if len(spec.Conditions) > 0 {
+			where, whereArgs, _ = buildWhereClause(table, spec.Conditions, spec.Operator, 1)
+		}
+		var err error
+		undo, err = captureUndoValues(tx, spec.Table, spec.Column, where, whereArgs)
+		if err != nil {
+			return err
+		}
+		result, err := txExec(tx, query, args...)
+		if err != nil {
+			return err
+		}
+		rowsAffected, _ = result.RowsAffected()
+		changes, err := columnChangesAudit(tx, undo)
+		if err != nil {
+			return err
+		}
+		return writeAudit(tx, auditUpdate, spec.Table, changes)
+	})
+	return undo, rowsAffected, err
 }
 
 // Пункт 4: Добавление записи
 func insertData(reader *bufio.Reader) {
-	fmt.Print("\nВведите количество создаваемых записей (минимум 1): ")
-	input, _ := reader.ReadString('\n')
-	input = strings.TrimSpace(input)
-
-	recordCount, err := strconv.Atoi(input)
-	if err != nil || recordCount < 1 {
-		fmt.Println("Ошибка: введите число больше 0")
+	recordCount, ok := promptInt(reader, msg("insert.count"), 1, maxPromptInt)
+	if !ok {
 		return
 	}
 
 	// Выбор таблицы
-	tableIndex := selectTable(reader, "ВЫБОР ТАБЛИЦЫ ДЛЯ ДОБАВЛЕНИЯ")
+	tableIndex := selectTable(reader, msg("select_table.insert"))
 	if tableIndex == -1 {
 		return
 	}
 
 	table := tables[tableIndex]
 
-	// Исключаем колонку id
-	insertColumns := table.Columns[1:]
+	// Исключаем колонки, которые заполняет СУБД (автоматический ключ)
+	insertColumns := editableColumns(table, insertableColumns(table))
 
+	// Сначала вводятся и проверяются все записи, затем они добавляются одной транзакцией.
+	// Колонки, для которых в первой записи выбрано значение по умолчанию, не указываются в INSERT
+	// и для остальных записей не запрашиваются.
+	var records [][]string
 	for i := 0; i < recordCount; i++ {
-		fmt.Printf("\n=== Ввод данных для записи %d из %d ===\n", i+1, recordCount)
+		fmt.Fprintln(stdout, msg("insert.record_title", i+1, recordCount))
 		
 		var values []interface{}
+		var recordColumns []string
 		for _, column := range insertColumns {
-			fmt.Printf("Введите значение для '%s': ", column)
-			value, _ := reader.ReadString('\n')
-			value = strings.TrimSpace(value)
+			if refTable := foreignKeyTarget(table, column); refTable != "" {
+				fmt.Fprintln(stdout, msg("insert.pick_value", column))
+				id, ok := pickForeignKey(reader, refTable)
+				if !ok {
+					return
+				}
+				recordColumns = append(recordColumns, column)
+				values = append(values, id)
+				continue
+			}
 
-			// Проверка white list
-			if !whiteListRegex.MatchString(value) {
-				fmt.Println("Ошибка: значение содержит недопустимые символы")
+			// Ввод значения с проверкой white list и числовых полей
+			var value string
+			var useDefault bool
+			if i == 0 {
+				value, useDefault, ok = promptInsertValue(reader, table, column, recordColumns, values)
+			} else {
+				value, ok = promptRecordValue(reader, table, column, recordColumns, values)
+			}
+			if !ok {
 				return
 			}
-			
-			// Проверка для числовых полей
-			if column == "price" || column == "quantity" || column == "founded_year" || 
-			   column == "category_id" || column == "manufacturer_id" || column == "component_id" {
-				if _, err := strconv.Atoi(value); err != nil {
-					fmt.Printf("Ошибка: поле '%s' должно быть числом\n", column)
-					return
-				}
+			if useDefault {
+				fmt.Fprintln(stdout, msg("insert.default_used", column))
+				continue
 			}
 			
+			recordColumns = append(recordColumns, column)
 			values = append(values, value)
 		}
+		if i == 0 {
+			if !requireInsertColumns(table, recordColumns) {
+				return
+			}
+			insertColumns = recordColumns
+		}
 
-		// Формирование запроса
-		placeholders := make([]string, len(insertColumns))
-		for j := range placeholders {
-			placeholders[j] = fmt.Sprintf("$%d", j+1)
+		record := make([]string, len(values))
+		for j, value := range values {
+			record[j] = value.(string)
 		}
+		records = append(records, record)
+	}
 
-		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
-			table.Name,
-			strings.Join(insertColumns, ", "),
-			strings.Join(placeholders, ", "))
+	executeInsert(reader, InsertSpec{Table: table.Name, Columns: insertColumns, Records: records})
+}
 
-		logToFileAndScreen(fmt.Sprintf("Выполнение вставки: %s с параметрами %v", query, values))
-		
-		_, err := db.Exec(query, values...)
-		if err != nil {
-			logToFileAndScreen(fmt.Sprintf("Ошибка вставки: %v", err))
-			fmt.Println("Ошибка: Не удалось добавить запись")
-			return
+// Функция для добавления записей по описанию одной транзакцией с проверкой правил
+func executeInsert(reader *bufio.Reader, spec InsertSpec) {
+	// Проверка похожих записей, уже существующих в таблице
+	if table, ok := findTable(spec.Table); ok {
+		for _, record := range spec.Records {
+			if action, _ := confirmDuplicates(reader, table, spec.Columns, stringArgs(record), false); action == duplicateCancel {
+				fmt.Fprintln(stdout, msg("insert.cancelled"))
+				return
+			}
 		}
+	}
 
-		fmt.Printf("Запись %d успешно добавлена\n", i+1)
-		logToFileAndScreen(fmt.Sprintf("Добавлена запись в таблицу %s", table.Name))
+	// Проверка правил из конфигурации до выполнения вставки
+	ruleRows := make([]ruleRow, len(spec.Records))
+	for i, record := range spec.Records {
+		ruleRows[i] = newRuleRow(spec.Columns, stringArgs(record))
+	}
+	if !confirmRowRules(reader, spec.Table, "insert", ruleRows) {
+		return
 	}
-	
-	fmt.Printf("\nВсего добавлено записей: %d\n", recordCount)
-}
 
-// Пункт 5: Добавление записи в связанные таблицы
-func insertRelatedData(reader *bufio.Reader) {
-	fmt.Print("\nВведите количество создаваемых записей (минимум 1): ")
-	input, _ := reader.ReadString('\n')
-	input = strings.TrimSpace(input)
+	// Сводка добавляемых записей и подтверждение перед выполнением
+	if !confirmSummary(reader, insertSummary(spec.Table, spec.Columns, spec.Records)) {
+		fmt.Fprintln(stdout, msg("insert.cancelled"))
+		return
+	}
 
-	recordCount, err := strconv.Atoi(input)
-	if err != nil || recordCount < 1 {
-		fmt.Println("Ошибка: введите число больше 0")
+	if dryRun {
+		printDryRunInsert(spec.Table, spec.Columns, spec.Records)
 		return
 	}
 
-	// Выбор связанных таблиц
-	fmt.Println("\n=== ВЫБОР СВЯЗАННЫХ ТАБЛИЦ ===")
-	for i, relation := range relatedTables {
-		fmt.Printf("%d. %s\n", i+1, relation)
+	// Все записи добавляются одним многострочным INSERT (или COPY) в транзакции
+	start := time.Now()
+	var ids []string
+	err := dbTransaction(func(tx *sql.Tx) error {
+		var err error
+		ids, err = insertRecords(tx, spec.Table, spec.Columns, spec.Records)
+		return err
+	})
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка вставки в таблицу %s, изменения отменены: %v", spec.Table, err))
+		printError(msg("insert.failed"))
+		return
+	}
+	elapsed := time.Since(start)
+	recordHistory(HistoryEntry{Kind: historyInsert, Insert: &spec})
+	if len(ids) == len(spec.Records) {
+		rememberUndo(msg("undo.kind_insert", spec.Table), []undoStep{{Table: spec.Table, IDs: ids}})
+	} else {
+		forgetUndo(fmt.Sprintf("не удалось получить id записей, добавленных в %s", spec.Table))
 	}
-	fmt.Println("0. Вернуться в меню")
 
-	fmt.Print("Выберите связанные таблицы: ")
-	choiceInput, _ := reader.ReadString('\n')
-	choiceInput = strings.TrimSpace(choiceInput)
+	logToFileAndScreen(fmt.Sprintf("Добавлено %d записей в таблицу %s за %s", len(spec.Records), spec.Table, elapsed))
+	fmt.Fprintln(stdout, msg("insert.done", len(spec.Records), elapsed.Round(time.Millisecond)))
+}
 
-	choice, err := strconv.Atoi(choiceInput)
-	if err != nil || choice < 0 || choice > len(relatedTables) {
-		fmt.Println("Ошибка: выберите цифру от 0 до", len(relatedTables))
-		return
+// Функция для преобразования строковых значений в параметры запроса
+func stringArgs(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value
 	}
+	return args
+}
 
-	if choice == 0 {
+// Пункт 5: Добавление записи в связанные таблицы
+func insertRelatedData(reader *bufio.Reader) {
+	recordCount, ok := promptInt(reader, msg("insert.count"), 1, maxPromptInt)
+	if !ok {
 		return
 	}
 
-	// Обработка выбранных связанных таблиц
-	relation := relatedTables[choice-1]
-	tablesInRelation := strings.Split(relation, " и ")
+	// Выбор связанных таблиц
+	if len(relatedTables) == 0 {
+		printError(msg("related.none"))
+		return
+	}
+	fmt.Fprintln(stdout, msg("related.title"))
+	for i, relation := range relatedTables {
+		fmt.Fprintf(stdout, "%d. %s\n", i+1, relation)
+	}
+	fmt.Fprintln(stdout, msg("common.back"))
 
-	if len(tablesInRelation) != 2 {
-		fmt.Println("Ошибка: некорректный формат связанных таблиц")
+	choice, ok := promptInt(reader, msg("related.prompt"), 0, len(relatedTables))
+	if !ok || choice == 0 {
 		return
 	}
 
-	// Находим информацию о таблицах
-	var table1, table2 TableInfo
-	for _, t := range tables {
-		if t.Name == tablesInRelation[0] {
-			table1 = t
-		}
-		if t.Name == tablesInRelation[1] {
-			table2 = t
+	// Первой добавляется запись родительской таблицы, второй — дочерней со ссылкой на нее
+	relation := relatedTables[choice-1]
+	table1, _ := findTable(relation.Parent)
+	table2, _ := findTable(relation.Child)
+
+	// Добавленные записи можно отменить, даже если операция прервана на одной из следующих записей
+	var undoSteps []undoStep
+	defer func() {
+		if len(undoSteps) > 0 {
+			rememberUndo(msg("undo.kind_related", relation), undoSteps)
 		}
-	}
+	}()
 
 	for i := 0; i < recordCount; i++ {
-		fmt.Printf("\n=== Ввод данных для связанных таблиц %d из %d ===\n", i+1, recordCount)
-		
-		// Вставка в первую таблицу
-		fmt.Printf("\n--- Данные для таблицы '%s' ---\n", table1.Name)
-		insertColumns1 := table1.Columns[1:]
-		var values1 []interface{}
-		
-		for _, column := range insertColumns1 {
-			fmt.Printf("Введите значение для '%s': ", column)
-			value, _ := reader.ReadString('\n')
-			value = strings.TrimSpace(value)
+		fmt.Fprintln(stdout, msg("related.record_title", i+1, recordCount))
 
-			if !whiteListRegex.MatchString(value) {
-				fmt.Println("Ошибка: значение содержит недопустимые символы")
+		// Обе записи сначала вводятся и проверяются, в базу они добавляются только после подтверждения
+		fmt.Fprintln(stdout, msg("related.table_data", table1.Name))
+		first := &productRecord{Table: table1}
+		for _, column := range editableColumns(table1, insertableColumns(table1)) {
+			if !first.promptColumn(reader, column) {
 				return
 			}
-			
-			// Проверка числовых полей
-			if column == "price" || column == "founded_year" || column == "category_id" || 
-			   column == "manufacturer_id" {
-				if _, err := strconv.Atoi(value); err != nil {
-					fmt.Printf("Ошибка: поле '%s' должно быть числом\n", column)
-					return
-				}
-			}
-			
-			values1 = append(values1, value)
 		}
-
-		placeholders1 := make([]string, len(insertColumns1))
-		for j := range placeholders1 {
-			placeholders1[j] = fmt.Sprintf("$%d", j+1)
-		}
-
-		query1 := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) RETURNING id",
-			table1.Name,
-			strings.Join(insertColumns1, ", "),
-			strings.Join(placeholders1, ", "))
-
-		logToFileAndScreen(fmt.Sprintf("Выполнение вставки в связанные таблицы: %s с параметрами %v", query1, values1))
-		
-		var insertedID int
-		err := db.QueryRow(query1, values1...).Scan(&insertedID)
-		if err != nil {
-			logToFileAndScreen(fmt.Sprintf("Ошибка вставки в первую таблицу: %v", err))
-			fmt.Println("Ошибка: Не удалось добавить запись в первую таблицу")
+		if !requireInsertColumns(table1, first.Columns) {
 			return
 		}
 
-		fmt.Printf("✓ В таблицу '%s' добавлена запись с ID: %d\n", table1.Name, insertedID)
-
-		// Вставка во вторую таблицу с использованием ID из первой
-		fmt.Printf("\n--- Данные для таблицы '%s' ---\n", table2.Name)
-		
-		// Находим колонку, которая ссылается на первую таблицу
-		var foreignKeyColumn string
-		for _, column := range table2.Columns {
-			if column == "component_id" || column == "category_id" || column == "manufacturer_id" {
-				if strings.Contains(table2.Name, "stock") && table1.Name == "components" && column == "component_id" {
-					foreignKeyColumn = column
-					break
-				} else if strings.Contains(table2.Name, "components") {
-					if table1.Name == "categories" && column == "category_id" {
-						foreignKeyColumn = column
-						break
-					} else if table1.Name == "manufacturers" && column == "manufacturer_id" {
-						foreignKeyColumn = column
-						break
-					}
-				}
-			}
-		}
-
-		if foreignKeyColumn == "" {
-			// Если не нашли явную связь, используем первую подходящую колонку
-			for _, column := range table2.Columns {
-				if column != "id" {
-					foreignKeyColumn = column
-					break
-				}
+		// Вместо новой записи можно использовать уже существующую похожую запись
+		action, reuseID := confirmDuplicates(reader, table1, first.Columns, first.Values, true)
+		switch action {
+		case duplicateCancel:
+			fmt.Fprintln(stdout, msg("insert.cancelled"))
+			return
+		case duplicateReuse:
+			first = &productRecord{Table: table1, ExistingID: reuseID}
+			fmt.Fprintln(stdout, msg("product.reused", table1.Name, reuseID))
+			logToFileAndScreen(fmt.Sprintf("Связанные таблицы: используется существующая запись %s с id %s", table1.Name, reuseID))
+		default:
+			if !confirmRowRules(reader, table1.Name, "insert", []ruleRow{newRuleRow(first.Columns, first.Values)}) {
+				return
 			}
 		}
 
-		// Ввод данных для второй таблицы
-		fmt.Printf("В таблицу '%s' будет добавлен внешний ключ '%s' = %d\n", table2.Name, foreignKeyColumn, insertedID)
-		
-		// Запрашиваем остальные данные для второй таблицы
-		insertColumns2 := table2.Columns[1:] // исключаем id
-		var values2 []interface{}
-
-		for _, column := range insertColumns2 {
+		// Внешний ключ второй таблицы заполняется id записи первой
+		fmt.Fprintln(stdout, msg("related.table_data", table2.Name))
+		foreignKeyColumn := relation.Column
+		second := &productRecord{Table: table2}
+		for _, column := range editableColumns(table2, insertableColumns(table2)) {
 			if column == foreignKeyColumn {
-				values2 = append(values2, insertedID)
-				fmt.Printf("  Автоматически установлено: %s = %d\n", column, insertedID)
+				second.addParent(column, first)
 				continue
 			}
-			
-			fmt.Printf("Введите значение для '%s': ", column)
-			value, _ := reader.ReadString('\n')
-			value = strings.TrimSpace(value)
-
-			if !whiteListRegex.MatchString(value) {
-				fmt.Println("Ошибка: значение содержит недопустимые символы")
+			if !second.promptColumn(reader, column) {
 				return
 			}
-			
-			// Проверка числовых полей
-			if column == "quantity" || column == "price" {
-				if _, err := strconv.Atoi(value); err != nil {
-					fmt.Printf("Ошибка: поле '%s' должно быть числом\n", column)
-					return
-				}
-			}
-			
-			values2 = append(values2, value)
 		}
 
-		placeholders2 := make([]string, len(insertColumns2))
-		for j := range placeholders2 {
-			placeholders2[j] = fmt.Sprintf("$%d", j+1)
+		if action, _ := confirmDuplicates(reader, table2, second.Columns, second.Values, false); action == duplicateCancel {
+			fmt.Fprintln(stdout, msg("insert.cancelled"))
+			return
+		}
+		if !confirmRowRules(reader, table2.Name, "insert", []ruleRow{newRuleRow(second.Columns, second.Values)}) {
+			return
 		}
 
-		query2 := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
-			table2.Name,
-			strings.Join(insertColumns2, ", "),
-			strings.Join(placeholders2, ", "))
+		plan := productPlan(second, nil)
+		if !reviewRecordPlan(reader, plan) {
+			fmt.Fprintln(stdout, msg("insert.cancelled"))
+			return
+		}
 
-		logToFileAndScreen(fmt.Sprintf("Выполнение вставки во вторую таблицу: %s с параметрами %v", query2, values2))
-		
-		_, err = db.Exec(query2, values2...)
+		if dryRun {
+			printPlanDryRun(plan)
+			continue
+		}
+
+		steps, err := insertRecordPlan(plan, "Вставка в связанные таблицы")
 		if err != nil {
-			logToFileAndScreen(fmt.Sprintf("Ошибка вставки во вторую таблицу: %v", err))
-			fmt.Println("Ошибка: Не удалось добавить запись во вторую таблицу")
+			logToFileAndScreen(fmt.Sprintf("Ошибка вставки в связанные таблицы %s, изменения отменены: %v", relation, err))
+			printError(msg("related.failed"))
 			return
 		}
+		undoSteps = append(undoSteps, steps...)
 
-		fmt.Printf("✓ В таблицу '%s' успешно добавлена запись\n", table2.Name)
+		for _, record := range plan {
+			if record.ExistingID == "" {
+				fmt.Fprintln(stdout, msg("product.inserted", record.Table.Name, record.InsertedID))
+			}
+		}
 		logToFileAndScreen(fmt.Sprintf("Добавлены записи в связанные таблицы %s", relation))
 	}
 	
-	fmt.Printf("\nВсего добавлено связанных записей: %d\n", recordCount)
+	if dryRun {
+		fmt.Fprintln(stdout, msg("related.dry_run_done", dryRunTag))
+		return
+	}
+	fmt.Fprintln(stdout, msg("related.done", recordCount))
 }
 
 // Вспомогательная функция для выбора таблицы
 func selectTable(reader *bufio.Reader, title string) int {
-	fmt.Printf("\n=== %s ===\n", title)
+	fmt.Fprintf(stdout, "\n=== %s ===\n", title)
 	for i, table := range tables {
-		fmt.Printf("%d. %s\n", i+1, table.Name)
-	}
-	fmt.Println("0. Вернуться в меню")
-
-	fmt.Print("Выберите таблицу: ")
-	input, _ := reader.ReadString('\n')
-	input = strings.TrimSpace(input)
-
-	choice, err := strconv.Atoi(input)
-	if err != nil || choice < 0 || choice > len(tables) {
-		fmt.Println("Ошибка: выберите цифру от 0 до", len(tables))
-		return -1
+		fmt.Fprintf(stdout, "%d. %s\n", i+1, table.Name)
 	}
+	fmt.Fprintln(stdout, msg("common.back"))
 
-	if choice == 0 {
+	choice, ok := promptInt(reader, msg("common.choose_table"), 0, len(tables))
+	if !ok || choice == 0 {
 		return -1
 	}
 
@@ -880,23 +1347,14 @@ func selectTable(reader *bufio.Reader, title string) int {
 
 // Вспомогательная функция для выбора колонки
 func selectColumn(reader *bufio.Reader, table TableInfo) int {
-	fmt.Printf("\n=== ВЫБОР КОЛОНКИ В ТАБЛИЦЕ '%s' ===\n", table.Name)
+	fmt.Fprintln(stdout, msg("select_column.title", table.Name))
 	for i, column := range table.Columns {
-		fmt.Printf("%d. %s\n", i+1, column)
-	}
-	fmt.Println("0. Вернуться в меню")
-
-	fmt.Print("Выберите колонку: ")
-	input, _ := reader.ReadString('\n')
-	input = strings.TrimSpace(input)
-
-	choice, err := strconv.Atoi(input)
-	if err != nil || choice < 0 || choice > len(table.Columns) {
-		fmt.Println("Ошибка: выберите цифру от 0 до", len(table.Columns))
-		return -1
+		fmt.Fprintf(stdout, "%d. %s\n", i+1, column)
 	}
+	fmt.Fprintln(stdout, msg("common.back"))
 
-	if choice == 0 {
+	choice, ok := promptInt(reader, msg("common.choose_column"), 0, len(table.Columns))
+	if !ok || choice == 0 {
 		return -1
 	}
 
@@ -0,0 +1,139 @@
+package osl
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+// Общая подготовка тестов: база SQLite в памяти вместо PostgreSQL, сценарии ввода
+// вместо клавиатуры и перехват вывода на экран. Журнал тестов не пишется в файл.
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	// Ошибки из журнала не дублируются на экран, чтобы не попадать в проверяемый вывод
+	logToStdout = true
+	language = "ru"
+	colorEnabled = false
+	os.Exit(m.Run())
+}
+
+// Функция для открытия пустой базы SQLite в памяти и выполнения запросов statements.
+// Глобальное состояние программы восстанавливается после теста.
+func openTestDB(t testing.TB, statements ...string) {
+	t.Helper()
+	saved := struct {
+		db            *sql.DB
+		dialect       Dialect
+		tables        []TableInfo
+		relatedTables []tableRelation
+		appConfig     AppConfig
+		rowRules      []*RowRule
+		readOnly      bool
+		assumeYes     bool
+		dryRun        bool
+		interactive   bool
+		auditReady    bool
+		lastUndo      *undoEntry
+		history       []HistoryEntry
+		displayMode   string
+	}{db, dialect, tables, relatedTables, appConfig, rowRules, readOnly, assumeYes, dryRun, interactive, auditReady, lastUndo, history, displayMode}
+	t.Cleanup(func() {
+		db.Close()
+		db, dialect, tables, relatedTables = saved.db, saved.dialect, saved.tables, saved.relatedTables
+		appConfig, rowRules, readOnly, assumeYes = saved.appConfig, saved.rowRules, saved.readOnly, saved.assumeYes
+		dryRun, interactive, auditReady, lastUndo = saved.dryRun, saved.interactive, saved.auditReady, saved.lastUndo
+		history, displayMode = saved.history, saved.displayMode
+	})
+
+	var err error
+	db, err = sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("открытие SQLite: %v", err)
+	}
+	// Каждое соединение с :memory: — отдельная база, поэтому соединение одно
+	db.SetMaxOpenConns(1)
+	dialect = sqliteDialect{}
+	tables, relatedTables, rowRules = nil, nil, nil
+	appConfig = AppConfig{}
+	readOnly, assumeYes, dryRun, interactive, auditReady = false, false, false, false, false
+	lastUndo, history = nil, nil
+	displayMode = displayTable
+
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("подготовка базы: %v\n%s", err, statement)
+		}
+	}
+}
+
+// Функция для открытия базы с основными таблицами программы и загрузки ее структуры
+func openBaseSchema(t testing.TB, statements ...string) {
+	t.Helper()
+	openTestDB(t)
+	for _, table := range baseSchema {
+		mustExec(t, table.createQuery())
+	}
+	for _, statement := range statements {
+		mustExec(t, statement)
+	}
+	loadSchema()
+}
+
+// Функция для открытия базы с произвольными таблицами: структура загружается из каталога,
+// журнал аудита создается, как при запуске программы
+func openSchema(t testing.TB, names []string, statements ...string) {
+	t.Helper()
+	openTestDB(t, statements...)
+	for _, name := range names {
+		tables = append(tables, TableInfo{Name: name})
+	}
+	loadTableSchemas()
+	loadColumnTypes()
+	loadForeignKeys()
+	loadColumnDetails()
+	loadRelations()
+	applyColumnOrder()
+	prepareAuditTable()
+}
+
+// Функция для выполнения запроса подготовки данных
+func mustExec(t testing.TB, query string, args ...interface{}) {
+	t.Helper()
+	if _, err := db.Exec(query, args...); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+}
+
+// Функция для получения одного значения запросом
+func queryString(t testing.TB, query string, args ...interface{}) string {
+	t.Helper()
+	var value sql.NullString
+	if err := db.QueryRow(query, args...).Scan(&value); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return value.String
+}
+
+// Функция для получения ввода пользователя из строк сценария
+func scriptReader(lines ...string) *bufio.Reader {
+	return bufio.NewReader(strings.NewReader(strings.Join(lines, "\n") + "\n"))
+}
+
+// Функция для перехвата вывода на экран во время выполнения fn
+func captureOutput(t testing.TB, fn func()) string {
+	t.Helper()
+	var buf bytes.Buffer
+	saved := stdout
+	stdout = &buf
+	defer func() {
+		stdout = saved
+	}()
+	fn()
+	return buf.String()
+}
@@ -0,0 +1,103 @@
+package osl
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Метрики в формате Prometheus: сервер включается только при заданном METRICS_ADDR и отдает /metrics.
+// Счетчики запросов и ошибок ведутся по видам операций timedQuery (query, exec, tx_exec, ...),
+// показатели пула соединений берутся из db.Stats() в момент запроса.
+
+// Накопленные счетчики запросов
+var queryMetrics = struct {
+	sync.Mutex
+	queries      map[string]int64 // вид операции -> выполнено запросов
+	errors       map[string]int64 // вид операции -> запросов с ошибкой
+	rowsAffected int64
+}{
+	queries: map[string]int64{},
+	errors:  map[string]int64{},
+}
+
+// Функция для учета выполненного запроса
+func recordQueryMetric(operation string, err error) {
+	queryMetrics.Lock()
+	defer queryMetrics.Unlock()
+	queryMetrics.queries[operation]++
+	if err != nil {
+		queryMetrics.errors[operation]++
+	}
+}
+
+// Функция для учета записей, измененных запросом
+func recordRowsAffected(n int64) {
+	if n <= 0 {
+		return
+	}
+	queryMetrics.Lock()
+	queryMetrics.rowsAffected += n
+	queryMetrics.Unlock()
+}
+
+// Функция для записи счетчика по видам операций
+func writeOperationCounter(b *strings.Builder, name, help string, values map[string]int64) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	operations := make([]string, 0, len(values))
+	for operation := range values {
+		operations = append(operations, operation)
+	}
+	sort.Strings(operations)
+	for _, operation := range operations {
+		fmt.Fprintf(b, "%s{operation=%q} %d\n", name, operation, values[operation])
+	}
+}
+
+// Функция для записи одного показателя без меток
+func writeMetric(b *strings.Builder, name, kind, help string, value interface{}) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n%s %v\n", name, help, name, kind, name, value)
+}
+
+// Функция для формирования ответа /metrics
+func buildMetrics() string {
+	var b strings.Builder
+
+	queryMetrics.Lock()
+	writeOperationCounter(&b, "osl_queries_total", "Queries executed, by operation.", queryMetrics.queries)
+	writeOperationCounter(&b, "osl_query_errors_total", "Queries that returned an error, by operation.", queryMetrics.errors)
+	writeMetric(&b, "osl_rows_affected_total", "counter", "Rows changed by INSERT, UPDATE and DELETE statements.", queryMetrics.rowsAffected)
+	queryMetrics.Unlock()
+
+	if db != nil {
+		stats := db.Stats()
+		writeMetric(&b, "osl_db_max_open_connections", "gauge", "Maximum number of open connections to the database.", stats.MaxOpenConnections)
+		writeMetric(&b, "osl_db_open_connections", "gauge", "Established connections, both in use and idle.", stats.OpenConnections)
+		writeMetric(&b, "osl_db_in_use_connections", "gauge", "Connections currently in use.", stats.InUse)
+		writeMetric(&b, "osl_db_idle_connections", "gauge", "Idle connections.", stats.Idle)
+		writeMetric(&b, "osl_db_wait_count_total", "counter", "Connections waited for.", stats.WaitCount)
+		writeMetric(&b, "osl_db_wait_duration_seconds_total", "counter", "Total time blocked waiting for a new connection.", stats.WaitDuration.Seconds())
+	}
+	return b.String()
+}
+
+// Функция для создания обработчика сервера метрик
+func metricsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		fmt.Fprint(w, buildMetrics())
+	})
+	return mux
+}
+
+// Функция для запуска сервера метрик, если задан METRICS_ADDR
+func startMetricsServer() {
+	addr := envString("METRICS_ADDR", "")
+	if addr == "" {
+		return
+	}
+	startHTTPListener("сервер метрик", localAddr(addr), metricsHandler())
+}
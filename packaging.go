@@ -0,0 +1,162 @@
+package osl
+
+import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Суффикс количества в упаковках, например "5уп"
+const packageSuffix = "уп"
+
+// Функция для проверки, есть ли в таблице components колонка units_per_package
+func packagingSupported() bool {
+	table, ok := findTable("components")
+	return ok && table.Types["units_per_package"] != ""
+}
+
+// Функция для получения количества штук в упаковке компонента (0 — не указано)
+func unitsPerPackage(componentID string) (int, error) {
+	if componentID == "" || !packagingSupported() {
+		return 0, nil
+	}
+	var units sql.NullInt64
+	err := dbScanRow("SELECT units_per_package FROM components WHERE id = $1", []interface{}{componentID}, &units)
+	if errors.Is(err, sql.ErrNoRows) || !units.Valid {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return int(units.Int64), nil
+}
+
+// Функция для разбора количества: целое число штук или целое число упаковок с суффиксом "уп".
+// Возвращает количество в штуках и число упаковок (0, если суффикс не использовался).
+func parseQuantity(input string, units int) (int, int, error) {
+	lower := strings.ToLower(strings.TrimSpace(input))
+	if !strings.HasSuffix(lower, packageSuffix) {
+		quantity, err := strconv.Atoi(lower)
+		if err != nil {
+			return 0, 0, errors.New(msg("input.not_number", "quantity"))
+		}
+		return quantity, 0, nil
+	}
+
+	number := strings.TrimSpace(strings.TrimSuffix(lower, packageSuffix))
+	packages, err := strconv.Atoi(number)
+	if err != nil {
+		if strings.ContainsAny(number, ".,") {
+			return 0, 0, errors.New(msg("packages.fractional"))
+		}
+		return 0, 0, errors.New(msg("packages.not_integer", packageSuffix))
+	}
+	if units <= 0 {
+		return 0, 0, errors.New(msg("packages.unknown_size"))
+	}
+	return packages * units, packages, nil
+}
+
+// Функция для ввода количества на складе с поддержкой упаковок компонента componentID.
+// Пересчет в штуки показывается для подтверждения.
+func promptQuantity(reader *bufio.Reader, prompt, componentID string) (string, bool) {
+	units, err := unitsPerPackage(componentID)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка получения размера упаковки компонента %s: %v", componentID, err))
+	}
+	if units > 0 {
+		prompt = strings.TrimSuffix(prompt, ": ") + msg("packages.prompt_hint", units, packageSuffix)
+	}
+
+	attempts := envInt("OSL_PROMPT_ATTEMPTS", 3)
+	for attempt := 1; attempt <= attempts; attempt++ {
+		input, ok := promptValidated(reader, prompt, func(input string) error {
+			quantity, _, err := parseQuantity(input, units)
+			if err != nil {
+				return err
+			}
+			// Количество в штуках проверяется по ограничениям колонки stock.quantity
+			if table, ok := findTable("stock"); ok {
+				return validateTableValue(table, "quantity", strconv.Itoa(quantity))
+			}
+			return nil
+		})
+		if !ok {
+			return "", false
+		}
+
+		quantity, packages, _ := parseQuantity(input, units)
+		if packages == 0 {
+			return strconv.Itoa(quantity), true
+		}
+
+		confirm := msg("packages.confirm", packages, packageSuffix, units, quantity)
+		if promptConfirm(reader, confirm) {
+			return strconv.Itoa(quantity), true
+		}
+	}
+	printError(msg("input.too_many_attempts"))
+	return "", false
+}
+
+// Функция для определения компонента, к которому относится вводимое количество в записи stock.
+// columns и values — уже введенные колонки записи.
+func stockComponentID(table TableInfo, columns []string, values []interface{}) string {
+	if table.Name != "stock" {
+		return ""
+	}
+	for i, column := range columns {
+		// Новый компонент еще не добавлен, его id неизвестен
+		if column == "component_id" && i < len(values) && values[i] != nil {
+			return fmt.Sprint(values[i])
+		}
+	}
+	return ""
+}
+
+// Функция для получения компонента строки stock по её id (пустая строка — не найден)
+func stockRowComponentID(stockID string) string {
+	var componentID sql.NullString
+	if err := dbScanRow("SELECT component_id FROM stock WHERE id = $1", []interface{}{stockID}, &componentID); err != nil {
+		return ""
+	}
+	return componentID.String
+}
+
+// Функция для проверки, вводится ли значение колонки в упаковках (stock.quantity)
+func isPackagedQuantity(table TableInfo, column string) bool {
+	return table.Name == "stock" && column == "quantity"
+}
+
+// Функция для ввода значения колонки записи с учетом количества в упаковках для stock.quantity
+func promptRecordValue(reader *bufio.Reader, table TableInfo, column string, columns []string, values []interface{}) (string, bool) {
+	prompt := msg("insert.value_prompt", column)
+	if isPackagedQuantity(table, column) {
+		return promptQuantity(reader, prompt, stockComponentID(table, columns, values))
+	}
+	return promptColumnValue(reader, prompt, table, column)
+}
+
+// Функция для ввода значения колонки новой записи. Если у колонки есть значение по умолчанию
+// в БД, его можно выбрать пустым вводом или словом DEFAULT: тогда возвращается признак
+// значения по умолчанию, и колонка не указывается в INSERT.
+func promptInsertValue(reader *bufio.Reader, table TableInfo, column string, columns []string, values []interface{}) (string, bool, bool) {
+	defaultExpr := columnDetails(table, column).Default
+	if defaultExpr == "" || isPackagedQuantity(table, column) {
+		value, ok := promptRecordValue(reader, table, column, columns, values)
+		return value, false, ok
+	}
+	return promptColumnValueOrDefault(reader, msg("insert.value_prompt", column), table, column, defaultExpr)
+}
+
+// Функция для проверки, что в новой записи осталась хотя бы одна колонка
+func requireInsertColumns(table TableInfo, columns []string) bool {
+	if len(columns) == 0 {
+		printError(msg("insert.all_default", table.Name))
+		return false
+	}
+	return true
+}
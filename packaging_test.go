@@ -0,0 +1,88 @@
+package osl
+
+import (
+	"strings"
+	"testing"
+)
+
+// Разбор количества в штуках и в упаковках с суффиксом "уп"
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		input              string
+		units              int
+		quantity, packages int
+		err                string
+	}{
+		{"12", 10, 12, 0, ""},
+		{"-3", 10, -3, 0, ""},
+		{"5уп", 10, 50, 5, ""},
+		{" 5 УП ", 10, 50, 5, ""},
+		{"-2уп", 10, -20, -2, ""},
+		{"2.5уп", 10, 0, 0, msg("packages.fractional")},
+		{"1,5уп", 10, 0, 0, msg("packages.fractional")},
+		{"пятьуп", 10, 0, 0, msg("packages.not_integer", packageSuffix)},
+		{"5уп", 0, 0, 0, msg("packages.unknown_size")},
+		{"12.5", 10, 0, 0, msg("input.not_number", "quantity")},
+	}
+	for _, tt := range tests {
+		quantity, packages, err := parseQuantity(tt.input, tt.units)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("parseQuantity(%q, %d): ошибка %v, ожидалось %q", tt.input, tt.units, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || quantity != tt.quantity || packages != tt.packages {
+			t.Errorf("parseQuantity(%q, %d) = %d, %d, %v, ожидалось %d, %d", tt.input, tt.units, quantity, packages, err, tt.quantity, tt.packages)
+		}
+	}
+}
+
+// Ввод остатка в упаковках: пересчет подтверждается, для компонента без размера упаковки
+// суффикс отклоняется с подсказкой
+func TestPromptQuantityPackages(t *testing.T) {
+	openBaseSchema(t,
+		"ALTER TABLE components ADD COLUMN units_per_package INTEGER",
+		"INSERT INTO categories (name, description) VALUES ('Память', 'RAM')",
+		"INSERT INTO manufacturers (name) VALUES ('Kingston')",
+		"INSERT INTO components (name, category_id, manufacturer_id, model, price, units_per_package) VALUES ('DDR4', 1, 1, 'KVR', 3000, 10), ('DDR5', 1, 1, 'FURY', 5000, NULL)")
+	if !packagingSupported() {
+		t.Fatal("колонка units_per_package не найдена")
+	}
+
+	var value string
+	var ok bool
+	output := captureOutput(t, func() {
+		value, ok = promptQuantity(scriptReader("5уп", "да"), "Количество: ", "1")
+	})
+	if !ok || value != "50" {
+		t.Errorf("5уп по 10 шт. = %q, %v, ожидалось 50", value, ok)
+	}
+	for _, want := range []string{msg("packages.prompt_hint", 10, packageSuffix), msg("packages.confirm", 5, packageSuffix, 10, 50)} {
+		if !strings.Contains(output, want) {
+			t.Errorf("в выводе нет %q:\n%s", want, output)
+		}
+	}
+
+	// Отказ от пересчета — количество вводится заново
+	captureOutput(t, func() {
+		value, ok = promptQuantity(scriptReader("5уп", "нет", "7"), "Количество: ", "1")
+	})
+	if !ok || value != "7" {
+		t.Errorf("после отказа от пересчета %q, %v, ожидалось 7", value, ok)
+	}
+
+	// Дробное количество упаковок и компонент без размера упаковки
+	output = captureOutput(t, func() {
+		value, ok = promptQuantity(scriptReader("1.5уп", "3"), "Количество: ", "1")
+	})
+	if !ok || value != "3" || !strings.Contains(output, msg("packages.fractional")) {
+		t.Errorf("дробные упаковки: %q, %v\n%s", value, ok, output)
+	}
+	output = captureOutput(t, func() {
+		value, ok = promptQuantity(scriptReader("5уп", "4"), "Количество: ", "2")
+	})
+	if !ok || value != "4" || !strings.Contains(output, msg("packages.unknown_size")) {
+		t.Errorf("компонент без упаковки: %q, %v\n%s", value, ok, output)
+	}
+}
@@ -0,0 +1,223 @@
+package osl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Постраничный просмотр больших таблиц. Если записи упорядочены по первичному ключу из одной
+// колонки, страницы выбираются по ключу (WHERE ключ > последний показанный LIMIT n): такой запрос
+// не замедляется к концу таблицы, а записи, добавленные между страницами, не сдвигают границы —
+// ни одна запись не пропускается и не повторяется. Границы уже показанных страниц хранятся в стеке,
+// поэтому переход назад тоже выполняется по ключу. Для другой сортировки используется LIMIT/OFFSET.
+
+// Функция для получения размера страницы просмотра (OSL_PAGE_SIZE, по умолчанию 100)
+func pageSize() int {
+	if size := envInt("OSL_PAGE_SIZE", 100); size > 0 {
+		return size
+	}
+	return 100
+}
+
+// Функция для получения колонки постраничной выборки по ключу (пусто — используется OFFSET).
+// Ключ должен однозначно задавать порядок: сортировка по первичному ключу из одной колонки.
+func keysetColumn(table TableInfo) string {
+	keys := primaryKeyColumns(table)
+	if sortColumn := getViewPrefs(table.Name).SortColumn; len(keys) != 1 || (sortColumn != "" && sortColumn != keys[0]) {
+		return ""
+	}
+	return keys[0]
+}
+
+// Состояние постраничного просмотра таблицы
+type pageBrowser struct {
+	table     TableInfo
+	columns   []string
+	condition string // условие WHERE без ключевого слова (пусто — все записи)
+	size      int
+	key       string        // колонка выборки по ключу (пусто — OFFSET)
+	bounds    []interface{} // последние ключи предыдущих страниц: граница текущей страницы — последний элемент
+	page      int           // номер текущей страницы с нуля
+}
+
+// Функция для построения запроса страницы. bound — нижняя граница ключа (hasBound false — с начала таблицы).
+func (b *pageBrowser) query(bound interface{}, hasBound bool) (string, []interface{}) {
+	columns := b.columns
+	if b.key != "" && !containsString(columns, b.key) {
+		// Ключ нужен для границы следующей страницы, даже если колонка не выбрана для вывода
+		columns = append([]string{b.key}, columns...)
+	}
+
+	var where []string
+	var args []interface{}
+	if b.condition != "" {
+		where = append(where, b.condition)
+	}
+	if b.key != "" && hasBound {
+		args = append(args, bound)
+		where = append(where, fmt.Sprintf("%s > $%d", dialect.QuoteIdent(b.key), len(args)))
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s", columnList(columns), b.table.SQLName())
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	if b.key != "" {
+		query += " ORDER BY " + dialect.QuoteIdent(b.key)
+	} else if order := orderByClause(b.table); order != "" {
+		query += " " + order
+		// Первичный ключ делает порядок однозначным, иначе OFFSET может повторить или пропустить записи
+		if pk := primaryKeyColumn(b.table); pk != "" && getViewPrefs(b.table.Name).SortColumn != pk {
+			query += ", " + dialect.QuoteIdent(pk)
+		}
+	}
+
+	args = append(args, b.size)
+	query += fmt.Sprintf(" LIMIT $%d", len(args))
+	if b.key == "" {
+		args = append(args, b.page*b.size)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+	return query, args
+}
+
+// Функция для выборки текущей страницы. Возвращает записи без служебной колонки ключа,
+// последний ключ страницы и выполненный запрос с параметрами. Ключ берется из значения,
+// прочитанного из базы, а не из ячейки для вывода: время в ячейке переведено в пояс
+// и формат вывода, и граница по нему сдвинула бы страницу.
+func (b *pageBrowser) fetch() (*ResultSet, interface{}, string, []interface{}, error) {
+	var bound interface{}
+	hasBound := len(b.bounds) > 0
+	if hasBound {
+		bound = b.bounds[len(b.bounds)-1]
+	}
+	query, args := b.query(bound, hasBound)
+	logToFileAndScreen(fmt.Sprintf("Выполнение запроса страницы %d: %s с параметрами %v", b.page+1, query, args))
+
+	rows, err := dbQuery(query, args...)
+	if err != nil {
+		return nil, nil, query, args, err
+	}
+	defer rows.Close()
+	rs, err := newResultSet(rows)
+	if err != nil {
+		return nil, nil, query, args, err
+	}
+	keyIndex := -1
+	for i, column := range rs.Columns {
+		if b.key != "" && column == b.key {
+			keyIndex = i
+			break
+		}
+	}
+	var last interface{}
+	for rows.Next() {
+		rowData, values, ok := rs.scanRowValues(rows)
+		if !ok {
+			continue
+		}
+		rs.Rows = append(rs.Rows, rowData)
+		if keyIndex >= 0 {
+			last = keysetBound(values[keyIndex])
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, query, args, err
+	}
+
+	if b.key != "" && len(rs.Rows) > 0 {
+		if !containsString(b.columns, b.key) {
+			rs.Columns, rs.Types = rs.Columns[1:], rs.Types[1:]
+			for i := range rs.Rows {
+				rs.Rows[i] = rs.Rows[i][1:]
+			}
+		}
+	}
+	return rs, last, query, args, nil
+}
+
+// Функция для получения границы страницы из значения ключа: байты драйвера передаются
+// в запрос строкой, иначе PostgreSQL принял бы их за bytea
+func keysetBound(value interface{}) interface{} {
+	if bytes, ok := value.([]byte); ok {
+		return string(bytes)
+	}
+	return value
+}
+
+// Функция для постраничного просмотра таблицы; total — количество записей при открытии
+func browsePages(reader *bufio.Reader, table TableInfo, columns []string, condition string, total int) {
+	b := &pageBrowser{table: table, columns: columns, condition: condition, size: pageSize(), key: keysetColumn(table)}
+	pages := (total + b.size - 1) / b.size
+	if b.key != "" {
+		logToFileAndScreen(fmt.Sprintf("Постраничный просмотр %s по ключу %s, страница %d записей", table.Name, b.key, b.size))
+	} else {
+		logToFileAndScreen(fmt.Sprintf("Постраничный просмотр %s через OFFSET, страница %d записей", table.Name, b.size))
+	}
+
+	var query string
+	var args []interface{}
+	for {
+		rs, last, pageQuery, pageArgs, err := b.fetch()
+		if err != nil {
+			logToFileAndScreen(fmt.Sprintf("Ошибка выполнения запроса: %v", err))
+			printError(msg("view.query_failed"))
+			return
+		}
+		query, args = pageQuery, pageArgs
+
+		// Пустая страница возможна, если записи удалили во время просмотра
+		hasNext := len(rs.Rows) == b.size
+		if len(rs.Rows) == 0 && b.page > 0 {
+			fmt.Fprintln(stdout, msg("paging.no_more"))
+			b.previous()
+			continue
+		}
+		printResult(rs)
+		first := b.page*b.size + 1
+		fmt.Fprintln(stdout, msg("paging.footer", b.page+1, pages, first, first+len(rs.Rows)-1, total))
+		offerRawDetails(reader, rs)
+
+		input, ok := promptValidated(reader, msg("paging.prompt"), func(input string) error {
+			switch input {
+			case "", "0", "1":
+				return nil
+			case "2":
+				if b.page == 0 {
+					return errors.New(msg("paging.first_page"))
+				}
+				return nil
+			}
+			return errors.New(msg("input.choose_range", 0, 2))
+		})
+		if !ok || input == "0" {
+			break
+		}
+		if input == "2" {
+			b.previous()
+			continue
+		}
+		if !hasNext {
+			fmt.Fprintln(stdout, msg("paging.no_more"))
+			break
+		}
+		if b.key != "" {
+			b.bounds = append(b.bounds, last)
+		}
+		b.page++
+	}
+	offerExplain(reader, query, args, nil)
+}
+
+// Функция для перехода к предыдущей странице
+func (b *pageBrowser) previous() {
+	if b.page == 0 {
+		return
+	}
+	b.page--
+	if b.key != "" && len(b.bounds) > 0 {
+		b.bounds = b.bounds[:len(b.bounds)-1]
+	}
+}
@@ -0,0 +1,41 @@
+package osl
+
+import (
+	"strings"
+	"testing"
+)
+
+// Граница страницы по ключу-времени берется из значения базы, а не из ячейки в формате
+// вывода (TIME_FORMAT): страницы идут подряд без пропусков и повторов
+func TestKeysetPagingByTimestamp(t *testing.T) {
+	openSchema(t, []string{"readings"},
+		"CREATE TABLE readings (taken_at TIMESTAMP PRIMARY KEY, value INTEGER)",
+		"INSERT INTO readings VALUES ('2024-01-01 10:00:00', 1), ('2024-01-01 11:00:00', 2), "+
+			"('2024-01-01 12:00:00', 3), ('2024-01-01 13:00:00', 4), ('2024-01-01 14:00:00', 5)")
+	t.Setenv("TIME_FORMAT", "02.01.2006 15:04")
+
+	b := &pageBrowser{table: tables[0], columns: []string{"value"}, size: 2, key: keysetColumn(tables[0])}
+	if b.key != "taken_at" {
+		t.Fatalf("колонка ключа %q", b.key)
+	}
+	var pages []string
+	for page := 0; page < 4; page++ {
+		rs, last, _, _, err := b.fetch()
+		if err != nil {
+			t.Fatalf("страница %d: %v", page+1, err)
+		}
+		if len(rs.Rows) == 0 {
+			break
+		}
+		var values []string
+		for _, row := range rs.Rows {
+			values = append(values, row[0])
+		}
+		pages = append(pages, strings.Join(values, ","))
+		b.bounds = append(b.bounds, last)
+		b.page++
+	}
+	if got := strings.Join(pages, " | "); got != "1,2 | 3,4 | 5" {
+		t.Errorf("страницы: %s", got)
+	}
+}
@@ -0,0 +1,68 @@
+package osl
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Настройки пула соединений. Интерактивный режим работает с одним пользователем и выполняет
+// запросы последовательно, поэтому ему достаточно одного-двух соединений; запас нужен для
+// фоновых задач (отладочный сервер, сводки) и команд, читающих одну таблицу при записи в другую.
+// Ограничение времени жизни закрывает соединения, простоявшие, пока меню ждет ввода,
+// чтобы не держать на сервере устаревшие сессии.
+
+// Параметры пула соединений
+type PoolSettings struct {
+	MaxOpen     int
+	MaxIdle     int
+	MaxLifetime time.Duration
+}
+
+// Функция для чтения параметров пула из DB_MAX_OPEN, DB_MAX_IDLE и DB_CONN_LIFETIME
+// (прежнее имя DB_CONN_MAX_LIFETIME тоже принимается)
+func poolSettings() PoolSettings {
+	settings := PoolSettings{
+		MaxOpen:     envInt("DB_MAX_OPEN", 5),
+		MaxIdle:     envInt("DB_MAX_IDLE", 2),
+		MaxLifetime: envDuration("DB_CONN_LIFETIME", envDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute)),
+	}
+	if settings.MaxOpen < 1 {
+		logToFileAndScreen(fmt.Sprintf("Некорректное значение DB_MAX_OPEN=%d, используется 5", settings.MaxOpen))
+		settings.MaxOpen = 5
+	}
+	if settings.MaxIdle < 0 {
+		logToFileAndScreen(fmt.Sprintf("Некорректное значение DB_MAX_IDLE=%d, используется 2", settings.MaxIdle))
+		settings.MaxIdle = 2
+	}
+	if settings.MaxIdle > settings.MaxOpen {
+		logToFileAndScreen(fmt.Sprintf("DB_MAX_IDLE=%d больше DB_MAX_OPEN=%d, используется %d",
+			settings.MaxIdle, settings.MaxOpen, settings.MaxOpen))
+		settings.MaxIdle = settings.MaxOpen
+	}
+	if settings.MaxLifetime < 0 {
+		logToFileAndScreen(fmt.Sprintf("Некорректное значение DB_CONN_LIFETIME=%s, используется 30m", settings.MaxLifetime))
+		settings.MaxLifetime = 30 * time.Minute
+	}
+	return settings
+}
+
+// Функция для описания ограничения времени запроса для журнала и состояния подключения.
+// Ограничение передается в строке подключения и действует для каждого нового соединения;
+// в SQLite серверного ограничения нет.
+func describeStatementTimeout(config DBConfig) string {
+	if config.StatementTimeout <= 0 {
+		return "нет"
+	}
+	if _, ok := dialect.(sqliteDialect); ok {
+		return fmt.Sprintf("%s (не поддерживается SQLite)", config.StatementTimeout)
+	}
+	return config.StatementTimeout.String()
+}
+
+// Функция для применения параметров пула к подключению
+func applyPoolSettings(conn *sql.DB, settings PoolSettings) {
+	conn.SetMaxOpenConns(settings.MaxOpen)
+	conn.SetMaxIdleConns(settings.MaxIdle)
+	conn.SetConnMaxLifetime(settings.MaxLifetime)
+}
@@ -0,0 +1,250 @@
+package osl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Наборы условий фильтрации сохраняются под именем в файле конфигурации (saved_filters,
+// тот же формат, что у команды run) и применяются из меню без повторного ввода условий.
+// Значения чувствительных колонок на диск не записываются: вместо них сохраняется параметр
+// «спросить», и значение запрашивается при применении.
+
+// Функция для преобразования описания фильтрации в сохраненный фильтр.
+// Возвращает также признак того, что значения чувствительных колонок заменены параметрами.
+func savedFilterFromSpec(table TableInfo, spec FilterSpec) (SavedFilter, bool, error) {
+	typeNames := make(map[int]string, len(savedConditionTypes))
+	for name, kind := range savedConditionTypes {
+		typeNames[kind] = name
+	}
+
+	saved := SavedFilter{Table: spec.Table, Operator: strings.TrimSpace(spec.Operator)}
+	if len(spec.Columns) != len(table.Columns) {
+		saved.Columns = append([]string(nil), spec.Columns...)
+	}
+	masked := false
+	for _, condition := range spec.Conditions {
+		values := append([]string(nil), condition.Values...)
+		if sensitiveColumns()[strings.ToLower(condition.Column)] {
+			// Параметр «спросить» не допускается в списке значений
+			if condition.Type == filterInList {
+				return SavedFilter{}, false, errors.New(msg("presets.sensitive_list", condition.Column))
+			}
+			for i := range values {
+				values[i] = askParam
+			}
+			masked = masked || len(values) > 0
+		}
+		saved.Conditions = append(saved.Conditions, SavedCondition{
+			Column: condition.Column,
+			Type:   typeNames[condition.Type],
+			Values: values,
+		})
+	}
+	return saved, masked, nil
+}
+
+// Функция для проверки имени сохраненного фильтра
+func validatePresetName(input string) error {
+	if input == "" {
+		return errors.New(msg("presets.name_required"))
+	}
+	for _, r := range input {
+		if unicode.IsControl(r) {
+			return errors.New(msg("validation.control_char", r))
+		}
+	}
+	return nil
+}
+
+// Функция для предложения сохранить условия выполненной фильтрации под именем
+func offerSavePreset(reader *bufio.Reader, spec FilterSpec) {
+	name, ok := promptString(reader, msg("presets.save_prompt"))
+	if !ok || name == "" {
+		return
+	}
+	if err := validatePresetName(name); err != nil {
+		printError(msg("common.error", err))
+		return
+	}
+	if _, exists := appConfig.SavedFilters[name]; exists && !promptConfirm(reader, msg("presets.overwrite", name)) {
+		fmt.Fprintln(stdout, msg("input.cancelled"))
+		return
+	}
+
+	table, _ := findTable(spec.Table)
+	saved, masked, err := savedFilterFromSpec(table, spec)
+	if err != nil {
+		printError(msg("common.error", err))
+		return
+	}
+	if masked {
+		fmt.Fprintln(stdout, msg("presets.masked"))
+	}
+
+	previous, existed := appConfig.SavedFilters[name]
+	if appConfig.SavedFilters == nil {
+		appConfig.SavedFilters = make(map[string]SavedFilter)
+	}
+	appConfig.SavedFilters[name] = saved
+	if err := saveAppConfig(); err != nil {
+		// Несохраненный фильтр не остается в памяти, чтобы список совпадал с файлом
+		if existed {
+			appConfig.SavedFilters[name] = previous
+		} else {
+			delete(appConfig.SavedFilters, name)
+		}
+		logToFileAndScreen(fmt.Sprintf("Ошибка сохранения фильтра '%s': %v", name, err))
+		printError(msg("presets.save_failed"))
+		return
+	}
+	fmt.Fprintln(stdout, msg("presets.saved", name))
+	logToFileAndScreen(fmt.Sprintf("Сохранен фильтр '%s': таблица %s, условий %d", name, saved.Table, len(saved.Conditions)))
+}
+
+// Функция для получения имен сохраненных фильтров по алфавиту
+func presetNames() []string {
+	names := make([]string, 0, len(appConfig.SavedFilters))
+	for name := range appConfig.SavedFilters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Функция для вывода списка сохраненных фильтров с условиями
+func printPresets(names []string) {
+	fmt.Fprintln(stdout, msg("presets.title"))
+	for i, name := range names {
+		saved := appConfig.SavedFilters[name]
+		spec, _, err := saved.filterSpec()
+		if err != nil {
+			fmt.Fprintln(stdout, msg("presets.item_invalid", i+1, name, saved.Table, err))
+			continue
+		}
+		fmt.Fprintln(stdout, msg("presets.item", i+1, name, saved.Table, describeConditions(spec.Conditions, spec.Operator)))
+	}
+}
+
+// Функция для выбора сохраненного фильтра по номеру или имени; пустая строка при отмене
+func promptPresetName(reader *bufio.Reader, names []string) string {
+	parse := func(input string) (string, error) {
+		if n, err := strconv.Atoi(input); err == nil && n >= 1 && n <= len(names) {
+			return names[n-1], nil
+		}
+		if _, ok := appConfig.SavedFilters[input]; ok {
+			return input, nil
+		}
+		return "", errors.New(msg("presets.not_found", input))
+	}
+	input, ok := promptValidated(reader, msg("presets.choose"), func(input string) error {
+		_, err := parse(input)
+		return err
+	})
+	if !ok {
+		return ""
+	}
+	name, _ := parse(input)
+	return name
+}
+
+// Пункт 24: Сохраненные фильтры
+func filterPresets(reader *bufio.Reader) {
+	names := presetNames()
+	if len(names) == 0 {
+		fmt.Fprintln(stdout, msg("presets.empty"))
+		return
+	}
+	printPresets(names)
+
+	fmt.Fprintln(stdout, msg("presets.action_apply"))
+	fmt.Fprintln(stdout, msg("presets.action_delete"))
+	fmt.Fprintln(stdout, msg("common.back"))
+	choice, ok := promptInt(reader, msg("presets.action_prompt"), 0, 2)
+	if !ok || choice == 0 {
+		return
+	}
+	name := promptPresetName(reader, names)
+	if name == "" {
+		return
+	}
+
+	if choice == 2 {
+		deletePreset(reader, name)
+		return
+	}
+	applyPreset(reader, name)
+}
+
+// Функция для применения сохраненного фильтра к его таблице или к другой таблице с теми же колонками
+func applyPreset(reader *bufio.Reader, name string) {
+	saved := appConfig.SavedFilters[name]
+	tableName, ok := promptValidated(reader, msg("presets.table_prompt", saved.Table), func(input string) error {
+		if input == "" {
+			return nil
+		}
+		if _, ok := findTable(input); !ok {
+			return errors.New(msg("common.table_not_found", input))
+		}
+		return nil
+	})
+	if !ok {
+		return
+	}
+	if tableName != "" {
+		saved.Table = tableName
+	}
+
+	// Условия проверяются заново по текущей структуре таблицы
+	spec, params, err := saved.filterSpec()
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Сохраненный фильтр '%s' не применим к таблице %s: %v", name, saved.Table, err))
+		printError(msg("presets.invalid", name, err))
+		return
+	}
+	table, _ := findTable(spec.Table)
+
+	// Значения параметров «спросить» (в том числе чувствительных колонок) вводятся сейчас
+	values := make([]string, len(params))
+	for i, param := range params {
+		value, ok := promptValidated(reader, msg("presets.param_prompt", param.Name), param.Validate)
+		if !ok {
+			return
+		}
+		values[i] = value
+	}
+	if err := applySpecParams(table, spec, params, values); err != nil {
+		printError(msg("common.error", err))
+		return
+	}
+
+	spec.IncludeArchived = promptIncludeArchived(reader, table, "filter.include_archived")
+	if !promptDisplayMode(reader) {
+		return
+	}
+	logToFileAndScreen(fmt.Sprintf("Применение сохраненного фильтра '%s' к таблице %s", name, table.Name))
+	executeFilter(reader, spec)
+}
+
+// Функция для удаления сохраненного фильтра из файла конфигурации
+func deletePreset(reader *bufio.Reader, name string) {
+	if !promptConfirm(reader, msg("presets.delete_confirm", name)) {
+		fmt.Fprintln(stdout, msg("input.cancelled"))
+		return
+	}
+	saved := appConfig.SavedFilters[name]
+	delete(appConfig.SavedFilters, name)
+	if err := saveAppConfig(); err != nil {
+		appConfig.SavedFilters[name] = saved
+		logToFileAndScreen(fmt.Sprintf("Ошибка удаления фильтра '%s': %v", name, err))
+		printError(msg("presets.save_failed"))
+		return
+	}
+	fmt.Fprintln(stdout, msg("presets.deleted", name))
+	logToFileAndScreen(fmt.Sprintf("Удален сохраненный фильтр '%s'", name))
+}
@@ -0,0 +1,364 @@
+package osl
+
+import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Полный ввод нового товара: категория и производитель (новые или существующие), компонент
+// и его строка склада. Сначала вводятся все данные, затем записи добавляются в одной транзакции:
+// id каждой новой записи из RETURNING подставляется во внешний ключ следующей. Отмена на любом
+// шаге или ошибка вставки не оставляют в базе ни одной записи из этого ввода.
+
+// Таблицы полного ввода товара и колонка склада, ссылающаяся на компонент
+const (
+	productTable       = "components"
+	productStockTable  = "stock"
+	productStockColumn = "component_id"
+)
+
+// Ссылка на запись, id которой становится известен только после её добавления
+type productParent struct {
+	Index  int // индекс колонки во внешнем ключе дочерней записи
+	Record *productRecord
+}
+
+// Запись полного ввода: выбранная существующая или новая, добавляемая в транзакции
+type productRecord struct {
+	Table      TableInfo
+	ExistingID string // id существующей записи; пусто — запись добавляется
+	Columns    []string
+	Values     []interface{}
+	Parents    []productParent
+	InsertedID string
+}
+
+// Функция для построения запроса добавления записи
+func (r *productRecord) insertQuery() string {
+	placeholders := make([]string, len(r.Columns))
+	for i := range placeholders {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		r.Table.SQLName(), columnList(r.Columns), strings.Join(placeholders, ", "))
+}
+
+// Функция для получения значений записи с id уже добавленных родительских записей
+func (r *productRecord) insertValues() []interface{} {
+	values := append([]interface{}(nil), r.Values...)
+	for _, parent := range r.Parents {
+		values[parent.Index] = parent.Record.InsertedID
+	}
+	return values
+}
+
+// Функция для добавления значения колонки в запись
+func (r *productRecord) add(column string, value interface{}) {
+	r.Columns = append(r.Columns, column)
+	r.Values = append(r.Values, value)
+}
+
+// Функция для заполнения внешнего ключа записи id родительской записи: существующей — сразу,
+// новой — после ее добавления
+func (r *productRecord) addParent(column string, parent *productRecord) {
+	if parent.ExistingID != "" {
+		r.add(column, parent.ExistingID)
+		fmt.Fprintln(stdout, msg("product.auto_existing", column, parent.ExistingID))
+		return
+	}
+	r.Parents = append(r.Parents, productParent{Index: len(r.Values), Record: parent})
+	r.add(column, nil)
+	fmt.Fprintln(stdout, msg("product.auto_new", column, parent.Table.Name))
+}
+
+// Функция для ввода значения колонки новой записи. Колонка, для которой выбрано значение
+// по умолчанию из БД, в запись не добавляется. Возвращает false при отмене.
+func (r *productRecord) promptColumn(reader *bufio.Reader, column string) bool {
+	var value string
+	var useDefault, ok bool
+	if foreignKeyTarget(r.Table, column) != "" {
+		value, ok = promptPlanValue(reader, r, column)
+	} else {
+		value, useDefault, ok = promptInsertValue(reader, r.Table, column, r.Columns, r.Values)
+	}
+	if !ok {
+		return false
+	}
+	if useDefault {
+		fmt.Fprintln(stdout, msg("insert.default_used", column))
+		return true
+	}
+	r.add(column, value)
+	return true
+}
+
+// Функция для получения записей в порядке добавления: родительские раньше дочерних
+func productPlan(record *productRecord, plan []*productRecord) []*productRecord {
+	for _, parent := range record.Parents {
+		plan = productPlan(parent.Record, plan)
+	}
+	return append(plan, record)
+}
+
+// Функция для ввода записи одного уровня. allowExisting разрешает выбрать существующую запись,
+// nested — создавать новые записи для внешних ключей (иначе значение выбирается из списка).
+// fixed задает колонки, значения которых берутся из записей предыдущих уровней.
+func promptProductRecord(reader *bufio.Reader, table TableInfo, allowExisting, nested bool, fixed map[string]*productRecord) (*productRecord, bool) {
+	fmt.Fprintln(stdout, msg("product.level", table.Name))
+	if allowExisting {
+		fmt.Fprintln(stdout, msg("product.pick_existing"))
+		fmt.Fprintln(stdout, msg("product.create_new"))
+		fmt.Fprintln(stdout, msg("common.back"))
+		choice, ok := promptInt(reader, msg("product.choice_prompt"), 0, 2)
+		if !ok || choice == 0 {
+			return nil, false
+		}
+		if choice == 1 {
+			id, ok := pickForeignKey(reader, table.Name)
+			if !ok {
+				return nil, false
+			}
+			return &productRecord{Table: table, ExistingID: id}, true
+		}
+	}
+
+	record := &productRecord{Table: table}
+	for _, column := range editableColumns(table, insertableColumns(table)) {
+		parent, isFixed := fixed[column]
+		if refTable := foreignKeyTarget(table, column); !isFixed && refTable != "" {
+			refInfo, found := findTable(refTable)
+			if !nested || !found {
+				if !record.promptColumn(reader, column) {
+					return nil, false
+				}
+				continue
+			}
+			nestedRecord, ok := promptProductRecord(reader, refInfo, true, false, nil)
+			if !ok {
+				return nil, false
+			}
+			parent = nestedRecord
+			fmt.Fprintln(stdout, msg("product.level", table.Name))
+		}
+
+		if parent != nil {
+			record.addParent(column, parent)
+			continue
+		}
+
+		if !record.promptColumn(reader, column) {
+			return nil, false
+		}
+	}
+	if !requireInsertColumns(table, record.Columns) {
+		return nil, false
+	}
+
+	// Вместо новой записи можно использовать уже существующую похожую запись
+	action, reuseID := confirmDuplicates(reader, table, record.Columns, record.Values, allowExisting)
+	switch action {
+	case duplicateCancel:
+		return nil, false
+	case duplicateReuse:
+		fmt.Fprintln(stdout, msg("product.reused", table.Name, reuseID))
+		return &productRecord{Table: table, ExistingID: reuseID}, true
+	}
+	if !confirmRowRules(reader, table.Name, "insert", []ruleRow{newRuleRow(record.Columns, record.Values)}) {
+		return nil, false
+	}
+	return record, true
+}
+
+// Функция для вывода итогового плана полного ввода
+func printProductPlan(plan []*productRecord) {
+	fmt.Fprintln(stdout, msg("product.plan_title"))
+	for _, record := range plan {
+		if record.ExistingID != "" {
+			fmt.Fprintln(stdout, msg("product.plan_existing", record.Table.Name, record.ExistingID))
+			continue
+		}
+		masked := maskParams(record.Columns, record.Values)
+		pairs := make([]string, len(record.Columns))
+		for i, column := range record.Columns {
+			pairs[i] = fmt.Sprintf("%s=%v", column, masked[i])
+		}
+		for _, parent := range record.Parents {
+			pairs[parent.Index] = msg("product.plan_parent", record.Columns[parent.Index], parent.Record.Table.Name)
+		}
+		fmt.Fprintln(stdout, msg("product.plan_new", record.Table.Name, strings.Join(pairs, ", ")))
+	}
+}
+
+// Функция для вывода запросов плана без выполнения (пробный режим)
+func printPlanDryRun(plan []*productRecord) {
+	for _, record := range plan {
+		if record.ExistingID == "" {
+			printDryRun(record.insertQuery(), record.Columns, record.Values)
+		}
+	}
+}
+
+// Функция для добавления новых записей плана одной транзакцией: id каждой добавленной записи
+// подставляется во внешние ключи следующих. Возвращает шаги отмены добавленных записей.
+func insertRecordPlan(plan []*productRecord, label string) ([]undoStep, error) {
+	var undoSteps []undoStep
+	err := dbTransaction(func(tx *sql.Tx) error {
+		undoSteps = nil
+		for _, record := range plan {
+			if record.ExistingID != "" {
+				continue
+			}
+			query, values := record.insertQuery(), record.insertValues()
+			logToFileAndScreen(fmt.Sprintf("%s: %s с параметрами %v", label, query, maskParams(record.Columns, values)))
+			ids, err := insertChunk(tx, record.Table.Name, tableKeyColumn(record.Table.Name), query, values, 1)
+			if err != nil {
+				return fmt.Errorf("%s: %w", record.Table.Name, err)
+			}
+			if len(ids) != 1 {
+				return errors.New(msg("product.no_id", record.Table.Name))
+			}
+			record.InsertedID = ids[0]
+			if err := writeAudit(tx, auditInsert, record.Table.Name, []auditRecord{{ID: ids[0], New: auditValues(record.Columns, values)}}); err != nil {
+				return err
+			}
+			undoSteps = append(undoSteps, undoStep{Table: record.Table.Name, IDs: ids})
+		}
+		return nil
+	})
+	return undoSteps, err
+}
+
+// Функция для ввода значения колонки новой записи: внешний ключ выбирается из связанной таблицы
+func promptPlanValue(reader *bufio.Reader, record *productRecord, column string) (string, bool) {
+	if refTable := foreignKeyTarget(record.Table, column); refTable != "" {
+		fmt.Fprintln(stdout, msg("insert.pick_value", column))
+		return pickForeignKey(reader, refTable)
+	}
+	return promptRecordValue(reader, record.Table, column, record.Columns, record.Values)
+}
+
+// Функция для проверки плана перед добавлением: записи можно добавить, исправить одно поле
+// (повторно вводится только оно) или отменить ввод. Возвращает true при подтверждении.
+func reviewRecordPlan(reader *bufio.Reader, plan []*productRecord) bool {
+	for {
+		printProductPlan(plan)
+		if assumeYes {
+			return true
+		}
+		fmt.Fprintln(stdout, msg("review.execute"))
+		fmt.Fprintln(stdout, msg("review.edit"))
+		fmt.Fprintln(stdout, msg("review.cancel"))
+		choice, ok := promptInt(reader, msg("review.prompt"), 0, 2)
+		if !ok || choice == 0 {
+			return false
+		}
+		if choice == 1 {
+			return true
+		}
+		editPlanField(reader, plan)
+	}
+}
+
+// Функция для исправления одного введенного поля новой записи плана
+func editPlanField(reader *bufio.Reader, plan []*productRecord) {
+	type planField struct {
+		record *productRecord
+		index  int
+	}
+	var fields []planField
+	fmt.Fprintln(stdout, msg("review.fields_title"))
+	for _, record := range plan {
+		if record.ExistingID != "" {
+			continue
+		}
+		masked := maskParams(record.Columns, record.Values)
+		for i, column := range record.Columns {
+			if record.parentIndex(i) {
+				continue
+			}
+			fields = append(fields, planField{record, i})
+			fmt.Fprintf(stdout, "%d. %s.%s = %v\n", len(fields), record.Table.Name, column, masked[i])
+		}
+	}
+	fmt.Fprintln(stdout, msg("common.back"))
+	choice, ok := promptInt(reader, msg("review.field_prompt"), 0, len(fields))
+	if !ok || choice == 0 {
+		return
+	}
+
+	field := fields[choice-1]
+	record, column := field.record, field.record.Columns[field.index]
+	value, ok := promptPlanValue(reader, record, column)
+	if !ok {
+		return
+	}
+	previous := record.Values[field.index]
+	record.Values[field.index] = value
+	// Исправленная запись снова проверяется правилами; при отказе остается прежнее значение
+	if !confirmRowRules(reader, record.Table.Name, "insert", []ruleRow{newRuleRow(record.Columns, record.Values)}) {
+		record.Values[field.index] = previous
+	}
+}
+
+// Функция для проверки, заполняется ли колонка записи id родительской записи плана
+func (r *productRecord) parentIndex(index int) bool {
+	for _, parent := range r.Parents {
+		if parent.Index == index {
+			return true
+		}
+	}
+	return false
+}
+
+// Пункт 27: Полный ввод нового товара
+func insertProduct(reader *bufio.Reader) {
+	components, okComponents := findTable(productTable)
+	stock, okStock := findTable(productStockTable)
+	if !okComponents || !okStock || foreignKeyTarget(stock, productStockColumn) != productTable {
+		printError(msg("product.unavailable", productTable, productStockTable, productStockColumn))
+		return
+	}
+
+	// До подтверждения в базу ничего не записывается, поэтому отмена на любом шаге ничего не оставляет
+	component, ok := promptProductRecord(reader, components, true, true, nil)
+	if !ok {
+		fmt.Fprintln(stdout, msg("insert.cancelled"))
+		return
+	}
+	stockRow, ok := promptProductRecord(reader, stock, false, false, map[string]*productRecord{productStockColumn: component})
+	if !ok {
+		fmt.Fprintln(stdout, msg("insert.cancelled"))
+		return
+	}
+
+	plan := productPlan(stockRow, nil)
+	printProductPlan(plan)
+	if !promptConfirm(reader, msg("product.confirm", activeConfig.Name)) {
+		fmt.Fprintln(stdout, msg("insert.cancelled"))
+		return
+	}
+
+	if dryRun {
+		printPlanDryRun(plan)
+		fmt.Fprintln(stdout, msg("product.dry_run", dryRunTag))
+		return
+	}
+
+	undoSteps, err := insertRecordPlan(plan, "Полный ввод товара")
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка полного ввода товара, изменения отменены: %v", err))
+		printError(msg("product.failed"))
+		return
+	}
+
+	for _, record := range plan {
+		if record.ExistingID == "" {
+			fmt.Fprintln(stdout, msg("product.inserted", record.Table.Name, record.InsertedID))
+		}
+	}
+	rememberUndo(msg("undo.kind_product"), undoSteps)
+	logToFileAndScreen(fmt.Sprintf("Полный ввод товара: добавлено записей %d", len(undoSteps)))
+}
@@ -0,0 +1,267 @@
+package osl
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Контроль качества после импорта: статистика добавленных строк сравнивается со статистикой
+// данных, которые были в таблице до импорта. Для числовых колонок считаются минимум, максимум
+// и среднее, для текстовых — пустые значения и NULL. Колонки, где новые значения сильно
+// отличаются от прежних, отмечаются с примерами строк файла. Отчет выводится на экран
+// и записывается рядом с файлом импорта как <имя>.quality.txt; импорт при этом не отменяется.
+// Отдельного файла отклоненных строк у импорта нет: он выполняется одной транзакцией,
+// и ошибка в любой строке отменяет его целиком, поэтому отчет строится только для
+// успешного импорта и лежит там же, где исходный файл.
+
+// Количество примеров строк для отмеченной колонки
+const qualityExampleRows = 5
+
+// Статистика колонки до импорта и по добавленным строкам
+type qualityColumn struct {
+	Name    string
+	Numeric bool
+	Index   int // номер значения в строке импорта
+
+	// Данные таблицы до импорта
+	OldRows, OldCount, OldNulls, OldEmpty int
+	OldMin, OldMax, OldAvg, OldStd        float64
+
+	// Добавленные строки
+	Count, Nulls  int
+	Min, Max, Sum float64
+	Outliers      int
+	Examples      []qualityExample
+}
+
+// Строка файла, значение которой отмечено в отчете
+type qualityExample struct {
+	Line  int
+	Value string
+}
+
+// Отчет контроля качества импорта в таблицу
+type qualityReport struct {
+	Table   string
+	Rows    int
+	Columns []*qualityColumn
+	ZScore  float64
+	Ratio   float64
+}
+
+// Функция для получения минимального числа строк импорта, после которого строится отчет
+// (контроль отключается переменной OSL_QUALITY_CHECK=0)
+func qualityMinRows() int {
+	return envInt("OSL_QUALITY_MIN_ROWS", 100)
+}
+
+// Функция для подготовки отчета: выбор колонок и статистика данных таблицы до импорта.
+// Ключ и внешние ключи не проверяются — их значения не имеют смысла как числа.
+// Возвращает nil, если контроль отключен или проверять нечего.
+func newQualityReport(tx *sql.Tx, table TableInfo, columns []string) (*qualityReport, error) {
+	if !envBool("OSL_QUALITY_CHECK", true) {
+		return nil, nil
+	}
+	report := &qualityReport{Table: table.Name,
+		ZScore: envFloat("OSL_QUALITY_ZSCORE", 4), Ratio: envFloat("OSL_QUALITY_RATIO", 10)}
+	selects := []string{"COUNT(*)"}
+	for i, column := range columns {
+		if column == tableKeyColumn(table.Name) || table.ForeignKeys[column] != "" {
+			continue
+		}
+		ref := dialect.QuoteIdent(column)
+		switch {
+		case isNumericColumn(table, column):
+			selects = append(selects, fmt.Sprintf("COUNT(%s), MIN(%s), MAX(%s), AVG(%s), AVG(%s * %s)", ref, ref, ref, ref, ref, ref))
+			report.Columns = append(report.Columns, &qualityColumn{Name: column, Numeric: true, Index: i})
+		case isTextColumn(table, column):
+			selects = append(selects, fmt.Sprintf("SUM(CASE WHEN %s IS NULL THEN 1 ELSE 0 END), SUM(CASE WHEN %s = '' THEN 1 ELSE 0 END)", ref, ref))
+			report.Columns = append(report.Columns, &qualityColumn{Name: column, Index: i})
+		}
+	}
+	if len(report.Columns) == 0 {
+		return nil, nil
+	}
+
+	var rows int
+	dest := []interface{}{&rows}
+	numbers := make([][5]sql.NullFloat64, len(report.Columns))
+	counts := make([][2]sql.NullInt64, len(report.Columns))
+	for i, column := range report.Columns {
+		if column.Numeric {
+			for j := range numbers[i] {
+				dest = append(dest, &numbers[i][j])
+			}
+		} else {
+			dest = append(dest, &counts[i][0], &counts[i][1])
+		}
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(selects, ", "), table.SQLName())
+	if err := txScanRow(tx, query, nil, dest...); err != nil {
+		return nil, fmt.Errorf("статистика таблицы %s: %w", table.Name, err)
+	}
+
+	for i, column := range report.Columns {
+		column.OldRows = rows
+		if column.Numeric {
+			stats := numbers[i]
+			column.OldCount = int(stats[0].Float64)
+			column.OldNulls = rows - column.OldCount
+			column.OldMin, column.OldMax, column.OldAvg = stats[1].Float64, stats[2].Float64, stats[3].Float64
+			// Дисперсия как среднее квадратов минус квадрат среднего
+			if variance := stats[4].Float64 - column.OldAvg*column.OldAvg; variance > 0 {
+				column.OldStd = math.Sqrt(variance)
+			}
+		} else {
+			column.OldNulls, column.OldEmpty = int(counts[i][0].Int64), int(counts[i][1].Int64)
+		}
+	}
+	return report, nil
+}
+
+// Функция для учета добавленной строки файла
+func (r *qualityReport) add(line int, values []interface{}) {
+	r.Rows++
+	for _, column := range r.Columns {
+		value := values[column.Index]
+		if value == nil {
+			column.Nulls++
+			if !column.Numeric {
+				column.addExample(line, "NULL")
+			}
+			continue
+		}
+		if !column.Numeric {
+			continue
+		}
+		number, err := strconv.ParseFloat(fmt.Sprint(value), 64)
+		if err != nil {
+			continue
+		}
+		if column.Count == 0 || number < column.Min {
+			column.Min = number
+		}
+		if column.Count == 0 || number > column.Max {
+			column.Max = number
+		}
+		column.Count++
+		column.Sum += number
+		if r.isOutlier(column, number) {
+			column.Outliers++
+			column.addExample(line, fmt.Sprint(value))
+		}
+	}
+}
+
+// Функция для сохранения примера строки (не больше qualityExampleRows на колонку)
+func (c *qualityColumn) addExample(line int, value string) {
+	if len(c.Examples) < qualityExampleRows {
+		c.Examples = append(c.Examples, qualityExample{Line: line, Value: value})
+	}
+}
+
+// Функция для проверки числа по прежним данным: отклонение от среднего больше порога
+// в стандартных отклонениях или выход за прежний диапазон больше чем в Ratio раз.
+// Без прежних данных (меньше двух значений) сравнивать не с чем.
+func (r *qualityReport) isOutlier(column *qualityColumn, value float64) bool {
+	if column.OldCount < 2 {
+		return false
+	}
+	if column.OldStd > 0 && math.Abs(value-column.OldAvg)/column.OldStd > r.ZScore {
+		return true
+	}
+	return (column.OldMax > 0 && value > column.OldMax*r.Ratio) || (column.OldMin > 0 && value < column.OldMin/r.Ratio)
+}
+
+// Функция для проверки, отмечена ли колонка в отчете
+func (r *qualityReport) flagged(column *qualityColumn) bool {
+	if column.Numeric {
+		if column.Outliers > 0 {
+			return true
+		}
+		return column.Count > 0 && column.OldCount >= 2 && column.OldStd > 0 &&
+			math.Abs(column.Sum/float64(column.Count)-column.OldAvg)/column.OldStd > r.ZScore
+	}
+	if r.Rows == 0 || column.OldRows == 0 {
+		return false
+	}
+	// Доля пустых до импорта считается с добавлением единицы, чтобы она не была нулевой
+	newShare := float64(column.Nulls) / float64(r.Rows)
+	oldShare := float64(column.OldNulls+column.OldEmpty+1) / float64(column.OldRows+1)
+	return newShare > oldShare*r.Ratio
+}
+
+// Функция для форматирования отчета; возвращает текст и количество отмеченных колонок
+func (r *qualityReport) format() (string, int) {
+	var b strings.Builder
+	b.WriteString(msg("quality.title", r.Table, r.Rows) + "\n")
+	flaggedCount := 0
+	for _, column := range r.Columns {
+		mark := "  "
+		if r.flagged(column) {
+			mark = "⚠ "
+			flaggedCount++
+		}
+		var line string
+		switch {
+		case !column.Numeric:
+			line = msg("quality.text", column.Name, column.Nulls, r.Rows, column.OldEmpty, column.OldNulls, column.OldRows)
+		case column.Count == 0:
+			line = msg("quality.numeric_empty", column.Name, column.Nulls)
+		default:
+			line = msg("quality.numeric", column.Name, formatStat(column.Min), formatStat(column.Max),
+				formatStat(column.Sum/float64(column.Count)), column.Nulls)
+			if column.OldCount > 0 {
+				line += " " + msg("quality.numeric_old", formatStat(column.OldMin), formatStat(column.OldMax), formatStat(column.OldAvg))
+			} else {
+				line += " " + msg("quality.no_old_data")
+			}
+		}
+		b.WriteString(mark + line + "\n")
+		if mark == "  " {
+			continue
+		}
+		if column.Numeric {
+			b.WriteString(msg("quality.outliers", column.Outliers) + "\n")
+		}
+		for _, example := range column.Examples {
+			b.WriteString(msg("quality.example", example.Line, example.Value) + "\n")
+		}
+	}
+	if flaggedCount == 0 {
+		b.WriteString(msg("quality.clean") + "\n")
+	} else {
+		b.WriteString(msg("quality.flagged", flaggedCount) + "\n")
+	}
+	return b.String(), flaggedCount
+}
+
+// Функция для записи числа статистики: до двух знаков после запятой без лишних нулей
+func formatStat(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
+
+// Функция для получения пути отчета рядом с файлом импорта: goods.csv -> goods.quality.txt
+func qualityReportPath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".quality.txt"
+}
+
+// Функция для вывода отчета на экран и записи его рядом с файлом импорта
+func showQualityReport(report *qualityReport, path string) {
+	text, flaggedCount := report.format()
+	fmt.Fprint(stdout, text)
+	reportPath := qualityReportPath(path)
+	if err := os.WriteFile(reportPath, []byte(text), 0644); err != nil {
+		printError(msg("quality.write_failed", reportPath, err))
+		logToFileAndScreen(fmt.Sprintf("Не удалось записать отчет контроля качества %s: %v", reportPath, err))
+		return
+	}
+	fmt.Fprintln(stdout, msg("quality.saved", reportPath))
+	logToFileAndScreen(fmt.Sprintf("Контроль качества импорта %s в таблицу %s: строк %d, отмечено колонок %d, отчет %s",
+		path, report.Table, report.Rows, flaggedCount, reportPath))
+}
@@ -0,0 +1,98 @@
+package osl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Отчет после импорта отмечает значения, далекие от прежних данных, с номерами строк файла
+func TestImportQualityReport(t *testing.T) {
+	statements := []string{"CREATE TABLE goods (id INTEGER PRIMARY KEY, name TEXT, price NUMERIC, qty INTEGER)"}
+	for i := 0; i < 50; i++ {
+		statements = append(statements, fmt.Sprintf("INSERT INTO goods (name, price, qty) VALUES ('товар %d', %d, %d)", i, 100+i, 1+i%10))
+	}
+	openSchema(t, []string{"goods"}, statements...)
+	t.Setenv("OSL_QUALITY_MIN_ROWS", "3")
+
+	path := filepath.Join(t.TempDir(), "goods.csv")
+	data := "name,price,qty\nкулер,120,5\nблок,0,3\n,130,1000000\n,125,4\nкорпус,110,2\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	output := captureOutput(t, func() {
+		if err := runImport(tables[0], path); err != nil {
+			t.Errorf("runImport: %v", err)
+		}
+	})
+
+	// Импорт не отменяется
+	if got := queryString(t, "SELECT COUNT(*) FROM goods"); got != "55" {
+		t.Errorf("записей %s, ожидалось 55", got)
+	}
+	for _, want := range []string{
+		"⚠ price: мин 0, макс 130, среднее 97, пустых 0 (до импорта: мин 100, макс 149, среднее 124.5)",
+		"    строка 3: 0",
+		"⚠ qty: мин 2, макс 1000000",
+		"    строка 4: 1000000",
+		"⚠ name: пустых 2 из 5",
+		"    строка 5: NULL",
+		"Отмечено колонок: 3",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("в отчете нет %q:\n%s", want, output)
+		}
+	}
+	report, err := os.ReadFile(filepath.Join(filepath.Dir(path), "goods.quality.txt"))
+	if err != nil {
+		t.Fatalf("файл отчета: %v", err)
+	}
+	if !strings.Contains(output, string(report)) {
+		t.Errorf("файл отчета отличается от вывода:\n%s", report)
+	}
+
+	// Обычные значения не отмечаются, маленький импорт отчета не получает
+	data = "name,price,qty\nкулер,120,5\nблок,140,3\nкорпус,110,2\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	output = captureOutput(t, func() { runImport(tables[0], path) })
+	if !strings.Contains(output, msg("quality.clean")) {
+		t.Errorf("обычные значения отмечены:\n%s", output)
+	}
+	t.Setenv("OSL_QUALITY_MIN_ROWS", "4")
+	output = captureOutput(t, func() { runImport(tables[0], path) })
+	if strings.Contains(output, "КОНТРОЛЬ КАЧЕСТВА") {
+		t.Errorf("отчет для импорта меньше порога:\n%s", output)
+	}
+}
+
+// Отчет записывается рядом с файлом импорта; отмененный импорт отчета не оставляет
+func TestQualityReportPath(t *testing.T) {
+	for _, tt := range []struct{ path, want string }{
+		{"goods.csv", "goods.quality.txt"},
+		{filepath.Join("import", "data.v2.csv"), filepath.Join("import", "data.v2.quality.txt")},
+		{"goods", "goods.quality.txt"},
+	} {
+		if got := qualityReportPath(tt.path); got != tt.want {
+			t.Errorf("qualityReportPath(%q) = %q, ожидалось %q", tt.path, got, tt.want)
+		}
+	}
+
+	openSchema(t, []string{"goods"}, "CREATE TABLE goods (id INTEGER PRIMARY KEY, name TEXT NOT NULL, qty INTEGER)")
+	t.Setenv("OSL_QUALITY_MIN_ROWS", "1")
+	path := filepath.Join(t.TempDir(), "goods.csv")
+	if err := os.WriteFile(path, []byte("name,qty\nкулер,5\nблок,много\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	captureOutput(t, func() {
+		if err := runImport(tables[0], path); err == nil {
+			t.Error("импорт с некорректной строкой выполнен")
+		}
+	})
+	if _, err := os.Stat(qualityReportPath(path)); !os.IsNotExist(err) {
+		t.Errorf("после отмененного импорта есть отчет: %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package osl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Учет времени запросов к БД. Все обертки над db.Query/Exec выполняют запрос через timedQuery:
+// время попадает в статистику /debug/osl и в журнал, а запросы дольше OSL_SLOW_QUERY_MS
+// записываются как медленные вместе с планом выполнения (для SELECT). Запросы чтения
+// учитываются для предложений индексов (indexes.go).
+
+// Время выполнения последнего запроса (выводится в итоге «Найдено записей»)
+var lastQueryDuration time.Duration
+
+// Предельное время выполнения плана медленного запроса
+const slowQueryExplainTimeout = 5 * time.Second
+
+// Функция для получения порога медленного запроса (OSL_SLOW_QUERY_MS, по умолчанию 1000 мс)
+func slowQueryThreshold() time.Duration {
+	return time.Duration(envInt("OSL_SLOW_QUERY_MS", 1000)) * time.Millisecond
+}
+
+// Функция для выполнения запроса с учетом времени. Параметры $n переводятся в синтаксис
+// текущей СУБД перед вызовом fn, поэтому новые операции получают учет времени без доработок.
+func timedQuery(operation, query string, args []interface{}, fn func(query string, args []interface{}) error) error {
+	if err := checkReadOnlyQuery(query); err != nil {
+		return err
+	}
+	boundQuery, boundArgs := rebind(query, args)
+	start := time.Now()
+	err := fn(boundQuery, boundArgs)
+	duration := time.Since(start)
+
+	recordTiming(operation, duration)
+	recordQueryMetric(operation, err)
+	if err == nil && isReadQuery(query) {
+		observeIndexQuery(query, args)
+	}
+	lastQueryDuration = duration
+	logQueryTiming(query, boundQuery, boundArgs, duration)
+	return err
+}
+
+// Функция для записи времени запроса в журнал; медленный SELECT дополняется планом выполнения
+func logQueryTiming(query, boundQuery string, boundArgs []interface{}, duration time.Duration) {
+	text := strings.Join(strings.Fields(query), " ")
+	elapsed := formatQueryDuration(duration)
+	threshold := slowQueryThreshold()
+	if threshold <= 0 || duration < threshold {
+		logToFileAndScreen(fmt.Sprintf("Запрос выполнен за %s: %s", elapsed, text))
+		return
+	}
+
+	logToFileAndScreen(fmt.Sprintf("[WARN] Медленный запрос: %s (порог %s): %s", elapsed, formatQueryDuration(threshold), text))
+	if !isReadQuery(query) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), slowQueryExplainTimeout)
+	defer cancel()
+	plan, err := explainQuery(ctx, boundQuery, boundArgs, false)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("[WARN] План медленного запроса не получен: %v", err))
+		return
+	}
+	logToFileAndScreen(fmt.Sprintf("[WARN] План медленного запроса:\n%s", plan))
+}
+
+// Функция для получения плана выполнения запроса с теми же параметрами.
+// Запрос выполняется напрямую через db, минуя timedQuery, чтобы не учитывать его повторно.
+func explainQuery(ctx context.Context, boundQuery string, boundArgs []interface{}, analyze bool) (string, error) {
+	rows, err := db.QueryContext(ctx, dialect.ExplainPrefix(analyze)+" "+boundQuery, boundArgs...)
+	if err != nil {
+		return "", err
+	}
+	rs, err := scanRows(rows)
+	rows.Close()
+	if err != nil {
+		return "", err
+	}
+
+	lines := make([]string, len(rs.Rows))
+	for i, row := range rs.Rows {
+		lines[i] = "  " + strings.Join(row, " | ")
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// Функция для вывода длительности с точностью до миллисекунды: "840ms", "1.25s"
+// (запросы быстрее миллисекунды — до микросекунды)
+func formatQueryDuration(duration time.Duration) string {
+	if duration < time.Millisecond {
+		return duration.Round(time.Microsecond).String()
+	}
+	return duration.Round(time.Millisecond).String()
+}
+
+// Функция для вывода итога выборки: количество записей и время последнего запроса
+func printFoundRows(count int) {
+	fmt.Fprintln(stdout, msg("common.found_rows", count, formatQueryDuration(lastQueryDuration)))
+}
@@ -0,0 +1,66 @@
+package osl
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Режим только для чтения (OSL_READONLY=1 или флаг --readonly) для пользователей, которые
+// не должны менять данные: пункты меню, изменяющие данные, скрыты и не выполняются,
+// а слой запросов дополнительно отклоняет всё, кроме SELECT, и не открывает транзакции.
+// Каждая отклоненная попытка записывается в журнал с пометкой [WARN].
+
+// Включен ли режим только для чтения (задается при запуске)
+var readOnly bool
+
+// Пункты главного меню, изменяющие данные, и описание операции для журнала
+var writeMenuItems = map[int]string{
+	3:  "изменение данных",
+	4:  "добавление записи",
+	5:  "добавление в связанные таблицы",
+	14: "изменение остатков",
+	16: "импорт",
+	17: "отмена операции",
+	19: "удаление записей",
+	20: "восстановление записи",
+	21: "перемещение товара",
+	23: "восстановление из SQL-файла",
+	26: "генерация тестовых данных",
+	27: "полный ввод товара",
+	32: "обслуживание индексов",
+}
+
+// Ошибка запроса, отклоненного в режиме только для чтения
+var errReadOnly = errors.New("режим только для чтения: изменение данных запрещено")
+
+// Функция для проверки, доступен ли пункт меню в текущем режиме
+func menuItemAllowed(item int) bool {
+	return !readOnly || writeMenuItems[item] == ""
+}
+
+// Функция для вывода пункта меню, если он доступен в текущем режиме
+func printMenuItem(item int, key string) {
+	if menuItemAllowed(item) {
+		fmt.Fprintln(stdout, msg(key))
+	}
+}
+
+// Функция для записи в журнал попытки изменить данные в режиме только для чтения
+func warnReadOnly(operation string) {
+	logToFileAndScreen(fmt.Sprintf("[WARN] Режим только для чтения: отклонена операция «%s»", operation))
+}
+
+// Функция для отказа в выполнении пункта меню, изменяющего данные
+func rejectMenuItem(item int) {
+	warnReadOnly(writeMenuItems[item])
+	printError(msg("readonly.blocked"))
+}
+
+// Функция для проверки запроса перед выполнением: в режиме только для чтения разрешен только SELECT
+func checkReadOnlyQuery(query string) error {
+	if !readOnly || isReadQuery(query) {
+		return nil
+	}
+	warnReadOnly(firstKeyword(query))
+	return errReadOnly
+}
@@ -0,0 +1,59 @@
+package osl
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Заменитель скрытых значений в логах
+const redactedValue = "***"
+
+var (
+	// Секреты текущего сеанса (например, пароль), которые не должны попадать в лог
+	secrets []string
+
+	// Параметр password=... в строке подключения
+	dsnPasswordRegex = regexp.MustCompile(`(?i)(password=)('(?:[^'\\]|\\.)*'|\S+)`)
+)
+
+// Функция для регистрации секрета, который будет вырезаться из лога
+func registerSecret(secret string) {
+	if secret != "" && !containsString(secrets, secret) {
+		secrets = append(secrets, secret)
+	}
+}
+
+// Функция для удаления секретов из сообщения перед записью в лог
+func redactSecrets(message string) string {
+	message = dsnPasswordRegex.ReplaceAllString(message, "${1}"+redactedValue)
+	for _, secret := range secrets {
+		message = strings.ReplaceAll(message, secret, redactedValue)
+	}
+	return message
+}
+
+// Функция для получения списка чувствительных колонок (OSL_SENSITIVE_COLUMNS через запятую)
+func sensitiveColumns() map[string]bool {
+	result := make(map[string]bool)
+	for _, name := range strings.Split(envString("OSL_SENSITIVE_COLUMNS", "password,passwd,secret,token"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" {
+			result[name] = true
+		}
+	}
+	return result
+}
+
+// Функция для маскирования значений чувствительных колонок в параметрах запроса.
+// columns[i] — имя колонки, к которой относится values[i] (пустая строка — не колонка).
+func maskParams(columns []string, values []interface{}) []interface{} {
+	sensitive := sensitiveColumns()
+	masked := make([]interface{}, len(values))
+	for i, value := range values {
+		masked[i] = value
+		if i < len(columns) && sensitive[strings.ToLower(columns[i])] {
+			masked[i] = redactedValue
+		}
+	}
+	return masked
+}
@@ -0,0 +1,64 @@
+package osl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"testing"
+)
+
+// Пароль не попадает ни в журнал, ни на экран: при подключении, при ошибке подключения
+// и при восстановлении соединения
+func TestPasswordNeverLogged(t *testing.T) {
+	const password = "S3cr3t-пароль"
+	openTestDB(t)
+	t.Setenv("DB_WAIT_TIMEOUT", "0")
+	t.Setenv("OSL_QUERY_RETRIES", "1")
+	t.Setenv("OSL_QUERY_RETRY_BACKOFF", "1ms")
+	var logged bytes.Buffer
+	savedConfig, savedSecrets, testDB := activeConfig, secrets, db
+	t.Cleanup(func() {
+		testDB.Close()
+		log.SetOutput(io.Discard)
+		logToStdout = true
+		activeConfig, secrets = savedConfig, savedSecrets
+	})
+
+	// Сервер по этому адресу не отвечает
+	config := DBConfig{Driver: "postgres", Host: "127.0.0.1", Port: "1", Name: "pc", User: "admin",
+		Password: password, SSLMode: "disable"}
+	var output bytes.Buffer
+	err := Run(context.Background(), Options{DB: config, LogTarget: logTargetStdout}, strings.NewReader(""), &output, &output)
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 {
+		t.Errorf("Run = %v, ожидалась ошибка подключения", err)
+	}
+	screen := output.String()
+	if !strings.Contains(screen, "password="+redactedValue) {
+		t.Errorf("строка подключения не записана в журнал:\n%s", screen)
+	}
+
+	// Восстановление соединения: журнал в буфер, ошибки дублируются на экран
+	log.SetOutput(&logged)
+	logToStdout = false
+	activeConfig = config
+	db.Close()
+	screen += captureOutput(t, func() {
+		if reconnect() {
+			t.Error("соединение восстановлено с недоступным сервером")
+		}
+		printError("ошибка подключения: " + postgresDialect{}.DSN(config))
+	})
+
+	if !strings.Contains(logged.String(), "Соединение с БД не восстановлено") {
+		t.Errorf("ошибка восстановления соединения не записана в журнал:\n%s", logged.String())
+	}
+	for name, text := range map[string]string{"экран": screen, "журнал": logged.String()} {
+		if strings.Contains(text, password) {
+			t.Errorf("пароль выведен (%s):\n%s", name, text)
+		}
+	}
+}
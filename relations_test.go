@@ -0,0 +1,53 @@
+package osl
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Связи основных таблиц берутся из внешних ключей
+func TestSampleSchemaRelations(t *testing.T) {
+	openBaseSchema(t, "INSERT INTO manufacturers (name) VALUES ('Intel')")
+	assumeYes = true
+
+	want := []tableRelation{
+		{Parent: "categories", Child: "components", Column: "category_id"},
+		{Parent: "manufacturers", Child: "components", Column: "manufacturer_id"},
+		{Parent: "components", Child: "stock", Column: "component_id"},
+	}
+	if !reflect.DeepEqual(relatedTables, want) {
+		t.Fatalf("связи %v, ожидалось %v", relatedTables, want)
+	}
+	if got := relatedTables[2].String(); got != "components → stock.component_id" {
+		t.Errorf("название связи %q", got)
+	}
+
+	// Добавление по связи categories → components: ключ новой категории подставляется в запись компонента
+	output := captureOutput(t, func() {
+		insertRelatedData(scriptReader("1", "1", "Процессоры", "ЦП", "Core i5", "1", "BX8070", "150"))
+	})
+	got := queryString(t, `SELECT c.name || '/' || k.name || '/' || m.name FROM components c
+		JOIN categories k ON k.id = c.category_id JOIN manufacturers m ON m.id = c.manufacturer_id`)
+	if got != "Core i5/Процессоры/Intel" {
+		t.Errorf("добавлено %q:\n%s", got, output)
+	}
+}
+
+// Связи произвольной схемы: таблица со ссылками на две таблицы и ссылка на неизвестную таблицу
+func TestDiscoveredRelations(t *testing.T) {
+	openSchema(t, []string{"customers", "orders", "items"},
+		"CREATE TABLE customers (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, customer_id INTEGER REFERENCES customers(id))",
+		`CREATE TABLE items (id INTEGER PRIMARY KEY, product_id INTEGER REFERENCES products(id),
+			order_id INTEGER REFERENCES orders(id), qty INTEGER)`)
+
+	// Таблица products программе не известна, поэтому связи с ней нет
+	want := []tableRelation{
+		{Parent: "customers", Child: "orders", Column: "customer_id"},
+		{Parent: "orders", Child: "items", Column: "order_id"},
+	}
+	if !reflect.DeepEqual(relatedTables, want) {
+		t.Errorf("связи %v, ожидалось %v", relatedTables, want)
+	}
+}
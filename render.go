@@ -0,0 +1,626 @@
+package osl
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// Структура для результата запроса, подготовленного к выводу
+type ResultSet struct {
+	Columns []string
+	Types   []string   // имена типов колонок в БД
+	Rows    [][]string // текстовое представление значений в том виде, как его вернул драйвер
+}
+
+// Типы, которые умеет отображать форматтер
+var renderableTypes = map[string]bool{
+	"INT2": true, "INT4": true, "INT8": true, "NUMERIC": true, "FLOAT4": true, "FLOAT8": true,
+	"TEXT": true, "VARCHAR": true, "BPCHAR": true, "CHAR": true, "NAME": true, "UUID": true,
+	"BOOL": true, "DATE": true, "TIME": true, "TIMETZ": true, "TIMESTAMP": true, "TIMESTAMPTZ": true,
+	"INTERVAL": true, "JSON": true, "JSONB": true, "MONEY": true, "": true,
+	// Имена типов SQLite и MySQL
+	"INTEGER": true, "INT": true, "BIGINT": true, "SMALLINT": true, "TINYINT": true, "MEDIUMINT": true,
+	"DECIMAL": true, "REAL": true, "DOUBLE": true, "FLOAT": true, "BOOLEAN": true, "DATETIME": true, "YEAR": true,
+}
+
+// Числовые типы: такие колонки выравниваются по правому краю
+var numericTypes = map[string]bool{
+	"INT2": true, "INT4": true, "INT8": true, "NUMERIC": true, "FLOAT4": true, "FLOAT8": true, "MONEY": true,
+	"INTEGER": true, "INT": true, "BIGINT": true, "SMALLINT": true, "TINYINT": true, "MEDIUMINT": true,
+	"DECIMAL": true, "REAL": true, "DOUBLE": true, "FLOAT": true,
+}
+
+// Денежные колонки выводятся с двумя знаками после запятой, разделителем тысяч
+// и символом валюты из CURRENCY (только на экране, в БД и журнале значения не меняются)
+var moneyColumns = map[string]bool{"price": true, "total_value": true}
+
+// Форматирование денежных колонок, переключается до конца сеанса (OSL_MONEY_FORMAT — начальное значение)
+var moneyFormatting = envBool("OSL_MONEY_FORMAT", true)
+
+// Функция для проверки, умеет ли форматтер отображать тип колонки
+func isRenderableType(dbType string) bool {
+	return renderableTypes[strings.ToUpper(dbType)]
+}
+
+// Функция для получения имени типа колонки результата без размера: SQLite возвращает тип
+// так, как он объявлен в CREATE TABLE (varchar(100), NUMERIC(10, 2)), PostgreSQL и MySQL — без размера
+func columnTypeName(columnType *sql.ColumnType) string {
+	name, _, _ := strings.Cut(columnType.DatabaseTypeName(), "(")
+	return strings.ToUpper(strings.TrimSpace(name))
+}
+
+// Функция для чтения всех строк результата в виде текстовых ячеек.
+// Значения любых типов читаются как есть, поэтому одна «экзотическая» колонка не ломает весь результат.
+func scanRows(rows *sql.Rows) (*ResultSet, error) {
+	rs, err := newResultSet(rows)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		if rowData, ok := rs.scanRow(rows); ok {
+			rs.Rows = append(rs.Rows, rowData)
+		}
+	}
+	return rs, rows.Err()
+}
+
+// Функция для создания пустого результата с колонками и типами запроса
+func newResultSet(rows *sql.Rows) (*ResultSet, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	rs := &ResultSet{Columns: columns, Types: make([]string, len(columns)), Rows: [][]string{}}
+	if columnTypes, err := rows.ColumnTypes(); err == nil {
+		for i, columnType := range columnTypes {
+			rs.Types[i] = columnTypeName(columnType)
+		}
+	}
+	return rs, nil
+}
+
+// Функция для чтения текущей строки результата в текстовые ячейки.
+// Строка, которую не удалось прочитать, пропускается с записью в журнал.
+func (rs *ResultSet) scanRow(rows *sql.Rows) ([]string, bool) {
+	rowData, _, ok := rs.scanRowValues(rows)
+	return rowData, ok
+}
+
+// Функция для чтения текущей строки результата: текстовые ячейки и значения в том виде,
+// как их вернул драйвер (нужны там, где значение снова передается в запрос)
+func (rs *ResultSet) scanRowValues(rows *sql.Rows) ([]string, []interface{}, bool) {
+	values := make([]interface{}, len(rs.Columns))
+	valuePtrs := make([]interface{}, len(rs.Columns))
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+
+	if err := rows.Scan(valuePtrs...); err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка чтения строки: %v", err))
+		return nil, nil, false
+	}
+
+	rowData := make([]string, len(rs.Columns))
+	for i, val := range values {
+		rowData[i] = formatRawValue(val, rs.Types[i])
+	}
+	return rowData, values, true
+}
+
+// Функция для получения текстового представления значения, полученного от драйвера
+func formatRawValue(val interface{}, dbType string) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case []byte:
+		if strings.EqualFold(dbType, "BYTEA") {
+			return "\\x" + hex.EncodeToString(v)
+		}
+		return string(v)
+	case time.Time:
+		// Без типа колонки (выгрузка) значение выводится как есть
+		if dbType == "" {
+			return fmt.Sprintf("%v", v)
+		}
+		return formatTimeValue(v, dbType)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// Функция для получения отображаемого значения ячейки
+func (rs *ResultSet) displayValue(row, col int) string {
+	if !isRenderableType(rs.Types[col]) {
+		if rs.Rows[row][col] == "" {
+			return ""
+		}
+		return msg("render.type_placeholder", strings.ToLower(rs.Types[col]))
+	}
+	if moneyFormatting && moneyColumns[strings.ToLower(rs.Columns[col])] {
+		return formatMoney(rs.Rows[row][col])
+	}
+	// Логические значения выводятся словами независимо от того, как их вернул драйвер
+	if booleanTypes[strings.ToUpper(rs.Types[col])] {
+		if value, ok := parseBoolValue(rs.Rows[row][col]); ok {
+			return yesNo(value)
+		}
+	}
+	return rs.Rows[row][col]
+}
+
+// Функция для проверки, выравнивается ли колонка по правому краю
+func (rs *ResultSet) isNumeric(col int) bool {
+	return numericTypes[strings.ToUpper(rs.Types[col])]
+}
+
+// Функция для получения разделителя тысяч (PRICE_THOUSANDS_SEP: space — пробел, none — без разделителя)
+func thousandsSeparator() string {
+	switch sep := envString("PRICE_THOUSANDS_SEP", "space"); strings.ToLower(sep) {
+	case "space":
+		return " "
+	case "none":
+		return ""
+	default:
+		return sep
+	}
+}
+
+// Функция для форматирования денежного значения: два знака после запятой и разделитель тысяч.
+// Значение, которое не является числом, возвращается без изменений.
+func formatMoney(value string) string {
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value
+	}
+	formatted := strconv.FormatFloat(number, 'f', 2, 64)
+	sign := ""
+	if strings.HasPrefix(formatted, "-") {
+		sign, formatted = "-", formatted[1:]
+	}
+	integer, fraction := formatted[:len(formatted)-3], formatted[len(formatted)-3:]
+
+	sep := thousandsSeparator()
+	var grouped strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteString(sep)
+		}
+		grouped.WriteRune(digit)
+	}
+	result := sign + grouped.String() + fraction
+	if currency := envString("CURRENCY", ""); currency != "" {
+		result += " " + currency
+	}
+	return result
+}
+
+// Функция для проверки наличия колонок, которые выводятся заглушкой
+func (rs *ResultSet) hasUnrenderable() bool {
+	for _, dbType := range rs.Types {
+		if !isRenderableType(dbType) {
+			return true
+		}
+	}
+	return false
+}
+
+// Максимальная ширина колонки, выбранная в сеансе (0 — из MAX_COL_WIDTH)
+var columnWidthLimit int
+
+// Функция для получения максимальной ширины колонки при выводе: выбранная в сеансе
+// или MAX_COL_WIDTH (по умолчанию 40)
+func maxColumnWidth() int {
+	if columnWidthLimit > 0 {
+		return columnWidthLimit
+	}
+	width := envInt("MAX_COL_WIDTH", 40)
+	if width < 1 {
+		return 40
+	}
+	return width
+}
+
+// Функция для обрезки значения до ширины колонки с многоточием в конце (по символам, а не байтам)
+func truncateCell(value string, width int) string {
+	if utf8.RuneCountInString(value) <= width {
+		return value
+	}
+	if width < 1 {
+		return ""
+	}
+	runes := []rune(value)
+	return string(runes[:width-1]) + "…"
+}
+
+// Режимы вывода результатов
+const (
+	displayTable    = "table"    // таблица
+	displayVertical = "vertical" // каждая запись блоком "колонка: значение" (как \G в MySQL)
+	displayAuto     = "auto"     // по записям, если таблица не помещается по ширине терминала
+)
+
+// Режим вывода результатов, запоминается до конца сеанса
+var displayMode = displayModeFromEnv()
+
+// Функция для чтения режима вывода из OSL_DISPLAY или DISPLAY (по умолчанию auto).
+// DISPLAY учитывается, только если в ней режим вывода, а не адрес X-сервера.
+func displayModeFromEnv() string {
+	for _, name := range []string{"OSL_DISPLAY", "DISPLAY"} {
+		switch mode := strings.ToLower(envString(name, "")); mode {
+		case displayTable, displayVertical, displayAuto:
+			return mode
+		}
+	}
+	return displayAuto
+}
+
+// Ширина вывода, если размер терминала определить не удалось (вывод в файл или канал)
+const defaultTerminalWidth = 80
+
+// Минимальная ширина колонки при сжатии таблицы под ширину терминала
+const minFittedColumnWidth = 3
+
+// Функция для получения ширины терминала: размер окна, затем COLUMNS, иначе defaultTerminalWidth
+func terminalWidth() int {
+	if file, ok := stdout.(*os.File); ok {
+		if width, _, err := term.GetSize(int(file.Fd())); err == nil && width > 0 {
+			return width
+		}
+	}
+	if width := envInt("COLUMNS", 0); width > 0 {
+		return width
+	}
+	return defaultTerminalWidth
+}
+
+// Функция для сжатия таблицы до ширины терминала: по одному символу урезается самая широкая
+// колонка, пока таблица не поместится (но не уже minFittedColumnWidth)
+func fitColumnWidths(columnWidths []int, width int) []int {
+	fitted := append([]int(nil), columnWidths...)
+	for tableWidth(fitted) > width {
+		widest := 0
+		for i := range fitted {
+			if fitted[i] > fitted[widest] {
+				widest = i
+			}
+		}
+		if fitted[widest] <= minFittedColumnWidth {
+			break
+		}
+		fitted[widest]--
+	}
+	return fitted
+}
+
+// Функция для получения ширины таблицы с разделителями " | "
+func tableWidth(columnWidths []int) int {
+	width := 0
+	for i, w := range columnWidths {
+		if i > 0 {
+			width += 3
+		}
+		width += w
+	}
+	return width
+}
+
+// Функция для выбора вывода по записям: явно выбранный режим или авто, когда таблица шире терминала
+func useVertical(columnWidths []int) bool {
+	switch displayMode {
+	case displayVertical:
+		return true
+	case displayAuto:
+		return tableWidth(columnWidths) > terminalWidth()
+	}
+	return false
+}
+
+// Функция для выбора режима вывода (Enter — оставить текущий).
+// Пункт 3 переключает форматирование цен, пункт 5 задает максимальную ширину колонки;
+// после них снова предлагается выбор. Возвращает false при отмене.
+func promptDisplayMode(reader *bufio.Reader) bool {
+	modes := map[string]string{"1": displayTable, "2": displayVertical, "4": displayAuto}
+	for {
+		current := msg("render.mode_" + displayMode)
+		moneyToggle := msg("render.money_raw")
+		if !moneyFormatting {
+			moneyToggle = msg("render.money_formatted")
+		}
+		input, ok := promptValidated(reader, msg("render.mode_prompt", moneyToggle, maxColumnWidth(), current),
+			func(input string) error {
+				if _, ok := modes[input]; input != "" && input != "3" && input != "5" && !ok {
+					return errors.New(msg("render.mode_invalid"))
+				}
+				return nil
+			})
+		if !ok {
+			return false
+		}
+		if input == "3" {
+			moneyFormatting = !moneyFormatting
+			continue
+		}
+		if input == "5" {
+			width, ok := promptInt(reader, msg("render.width_prompt", maxColumnWidth()), minFittedColumnWidth, maxPromptInt)
+			if !ok {
+				return false
+			}
+			columnWidthLimit = width
+			continue
+		}
+		if mode, ok := modes[input]; ok {
+			displayMode = mode
+		}
+		return true
+	}
+}
+
+// Функция для вывода результата в выбранном режиме
+func printResult(rs *ResultSet) {
+	if useVertical(tableColumnWidths(rs)) {
+		printVertical(rs)
+		return
+	}
+	printTable(rs)
+}
+
+// Функция для проверки, обрезаны ли значения при выводе результата таблицей
+func (rs *ResultSet) hasTruncatedCells() bool {
+	widths := tableColumnWidths(rs)
+	if useVertical(widths) {
+		return false
+	}
+	widths = fitColumnWidths(widths, terminalWidth())
+	for r := range rs.Rows {
+		for i := range rs.Columns {
+			if utf8.RuneCountInString(rs.displayValue(r, i)) > widths[i] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Функция для вывода каждой записи отдельным блоком пар "колонка: значение"
+func printVertical(rs *ResultSet) {
+	labelWidth := verticalLabelWidth(rs)
+	for r := range rs.Rows {
+		printVerticalRecord(rs, r, r+1, labelWidth)
+	}
+}
+
+// Функция для получения ширины колонки названий при выводе по записям (в символах, а не байтах)
+func verticalLabelWidth(rs *ResultSet) int {
+	labelWidth := 0
+	for _, col := range rs.Columns {
+		if width := visibleWidth(col); width > labelWidth {
+			labelWidth = width
+		}
+	}
+	return labelWidth
+}
+
+// Функция для вывода одной записи блоком пар "колонка: значение"
+func printVerticalRecord(rs *ResultSet, row, number, labelWidth int) {
+	fmt.Fprintln(stdout, msg("render.record_title", number))
+	for i, col := range rs.Columns {
+		fmt.Fprintf(stdout, "%s: %s\n", padRight(col, labelWidth), rs.displayValue(row, i))
+	}
+}
+
+// Функция для вывода строк в виде выровненной таблицы
+func printTable(rs *ResultSet) {
+	columnWidths := fitColumnWidths(tableColumnWidths(rs), terminalWidth())
+	printTableHeader(rs, columnWidths)
+	for r := range rs.Rows {
+		printTableRow(rs, r, r, columnWidths)
+	}
+}
+
+// Функция для вычисления ширины колонок по заголовкам и значениям (не больше MAX_COL_WIDTH)
+func tableColumnWidths(rs *ResultSet) []int {
+	maxWidth := maxColumnWidth()
+
+	// Определяем ширину для каждой колонки, но не больше maxWidth
+	columnWidths := make([]int, len(rs.Columns))
+	for i, col := range rs.Columns {
+		columnWidths[i] = utf8.RuneCountInString(col)
+	}
+	for r := range rs.Rows {
+		for i := range rs.Columns {
+			if width := utf8.RuneCountInString(rs.displayValue(r, i)); width > columnWidths[i] {
+				columnWidths[i] = width
+			}
+		}
+	}
+	for i := range columnWidths {
+		if columnWidths[i] > maxWidth {
+			columnWidths[i] = maxWidth
+		}
+	}
+	return columnWidths
+}
+
+// Функция для вывода заголовков и разделительной линии таблицы
+func printTableHeader(rs *ResultSet, columnWidths []int) {
+	// Вывод заголовков с выравниванием (заголовки всегда по левому краю)
+	headerParts := make([]string, len(rs.Columns))
+	for i, col := range rs.Columns {
+		headerParts[i] = bold(padRight(truncateCell(col, columnWidths[i]), columnWidths[i]))
+	}
+	fmt.Fprintln(stdout, "\n" + strings.Join(headerParts, " | "))
+
+	// Вывод разделительной линии
+	dividerParts := make([]string, len(rs.Columns))
+	for i, width := range columnWidths {
+		dividerParts[i] = strings.Repeat("-", width)
+	}
+	fmt.Fprintln(stdout, dim(strings.Join(dividerParts, "-+-")))
+}
+
+// Функция для вывода строки таблицы: числа по правому краю, остальное по левому.
+// number — порядковый номер строки в выводе (с нуля), по нему чередуется фон строк.
+func printTableRow(rs *ResultSet, r, number int, columnWidths []int) {
+	rowParts := make([]string, len(rs.Columns))
+	for i := range rs.Columns {
+		cell := truncateCell(rs.displayValue(r, i), columnWidths[i])
+		if rs.isNumeric(i) {
+			rowParts[i] = padLeft(cell, columnWidths[i])
+		} else {
+			rowParts[i] = padRight(cell, columnWidths[i])
+		}
+	}
+	line := strings.Join(rowParts, " | ")
+	if zebraRow(len(rs.Columns), number) {
+		line = colorize(ansiZebra, line)
+	}
+	fmt.Fprintln(stdout, line)
+}
+
+// Функция для получения числа записей, начиная с которого результат выводится потоком
+func streamThreshold() int {
+	return envInt("STREAM_ROWS_THRESHOLD", 10000)
+}
+
+// Функция для вывода результата по мере чтения строк, без накопления в памяти.
+// Ширина колонок задается STREAM_COL_WIDTH или вычисляется по первым STREAM_SAMPLE_ROWS строкам,
+// поэтому более длинные значения дальше обрезаются. Возвращает количество выведенных записей.
+func streamResult(rows *sql.Rows) (int, error) {
+	rs, err := newResultSet(rows)
+	if err != nil {
+		return 0, err
+	}
+
+	// Первые строки читаются заранее, чтобы подобрать ширину колонок
+	sampleSize := envInt("STREAM_SAMPLE_ROWS", 100)
+	for len(rs.Rows) < sampleSize && rows.Next() {
+		if rowData, ok := rs.scanRow(rows); ok {
+			rs.Rows = append(rs.Rows, rowData)
+		}
+	}
+
+	columnWidths := tableColumnWidths(rs)
+	if width := envInt("STREAM_COL_WIDTH", 0); width > 0 {
+		for i := range columnWidths {
+			columnWidths[i] = width
+		}
+	}
+	labelWidth := verticalLabelWidth(rs)
+	vertical := useVertical(columnWidths)
+
+	count := 0
+	printRow := func(r int) {
+		count++
+		if vertical {
+			printVerticalRecord(rs, r, count, labelWidth)
+		} else {
+			printTableRow(rs, r, count-1, columnWidths)
+		}
+	}
+
+	if !vertical {
+		columnWidths = fitColumnWidths(columnWidths, terminalWidth())
+		printTableHeader(rs, columnWidths)
+	}
+	for r := range rs.Rows {
+		printRow(r)
+	}
+
+	// Остальные строки выводятся сразу; в памяти хранится только текущая
+	rs.Rows = rs.Rows[:0]
+	for rows.Next() {
+		if rowData, ok := rs.scanRow(rows); ok {
+			rs.Rows = append(rs.Rows[:0], rowData)
+			printRow(0)
+		}
+	}
+	return count, rows.Err()
+}
+
+// Функция для просмотра исходных значений строки целиком, если в результате есть заглушки типов
+// или значения, обрезанные до ширины колонки
+func offerRawDetails(reader *bufio.Reader, rs *ResultSet) {
+	if len(rs.Rows) == 0 {
+		return
+	}
+	prompt := msg("render.raw_prompt", len(rs.Rows))
+	if !rs.hasUnrenderable() {
+		if !rs.hasTruncatedCells() {
+			return
+		}
+		prompt = msg("render.truncated_prompt", len(rs.Rows))
+	}
+
+	input, ok := promptValidated(reader, prompt,
+		func(input string) error {
+			if input == "" {
+				return nil
+			}
+			if row, err := strconv.Atoi(input); err != nil || row < 1 || row > len(rs.Rows) {
+				return errors.New(msg("input.choose_range", 1, len(rs.Rows)))
+			}
+			return nil
+		})
+	if !ok || input == "" {
+		return
+	}
+	row, _ := strconv.Atoi(input)
+
+	fmt.Fprintln(stdout, msg("render.record_title", row))
+	for i, col := range rs.Columns {
+		fmt.Fprintf(stdout, "%s (%s): %s\n", col, strings.ToLower(rs.Types[i]), rs.Rows[row-1][i])
+	}
+}
+
+// Функция для выбора подмножества колонок для вывода.
+// Возвращает выбранные колонки в порядке ввода и false при отмене.
+func selectColumnSubset(reader *bufio.Reader, table TableInfo) ([]string, bool) {
+	fmt.Fprintln(stdout, msg("render.columns_title", table.Name))
+	for i, column := range table.Columns {
+		fmt.Fprintf(stdout, "%d. %s\n", i+1, column)
+	}
+
+	input, ok := promptValidated(reader, msg("render.columns_prompt"),
+		func(input string) error {
+			_, err := parseColumnSubset(table, input)
+			return err
+		})
+	if !ok {
+		return nil, false
+	}
+	selected, _ := parseColumnSubset(table, input)
+	return selected, true
+}
+
+// Функция для разбора списка номеров колонок вида "1,3,5"
+func parseColumnSubset(table TableInfo, input string) ([]string, error) {
+	if input == "" || strings.EqualFold(input, "все колонки") || strings.EqualFold(input, "все") || strings.EqualFold(input, "all") {
+		return table.Columns, nil
+	}
+
+	var selected []string
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(input, ",") {
+		choice, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || choice < 1 || choice > len(table.Columns) {
+			return nil, errors.New(msg("render.columns_invalid", len(table.Columns)))
+		}
+		if seen[choice] {
+			continue
+		}
+		seen[choice] = true
+		selected = append(selected, table.Columns[choice-1])
+	}
+	return selected, nil
+}
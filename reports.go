@@ -0,0 +1,107 @@
+package osl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Пункт 7: Отчёты
+func reportsMenu(reader *bufio.Reader) {
+	fmt.Fprintln(stdout, msg("reports.title"))
+	fmt.Fprintln(stdout, "1. " + msg("reports.stock_by_component"))
+	fmt.Fprintln(stdout, "2. " + msg("reports.stock_by_warehouse"))
+	fmt.Fprintln(stdout, "3. " + msg("reports.low_stock"))
+	fmt.Fprintln(stdout, "4. " + msg("reports.audit"))
+	fmt.Fprintln(stdout, msg("common.back"))
+
+	choice, ok := promptInt(reader, msg("reports.prompt"), 0, 4)
+	if !ok {
+		return
+	}
+
+	switch choice {
+	case 0:
+		return
+	case 1:
+		runReport(msg("reports.stock_by_component"), fmt.Sprintf(
+			`SELECT c.id, c.name, COALESCE(SUM(s.quantity), 0) AS total_quantity,
+			        COALESCE(SUM(s.quantity), 0) * c.price AS total_value
+			 FROM components c
+			 LEFT JOIN stock s ON s.component_id = c.id
+			 GROUP BY c.id, c.name, c.price
+			 ORDER BY c.name%s`, collateSuffix("components", "name")))
+	case 2:
+		runReport(msg("reports.stock_by_warehouse"), fmt.Sprintf(
+			`SELECT s.warehouse_location, COUNT(DISTINCT s.component_id) AS components,
+			        SUM(s.quantity) AS total_quantity,
+			        COALESCE(SUM(s.quantity * c.price), 0) AS total_value
+			 FROM stock s
+			 JOIN components c ON c.id = s.component_id
+			 GROUP BY s.warehouse_location
+			 ORDER BY s.warehouse_location%s`, collateSuffix("stock", "warehouse_location")))
+	case 3:
+		defaultThreshold := envInt("OSL_LOW_STOCK_THRESHOLD", 10)
+		thresholdInput, ok := promptValidated(reader, msg("reports.threshold_prompt", defaultThreshold),
+			func(input string) error {
+				if input == "" {
+					return nil
+				}
+				if n, err := strconv.Atoi(input); err != nil || n < 0 {
+					return errors.New(msg("reports.threshold_invalid"))
+				}
+				return nil
+			})
+		if !ok {
+			return
+		}
+
+		threshold := defaultThreshold
+		if thresholdInput != "" {
+			threshold, _ = strconv.Atoi(thresholdInput)
+		}
+
+		runReport(msg("reports.low_stock_title", threshold), fmt.Sprintf(
+			`SELECT c.id, c.name, COALESCE(SUM(s.quantity), 0) AS total_quantity
+			 FROM components c
+			 LEFT JOIN stock s ON s.component_id = c.id
+			 GROUP BY c.id, c.name
+			 %s
+			 ORDER BY total_quantity, c.name%s`, lowStockCondition, collateSuffix("components", "name")), threshold)
+	case 4:
+		auditReport(reader)
+	}
+}
+
+// Функция для выполнения отчёта и вывода результата в виде таблицы
+func runReport(title, query string, args ...interface{}) {
+	logToFileAndScreen(fmt.Sprintf("Выполнение отчёта '%s': %s с параметрами %v", title, query, args))
+
+	rows, err := dbQuery(query, args...)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка выполнения отчёта: %v", err))
+		printError(msg("reports.failed"))
+		return
+	}
+
+	rs, err := scanRows(rows)
+	rows.Close()
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка чтения результата: %v", err))
+		printError(msg("reports.failed"))
+		return
+	}
+
+	fmt.Fprintf(stdout, "\n=== %s ===\n", strings.ToUpper(title))
+	if len(rs.Rows) == 0 {
+		fmt.Fprintln(stdout, msg("reports.none"))
+		logToFileAndScreen(fmt.Sprintf("Отчёт '%s': записей не найдено", title))
+		return
+	}
+
+	printTable(rs)
+	printFoundRows(len(rs.Rows))
+	logToFileAndScreen(fmt.Sprintf("Отчёт '%s': найдено %d записей", title, len(rs.Rows)))
+}
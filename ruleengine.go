@@ -0,0 +1,796 @@
+package osl
+
+import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Правила проверки строк задаются в файле конфигурации (раздел "rules") и проверяются
+// перед выполнением вставки или обновления. Условие — ограниченное выражение над значениями
+// новой строки, которое должно быть истинным:
+//
+//	price IS NOT NULL AND price > 0
+//	EXISTS(components WHERE id = :component_id AND price IS NOT NULL)
+//	NOT (price IS NULL AND EXISTS(stock WHERE component_id = :id AND quantity > 0))
+//
+// Вне EXISTS имена означают колонки таблицы правила в новой строке. Внутри EXISTS слева
+// от сравнения стоит колонка таблицы поиска, а справа — литерал или :колонка новой строки.
+// Колонка, значение которой не введено, считается NULL. Как в SQL, сравнение с NULL дает
+// «неизвестно», NOT не делает его истинным, и правило выполнено, только если условие истинно.
+
+// Правило проверки строки из файла конфигурации
+type RowRule struct {
+	Name       string   `json:"name"`
+	Table      string   `json:"table"`
+	Operations []string `json:"operations"` // insert, update; пусто — все операции
+	Condition  string   `json:"condition"`
+	Severity   string   `json:"severity"` // block — запретить, warn — запросить подтверждение
+	Message    string   `json:"message"`
+
+	expr ruleExpr
+}
+
+// Нарушение правила для одной строки
+type RuleViolation struct {
+	Rule *RowRule
+	Row  int // номер строки (с 1)
+}
+
+// Значения строки по колонкам (nil — NULL)
+type ruleRow map[string]*string
+
+// Функция поиска в другой таблице: есть ли строка, удовлетворяющая условиям
+type ruleLookup func(table string, conditions []lookupCondition, row ruleRow) (bool, error)
+
+// Значение условия в трехзначной логике SQL
+type ruleValue int
+
+const (
+	ruleFalse ruleValue = iota
+	ruleTrue
+	ruleUnknown // сравнение с NULL
+)
+
+// Функция для перевода логического значения в значение условия
+func ruleBool(value bool) ruleValue {
+	if value {
+		return ruleTrue
+	}
+	return ruleFalse
+}
+
+// Узел разобранного выражения
+type ruleExpr interface {
+	eval(row ruleRow, lookup ruleLookup) (ruleValue, error)
+}
+
+// Операнд сравнения: колонка строки, литерал или NULL
+type ruleOperand struct {
+	field   string
+	literal *string
+}
+
+// Функция для получения значения операнда (nil — NULL)
+func (o ruleOperand) value(row ruleRow) *string {
+	if o.field != "" {
+		return row[o.field]
+	}
+	return o.literal
+}
+
+type andExpr struct{ left, right ruleExpr }
+type orExpr struct{ left, right ruleExpr }
+type notExpr struct{ inner ruleExpr }
+
+type compareExpr struct {
+	left, right ruleOperand
+	op          string
+}
+
+type nullCheckExpr struct {
+	operand ruleOperand
+	negate  bool // IS NOT NULL
+}
+
+type existsExpr struct {
+	table      string
+	conditions []lookupCondition
+}
+
+// Условие поиска внутри EXISTS: колонка таблицы поиска и значение для сравнения
+type lookupCondition struct {
+	Column string
+	Op     string // оператор сравнения, IS NULL или IS NOT NULL
+	Value  ruleOperand
+}
+
+// Ложь с любой стороны AND дает ложь, даже если другая сторона неизвестна
+func (e andExpr) eval(row ruleRow, lookup ruleLookup) (ruleValue, error) {
+	left, err := e.left.eval(row, lookup)
+	if err != nil || left == ruleFalse {
+		return ruleFalse, err
+	}
+	right, err := e.right.eval(row, lookup)
+	if err != nil || right == ruleFalse {
+		return ruleFalse, err
+	}
+	if left == ruleUnknown || right == ruleUnknown {
+		return ruleUnknown, nil
+	}
+	return ruleTrue, nil
+}
+
+// Истина с любой стороны OR дает истину, даже если другая сторона неизвестна
+func (e orExpr) eval(row ruleRow, lookup ruleLookup) (ruleValue, error) {
+	left, err := e.left.eval(row, lookup)
+	if err != nil || left == ruleTrue {
+		return left, err
+	}
+	right, err := e.right.eval(row, lookup)
+	if err != nil || right == ruleTrue {
+		return right, err
+	}
+	if left == ruleUnknown || right == ruleUnknown {
+		return ruleUnknown, nil
+	}
+	return ruleFalse, nil
+}
+
+// Отрицание неизвестного значения остается неизвестным
+func (e notExpr) eval(row ruleRow, lookup ruleLookup) (ruleValue, error) {
+	result, err := e.inner.eval(row, lookup)
+	switch result {
+	case ruleTrue:
+		return ruleFalse, err
+	case ruleFalse:
+		return ruleTrue, err
+	}
+	return result, err
+}
+
+func (e compareExpr) eval(row ruleRow, lookup ruleLookup) (ruleValue, error) {
+	left, right := e.left.value(row), e.right.value(row)
+	if left == nil || right == nil {
+		return ruleUnknown, nil
+	}
+	return ruleBool(compareValues(*left, e.op, *right)), nil
+}
+
+func (e nullCheckExpr) eval(row ruleRow, lookup ruleLookup) (ruleValue, error) {
+	isNull := e.operand.value(row) == nil
+	return ruleBool(isNull != e.negate), nil
+}
+
+func (e existsExpr) eval(row ruleRow, lookup ruleLookup) (ruleValue, error) {
+	// Сравнение с NULL никогда не истинно, поэтому такая строка заведомо не найдется
+	for _, condition := range e.conditions {
+		if condition.Op != "IS NULL" && condition.Op != "IS NOT NULL" && condition.Value.value(row) == nil {
+			return ruleFalse, nil
+		}
+	}
+	found, err := lookup(e.table, e.conditions, row)
+	return ruleBool(found), err
+}
+
+// Функция для сравнения значений: как чисел, если оба значения числа, иначе как строк
+func compareValues(left, op, right string) bool {
+	cmp := strings.Compare(left, right)
+	leftNumber, leftErr := strconv.ParseFloat(strings.TrimSpace(left), 64)
+	rightNumber, rightErr := strconv.ParseFloat(strings.TrimSpace(right), 64)
+	if leftErr == nil && rightErr == nil {
+		switch {
+		case leftNumber < rightNumber:
+			cmp = -1
+		case leftNumber > rightNumber:
+			cmp = 1
+		default:
+			cmp = 0
+		}
+	}
+
+	switch op {
+	case "=":
+		return cmp == 0
+	case "!=", "<>":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+// Лексема выражения
+type ruleToken struct {
+	kind  string // ident, param, number, string, op, (, ), end
+	value string
+}
+
+// Допустимые имена колонок и таблиц
+var ruleIdentRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Функция для разбиения выражения на лексемы
+func tokenizeRule(text string) ([]ruleToken, error) {
+	var tokens []ruleToken
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')' || r == ',':
+			tokens = append(tokens, ruleToken{kind: string(r), value: string(r)})
+			i++
+		case r == '\'':
+			// Строковый литерал, '' внутри означает кавычку
+			var b strings.Builder
+			j := i + 1
+			for {
+				if j >= len(runes) {
+					return nil, errors.New("не закрыта кавычка")
+				}
+				if runes[j] == '\'' {
+					if j+1 < len(runes) && runes[j+1] == '\'' {
+						b.WriteRune('\'')
+						j += 2
+						continue
+					}
+					break
+				}
+				b.WriteRune(runes[j])
+				j++
+			}
+			tokens = append(tokens, ruleToken{kind: "string", value: b.String()})
+			i = j + 1
+		case strings.ContainsRune("=!<>", r):
+			op := string(r)
+			if i+1 < len(runes) && (runes[i+1] == '=' || (r == '<' && runes[i+1] == '>')) {
+				op += string(runes[i+1])
+			}
+			if op == "!" {
+				return nil, errors.New("ожидается '!='")
+			}
+			tokens = append(tokens, ruleToken{kind: "op", value: op})
+			i += len([]rune(op))
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			j := i + 1
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, ruleToken{kind: "number", value: string(runes[i:j])})
+			i = j
+		case r == ':' || r == '_' || unicode.IsLetter(r):
+			j := i + 1
+			for j < len(runes) && (runes[j] == '_' || unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j])) {
+				j++
+			}
+			word := string(runes[i:j])
+			kind := "ident"
+			if r == ':' {
+				kind, word = "param", word[1:]
+			}
+			if !ruleIdentRegex.MatchString(word) {
+				return nil, fmt.Errorf("недопустимое имя '%s'", string(runes[i:j]))
+			}
+			tokens = append(tokens, ruleToken{kind: kind, value: word})
+			i = j
+		default:
+			return nil, fmt.Errorf("недопустимый символ '%c'", r)
+		}
+	}
+	return append(tokens, ruleToken{kind: "end"}), nil
+}
+
+// Разбор выражения рекурсивным спуском
+type ruleParser struct {
+	tokens []ruleToken
+	pos    int
+}
+
+// Функция для разбора условия правила
+func parseRuleExpr(text string) (ruleExpr, error) {
+	tokens, err := tokenizeRule(text)
+	if err != nil {
+		return nil, err
+	}
+	p := &ruleParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != "end" {
+		return nil, fmt.Errorf("лишний текст после выражения: '%s'", p.peek().value)
+	}
+	return expr, nil
+}
+
+func (p *ruleParser) peek() ruleToken {
+	return p.tokens[p.pos]
+}
+
+func (p *ruleParser) next() ruleToken {
+	token := p.tokens[p.pos]
+	if token.kind != "end" {
+		p.pos++
+	}
+	return token
+}
+
+// Функция для проверки, что текущая лексема — ключевое слово
+func (p *ruleParser) isKeyword(word string) bool {
+	token := p.peek()
+	return token.kind == "ident" && strings.EqualFold(token.value, word)
+}
+
+func (p *ruleParser) expectKeyword(word string) error {
+	if !p.isKeyword(word) {
+		return fmt.Errorf("ожидается %s, найдено '%s'", word, p.peek().value)
+	}
+	p.next()
+	return nil
+}
+
+func (p *ruleParser) expect(kind string) error {
+	if p.peek().kind != kind {
+		return fmt.Errorf("ожидается '%s', найдено '%s'", kind, p.peek().value)
+	}
+	p.next()
+	return nil
+}
+
+func (p *ruleParser) parseOr() (ruleExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *ruleParser) parseAnd() (ruleExpr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("AND") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *ruleParser) parseNot() (ruleExpr, error) {
+	if p.isKeyword("NOT") {
+		p.next()
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{inner}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *ruleParser) parsePrimary() (ruleExpr, error) {
+	if p.peek().kind == "(" {
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return expr, p.expect(")")
+	}
+	if p.isKeyword("EXISTS") {
+		p.next()
+		return p.parseExists()
+	}
+
+	left, err := p.parseOperand(false)
+	if err != nil {
+		return nil, err
+	}
+	if p.isKeyword("IS") {
+		p.next()
+		negate := false
+		if p.isKeyword("NOT") {
+			p.next()
+			negate = true
+		}
+		return nullCheckExpr{operand: left, negate: negate}, p.expectKeyword("NULL")
+	}
+
+	op := p.next()
+	if op.kind != "op" {
+		return nil, fmt.Errorf("ожидается оператор сравнения, найдено '%s'", op.value)
+	}
+	right, err := p.parseOperand(false)
+	if err != nil {
+		return nil, err
+	}
+	return compareExpr{left: left, op: op.value, right: right}, nil
+}
+
+// Функция для разбора операнда; внутри EXISTS значения строки задаются как :колонка
+func (p *ruleParser) parseOperand(inLookup bool) (ruleOperand, error) {
+	token := p.next()
+	switch token.kind {
+	case "number", "string":
+		value := token.value
+		return ruleOperand{literal: &value}, nil
+	case "param":
+		return ruleOperand{field: token.value}, nil
+	case "ident":
+		if strings.EqualFold(token.value, "NULL") {
+			return ruleOperand{}, errors.New("вместо сравнения с NULL используйте IS NULL")
+		}
+		if inLookup {
+			return ruleOperand{}, fmt.Errorf("внутри EXISTS значение строки задается как :%s", token.value)
+		}
+		return ruleOperand{field: token.value}, nil
+	}
+	return ruleOperand{}, fmt.Errorf("ожидается значение, найдено '%s'", token.value)
+}
+
+// Функция для разбора EXISTS(таблица WHERE условие AND условие ...)
+func (p *ruleParser) parseExists() (ruleExpr, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	table := p.next()
+	if table.kind != "ident" {
+		return nil, fmt.Errorf("ожидается имя таблицы, найдено '%s'", table.value)
+	}
+	expr := existsExpr{table: table.value}
+
+	if p.isKeyword("WHERE") {
+		p.next()
+		for {
+			column := p.next()
+			if column.kind != "ident" {
+				return nil, fmt.Errorf("ожидается имя колонки, найдено '%s'", column.value)
+			}
+			condition := lookupCondition{Column: column.value}
+			if p.isKeyword("IS") {
+				p.next()
+				condition.Op = "IS NULL"
+				if p.isKeyword("NOT") {
+					p.next()
+					condition.Op = "IS NOT NULL"
+				}
+				if err := p.expectKeyword("NULL"); err != nil {
+					return nil, err
+				}
+			} else {
+				op := p.next()
+				if op.kind != "op" {
+					return nil, fmt.Errorf("ожидается оператор сравнения, найдено '%s'", op.value)
+				}
+				value, err := p.parseOperand(true)
+				if err != nil {
+					return nil, err
+				}
+				condition.Op, condition.Value = op.value, value
+			}
+			expr.conditions = append(expr.conditions, condition)
+
+			if !p.isKeyword("AND") {
+				break
+			}
+			p.next()
+		}
+	}
+	return expr, p.expect(")")
+}
+
+// Функция для проверки имен таблиц и колонок по известной структуре БД: колонки новой строки
+// (вне EXISTS и :колонка внутри) — в таблице правила target, колонки поиска — в таблице EXISTS
+func checkRuleTables(expr ruleExpr, target TableInfo) error {
+	checkField := func(operand ruleOperand) error {
+		if operand.field != "" && !containsString(target.Columns, operand.field) {
+			return fmt.Errorf("в таблице '%s' нет колонки '%s'", target.Name, operand.field)
+		}
+		return nil
+	}
+	switch e := expr.(type) {
+	case andExpr:
+		if err := checkRuleTables(e.left, target); err != nil {
+			return err
+		}
+		return checkRuleTables(e.right, target)
+	case orExpr:
+		if err := checkRuleTables(e.left, target); err != nil {
+			return err
+		}
+		return checkRuleTables(e.right, target)
+	case notExpr:
+		return checkRuleTables(e.inner, target)
+	case compareExpr:
+		if err := checkField(e.left); err != nil {
+			return err
+		}
+		return checkField(e.right)
+	case nullCheckExpr:
+		return checkField(e.operand)
+	case existsExpr:
+		table, ok := findTable(e.table)
+		if !ok {
+			return fmt.Errorf("неизвестная таблица '%s'", e.table)
+		}
+		for _, condition := range e.conditions {
+			if !containsString(table.Columns, condition.Column) {
+				return fmt.Errorf("в таблице '%s' нет колонки '%s'", e.table, condition.Column)
+			}
+			if err := checkField(condition.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Функция для проверки наличия строки в списке
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Скомпилированные правила из конфигурации
+var rowRules []*RowRule
+
+// Функция для разбора правил из конфигурации; ошибочные правила пропускаются с сообщением
+func compileRowRules() {
+	rowRules = nil
+	for i := range appConfig.Rules {
+		rule := appConfig.Rules[i]
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("правило %d", i+1)
+		}
+		if err := compileRowRule(&rule); err != nil {
+			logToFileAndScreen(fmt.Sprintf("Ошибка в правиле '%s', оно пропущено: %v", rule.Name, err))
+			continue
+		}
+		rowRules = append(rowRules, &rule)
+	}
+}
+
+// Функция для проверки и разбора одного правила
+func compileRowRule(rule *RowRule) error {
+	target, ok := findTable(rule.Table)
+	if !ok {
+		return fmt.Errorf("неизвестная таблица '%s'", rule.Table)
+	}
+	switch rule.Severity {
+	case "":
+		rule.Severity = "block"
+	case "block", "warn":
+	default:
+		return fmt.Errorf("severity должно быть block или warn, а не '%s'", rule.Severity)
+	}
+	for _, operation := range rule.Operations {
+		if operation != "insert" && operation != "update" {
+			return fmt.Errorf("неизвестная операция '%s' (допустимы insert, update)", operation)
+		}
+	}
+
+	expr, err := parseRuleExpr(rule.Condition)
+	if err != nil {
+		return err
+	}
+	if err := checkRuleTables(expr, target); err != nil {
+		return err
+	}
+	rule.expr = expr
+	return nil
+}
+
+// Функция для проверки, применяется ли правило к операции над таблицей
+func (r *RowRule) appliesTo(table, operation string) bool {
+	return r.Table == table && (len(r.Operations) == 0 || containsString(r.Operations, operation))
+}
+
+// Функция для проверки, есть ли правила для операции над таблицей
+func hasRowRules(table, operation string) bool {
+	for _, rule := range rowRules {
+		if rule.appliesTo(table, operation) {
+			return true
+		}
+	}
+	return false
+}
+
+// Функция для построения строки для правил из колонок и значений
+func newRuleRow(columns []string, values []interface{}) ruleRow {
+	row := make(ruleRow, len(columns))
+	for i, column := range columns {
+		if i >= len(values) || values[i] == nil {
+			row[column] = nil
+			continue
+		}
+		value := formatRawValue(values[i], "")
+		row[column] = &value
+	}
+	return row
+}
+
+// Функция для поиска строки в другой таблице по условиям EXISTS
+func dbRuleLookup(table string, conditions []lookupCondition, row ruleRow) (bool, error) {
+	query, args := ruleLookupQuery(table, conditions, row)
+	var count int
+	if err := dbScanRow(query, args, &count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Функция для получения поиска по условиям EXISTS внутри транзакции: при импорте
+// видны строки, уже добавленные из того же файла
+func txRuleLookup(tx *sql.Tx) ruleLookup {
+	return func(table string, conditions []lookupCondition, row ruleRow) (bool, error) {
+		query, args := ruleLookupQuery(table, conditions, row)
+		var count int
+		if err := txScanRow(tx, query, args, &count); err != nil {
+			return false, err
+		}
+		return count > 0, nil
+	}
+}
+
+// Функция для построения запроса количества строк по условиям EXISTS
+func ruleLookupQuery(table string, conditions []lookupCondition, row ruleRow) (string, []interface{}) {
+	var where []string
+	var args []interface{}
+	for _, condition := range conditions {
+		if condition.Op == "IS NULL" || condition.Op == "IS NOT NULL" {
+			where = append(where, fmt.Sprintf("%s %s", dialect.QuoteIdent(condition.Column), condition.Op))
+			continue
+		}
+		args = append(args, *condition.Value.value(row))
+		where = append(where, fmt.Sprintf("%s %s $%d", dialect.QuoteIdent(condition.Column), condition.Op, len(args)))
+	}
+
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", tableRef(table))
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	return query, args
+}
+
+// Функция для проверки строк по правилам таблицы
+func evaluateRowRules(table, operation string, rows []ruleRow, lookup ruleLookup) ([]RuleViolation, error) {
+	var violations []RuleViolation
+	for _, rule := range rowRules {
+		if !rule.appliesTo(table, operation) {
+			continue
+		}
+		for i, row := range rows {
+			result, err := rule.expr.eval(row, lookup)
+			if err != nil {
+				return nil, fmt.Errorf("правило '%s': %w", rule.Name, err)
+			}
+			// Неизвестный результат (сравнение с NULL) не выполняет правило
+			if result != ruleTrue {
+				violations = append(violations, RuleViolation{Rule: rule, Row: i + 1})
+			}
+		}
+	}
+	return violations, nil
+}
+
+// Функция для проверки строк по правилам перед выполнением запроса.
+// Нарушение блокирующего правила запрещает операцию, предупреждения требуют подтверждения.
+// Возвращает true, если операцию можно выполнять.
+func confirmRowRules(reader *bufio.Reader, table, operation string, rows []ruleRow) bool {
+	violations, err := evaluateRowRules(table, operation, rows, dbRuleLookup)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка проверки правил для %s: %v", table, err))
+		printError(msg("rules.check_failed"))
+		return false
+	}
+	if len(violations) == 0 {
+		return true
+	}
+
+	blocked := false
+	for _, violation := range violations {
+		labelKey := "rules.warning"
+		if violation.Rule.Severity == "block" {
+			labelKey = "rules.blocked"
+			blocked = true
+		}
+		fmt.Fprintln(stdout, "⚠ " + msg("rules.violation", msg(labelKey), violation.Rule.Name, violation.Row, violation.Rule.Message))
+		logToFileAndScreen(fmt.Sprintf("Правило нарушено (%s, %s, запись %d): %s",
+			violation.Rule.Severity, violation.Rule.Name, violation.Row, violation.Rule.Message))
+	}
+	if blocked {
+		fmt.Fprintln(stdout, msg("rules.not_executed"))
+		return false
+	}
+	return promptConfirm(reader, msg("rules.confirm"))
+}
+
+// Количество ключей в одном запросе чтения строк для правил (подменяется в тестах)
+var ruleRowsChunk = maxQueryParams
+
+// Функция для получения строк для проверки правил при обновлении:
+// текущие значения записей с новым значением колонки. Если правил для обновления таблицы нет,
+// записи не читаются; большой список ключей читается частями.
+func updatedRuleRows(table string, ids []string, column, newValue string) ([]ruleRow, error) {
+	if !hasRowRules(table, "update") || len(ids) == 0 {
+		return nil, nil
+	}
+	key, err := tableKeyRef(table)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []ruleRow
+	for start := 0; start < len(ids); start += ruleRowsChunk {
+		end := start + ruleRowsChunk
+		if end > len(ids) {
+			end = len(ids)
+		}
+		where, args := inListCondition(tableKeyColumn(table), ids[start:end], 1)
+		chunk, err := readUpdatedRuleRows(fmt.Sprintf("SELECT * FROM %s WHERE %s ORDER BY %s", tableRef(table), where, key), args, column, newValue)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, chunk...)
+	}
+	return result, nil
+}
+
+// Функция для чтения записей в строки для правил с подстановкой нового значения колонки
+func readUpdatedRuleRows(query string, args []interface{}, column, newValue string) ([]ruleRow, error) {
+	rows, err := dbQuery(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var result []ruleRow
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		valuePtrs := make([]interface{}, len(columns))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, err
+		}
+		row := newRuleRow(columns, values)
+		value := newValue
+		row[column] = &value
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
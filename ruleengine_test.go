@@ -0,0 +1,345 @@
+package osl
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenizeRule(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    []ruleToken
+		wantErr string
+	}{
+		{text: "price >= -1.5", want: []ruleToken{{"ident", "price"}, {"op", ">="}, {"number", "-1.5"}, {"end", ""}}},
+		{text: "name <> 'it''s'", want: []ruleToken{{"ident", "name"}, {"op", "<>"}, {"string", "it's"}, {"end", ""}}},
+		{text: "EXISTS(stock WHERE id=:component_id)", want: []ruleToken{{"ident", "EXISTS"}, {"(", "("},
+			{"ident", "stock"}, {"ident", "WHERE"}, {"ident", "id"}, {"op", "="}, {"param", "component_id"}, {")", ")"}, {"end", ""}}},
+		{text: "a != b", want: []ruleToken{{"ident", "a"}, {"op", "!="}, {"ident", "b"}, {"end", ""}}},
+		{text: "", want: []ruleToken{{"end", ""}}},
+		{text: "name = 'открыта", wantErr: "не закрыта кавычка"},
+		{text: "a ! b", wantErr: "ожидается '!='"},
+		{text: "price # 1", wantErr: "недопустимый символ '#'"},
+		{text: "цена > 0", wantErr: "недопустимое имя 'цена'"},
+	}
+	for _, tt := range tests {
+		got, err := tokenizeRule(tt.text)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("tokenizeRule(%q): ошибка %v, ожидалась %q", tt.text, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tokenizeRule(%q) = %v, %v\nожидалось %v", tt.text, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseRuleErrors(t *testing.T) {
+	tests := []struct {
+		text, wantErr string
+	}{
+		{"", "ожидается значение"},
+		{"price >", "ожидается значение"},
+		{"price 5", "ожидается оператор сравнения"},
+		{"price = NULL", "IS NULL"},
+		{"price IS NOT", "ожидается NULL"},
+		{"(price > 0", "ожидается ')'"},
+		{"price > 0 price", "лишний текст"},
+		{"EXISTS stock", "ожидается '('"},
+		{"EXISTS(stock WHERE component_id = id)", "значение строки задается как :id"},
+		{"EXISTS(stock WHERE 5 = :id)", "ожидается имя колонки"},
+		{"EXISTS('stock')", "ожидается имя таблицы"},
+	}
+	for _, tt := range tests {
+		if _, err := parseRuleExpr(tt.text); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("parseRuleExpr(%q): ошибка %v, ожидалась %q", tt.text, err, tt.wantErr)
+		}
+	}
+}
+
+// Функция для получения строки для правил из пар колонка-значение (значение "NULL" — NULL)
+func testRuleRow(pairs ...string) ruleRow {
+	row := ruleRow{}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] == "NULL" {
+			row[pairs[i]] = nil
+			continue
+		}
+		value := pairs[i+1]
+		row[pairs[i]] = &value
+	}
+	return row
+}
+
+func TestEvalRule(t *testing.T) {
+	row := testRuleRow("a", "1", "b", "0", "c", "0", "price", "NULL", "qty", "10", "name", "b")
+	tests := []struct {
+		text string
+		want ruleValue
+	}{
+		// AND связывает сильнее OR, NOT — сильнее AND
+		{"a = 1 OR b = 1 AND c = 1", ruleTrue},
+		{"(a = 1 OR b = 1) AND c = 1", ruleFalse},
+		{"NOT a = 1 AND b = 1", ruleFalse},
+		{"NOT (a = 1 AND b = 1)", ruleTrue},
+		{"NOT NOT a = 1", ruleTrue},
+		// Сравнение с NULL и с отсутствующей колонкой неизвестно, в том числе под NOT
+		{"price > 0", ruleUnknown},
+		{"price <= 0", ruleUnknown},
+		{"NOT price > 0", ruleUnknown},
+		{"missing = 1", ruleUnknown},
+		{"price > 0 AND a = 0", ruleFalse},
+		{"price > 0 AND a = 1", ruleUnknown},
+		{"price > 0 OR a = 1", ruleTrue},
+		{"NOT (price > 0 OR a = 0)", ruleUnknown},
+		{"NOT price > 0 OR price IS NULL", ruleTrue},
+		{"price IS NULL", ruleTrue},
+		{"price IS NOT NULL", ruleFalse},
+		{"missing IS NULL", ruleTrue},
+		{"qty IS NOT NULL AND qty > 0", ruleTrue},
+		// Числа сравниваются как числа, остальное — как строки
+		{"qty > 9", ruleTrue},
+		{"qty > '9'", ruleTrue},
+		{"name > 'a'", ruleTrue},
+		{"name = 'B'", ruleFalse},
+		{"qty <> 10.0", ruleFalse},
+	}
+	for _, tt := range tests {
+		expr, err := parseRuleExpr(tt.text)
+		if err != nil {
+			t.Errorf("parseRuleExpr(%q): %v", tt.text, err)
+			continue
+		}
+		got, err := expr.eval(row, nil)
+		if err != nil || got != tt.want {
+			t.Errorf("%q = %v, %v, ожидалось %v", tt.text, got, err, tt.want)
+		}
+	}
+}
+
+func TestEvalRuleExists(t *testing.T) {
+	var calls []string
+	found := map[string]bool{"stock": true, "components": false}
+	lookup := func(table string, conditions []lookupCondition, row ruleRow) (bool, error) {
+		var parts []string
+		for _, condition := range conditions {
+			part := condition.Column + " " + condition.Op
+			if value := condition.Value.value(row); value != nil {
+				part += " " + *value
+			}
+			parts = append(parts, part)
+		}
+		calls = append(calls, table+": "+strings.Join(parts, ", "))
+		if table == "broken" {
+			return false, errors.New("нет соединения")
+		}
+		return found[table], nil
+	}
+
+	row := testRuleRow("id", "7", "discontinued", "true", "category_id", "NULL")
+	tests := []struct {
+		text      string
+		want      bool
+		wantCalls []string
+		wantErr   bool
+	}{
+		{text: "EXISTS(stock WHERE component_id = :id AND quantity > 0)", want: true,
+			wantCalls: []string{"stock: component_id = 7, quantity > 0"}},
+		{text: "NOT EXISTS(stock WHERE component_id = :id)", want: false,
+			wantCalls: []string{"stock: component_id = 7"}},
+		{text: "EXISTS(components WHERE id = :id AND price IS NOT NULL)", want: false,
+			wantCalls: []string{"components: id = 7, price IS NOT NULL"}},
+		{text: "EXISTS(stock)", want: true, wantCalls: []string{"stock: "}},
+		// Значение NULL в условии: строка заведомо не найдется, запрос не выполняется
+		{text: "EXISTS(stock WHERE category_id = :category_id)", want: false},
+		{text: "NOT EXISTS(stock WHERE category_id = :category_id)", want: true},
+		// Короткое вычисление: правая часть не проверяется
+		{text: "discontinued = 'false' AND EXISTS(stock)", want: false},
+		{text: "NOT (discontinued = 'true' AND EXISTS(stock WHERE component_id = :id))", want: false,
+			wantCalls: []string{"stock: component_id = 7"}},
+		{text: "EXISTS(broken WHERE id = :id)", wantErr: true, wantCalls: []string{"broken: id = 7"}},
+	}
+	for _, tt := range tests {
+		calls = nil
+		expr, err := parseRuleExpr(tt.text)
+		if err != nil {
+			t.Errorf("parseRuleExpr(%q): %v", tt.text, err)
+			continue
+		}
+		got, err := expr.eval(row, lookup)
+		if (err != nil) != tt.wantErr || (got == ruleTrue) != tt.want {
+			t.Errorf("%q = %v, %v, ожидалось %v (ошибка: %v)", tt.text, got, err, tt.want, tt.wantErr)
+		}
+		if !reflect.DeepEqual(calls, tt.wantCalls) {
+			t.Errorf("%q: запросы поиска %q, ожидалось %q", tt.text, calls, tt.wantCalls)
+		}
+	}
+}
+
+// Таблица для проверок правил на базе SQLite
+func openRulesSchema(t *testing.T, rules ...RowRule) {
+	t.Helper()
+	openSchema(t, []string{"parts", "stock"},
+		"CREATE TABLE parts (id INTEGER PRIMARY KEY, name TEXT NOT NULL, price NUMERIC)",
+		"CREATE TABLE stock (id INTEGER PRIMARY KEY, part_id INTEGER, quantity INTEGER)",
+		"INSERT INTO parts (name, price) VALUES ('Кулер', 10), ('Корпус', 50), ('Вентилятор', 5), ('Блок', 70), ('Плата', 90)",
+		"INSERT INTO stock (part_id, quantity) VALUES (1, 3)")
+	appConfig.Rules = rules
+	compileRowRules()
+	if len(rowRules) != len(rules) {
+		t.Fatalf("скомпилировано правил %d из %d", len(rowRules), len(rules))
+	}
+}
+
+func TestRowRuleSeverities(t *testing.T) {
+	openRulesSchema(t,
+		RowRule{Name: "цена", Table: "parts", Condition: "price > 0", Message: "цена должна быть положительной"},
+		RowRule{Name: "дорого", Table: "parts", Operations: []string{"insert"}, Severity: "warn",
+			Condition: "price < 100", Message: "подозрительно высокая цена"},
+		RowRule{Name: "склад", Table: "stock", Condition: "EXISTS(parts WHERE id = :part_id)", Message: "нет такой детали"})
+
+	if rowRules[0].Severity != "block" {
+		t.Errorf("severity по умолчанию %q, ожидалось block", rowRules[0].Severity)
+	}
+
+	rows := []ruleRow{testRuleRow("price", "10"), testRuleRow("price", "0"), testRuleRow("price", "150")}
+	violations, err := evaluateRowRules("parts", "insert", rows, dbRuleLookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, violation := range violations {
+		got = append(got, violation.Rule.Severity+":"+violation.Rule.Name+":"+strings.Repeat("#", violation.Row))
+	}
+	if want := []string{"block:цена:##", "warn:дорого:###"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("нарушения %v, ожидалось %v", got, want)
+	}
+	// Правило только для вставки не проверяется при обновлении
+	if violations, _ := evaluateRowRules("parts", "update", rows[2:], dbRuleLookup); len(violations) != 0 {
+		t.Errorf("нарушения при обновлении: %d", len(violations))
+	}
+	// EXISTS обращается к базе
+	if violations, _ := evaluateRowRules("stock", "insert", []ruleRow{testRuleRow("part_id", "9")}, dbRuleLookup); len(violations) != 1 {
+		t.Errorf("ссылка на несуществующую деталь не найдена: %d", len(violations))
+	}
+
+	var blocked, warnedYes, warnedNo bool
+	output := captureOutput(t, func() {
+		blocked = confirmRowRules(scriptReader(), "parts", "insert", rows[1:2])
+		warnedYes = confirmRowRules(scriptReader("да"), "parts", "insert", rows[2:])
+		warnedNo = confirmRowRules(scriptReader("нет"), "parts", "insert", rows[2:])
+	})
+	if blocked || !warnedYes || warnedNo {
+		t.Errorf("confirmRowRules: блок=%v, предупреждение с согласием=%v, с отказом=%v", blocked, warnedYes, warnedNo)
+	}
+	if !strings.Contains(output, "Запрещено (цена, запись 1)") || !strings.Contains(output, "Предупреждение (дорого, запись 1)") {
+		t.Errorf("вывод нарушений:\n%s", output)
+	}
+}
+
+func TestCompileRowRuleErrors(t *testing.T) {
+	openRulesSchema(t)
+	tests := []struct {
+		rule    RowRule
+		wantErr string
+	}{
+		{RowRule{Table: "nope", Condition: "a = 1"}, "неизвестная таблица 'nope'"},
+		{RowRule{Table: "parts", Severity: "fatal", Condition: "price > 0"}, "severity"},
+		{RowRule{Table: "parts", Operations: []string{"delete"}, Condition: "price > 0"}, "неизвестная операция 'delete'"},
+		{RowRule{Table: "parts", Condition: "EXISTS(orders WHERE id = :id)"}, "неизвестная таблица 'orders'"},
+		{RowRule{Table: "parts", Condition: "EXISTS(stock WHERE amount > 0)"}, "нет колонки 'amount'"},
+		{RowRule{Table: "parts", Condition: "discontinued = 'true'"}, "в таблице 'parts' нет колонки 'discontinued'"},
+		{RowRule{Table: "parts", Condition: "weight IS NULL OR price > 0"}, "нет колонки 'weight'"},
+		{RowRule{Table: "stock", Condition: "EXISTS(parts WHERE id = :component_id)"}, "в таблице 'stock' нет колонки 'component_id'"},
+		{RowRule{Table: "parts", Condition: "price >"}, "ожидается значение"},
+	}
+	for _, tt := range tests {
+		rule := tt.rule
+		if err := compileRowRule(&rule); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("compileRowRule(%+v): ошибка %v, ожидалась %q", tt.rule, err, tt.wantErr)
+		}
+	}
+}
+
+func TestUpdatedRuleRows(t *testing.T) {
+	openRulesSchema(t)
+	// Без правил для обновления записи не читаются (таблицы нет, но ошибки тоже нет)
+	if rows, err := updatedRuleRows("missing", []string{"1"}, "price", "1"); rows != nil || err != nil {
+		t.Fatalf("updatedRuleRows без правил = %v, %v", rows, err)
+	}
+
+	openRulesSchema(t, RowRule{Name: "цена", Table: "parts", Condition: "price > 0"})
+	saved := ruleRowsChunk
+	ruleRowsChunk = 2
+	defer func() { ruleRowsChunk = saved }()
+
+	rows, err := updatedRuleRows("parts", []string{"5", "1", "3", "2", "4"}, "price", "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, row := range rows {
+		if *row["price"] != "0" {
+			t.Errorf("в строке %s не подставлено новое значение: %s", *row["name"], *row["price"])
+		}
+		names = append(names, *row["name"])
+	}
+	// Части читаются по порядку, внутри части — по ключу
+	if want := "Кулер,Плата,Корпус,Вентилятор,Блок"; strings.Join(names, ",") != want {
+		t.Errorf("прочитаны строки %v, ожидалось %s", names, want)
+	}
+}
+
+func TestImportRowRules(t *testing.T) {
+	openRulesSchema(t,
+		RowRule{Name: "цена", Table: "parts", Condition: "price > 0", Message: "цена должна быть положительной"},
+		RowRule{Name: "дорого", Table: "parts", Severity: "warn", Condition: "price < 100", Message: "подозрительно высокая цена"})
+	dir := t.TempDir()
+
+	// Блокирующее правило прерывает импорт с номером строки, ничего не добавляется
+	blocked := filepath.Join(dir, "blocked.csv")
+	os.WriteFile(blocked, []byte("name,price\nМышь,15\nКоврик,0\n"), 0644)
+	var err error
+	output := captureOutput(t, func() { err = runImport(tables[0], blocked) })
+	var lineErr *importLineError
+	if !errors.As(err, &lineErr) || lineErr.Line != 3 || !strings.Contains(err.Error(), "цена должна быть положительной") {
+		t.Fatalf("импорт с нарушением правила: %v\n%s", err, output)
+	}
+	if got := queryString(t, "SELECT COUNT(*) FROM parts"); got != "5" {
+		t.Errorf("после отмененного импорта в таблице %s записей", got)
+	}
+
+	// Предупреждение выводится с номером строки, импорт продолжается
+	warned := filepath.Join(dir, "warned.csv")
+	os.WriteFile(warned, []byte("name,price\nМонитор,250\nМышь,15\n"), 0644)
+	output = captureOutput(t, func() { err = runImport(tables[0], warned) })
+	if err != nil || !strings.Contains(output, "Предупреждение (дорого, строка 2): подозрительно высокая цена") {
+		t.Fatalf("импорт с предупреждением: %v\n%s", err, output)
+	}
+	if got := queryString(t, "SELECT COUNT(*) FROM parts"); got != "7" {
+		t.Errorf("после импорта в таблице %s записей, ожидалось 7", got)
+	}
+}
+
+// Правила из config.example.json ссылаются только на существующие таблицы и колонки
+func TestExampleConfigRules(t *testing.T) {
+	openBaseSchema(t)
+	t.Setenv("OSL_CONFIG", "config.example.json")
+	if err := loadAppConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if len(appConfig.Rules) == 0 {
+		t.Fatal("в примере конфигурации нет правил")
+	}
+	for _, rule := range appConfig.Rules {
+		if err := compileRowRule(&rule); err != nil {
+			t.Errorf("правило %q: %v", rule.Name, err)
+		}
+	}
+}
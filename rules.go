@@ -0,0 +1,184 @@
+package osl
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Проверка подозрительно больших изменений числовых колонок при обновлении: интерактивно
+// изменение подтверждается отдельным вопросом. Подтверждение --yes на такие изменения
+// не распространяется: без флага --allow-large-price-changes (OSL_ALLOW_LARGE_CHANGES)
+// неинтерактивное обновление отклоняется. Импорт только добавляет записи, текущего значения
+// для сравнения у него нет, поэтому проверка к нему не применяется.
+
+// Подозрительно большие изменения принимаются без подтверждения (--allow-large-price-changes)
+var allowLargeChanges bool
+
+// Правило проверки изменения числовой колонки: предупреждение, если новое значение
+// отличается от текущего больше чем на MaxPercent процентов
+type ChangeRule struct {
+	Table      string
+	Column     string
+	MaxPercent float64
+}
+
+// Предупреждение о подозрительном изменении значения
+type ChangeWarning struct {
+	Rule     ChangeRule
+	ID       string
+	OldValue float64
+	NewValue float64
+	Percent  float64
+}
+
+// Функция для получения правил проверки изменений.
+// OSL_CHANGE_RULES задает список вида "таблица.колонка=процент" через запятую,
+// по умолчанию проверяется только цена компонентов (components.price=50).
+func changeRules() []ChangeRule {
+	value := envString("OSL_CHANGE_RULES", "components.price=50")
+	var rules []ChangeRule
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		rule, err := parseChangeRule(part)
+		if err != nil {
+			log.Printf("Некорректное правило в OSL_CHANGE_RULES: %v", err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// Функция для разбора правила вида "таблица.колонка=процент"
+func parseChangeRule(text string) (ChangeRule, error) {
+	target, percentText, found := strings.Cut(text, "=")
+	if !found {
+		return ChangeRule{}, fmt.Errorf("%q: ожидается таблица.колонка=процент", text)
+	}
+	table, column, found := strings.Cut(strings.TrimSpace(target), ".")
+	if !found || table == "" || column == "" {
+		return ChangeRule{}, fmt.Errorf("%q: ожидается таблица.колонка=процент", text)
+	}
+	percent, err := strconv.ParseFloat(strings.TrimSpace(percentText), 64)
+	if err != nil || percent <= 0 {
+		return ChangeRule{}, fmt.Errorf("%q: процент должен быть положительным числом", text)
+	}
+	return ChangeRule{Table: table, Column: column, MaxPercent: percent}, nil
+}
+
+// Функция для поиска правила для колонки таблицы
+func findChangeRule(table, column string) (ChangeRule, bool) {
+	for _, rule := range changeRules() {
+		if rule.Table == table && rule.Column == column {
+			return rule, true
+		}
+	}
+	return ChangeRule{}, false
+}
+
+// Функция для проверки одного изменения по правилу.
+// Если старого значения нет (NULL) или оно равно нулю, сравнивать не с чем и предупреждение не выдается.
+func checkChange(rule ChangeRule, id string, oldValue sql.NullString, newValue string) (ChangeWarning, bool) {
+	if !oldValue.Valid {
+		return ChangeWarning{}, false
+	}
+	oldNumber, err := strconv.ParseFloat(strings.TrimSpace(oldValue.String), 64)
+	if err != nil || oldNumber == 0 {
+		return ChangeWarning{}, false
+	}
+	newNumber, err := strconv.ParseFloat(strings.TrimSpace(newValue), 64)
+	if err != nil {
+		return ChangeWarning{}, false
+	}
+
+	percent := (newNumber - oldNumber) / math.Abs(oldNumber) * 100
+	if math.Abs(percent) <= rule.MaxPercent {
+		return ChangeWarning{}, false
+	}
+	return ChangeWarning{Rule: rule, ID: id, OldValue: oldNumber, NewValue: newNumber, Percent: percent}, true
+}
+
+// Функция для проверки изменения колонки в записях с указанными id.
+// Возвращает предупреждения для записей, изменение которых превышает порог правила.
+func checkChangeRules(table, column string, ids []string, newValue string) ([]ChangeWarning, error) {
+	rule, ok := findChangeRule(table, column)
+	if !ok || len(ids) == 0 {
+		return nil, nil
+	}
+
+	key, err := tableKeyRef(table)
+	if err != nil {
+		return nil, err
+	}
+	where, args := inListCondition(tableKeyColumn(table), ids, 1)
+	query := fmt.Sprintf("SELECT %[1]s, %[2]s FROM %[3]s WHERE %[4]s ORDER BY %[1]s",
+		key, dialect.QuoteIdent(column), tableRef(table), where)
+
+	rows, err := dbQuery(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var warnings []ChangeWarning
+	for rows.Next() {
+		var id string
+		var oldValue sql.NullString
+		if err := rows.Scan(&id, &oldValue); err != nil {
+			return nil, err
+		}
+		if warning, found := checkChange(rule, id, oldValue, newValue); found {
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings, rows.Err()
+}
+
+// Функция для описания предупреждения на экране (на активном языке)
+func (w ChangeWarning) describe() string {
+	return msg("rules.change_warning",
+		w.Rule.Table, w.Rule.Column, w.ID,
+		strconv.FormatFloat(w.OldValue, 'f', -1, 64), strconv.FormatFloat(w.NewValue, 'f', -1, 64),
+		w.Percent, w.Rule.MaxPercent)
+}
+
+// Функция для описания предупреждения для журнала
+func (w ChangeWarning) String() string {
+	return fmt.Sprintf("%s.%s у записи %s: было %s, станет %s (%+.1f%%, порог %.0f%%)",
+		w.Rule.Table, w.Rule.Column, w.ID,
+		strconv.FormatFloat(w.OldValue, 'f', -1, 64), strconv.FormatFloat(w.NewValue, 'f', -1, 64),
+		w.Percent, w.Rule.MaxPercent)
+}
+
+// Функция для вывода предупреждений и запроса подтверждения.
+// Возвращает true, если предупреждений нет, изменение подтверждено пользователем
+// или разрешено флагом --allow-large-price-changes; с --yes без этого флага — false.
+func confirmChangeWarnings(reader *bufio.Reader, warnings []ChangeWarning) bool {
+	if len(warnings) == 0 {
+		return true
+	}
+
+	fmt.Fprintln(stdout, msg("rules.change_title"))
+	for _, warning := range warnings {
+		fmt.Fprintln(stdout, "  " + warning.describe())
+		logToFileAndScreen(fmt.Sprintf("Предупреждение: %s", warning))
+	}
+	if allowLargeChanges {
+		logToFileAndScreen("Подозрительное изменение принято автоматически (--allow-large-price-changes)")
+		return true
+	}
+	if assumeYes {
+		printError(msg("rules.change_refused"))
+		logToFileAndScreen("Подозрительное изменение отклонено: --yes не подтверждает его без --allow-large-price-changes")
+		return false
+	}
+	return promptConfirm(reader, msg("rules.change_confirm"))
+}
@@ -0,0 +1,52 @@
+package osl
+
+import (
+	"strings"
+	"testing"
+)
+
+// Подозрительное изменение цены: вопрос в интерактивном режиме, отказ с --yes
+// и автоматическое подтверждение с --allow-large-price-changes
+func TestLargePriceChange(t *testing.T) {
+	openStockSchema(t)
+	t.Cleanup(func() { allowLargeChanges = false })
+	spec := UpdateSpec{Table: "components", Column: "price", IDs: []string{"1"}, Value: "1599"}
+	price := func() string { return queryString(t, "SELECT price FROM components WHERE id = 1") }
+
+	// Отказ на вопрос о подозрительном изменении
+	output := captureOutput(t, func() { executeUpdate(scriptReader("нет"), spec) })
+	if got := price(); got != "15990" {
+		t.Fatalf("цена после отказа %s:\n%s", got, output)
+	}
+	if !strings.Contains(output, "было 15990, станет 1599 (-90.0%, порог 50%)") {
+		t.Errorf("нет предупреждения об изменении:\n%s", output)
+	}
+
+	// --yes подтверждает сводку, но не подозрительное изменение
+	assumeYes = true
+	output = captureOutput(t, func() { executeUpdate(scriptReader(), spec) })
+	if got := price(); got != "15990" {
+		t.Fatalf("цена изменена с --yes без --allow-large-price-changes: %s", got)
+	}
+	if !strings.Contains(output, msg("rules.change_refused")) {
+		t.Errorf("нет сообщения об отказе:\n%s", output)
+	}
+
+	allowLargeChanges = true
+	output = captureOutput(t, func() { executeUpdate(scriptReader(), spec) })
+	if got := price(); got != "1599" {
+		t.Errorf("цена с --allow-large-price-changes %s:\n%s", got, output)
+	}
+}
+
+// Флаг --allow-large-price-changes задается среди общих флагов перед командой
+func TestGlobalFlagsLargeChanges(t *testing.T) {
+	flags, args := globalFlags([]string{"--yes", "--allow-large-price-changes", "import", "--table", "components"})
+	if !flags.AssumeYes || !flags.AllowLargeChanges || strings.Join(args, " ") != "import --table components" {
+		t.Errorf("globalFlags: %+v, %v", flags, args)
+	}
+	t.Setenv("OSL_ALLOW_LARGE_CHANGES", "1")
+	if opts := OptionsFromEnv(nil); !opts.AllowLargeChanges {
+		t.Error("OSL_ALLOW_LARGE_CHANGES не учтен")
+	}
+}
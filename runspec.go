@@ -0,0 +1,379 @@
+package osl
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Сохраненные фильтры описываются в файле конфигурации (saved_filters).
+// Значение "?" в условии означает параметр «спросить»: в команде run его значения
+// берутся из файла параметров, по одной строке CSV на запуск.
+
+// Значение условия, которое задается при запуске
+const askParam = "?"
+
+// Сохраненный фильтр в файле конфигурации
+type SavedFilter struct {
+	Table      string           `json:"table"`
+	Operator   string           `json:"operator,omitempty"` // AND (по умолчанию) или OR
+	Columns    []string         `json:"columns,omitempty"`  // пусто — все колонки
+	Conditions []SavedCondition `json:"conditions"`
+}
+
+// Условие сохраненного фильтра
+type SavedCondition struct {
+	Column string   `json:"column"`
+	Type   string   `json:"type"`             // equals, in, range, is_null или not_null
+	Values []string `json:"values,omitempty"` // для range — нижняя и верхняя граница; для is_null и not_null — пусто
+}
+
+// Типы условий в файле конфигурации
+var savedConditionTypes = map[string]int{
+	"equals": filterEquals, "in": filterInList, "range": filterRange,
+	"is_null": filterIsNull, "not_null": filterNotNull,
+}
+
+// Количество значений в условиях, где оно фиксировано
+var savedValueCounts = map[int]int{filterEquals: 1, filterRange: 2, filterIsNull: 0, filterNotNull: 0}
+
+// Параметр сохраненного фильтра: имя и место значения в описании фильтрации
+type specParam struct {
+	Name     string
+	Value    *string
+	Validate func(string) error
+}
+
+// Функция для построения описания фильтрации по сохраненному фильтру.
+// Возвращает описание и параметры «спросить» в порядке условий.
+func (f SavedFilter) filterSpec() (FilterSpec, []specParam, error) {
+	table, ok := findTable(f.Table)
+	if !ok {
+		return FilterSpec{}, nil, fmt.Errorf("таблица '%s' не найдена", f.Table)
+	}
+
+	spec := FilterSpec{Table: table.Name, Operator: " AND ", Columns: table.Columns}
+	switch strings.ToUpper(f.Operator) {
+	case "", "AND":
+	case "OR":
+		spec.Operator = " OR "
+	default:
+		return FilterSpec{}, nil, fmt.Errorf("неизвестный оператор '%s' (допускаются AND и OR)", f.Operator)
+	}
+	if len(f.Columns) > 0 {
+		for _, column := range f.Columns {
+			if !containsString(table.Columns, column) {
+				return FilterSpec{}, nil, fmt.Errorf("колонка '%s' не найдена в таблице '%s'", column, table.Name)
+			}
+		}
+		spec.Columns = f.Columns
+	}
+	if len(f.Conditions) == 0 {
+		return FilterSpec{}, nil, errors.New("в фильтре нет условий")
+	}
+
+	for _, saved := range f.Conditions {
+		kind, ok := savedConditionTypes[saved.Type]
+		if !ok {
+			return FilterSpec{}, nil, fmt.Errorf("неизвестный тип условия '%s' (допускаются equals, in, range, is_null, not_null)", saved.Type)
+		}
+		if !containsString(table.Columns, saved.Column) {
+			return FilterSpec{}, nil, fmt.Errorf("колонка '%s' не найдена в таблице '%s'", saved.Column, table.Name)
+		}
+		if kind == filterRange && !supportsRangeFilter(table, saved.Column) {
+			return FilterSpec{}, nil, fmt.Errorf("диапазон для колонки '%s' не поддерживается", saved.Column)
+		}
+		expected, fixed := savedValueCounts[kind]
+		if (fixed && len(saved.Values) != expected) || (!fixed && len(saved.Values) == 0) {
+			return FilterSpec{}, nil, fmt.Errorf("неверное количество значений в условии по '%s'", saved.Column)
+		}
+		// Значения проверяются заново: тип колонки в БД мог измениться после сохранения фильтра
+		if err := validateSavedValues(table, kind, saved); err != nil {
+			return FilterSpec{}, nil, fmt.Errorf("условие по '%s': %w", saved.Column, err)
+		}
+		spec.Conditions = append(spec.Conditions, FilterCondition{Column: saved.Column, Type: kind,
+			Values: append([]string(nil), saved.Values...)})
+	}
+
+	// Параметры собираются после заполнения условий, чтобы указатели вели в итоговые срезы
+	var params []specParam
+	for i := range spec.Conditions {
+		condition := &spec.Conditions[i]
+		column := condition.Column
+		for j := range condition.Values {
+			if condition.Values[j] != askParam {
+				continue
+			}
+			// Форма запроса не должна зависеть от значений, чтобы все запуски шли одним подготовленным запросом
+			param := specParam{Name: column, Value: &condition.Values[j]}
+			switch condition.Type {
+			case filterInList:
+				return FilterSpec{}, nil, fmt.Errorf("параметр '?' не допускается в списке значений (колонка '%s')", column)
+			case filterRange:
+				param.Name = column + map[int]string{0: "_from", 1: "_to"}[j]
+				param.Validate = func(value string) error {
+					if value == "" {
+						return errors.New("граница диапазона не может быть пустой")
+					}
+					return validateRangeBound(table, column, value)
+				}
+			default:
+				param.Validate = func(value string) error { return checkAllowedChars(table, column, value) }
+			}
+			params = append(params, param)
+		}
+	}
+	return spec, params, nil
+}
+
+// Функция для проверки заданных значений условия (параметры «спросить» проверяются при вводе)
+func validateSavedValues(table TableInfo, kind int, saved SavedCondition) error {
+	for i, value := range saved.Values {
+		if value == askParam {
+			continue
+		}
+		var err error
+		switch kind {
+		case filterRange:
+			err = validateRangeBound(table, saved.Column, value)
+		case filterInList:
+			err = validateColumnValue(table, saved.Column, value)
+		default:
+			err = checkAllowedChars(table, saved.Column, value)
+		}
+		if err != nil {
+			if kind == filterInList {
+				return fmt.Errorf("'%s': %w", value, err)
+			}
+			return err
+		}
+		if kind == filterRange && i == 1 && saved.Values[0] != askParam {
+			if saved.Values[0] == "" && value == "" {
+				return errors.New(msg("filter.bound_required"))
+			}
+			if err := checkRangeOrder(table, saved.Column, saved.Values[0], value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Команда run: osl run --spec <сохраненный фильтр> --params-file params.csv
+func runSpecCommand(args []string) int {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	specName := fs.String("spec", "", "имя сохраненного фильтра (saved_filters в файле конфигурации)")
+	paramsFile := fs.String("params-file", "", "CSV-файл значений параметров, одна строка на запуск (- — стандартный ввод)")
+	outTemplate := fs.String("out", "", "шаблон имени файла результата: {line} и {<параметр>} заменяются значениями")
+	combined := fs.String("combined", "", "общий файл результата с колонками параметров (вместо файла на запуск)")
+	failOnError := fs.Bool("fail-on-error", false, "прервать выполнение на первой ошибочной строке параметров")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	saved, ok := appConfig.SavedFilters[*specName]
+	if !ok {
+		fmt.Fprintln(stderr, msg("cli.spec_not_found", *specName))
+		return 2
+	}
+	spec, params, err := saved.filterSpec()
+	if err != nil {
+		fmt.Fprintln(stderr, msg("cli.spec_invalid", *specName, err))
+		return 2
+	}
+	if *paramsFile == "" {
+		fmt.Fprintln(stderr, msg("cli.params_file_required"))
+		return 2
+	}
+	if *outTemplate == "" {
+		*outTemplate = *specName + "_{line}.csv"
+	}
+	table, _ := findTable(spec.Table)
+
+	input := stdin
+	if *paramsFile != "-" {
+		file, err := os.Open(*paramsFile)
+		if err != nil {
+			logToFileAndScreen(fmt.Sprintf("Ошибка открытия файла параметров: %v", err))
+			return 1
+		}
+		defer file.Close()
+		input = file
+	}
+	paramsReader := csv.NewReader(input)
+	paramsReader.FieldsPerRecord = -1
+	paramsReader.TrimLeadingSpace = true
+
+	// Все запуски выполняются одним подготовленным запросом на одном соединении
+	query, queryArgs, _ := buildFilterQuery(table, spec)
+	boundQuery, _ := rebind(query, queryArgs)
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка подключения к БД: %v", err))
+		return 1
+	}
+	defer conn.Close()
+	stmt, err := conn.PrepareContext(context.Background(), boundQuery)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка подготовки запроса фильтра '%s': %v", *specName, err))
+		return 1
+	}
+	defer stmt.Close()
+
+	var combinedWriter *csv.Writer
+	if *combined != "" {
+		file, err := os.Create(*combined)
+		if err != nil {
+			logToFileAndScreen(fmt.Sprintf("Ошибка создания файла результата: %v", err))
+			return 1
+		}
+		defer file.Close()
+		combinedWriter = csv.NewWriter(file)
+		header := make([]string, 0, len(params)+len(spec.Columns))
+		for _, param := range params {
+			header = append(header, param.Name)
+		}
+		combinedWriter.Write(append(header, spec.Columns...))
+	}
+
+	runs, failed := 0, 0
+	for line := 1; ; line++ {
+		record, err := paramsReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err == nil && line == 1 && isParamsHeader(record, params) {
+			continue
+		}
+		if err == nil {
+			err = applySpecParams(table, spec, params, record)
+		}
+
+		var count int
+		var target string
+		if err == nil {
+			count, target, err = runSpecLine(stmt, table, spec, params, line, *outTemplate, combinedWriter, *combined)
+		}
+		if err != nil {
+			failed++
+			logToFileAndScreen(fmt.Sprintf("Ошибка: файл параметров, строка %d: %v", line, err))
+			if *failOnError {
+				return 1
+			}
+			continue
+		}
+		runs++
+		fmt.Fprintln(stdout, msg("runspec.line_done", line, count, target))
+	}
+
+	if combinedWriter != nil {
+		combinedWriter.Flush()
+		if err := combinedWriter.Error(); err != nil {
+			logToFileAndScreen(fmt.Sprintf("Ошибка записи файла результата: %v", err))
+			return 1
+		}
+	}
+	fmt.Fprintln(stdout, msg("runspec.summary", runs, failed))
+	logToFileAndScreen(fmt.Sprintf("Пакетный запуск фильтра '%s': выполнено %d, пропущено %d", *specName, runs, failed))
+	return 0
+}
+
+// Функция для проверки, является ли строка заголовком с именами параметров
+func isParamsHeader(record []string, params []specParam) bool {
+	if len(record) != len(params) {
+		return false
+	}
+	for i, param := range params {
+		if strings.TrimSpace(record[i]) != param.Name {
+			return false
+		}
+	}
+	return true
+}
+
+// Функция для подстановки значений строки файла параметров с проверкой
+func applySpecParams(table TableInfo, spec FilterSpec, params []specParam, record []string) error {
+	if len(record) != len(params) {
+		return fmt.Errorf("ожидается значений: %d, получено: %d", len(params), len(record))
+	}
+	for i, param := range params {
+		value := strings.TrimSpace(record[i])
+		if err := param.Validate(value); err != nil {
+			return fmt.Errorf("параметр '%s': %w", param.Name, err)
+		}
+		*param.Value = value
+	}
+	for _, condition := range spec.Conditions {
+		if condition.Type == filterRange {
+			if err := checkRangeOrder(table, condition.Column, condition.Values[0], condition.Values[1]); err != nil {
+				return fmt.Errorf("'%s': %w", condition.Column, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Функция для выполнения фильтра с текущими значениями параметров и записи результата:
+// в отдельный файл по шаблону или (если задан combinedWriter) в общий файл.
+// Возвращает количество записей и куда записан результат.
+func runSpecLine(stmt *sql.Stmt, table TableInfo, spec FilterSpec, params []specParam, line int,
+	outTemplate string, combinedWriter *csv.Writer, combinedPath string) (int, string, error) {
+	query, args, _ := buildFilterQuery(table, spec)
+	_, boundArgs := rebind(query, args)
+	rows, err := stmt.Query(boundArgs...)
+	if err != nil {
+		return 0, "", err
+	}
+	rs, err := scanRows(rows)
+	rows.Close()
+	if err != nil {
+		return 0, "", err
+	}
+
+	if combinedWriter != nil {
+		values := make([]string, len(params))
+		for i, param := range params {
+			values[i] = *param.Value
+		}
+		for _, row := range rs.Rows {
+			if err := combinedWriter.Write(append(append([]string(nil), values...), row...)); err != nil {
+				return 0, "", err
+			}
+		}
+		return len(rs.Rows), combinedPath, nil
+	}
+
+	path := expandOutTemplate(outTemplate, params, line)
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer file.Close()
+	writer := csv.NewWriter(file)
+	writer.Write(rs.Columns)
+	for _, row := range rs.Rows {
+		writer.Write(row)
+	}
+	writer.Flush()
+	return len(rs.Rows), path, writer.Error()
+}
+
+// Недопустимые в имени файла символы при подстановке значений параметров
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Zа-яА-ЯёЁ0-9._-]+`)
+
+// Функция для построения имени файла результата по шаблону
+func expandOutTemplate(template string, params []specParam, line int) string {
+	name := strings.ReplaceAll(template, "{line}", strconv.Itoa(line))
+	for _, param := range params {
+		name = strings.ReplaceAll(name, "{"+param.Name+"}", unsafeFileChars.ReplaceAllString(*param.Value, "_"))
+	}
+	return name
+}
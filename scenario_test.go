@@ -0,0 +1,146 @@
+package osl
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// Сценарии работы с меню: файлы testdata/scenarios/*.txt содержат ввод пользователя и полный
+// ожидаемый вывод (приглашения и результаты). Сценарий выполняется на базе SQLite с основными
+// таблицами и тестовыми данными. Строка заголовка "# флаги: ..." задает общие флаги запуска
+// (например, --readonly). После намеренного изменения интерфейса ожидаемый вывод
+// обновляется командой: go test -run TestScenarios -update
+
+var updateScenarios = flag.Bool("update", false, "перезаписать ожидаемый вывод сценариев")
+
+// Разделители частей файла сценария
+const (
+	scenarioInputMarker  = "--- ввод\n"
+	scenarioOutputMarker = "--- вывод\n"
+	scenarioFlagsPrefix  = "# флаги:"
+)
+
+// Подключение, с которым выполняются сценарии: имя базы выводится в заголовке меню
+var scenarioConfig = DBConfig{Driver: "sqlite", Name: "osl_test"}
+
+// Данные, с которыми выполняются все сценарии
+var scenarioSeed = []string{
+	"INSERT INTO categories (name, description) VALUES ('Процессоры', 'CPU'), ('Память', NULL)",
+	"INSERT INTO manufacturers (name, country, founded_year) VALUES ('Intel', 'США', 1968)",
+	"INSERT INTO components (name, category_id, manufacturer_id, model, price) VALUES ('Core i5', 1, 1, '12400F', 15990.5)",
+	"INSERT INTO stock (component_id, quantity, warehouse_location) VALUES (1, 12, 'A-1')",
+}
+
+// Время выполнения и время операций в выводе меняются от запуска к запуску
+var (
+	scenarioDurationRegex = regexp.MustCompile(`\d+(\.\d+)?(ns|µs|ms|s|m)\b`)
+	scenarioClockRegex    = regexp.MustCompile(`\b\d{2}:\d{2}:\d{2}\b`)
+)
+
+func TestScenarios(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "scenarios", "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("нет файлов сценариев в testdata/scenarios")
+	}
+	for _, file := range files {
+		file := file
+		t.Run(strings.TrimSuffix(filepath.Base(file), ".txt"), func(t *testing.T) {
+			runScenario(t, file)
+		})
+	}
+}
+
+// Функция для выполнения одного сценария и сравнения вывода с ожидаемым
+func runScenario(t *testing.T, file string) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, rest, found := strings.Cut(string(data), scenarioInputMarker)
+	if !found {
+		t.Fatalf("в сценарии нет раздела %q", strings.TrimSpace(scenarioInputMarker))
+	}
+	input, expected, found := strings.Cut(rest, scenarioOutputMarker)
+	if !found {
+		t.Fatalf("в сценарии нет раздела %q", strings.TrimSpace(scenarioOutputMarker))
+	}
+
+	openBaseSchema(t, scenarioSeed...)
+	applyScenarioFlags(t, header)
+	var code int
+	output := captureOutput(t, func() {
+		code = mainMenu(bufio.NewReader(strings.NewReader(input)))
+	})
+	if code != 0 {
+		t.Errorf("код завершения меню %d, ожидался 0", code)
+	}
+	output = scenarioDurationRegex.ReplaceAllString(output, "<время>")
+	output = scenarioClockRegex.ReplaceAllString(output, "<чч:мм:сс>")
+
+	if *updateScenarios {
+		updated := header + scenarioInputMarker + input + scenarioOutputMarker + output
+		if err := os.WriteFile(file, []byte(updated), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	if output != expected {
+		t.Errorf("вывод сценария отличается от ожидаемого (go test -run TestScenarios -update для обновления)\n%s",
+			transcriptDiff(expected, output))
+	}
+}
+
+// Функция для установки подключения и флагов запуска из заголовка сценария
+// (исходные значения восстанавливаются после теста)
+func applyScenarioFlags(t *testing.T, header string) {
+	t.Helper()
+	savedConfig, savedWrite, savedLarge, savedDDL := activeConfig, allowWriteSQL, allowLargeChanges, allowDDL
+	t.Cleanup(func() {
+		activeConfig, allowWriteSQL, allowLargeChanges, allowDDL = savedConfig, savedWrite, savedLarge, savedDDL
+	})
+	activeConfig = scenarioConfig
+
+	for _, line := range strings.Split(header, "\n") {
+		value, found := strings.CutPrefix(line, scenarioFlagsPrefix)
+		if !found {
+			continue
+		}
+		flags, rest := globalFlags(strings.Fields(value))
+		if len(rest) > 0 {
+			t.Fatalf("неизвестные флаги сценария: %v", rest)
+		}
+		allowWriteSQL = flags.AllowWrite
+		assumeYes = flags.AssumeYes
+		readOnly = flags.ReadOnly
+		allowLargeChanges = flags.AllowLargeChanges
+		allowDDL = flags.AllowDDL
+	}
+}
+
+// Функция для описания первого расхождения ожидаемого и полученного вывода (по строкам)
+func transcriptDiff(expected, actual string) string {
+	want := strings.Split(expected, "\n")
+	got := strings.Split(actual, "\n")
+	for i := 0; i < len(want) || i < len(got); i++ {
+		var w, g string
+		if i < len(want) {
+			w = want[i]
+		}
+		if i < len(got) {
+			g = got[i]
+		}
+		if w != g {
+			return fmt.Sprintf("строка %d:\n  ожидалось: %s\n  получено:  %s", i+1, w, g)
+		}
+	}
+	return ""
+}
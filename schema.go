@@ -0,0 +1,737 @@
+package osl
+
+import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Структура с описанием колонки из каталога БД
+type ColumnInfo struct {
+	Name     string
+	Type     string
+	Nullable bool
+	Default  string
+	IsPK     bool
+	FKTarget string // таблица, на которую ссылается колонка (пусто — не внешний ключ)
+	Identity bool   // значение заполняет СУБД (IDENTITY, AUTO_INCREMENT)
+
+	MaxLength int // максимальная длина строки в символах (0 — не ограничена)
+	Precision int // количество значащих цифр десятичного числа (0 — не задано)
+	Scale     int // количество цифр после запятой
+}
+
+// Функция для разделения имени вида "схема.таблица" на схему и имя таблицы
+func splitTableName(name string) (string, string) {
+	if schema, table, found := strings.Cut(name, "."); found {
+		return schema, table
+	}
+	return "", name
+}
+
+// Функция для получения схемы (пусто — схема по умолчанию) и имени таблицы без схемы
+func (t TableInfo) schemaAndName() (string, string) {
+	schema, name := splitTableName(t.Name)
+	if t.Schema != "" {
+		schema = t.Schema
+	}
+	return schema, name
+}
+
+// Функция для получения имени таблицы для SQL: экранированное, со схемой, если она известна.
+// Имена вроде "order" или "user" без экранирования являются зарезервированными словами.
+func (t TableInfo) SQLName() string {
+	schema, name := t.schemaAndName()
+	if schema == "" {
+		return dialect.QuoteIdent(name)
+	}
+	return dialect.QuoteIdent(schema) + "." + dialect.QuoteIdent(name)
+}
+
+// Функция для получения имени таблицы для SQL по имени из списка таблиц
+// (неизвестная таблица, например из правила, экранируется как есть)
+func tableRef(name string) string {
+	if table, ok := findTable(name); ok {
+		return table.SQLName()
+	}
+	return TableInfo{Name: name}.SQLName()
+}
+
+// Функция для получения списка колонок для SQL через запятую; каждое имя экранируется,
+// поэтому колонки с зарезервированными именами (order, user) не ломают запрос
+func columnList(columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = dialect.QuoteIdent(column)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// Функция для определения схем таблиц по каталогу БД. Схема запоминается, только если
+// таблицы нет в текущей схеме (например, она есть лишь в sales); СУБД без схем пропускаются.
+func loadTableSchemas() {
+	query := dialect.TableSchemaQuery()
+	if query == "" {
+		return
+	}
+	for i := range tables {
+		table := &tables[i]
+		if schema, _ := splitTableName(table.Name); schema != "" {
+			table.Schema = schema
+			continue
+		}
+		var schema string
+		var current bool
+		err := dbScanRow(query, []interface{}{table.Name}, &schema, &current)
+		if errors.Is(err, sql.ErrNoRows) {
+			logToFileAndScreen(fmt.Sprintf("Ошибка: таблица %s не найдена ни в одной схеме", table.Name))
+			continue
+		}
+		if err != nil {
+			logToFileAndScreen(fmt.Sprintf("Не удалось определить схему таблицы %s: %v", table.Name, err))
+			continue
+		}
+		if !current {
+			table.Schema = schema
+			logToFileAndScreen(fmt.Sprintf("Таблица %s найдена в схеме %s", table.Name, schema))
+		}
+	}
+}
+
+// Функция для загрузки типов колонок всех таблиц по пустой выборке
+func loadColumnTypes() {
+	for i := range tables {
+		table := &tables[i]
+		table.Types = make(map[string]string)
+
+		rows, err := dbQuery(fmt.Sprintf("SELECT * FROM %s LIMIT 0", table.SQLName()))
+		if err != nil {
+			logToFileAndScreen(fmt.Sprintf("Не удалось получить типы колонок таблицы %s: %v", table.Name, err))
+			continue
+		}
+		columnTypes, err := rows.ColumnTypes()
+		rows.Close()
+		if err != nil {
+			logToFileAndScreen(fmt.Sprintf("Не удалось получить типы колонок таблицы %s: %v", table.Name, err))
+			continue
+		}
+		for _, columnType := range columnTypes {
+			table.Types[columnType.Name()] = columnTypeName(columnType)
+		}
+	}
+}
+
+// Функция для отбора колонок, значения которых можно вводить с клавиатуры.
+// Колонки неподдерживаемых типов пропускаются с пояснением.
+func editableColumns(table TableInfo, columns []string) []string {
+	result := make([]string, 0, len(columns))
+	for _, column := range columns {
+		if dbType := table.Types[column]; !isRenderableType(dbType) {
+			fmt.Fprintln(stdout, msg("schema.unsupported_type", column, strings.ToLower(dbType)))
+			continue
+		}
+		result = append(result, column)
+	}
+	return result
+}
+
+// Функция для загрузки внешних ключей из каталога текущей СУБД.
+// Найденные связи дополняют известные заранее; при ошибке остаются только известные.
+func loadForeignKeys() {
+	rows, err := dbQuery(dialect.ForeignKeysQuery())
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Не удалось загрузить внешние ключи: %v", err))
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var schema, tableName, column, refSchema, refTable string
+		var current sql.NullString
+		if err := rows.Scan(&schema, &tableName, &column, &refSchema, &refTable, &current); err != nil {
+			logToFileAndScreen(fmt.Sprintf("Ошибка чтения внешнего ключа: %v", err))
+			continue
+		}
+		i := catalogTableIndex(schema, tableName, current.String)
+		if i < 0 {
+			continue
+		}
+		if tables[i].ForeignKeys == nil {
+			tables[i].ForeignKeys = make(map[string]string)
+		}
+		// Родитель записывается под именем из списка таблиц программы (например, sales.order)
+		if j := catalogTableIndex(refSchema, refTable, current.String); j >= 0 {
+			refTable = tables[j].Name
+		} else if refSchema != current.String {
+			refTable = refSchema + "." + refTable
+		}
+		tables[i].ForeignKeys[column] = refTable
+	}
+}
+
+// Функция для поиска таблицы программы по схеме и имени из каталога БД; таблица без
+// схемы находится в текущей схеме current. Возвращает -1, если таблица не используется.
+func catalogTableIndex(schema, name, current string) int {
+	for i := range tables {
+		tableSchema, tableName := tables[i].schemaAndName()
+		if tableSchema == "" {
+			tableSchema = current
+		}
+		if tableSchema == schema && tableName == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Связь между таблицами по внешнему ключу: колонка Column дочерней таблицы ссылается на родительскую
+type tableRelation struct {
+	Parent string
+	Child  string
+	Column string
+}
+
+// Функция для вывода связи в списке и журнале
+func (r tableRelation) String() string {
+	return fmt.Sprintf("%s → %s.%s", r.Parent, r.Child, r.Column)
+}
+
+// Функция для построения связей между таблицами по их внешним ключам (в порядке таблиц и колонок).
+// Учитываются только ссылки на таблицы из списка, иначе родительскую запись не добавить.
+func loadRelations() {
+	relatedTables = nil
+	for _, table := range tables {
+		columns := make([]string, 0, len(table.ForeignKeys))
+		for column := range table.ForeignKeys {
+			columns = append(columns, column)
+		}
+		sort.Slice(columns, func(i, j int) bool {
+			pi, pj := columnPosition(table, columns[i]), columnPosition(table, columns[j])
+			if pi != pj {
+				return pi < pj
+			}
+			return columns[i] < columns[j]
+		})
+		for _, column := range columns {
+			if _, ok := findTable(table.ForeignKeys[column]); !ok {
+				continue
+			}
+			relatedTables = append(relatedTables, tableRelation{Parent: table.ForeignKeys[column], Child: table.Name, Column: column})
+		}
+	}
+	logToFileAndScreen(fmt.Sprintf("Найдено связей между таблицами: %d", len(relatedTables)))
+}
+
+// Функция для получения позиции колонки в списке колонок таблицы (неизвестные — в конце по имени)
+func columnPosition(table TableInfo, column string) int {
+	for i, name := range table.Columns {
+		if name == column {
+			return i
+		}
+	}
+	return len(table.Columns)
+}
+
+// Функция для получения тех из ids, записи с которыми есть в таблице (в исходном порядке)
+func existingIDs(table string, ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	key, err := tableKeyRef(table)
+	if err != nil {
+		return nil, err
+	}
+	where, args := inListCondition(tableKeyColumn(table), ids, 1)
+	rows, err := dbQuery(fmt.Sprintf("SELECT %s FROM %s WHERE %s", key, tableRef(table), where), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	found := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		found[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, id := range ids {
+		if found[id] {
+			result = append(result, id)
+		}
+	}
+	return result, nil
+}
+
+// Функция для получения ключей записей, удовлетворяющих условию WHERE
+func matchingIDs(table, where string, args []interface{}) ([]string, error) {
+	key, err := tableKeyRef(table)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := dbQuery(fmt.Sprintf("SELECT %[1]s FROM %[2]s WHERE %[3]s ORDER BY %[1]s", key, tableRef(table), where), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Функция для получения ID из ids, которых нет среди existing
+func missingIDs(ids, existing []string) []string {
+	found := make(map[string]bool, len(existing))
+	for _, id := range existing {
+		found[id] = true
+	}
+	var missing []string
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
+
+// Функция для проверки существования записи в родительской таблице (по ее первичному ключу,
+// на который ссылается внешний ключ)
+func foreignKeyExists(refTable, id string) (bool, error) {
+	key, err := tableKeyRef(refTable)
+	if err != nil {
+		return false, err
+	}
+	var exists int
+	err = dbScanRow(fmt.Sprintf("SELECT 1 FROM %s WHERE %s = $1", tableRef(refTable), key), []interface{}{id}, &exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Функция для загрузки структуры колонок всех таблиц
+func loadColumnDetails() {
+	for i := range tables {
+		details, err := queryColumnDetails(tables[i])
+		if err != nil {
+			logToFileAndScreen(fmt.Sprintf("Не удалось получить структуру таблицы %s: %v", tables[i].Name, err))
+			continue
+		}
+		tables[i].Details = details
+		// Для таблиц из конфигурации колонки известны только из каталога
+		if len(tables[i].Columns) == 0 {
+			for _, column := range details {
+				tables[i].Columns = append(tables[i].Columns, column.Name)
+			}
+			continue
+		}
+		syncCatalogColumns(&tables[i])
+	}
+}
+
+// Функция для сверки известных заранее колонок с каталогом: колонки, которых нет в таблице,
+// убираются, а новые колонки из каталога добавляются в конец списка
+func syncCatalogColumns(table *TableInfo) {
+	if len(table.Details) == 0 {
+		return
+	}
+	inCatalog := make(map[string]bool, len(table.Details))
+	for _, column := range table.Details {
+		inCatalog[column.Name] = true
+	}
+	known := make(map[string]bool, len(table.Columns))
+	columns := make([]string, 0, len(table.Details))
+	for _, column := range table.Columns {
+		known[column] = true
+		if inCatalog[column] {
+			columns = append(columns, column)
+		} else {
+			logToFileAndScreen(fmt.Sprintf("Колонка %s.%s не найдена в каталоге БД и не используется", table.Name, column))
+		}
+	}
+	for _, column := range table.Details {
+		if !known[column.Name] {
+			columns = append(columns, column.Name)
+			logToFileAndScreen(fmt.Sprintf("Колонка %s.%s добавлена из каталога БД", table.Name, column.Name))
+		}
+	}
+	table.Columns = columns
+}
+
+// Функция для получения колонок всех найденных в БД таблиц (таблица без колонок в каталоге
+// считается отсутствующей)
+func schemaSnapshot() map[string][]string {
+	snapshot := make(map[string][]string, len(tables))
+	for _, table := range tables {
+		if len(table.Details) == 0 {
+			continue
+		}
+		columns := make([]string, len(table.Details))
+		for i, column := range table.Details {
+			columns[i] = column.Name
+		}
+		snapshot[table.Name] = columns
+	}
+	return snapshot
+}
+
+// Функция для сравнения двух снимков структуры БД; возвращает строки отчета об изменениях
+func schemaChanges(before, after map[string][]string) []string {
+	names := make(map[string]bool, len(before)+len(after))
+	for name := range before {
+		names[name] = true
+	}
+	for name := range after {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var changes []string
+	for _, name := range sorted {
+		oldColumns, wasPresent := before[name]
+		newColumns, isPresent := after[name]
+		switch {
+		case !wasPresent:
+			changes = append(changes, msg("reload.table_added", name, strings.Join(newColumns, ", ")))
+		case !isPresent:
+			changes = append(changes, msg("reload.table_removed", name))
+		default:
+			for _, column := range columnsMissing(newColumns, oldColumns) {
+				changes = append(changes, msg("reload.column_added", name, column))
+			}
+			for _, column := range columnsMissing(oldColumns, newColumns) {
+				changes = append(changes, msg("reload.column_removed", name, column))
+			}
+		}
+	}
+	return changes
+}
+
+// Функция для получения колонок из columns, которых нет в other
+func columnsMissing(columns, other []string) []string {
+	present := make(map[string]bool, len(other))
+	for _, column := range other {
+		present[column] = true
+	}
+	var result []string
+	for _, column := range columns {
+		if !present[column] {
+			result = append(result, column)
+		}
+	}
+	return result
+}
+
+// Пункт 30: Повторная загрузка структуры БД (таблицы, колонки, ключи и связи) без перезапуска
+func reloadSchema() {
+	before := schemaSnapshot()
+	loadSchema()
+	changes := schemaChanges(before, schemaSnapshot())
+
+	if len(changes) == 0 {
+		fmt.Fprintln(stdout, msg("reload.no_changes"))
+	} else {
+		fmt.Fprintln(stdout, msg("reload.changes_title"))
+		for _, change := range changes {
+			fmt.Fprintln(stdout, change)
+		}
+	}
+	fmt.Fprintln(stdout, msg("reload.done", len(tables)))
+	logToFileAndScreen(fmt.Sprintf("Структура БД загружена повторно, изменений: %d", len(changes)))
+}
+
+// Функция для чтения структуры колонок таблицы из каталога текущей СУБД
+func queryColumnDetails(table TableInfo) ([]ColumnInfo, error) {
+	schema, name := table.schemaAndName()
+	rows, err := dbQuery(dialect.ColumnsQuery(), name, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var details []ColumnInfo
+	for rows.Next() {
+		var column ColumnInfo
+		if err := rows.Scan(&column.Name, &column.Type, &column.Nullable, &column.Default, &column.IsPK,
+			&column.Identity, &column.MaxLength, &column.Precision, &column.Scale); err != nil {
+			return nil, err
+		}
+		if column.MaxLength == 0 && column.Precision == 0 {
+			column.MaxLength, column.Precision, column.Scale = typeLimits(column.Type)
+		}
+		column.FKTarget = foreignKeyTarget(table, column.Name)
+		details = append(details, column)
+	}
+	return details, rows.Err()
+}
+
+// Пункт 9: Структура таблицы
+func describeTable(reader *bufio.Reader) {
+	tableIndex := selectTable(reader, msg("select_table.describe"))
+	if tableIndex == -1 {
+		return
+	}
+	table := &tables[tableIndex]
+
+	// Структура читается заново, чтобы отражать текущее состояние БД
+	details, err := queryColumnDetails(*table)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка получения структуры таблицы %s: %v", table.Name, err))
+		printError(msg("schema.failed"))
+		return
+	}
+	table.Details = details
+
+	// Колонки выводятся в том же порядке, что и при просмотре таблицы
+	byName := make(map[string]ColumnInfo, len(details))
+	names := make([]string, len(details))
+	for i, column := range details {
+		byName[column.Name] = column
+		names[i] = column.Name
+	}
+
+	rs := &ResultSet{
+		Columns: []string{msg("schema.col_name"), msg("schema.col_type"), "NOT NULL", msg("schema.col_default"), msg("schema.col_key")},
+		Types:   make([]string, 5),
+	}
+	for _, name := range orderColumns(names, table.Columns) {
+		column := byName[name]
+		rs.Rows = append(rs.Rows, []string{column.Name, column.Type, yesNo(!column.Nullable), column.Default, describeKey(column)})
+	}
+
+	fmt.Fprint(stdout, msg("schema.title", table.Name))
+	printTable(rs)
+
+	// Сверка с метаданными, загруженными программой при запуске
+	mismatches := metadataMismatches(*table, details)
+	if len(mismatches) == 0 {
+		fmt.Fprintln(stdout, msg("schema.matches"))
+		return
+	}
+	fmt.Fprintln(stdout, msg("schema.mismatches"))
+	for _, mismatch := range mismatches {
+		fmt.Fprintln(stdout, "- " + mismatch)
+	}
+	logToFileAndScreen(fmt.Sprintf("Структура таблицы %s: расхождения с метаданными: %s", table.Name, strings.Join(mismatches, "; ")))
+}
+
+// Функция для поиска расхождений между колонками, известными программе, и каталогом БД
+func metadataMismatches(table TableInfo, details []ColumnInfo) []string {
+	inCatalog := make(map[string]bool, len(details))
+	for _, column := range details {
+		inCatalog[column.Name] = true
+	}
+	known := make(map[string]bool, len(table.Columns))
+	var mismatches []string
+	for _, column := range table.Columns {
+		known[column] = true
+		if !inCatalog[column] {
+			mismatches = append(mismatches, msg("schema.missing_in_db", column))
+		}
+	}
+	for _, column := range details {
+		if !known[column.Name] {
+			mismatches = append(mismatches, msg("schema.unknown_column", column.Name))
+		}
+	}
+	return mismatches
+}
+
+// Функция для описания ключей колонки
+func describeKey(column ColumnInfo) string {
+	var keys []string
+	if column.IsPK {
+		keys = append(keys, "PK")
+	}
+	if column.FKTarget != "" {
+		keys = append(keys, "FK -> "+column.FKTarget)
+	}
+	return strings.Join(keys, ", ")
+}
+
+// Функция для вывода логического значения словами
+func yesNo(value bool) string {
+	if value {
+		return msg("common.yes")
+	}
+	return msg("common.no")
+}
+
+// Функция для получения колонки первичного ключа (первой из составного ключа).
+// Пусто — у таблицы нет первичного ключа. Если структура из каталога неизвестна, ключом считается id.
+func primaryKeyColumn(table TableInfo) string {
+	for _, column := range table.Details {
+		if column.IsPK {
+			return column.Name
+		}
+	}
+	if len(table.Details) == 0 && containsString(table.Columns, "id") {
+		return "id"
+	}
+	return ""
+}
+
+// Функция для получения всех колонок первичного ключа таблицы в порядке колонок
+func primaryKeyColumns(table TableInfo) []string {
+	var keys []string
+	for _, column := range table.Details {
+		if column.IsPK {
+			keys = append(keys, column.Name)
+		}
+	}
+	if pk := primaryKeyColumn(table); len(keys) == 0 && pk != "" {
+		keys = append(keys, pk)
+	}
+	return keys
+}
+
+// Функция для получения колонки ключа, по которой записи таблицы находятся при обновлении,
+// удалении и отмене. Пусто — у таблицы нет первичного ключа из одной колонки.
+// Для таблицы, структура которой не загружена, ключом считается id.
+func tableKeyColumn(name string) string {
+	table, ok := findTable(name)
+	if !ok {
+		return "id"
+	}
+	if keys := primaryKeyColumns(table); len(keys) == 1 {
+		return keys[0]
+	}
+	return ""
+}
+
+// Функция для получения экранированной колонки ключа для запросов по ключу записи
+func tableKeyRef(name string) (string, error) {
+	key := tableKeyColumn(name)
+	if key == "" {
+		return "", fmt.Errorf("у таблицы %s нет первичного ключа из одной колонки", name)
+	}
+	return dialect.QuoteIdent(key), nil
+}
+
+// Функция для проверки, заполняет ли значение колонки сама СУБД: первичный ключ со значением
+// по умолчанию (SERIAL, IDENTITY, AUTO_INCREMENT, gen_random_uuid()), ключ INTEGER PRIMARY KEY
+// в SQLite и любые колонки, которые берут значение из последовательности
+func autoGeneratedColumn(table TableInfo, column string) bool {
+	if len(table.Details) == 0 {
+		return column == "id"
+	}
+	pkCount := 0
+	for _, info := range table.Details {
+		if info.IsPK {
+			pkCount++
+		}
+	}
+	for _, info := range table.Details {
+		if info.Name != column {
+			continue
+		}
+		if info.Identity || isSerialColumn(info) {
+			return true
+		}
+		if !info.IsPK {
+			return false
+		}
+		if info.Default != "" {
+			return true
+		}
+		// В SQLite единственный ключ INTEGER PRIMARY KEY — псевдоним rowid
+		_, sqlite := dialect.(sqliteDialect)
+		return sqlite && pkCount == 1 && strings.EqualFold(info.Type, "INTEGER")
+	}
+	return false
+}
+
+// Функция для получения колонок, значения которых вводятся при добавлении записи
+// (без колонок, которые заполняет СУБД)
+func insertableColumns(table TableInfo) []string {
+	columns := make([]string, 0, len(table.Columns))
+	for _, column := range table.Columns {
+		if !autoGeneratedColumn(table, column) {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// Функция для упорядочивания колонок всех таблиц: сначала по каталогу БД,
+// затем с учетом порядка из конфигурации (column_order)
+func applyColumnOrder() {
+	for i := range tables {
+		table := &tables[i]
+		catalog := make([]string, len(table.Details))
+		for j, column := range table.Details {
+			catalog[j] = column.Name
+		}
+		table.Columns = orderColumns(table.Columns, catalog)
+
+		override := appConfig.ColumnOrder[table.Name]
+		for _, name := range unknownColumns(table.Columns, override) {
+			logToFileAndScreen(fmt.Sprintf("Порядок колонок для %s: колонки '%s' нет в таблице, она пропущена", table.Name, name))
+		}
+		table.Columns = orderColumns(table.Columns, override)
+	}
+	for name := range appConfig.ColumnOrder {
+		if _, ok := findTable(name); !ok {
+			logToFileAndScreen(fmt.Sprintf("Порядок колонок: таблица '%s' не найдена и пропущена", name))
+		}
+	}
+}
+
+// Функция для упорядочивания колонок: перечисленные в order идут первыми в заданном порядке,
+// остальные — следом в исходном порядке. Имена из order, которых нет среди колонок, пропускаются.
+func orderColumns(columns, order []string) []string {
+	present := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		present[column] = true
+	}
+
+	result := make([]string, 0, len(columns))
+	placed := make(map[string]bool, len(columns))
+	for _, column := range order {
+		if present[column] && !placed[column] {
+			result = append(result, column)
+			placed[column] = true
+		}
+	}
+	for _, column := range columns {
+		if !placed[column] {
+			result = append(result, column)
+		}
+	}
+	return result
+}
+
+// Функция для поиска имен из order, которых нет среди колонок таблицы
+func unknownColumns(columns, order []string) []string {
+	present := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		present[column] = true
+	}
+	var unknown []string
+	for _, column := range order {
+		if !present[column] {
+			unknown = append(unknown, column)
+		}
+	}
+	return unknown
+}
@@ -0,0 +1,177 @@
+package osl
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSQLNameQuoting(t *testing.T) {
+	saved := dialect
+	defer func() { dialect = saved }()
+
+	tests := []struct {
+		dialect Dialect
+		table   TableInfo
+		want    string
+	}{
+		{postgresDialect{}, TableInfo{Name: "order"}, `"order"`},
+		{postgresDialect{}, TableInfo{Name: "sales.order"}, `"sales"."order"`},
+		{postgresDialect{}, TableInfo{Name: "order", Schema: "sales"}, `"sales"."order"`},
+		{postgresDialect{}, TableInfo{Name: `we"ird`}, `"we""ird"`},
+		{mysqlDialect{}, TableInfo{Name: "sales.order"}, "`sales`.`order`"},
+	}
+	for _, tt := range tests {
+		dialect = tt.dialect
+		if got := tt.table.SQLName(); got != tt.want {
+			t.Errorf("SQLName(%+v) = %s, ожидалось %s", tt.table, got, tt.want)
+		}
+	}
+
+	dialect = postgresDialect{}
+	if got := columnList([]string{"user", "group", "name"}); got != `"user", "group", "name"` {
+		t.Errorf("columnList = %s", got)
+	}
+}
+
+// Таблица и колонки с зарезервированными именами, в том числе в другой схеме
+func TestReservedWordTable(t *testing.T) {
+	openSchema(t, []string{"order", "sales.order"},
+		`CREATE TABLE "order" (id INTEGER PRIMARY KEY, "user" TEXT NOT NULL, "group" INTEGER)`,
+		"ATTACH DATABASE ':memory:' AS sales",
+		`CREATE TABLE sales."order" (id INTEGER PRIMARY KEY, "select" TEXT)`)
+	assumeYes = true
+
+	if got := tables[0].Columns; strings.Join(got, ",") != "id,user,group" {
+		t.Fatalf("колонки таблицы order: %v", got)
+	}
+	if got := insertableColumns(tables[0]); strings.Join(got, ",") != "user,group" {
+		t.Fatalf("колонки для ввода: %v", got)
+	}
+	if got := tables[1].Columns; strings.Join(got, ",") != "id,select" {
+		t.Fatalf("колонки таблицы sales.order: %v", got)
+	}
+
+	captureOutput(t, func() {
+		executeInsert(scriptReader(), InsertSpec{Table: "order", Columns: []string{"user", "group"},
+			Records: [][]string{{"alice", "1"}, {"bob", "2"}}})
+		executeInsert(scriptReader(), InsertSpec{Table: "sales.order", Columns: []string{"select"},
+			Records: [][]string{{"x"}}})
+	})
+	if got := queryString(t, `SELECT COUNT(*) FROM "order"`); got != "2" {
+		t.Fatalf("в таблицу order добавлено %s записей, ожидалось 2", got)
+	}
+	if got := queryString(t, `SELECT "select" FROM sales."order"`); got != "x" {
+		t.Fatalf("в таблицу sales.order добавлено %q", got)
+	}
+
+	// Выборка с фильтром по колонке с зарезервированным именем
+	spec := FilterSpec{Table: "order", Operator: " AND ", Columns: []string{"user", "group"},
+		Conditions: []FilterCondition{{Column: "group", Type: filterEquals, Values: []string{"2"}}}}
+	query, args, _ := buildFilterQuery(tables[0], spec)
+	rs, err := queryRecords(query, args...)
+	if err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	if len(rs.Rows) != 1 || rs.Rows[0][0] != "bob" {
+		t.Fatalf("результат фильтра %v", rs.Rows)
+	}
+
+	// Просмотр всей таблицы с сортировкой по ключу
+	output := captureOutput(t, func() { viewTable(scriptReader("2", "", "", "")) })
+	if !strings.Contains(output, "| select") || !strings.Contains(output, "Найдено записей: 1") {
+		t.Errorf("просмотр sales.order:\n%s", output)
+	}
+}
+
+// Порядок колонок из конфигурации: перечисленные колонки идут первыми, остальные — в порядке
+// каталога, в том числе добавленные в таблицу после того, как порядок был записан.
+// Неизвестные колонки и таблицы пропускаются с записью в журнал.
+func TestColumnOrderOverride(t *testing.T) {
+	openSchema(t, []string{"parts"},
+		"CREATE TABLE parts (id INTEGER PRIMARY KEY, model TEXT, stock INTEGER, name TEXT, price NUMERIC)")
+	appConfig.ColumnOrder = map[string][]string{
+		"parts":  {"name", "price", "discontinued"},
+		"ghosts": {"id"},
+	}
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	t.Cleanup(func() { log.SetOutput(io.Discard) })
+	reload := func() []string {
+		logged.Reset()
+		tables = []TableInfo{{Name: "parts"}}
+		loadTableSchemas()
+		loadColumnTypes()
+		loadColumnDetails()
+		applyColumnOrder()
+		return tables[0].Columns
+	}
+
+	if got := strings.Join(reload(), ","); got != "name,price,id,model,stock" {
+		t.Errorf("частичный порядок: %s", got)
+	}
+	for _, want := range []string{"колонки 'discontinued' нет в таблице", "таблица 'ghosts' не найдена"} {
+		if !strings.Contains(logged.String(), want) {
+			t.Errorf("в журнале нет %q:\n%s", want, logged.String())
+		}
+	}
+
+	// Колонки, добавленные позже: упомянутая в порядке встает на свое место, остальные — в конец
+	mustExec(t, "ALTER TABLE parts ADD COLUMN warranty INTEGER")
+	mustExec(t, "ALTER TABLE parts ADD COLUMN discontinued INTEGER")
+	if got := strings.Join(reload(), ","); got != "name,price,discontinued,id,model,stock,warranty" {
+		t.Errorf("порядок после добавления колонок: %s", got)
+	}
+	if strings.Contains(logged.String(), "discontinued") {
+		t.Errorf("колонка discontinued есть в таблице, но отмечена как неизвестная:\n%s", logged.String())
+	}
+
+	// Без порядка в конфигурации колонки идут в порядке каталога
+	appConfig.ColumnOrder = nil
+	if got := strings.Join(reload(), ","); got != "id,model,stock,name,price,warranty,discontinued" {
+		t.Errorf("порядок каталога: %s", got)
+	}
+}
+
+// Внешние ключи таблиц другой схемы привязываются к таблицам с тем же именем схемы,
+// а не к одноименным таблицам текущей схемы
+func TestQualifiedTableForeignKeys(t *testing.T) {
+	openSchema(t, []string{"customers", "orders", "sales.customers", "sales.orders"},
+		"CREATE TABLE customers (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, customer_id INTEGER REFERENCES customers(id))",
+		"ATTACH DATABASE ':memory:' AS sales",
+		"CREATE TABLE sales.customers (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE sales.orders (id INTEGER PRIMARY KEY, buyer_id INTEGER REFERENCES customers(id))",
+		"INSERT INTO sales.customers VALUES (1, 'ООО Ромашка')",
+		"INSERT INTO sales.orders VALUES (10, 1), (11, 1)")
+
+	for name, want := range map[string]map[string]string{
+		"orders":       {"customer_id": "customers"},
+		"sales.orders": {"buyer_id": "sales.customers"},
+	} {
+		table, _ := findTable(name)
+		if !reflect.DeepEqual(table.ForeignKeys, want) {
+			t.Errorf("внешние ключи %s: %v, ожидалось %v", name, table.ForeignKeys, want)
+		}
+	}
+	want := []tableRelation{
+		{Parent: "customers", Child: "orders", Column: "customer_id"},
+		{Parent: "sales.customers", Child: "sales.orders", Column: "buyer_id"},
+	}
+	if !reflect.DeepEqual(relatedTables, want) {
+		t.Errorf("связи %v, ожидалось %v", relatedTables, want)
+	}
+
+	// Каскадное удаление находит ссылающиеся записи в той же схеме
+	refs, err := collectReferences("sales.customers", []string{"1"}, map[string]bool{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 1 || refs[0].Table != "sales.orders" || refs[0].Count != 2 {
+		t.Errorf("ссылающиеся записи %+v", refs)
+	}
+}
@@ -0,0 +1,139 @@
+package osl
+
+import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Проверка схемы при запуске: после подключения проверяется, что в базе есть основные таблицы
+// (категории, производители, компоненты, склад). Недостающие таблицы перечисляются, и после
+// подтверждения создаются встроенным скриптом — только те, которых нет, в порядке зависимостей.
+// OSL_SKIP_SCHEMA_CHECK=true отключает проверку для баз с намеренно другой структурой.
+
+// Основная таблица программы и запрос ее создания
+type baseTable struct {
+	Name string
+	DDL  string // %[1]s заменяется определением колонки id для текущей СУБД
+}
+
+// Встроенный скрипт создания основных таблиц (родительские таблицы раньше дочерних)
+var baseSchema = []baseTable{
+	{"categories", `CREATE TABLE categories (
+	id %[1]s,
+	name VARCHAR(100) NOT NULL,
+	description TEXT
+)`},
+	{"manufacturers", `CREATE TABLE manufacturers (
+	id %[1]s,
+	name VARCHAR(100) NOT NULL,
+	country VARCHAR(100),
+	founded_year INTEGER
+)`},
+	{"components", `CREATE TABLE components (
+	id %[1]s,
+	name VARCHAR(200) NOT NULL,
+	category_id INTEGER,
+	manufacturer_id INTEGER,
+	model VARCHAR(100),
+	price NUMERIC(10, 2),
+	FOREIGN KEY (category_id) REFERENCES categories (id),
+	FOREIGN KEY (manufacturer_id) REFERENCES manufacturers (id)
+)`},
+	{"stock", `CREATE TABLE stock (
+	id %[1]s,
+	component_id INTEGER NOT NULL,
+	quantity INTEGER NOT NULL DEFAULT 0,
+	warehouse_location VARCHAR(100),
+	FOREIGN KEY (component_id) REFERENCES components (id)
+)`},
+}
+
+// Функция для получения запроса создания основной таблицы для текущей СУБД
+func (t baseTable) createQuery() string {
+	idColumn := "SERIAL PRIMARY KEY"
+	switch dialect.(type) {
+	case mysqlDialect:
+		idColumn = "INT AUTO_INCREMENT PRIMARY KEY"
+	case sqliteDialect:
+		idColumn = "INTEGER PRIMARY KEY AUTOINCREMENT"
+	}
+	return fmt.Sprintf(t.DDL, idColumn)
+}
+
+// Функция для проверки наличия таблицы в текущей схеме или (в PostgreSQL) в любой схеме поиска
+func tableExists(name string) (bool, error) {
+	var count int
+	if err := dbScanRow(dialect.TableExistsQuery(), []interface{}{name}, &count); err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+	query := dialect.TableSchemaQuery()
+	if query == "" {
+		return false, nil
+	}
+	var schema string
+	var current bool
+	err := dbScanRow(query, []interface{}{name}, &schema, &current)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Функция для проверки основных таблиц после подключения и создания недостающих с подтверждения
+func checkBaseSchema(reader *bufio.Reader) {
+	if envBool("OSL_SKIP_SCHEMA_CHECK", false) {
+		logToFileAndScreen("Проверка схемы БД пропущена (OSL_SKIP_SCHEMA_CHECK=true)")
+		return
+	}
+
+	var missing []baseTable
+	for _, table := range baseSchema {
+		exists, err := tableExists(table.Name)
+		if err != nil {
+			logToFileAndScreen(fmt.Sprintf("Не удалось проверить наличие таблицы %s: %v, проверка схемы пропущена", table.Name, err))
+			return
+		}
+		if !exists {
+			missing = append(missing, table)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	names := make([]string, len(missing))
+	for i, table := range missing {
+		names[i] = table.Name
+	}
+	logToFileAndScreen(fmt.Sprintf("В базе нет таблиц: %s", strings.Join(names, ", ")))
+	fmt.Fprintln(stdout, msg("schema_check.missing", strings.Join(names, ", ")))
+	if readOnly {
+		fmt.Fprintln(stdout, msg("schema_check.readonly"))
+		return
+	}
+	if !assumeYes && !promptConfirm(reader, msg("schema_check.confirm")) {
+		fmt.Fprintln(stdout, msg("schema_check.skipped"))
+		return
+	}
+
+	for _, table := range missing {
+		query := table.createQuery()
+		if dryRun {
+			printDryRun(query, nil, nil)
+			continue
+		}
+		if _, err := dbExec(query); err != nil {
+			logToFileAndScreen(fmt.Sprintf("Ошибка создания таблицы %s: %v", table.Name, err))
+			printError(msg("schema_check.failed", table.Name))
+			return
+		}
+		logToFileAndScreen(fmt.Sprintf("Создана таблица %s", table.Name))
+		fmt.Fprintln(stdout, msg("schema_check.created", table.Name))
+	}
+}
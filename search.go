@@ -0,0 +1,164 @@
+package osl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Поиск подстроки сразу во всех текстовых колонках таблицы (по типам из метаданных БД)
+// или во всех колонках: нетекстовые значения перед сравнением приводятся к тексту.
+// Символы % и _ в строке поиска экранируются и ищутся буквально; строка передается
+// параметром запроса, поэтому проверка допустимых символов к ней не применяется.
+
+// Области поиска
+const (
+	searchTextColumns = 1 // только текстовые колонки
+	searchAllColumns  = 2 // все колонки, кроме неотображаемых типов
+)
+
+// Символ экранирования в шаблоне LIKE (обратная косая черта в MySQL сама требует экранирования)
+const likeEscapeChar = "!"
+
+// Текстовые типы колонок (имена типов PostgreSQL, SQLite и MySQL)
+var textTypes = map[string]bool{
+	"TEXT": true, "VARCHAR": true, "BPCHAR": true, "CHAR": true, "NAME": true, "CITEXT": true,
+	"CHARACTER": true, "CHARACTER VARYING": true, "NVARCHAR": true, "NCHAR": true,
+	"TINYTEXT": true, "MEDIUMTEXT": true, "LONGTEXT": true,
+}
+
+// Функция для проверки, является ли тип в БД текстовым (размер вида VARCHAR(255) не учитывается)
+func isTextType(dbType string) bool {
+	dbType = strings.ToUpper(dbType)
+	if i := strings.Index(dbType, "("); i >= 0 {
+		dbType = strings.TrimSpace(dbType[:i])
+	}
+	return textTypes[dbType]
+}
+
+// Функция для получения текстовых колонок таблицы по метаданным БД
+func textColumns(table TableInfo) []string {
+	var columns []string
+	for _, column := range table.Columns {
+		if isTextType(table.Types[column]) {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// Функция для получения колонок таблицы, значения которых можно искать как текст
+func searchableColumns(table TableInfo) []string {
+	var columns []string
+	for _, column := range table.Columns {
+		if isRenderableType(table.Types[column]) {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// Функция для экранирования подстановочных символов шаблона LIKE
+func escapeLikePattern(term string) string {
+	replacer := strings.NewReplacer(likeEscapeChar, likeEscapeChar+likeEscapeChar,
+		"%", likeEscapeChar+"%", "_", likeEscapeChar+"_")
+	return replacer.Replace(term)
+}
+
+// Функция для построения условия поиска подстроки ($firstArg) в любой из колонок.
+// Нетекстовые колонки приводятся к тексту.
+func searchCondition(table TableInfo, columns []string, firstArg int) string {
+	conditions := make([]string, len(columns))
+	for i, column := range columns {
+		expr := dialect.QuoteIdent(column)
+		if !isTextType(table.Types[column]) {
+			expr = dialect.TextCast(expr)
+		}
+		conditions[i] = fmt.Sprintf("%s %s $%d ESCAPE '%s'", expr, dialect.CaseInsensitiveLike(), firstArg, likeEscapeChar)
+	}
+	return joinConditions(conditions, " OR ")
+}
+
+// Пункт 18: Поиск по текстовым или по всем колонкам
+func searchTable(reader *bufio.Reader) {
+	tableIndex := selectTable(reader, msg("select_table.search"))
+	if tableIndex == -1 {
+		return
+	}
+	table := tables[tableIndex]
+
+	fmt.Fprintln(stdout, msg("search.scope_text"))
+	fmt.Fprintln(stdout, msg("search.scope_all"))
+	fmt.Fprintln(stdout, msg("common.back"))
+	scope, ok := promptInt(reader, msg("search.scope_prompt"), 0, searchAllColumns)
+	if !ok || scope == 0 {
+		return
+	}
+	columns := textColumns(table)
+	if scope == searchAllColumns {
+		columns = searchableColumns(table)
+	}
+	if len(columns) == 0 {
+		fmt.Fprintln(stdout, msg("search.no_text_columns", table.Name))
+		return
+	}
+	fmt.Fprintln(stdout, msg("search.columns", strings.Join(columns, ", ")))
+
+	term, ok := promptValidated(reader, msg("search.prompt"), func(input string) error {
+		if input == "" {
+			return errors.New(msg("search.empty"))
+		}
+		return nil
+	})
+	if !ok {
+		return
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s %s",
+		columnList(table.Columns), table.SQLName(), withActiveRows(table, searchCondition(table, columns, 1)), orderByClause(table))
+	args := []interface{}{"%" + escapeLikePattern(term) + "%"}
+	logToFileAndScreen(fmt.Sprintf("Выполнение поиска: %s с параметрами %v", query, args))
+
+	rows, err := dbQuery(query, args...)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка выполнения поиска: %v", err))
+		printError(msg("search.failed"))
+		return
+	}
+	rs, err := scanRows(rows)
+	rows.Close()
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка чтения результата: %v", err))
+		return
+	}
+
+	if len(rs.Rows) == 0 {
+		fmt.Fprintln(stdout, msg("search.none", term))
+		logToFileAndScreen(fmt.Sprintf("Поиск '%s' в таблице %s: записей не найдено", term, table.Name))
+		return
+	}
+
+	addMatchedColumns(rs, columns, term)
+	printResult(rs)
+	printFoundRows(len(rs.Rows))
+	logToFileAndScreen(fmt.Sprintf("Поиск '%s' в таблице %s: найдено %d записей", term, table.Name, len(rs.Rows)))
+}
+
+// Функция для добавления в результат колонки с именами колонок, в которых найдена подстрока.
+// Сравнение без учета регистра повторяет ILIKE; если СУБД сравнила иначе (например, по правилу
+// сортировки), ячейка остается пустой.
+func addMatchedColumns(rs *ResultSet, columns []string, term string) {
+	lowerTerm := strings.ToLower(term)
+	rs.Columns = append(rs.Columns, msg("search.matched_column"))
+	rs.Types = append(rs.Types, "TEXT")
+	for r, row := range rs.Rows {
+		var matched []string
+		for c, column := range rs.Columns[:len(row)] {
+			if containsString(columns, column) && strings.Contains(strings.ToLower(row[c]), lowerTerm) {
+				matched = append(matched, column)
+			}
+		}
+		rs.Rows[r] = append(row, strings.Join(matched, ", "))
+	}
+}
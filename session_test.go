@@ -0,0 +1,127 @@
+package osl
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Полный сеанс через Run: подключение к файлу SQLite, создание основных таблиц,
+// добавление и просмотр записи, выход из меню
+func TestRunFullSession(t *testing.T) {
+	openTestDB(t)
+	testDB, savedConfig := db, activeConfig
+	t.Cleanup(func() {
+		testDB.Close()
+		activeConfig = savedConfig
+		log.SetOutput(io.Discard)
+		logToStdout = true
+	})
+	t.Setenv("OSL_DAILY_SUMMARY", "false")
+	path := filepath.Join(t.TempDir(), "session.db")
+
+	opts := Options{DB: DBConfig{Driver: "sqlite", Name: path}, LogTarget: logTargetStdout}
+	var output bytes.Buffer
+	err := Run(context.Background(), opts, strings.NewReader(strings.Join([]string{
+		// Логин и пароль (для SQLite не используются)
+		"admin", "",
+		// Создать недостающие основные таблицы
+		"да",
+		// Пункт 4: одна запись в categories
+		"4", "1", "1", "Процессоры", "CPU", "да",
+		// Пункт 1: просмотр categories без фильтра и сортировки
+		"1", "1", "", "", "",
+		"0",
+	}, "\n")+"\n"), &output, &output)
+	if err != nil {
+		t.Fatalf("Run: %v\n%s", err, output.String())
+	}
+	for _, want := range []string{msg("connect.ok"), "Процессоры", "CPU", msg("menu.bye")} {
+		if !strings.Contains(output.String(), want) {
+			t.Errorf("в выводе сеанса нет %q:\n%s", want, output.String())
+		}
+	}
+
+	// Изменения сеанса сохранены в файле базы
+	check, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer check.Close()
+	var name string
+	if err := check.QueryRow("SELECT name FROM categories").Scan(&name); err != nil || name != "Процессоры" {
+		t.Errorf("в базе категория %q, %v", name, err)
+	}
+}
+
+// Код завершения и сообщения об ошибках команды: ошибки пишутся в stderr сеанса,
+// ненулевой код возвращается как *ExitError
+func TestRunCommandStreams(t *testing.T) {
+	openTestDB(t)
+	testDB, savedConfig := db, activeConfig
+	t.Cleanup(func() {
+		testDB.Close()
+		activeConfig = savedConfig
+		log.SetOutput(io.Discard)
+		logToStdout = true
+	})
+	t.Setenv("OSL_DAILY_SUMMARY", "false")
+	t.Setenv("OSL_SKIP_SCHEMA_CHECK", "true")
+	path := filepath.Join(t.TempDir(), "session.db")
+
+	opts := Options{DB: DBConfig{Driver: "sqlite", Name: path, User: "admin"}, LogTarget: logTargetStdout,
+		Args: []string{"unknown"}}
+	var output, errOutput bytes.Buffer
+	err := Run(context.Background(), opts, strings.NewReader(""), &output, &errOutput)
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 2 {
+		t.Fatalf("Run = %v, ожидался код 2", err)
+	}
+	if !strings.Contains(errOutput.String(), msg("cli.unknown_command", "unknown")) {
+		t.Errorf("в stderr нет сообщения о неизвестной команде:\n%s", errOutput.String())
+	}
+	if strings.Contains(output.String(), msg("cli.unknown_command", "unknown")) {
+		t.Errorf("сообщение об ошибке попало в stdout:\n%s", output.String())
+	}
+
+	// Неизвестная СУБД — код 1 до подключения
+	opts = Options{DB: DBConfig{Driver: "oracle", User: "admin"}, LogTarget: logTargetStdout}
+	output.Reset()
+	err = Run(context.Background(), opts, strings.NewReader(""), &output, &output)
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 {
+		t.Errorf("Run с неизвестной СУБД = %v, ожидался код 1", err)
+	}
+}
+
+// Отмененный контекст завершает меню до выполнения пункта
+func TestRunCancelled(t *testing.T) {
+	openTestDB(t)
+	testDB, savedConfig := db, activeConfig
+	t.Cleanup(func() {
+		testDB.Close()
+		activeConfig = savedConfig
+		log.SetOutput(io.Discard)
+		logToStdout = true
+	})
+	t.Setenv("OSL_DAILY_SUMMARY", "false")
+	t.Setenv("OSL_SKIP_SCHEMA_CHECK", "true")
+	path := filepath.Join(t.TempDir(), "session.db")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	opts := Options{DB: DBConfig{Driver: "sqlite", Name: path, User: "admin"}, LogTarget: logTargetStdout}
+	var output bytes.Buffer
+	err := Run(ctx, opts, strings.NewReader("4\n"), &output, &output)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run = %v, ожидалась отмена", err)
+	}
+	if strings.Contains(output.String(), msg("menu.bye")) {
+		t.Errorf("меню выполнялось после отмены:\n%s", output.String())
+	}
+}
@@ -0,0 +1,308 @@
+package osl
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Мягкое удаление: если в таблице есть логическая колонка archived или колонка времени deleted_at,
+// удаление только помечает записи, а просмотр, фильтрация, поиск и выбор из списка скрывают их.
+// Колонка определяется по метаданным БД; таблицы без нее удаляются как обычно (DELETE).
+
+// Логические типы колонки archived (BOOLEAN в MySQL хранится как TINYINT)
+var archiveFlagTypes = map[string]bool{"BOOL": true, "BOOLEAN": true, "TINYINT": true}
+
+// Функция для получения колонки мягкого удаления таблицы (пусто — записи удаляются физически)
+func softDeleteColumn(table TableInfo) string {
+	if containsString(table.Columns, "archived") && archiveFlagTypes[strings.ToUpper(table.Types["archived"])] {
+		return "archived"
+	}
+	if containsString(table.Columns, "deleted_at") && isTimestampColumn(table, "deleted_at") {
+		return "deleted_at"
+	}
+	return ""
+}
+
+// Функция для получения условия отбора неархивных записей (пусто — в таблице нет мягкого удаления)
+func activeRowsCondition(table TableInfo) string {
+	switch column := softDeleteColumn(table); column {
+	case "archived":
+		return dialect.QuoteIdent(column) + " IS NOT TRUE"
+	case "deleted_at":
+		return dialect.QuoteIdent(column) + " IS NULL"
+	}
+	return ""
+}
+
+// Функция для получения условия отбора архивных записей
+func archivedRowsCondition(table TableInfo) string {
+	switch column := softDeleteColumn(table); column {
+	case "archived":
+		return dialect.QuoteIdent(column) + " IS TRUE"
+	case "deleted_at":
+		return dialect.QuoteIdent(column) + " IS NOT NULL"
+	}
+	return ""
+}
+
+// Функция для получения присваивания, которое архивирует или восстанавливает запись
+func archiveAssignment(table TableInfo, archive bool) string {
+	column := softDeleteColumn(table)
+	switch {
+	case column == "archived":
+		return fmt.Sprintf("%s = %s", dialect.QuoteIdent(column), strings.ToUpper(strconv.FormatBool(archive)))
+	case archive:
+		return dialect.QuoteIdent(column) + " = CURRENT_TIMESTAMP"
+	default:
+		return dialect.QuoteIdent(column) + " = NULL"
+	}
+}
+
+// Функция для добавления к условию WHERE отбора только неархивных записей
+func withActiveRows(table TableInfo, where string) string {
+	condition := activeRowsCondition(table)
+	switch {
+	case condition == "":
+		return where
+	case where == "":
+		return condition
+	}
+	return fmt.Sprintf("(%s) AND %s", where, condition)
+}
+
+// Функция для вопроса, показывать ли архивные записи (спрашивается, только если они есть)
+func promptIncludeArchived(reader *bufio.Reader, table TableInfo, prompt string) bool {
+	condition := archivedRowsCondition(table)
+	if condition == "" {
+		return false
+	}
+	var archived int
+	if err := dbScanRow(fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", table.SQLName(), condition), nil, &archived); err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка подсчета архивных записей в %s: %v", table.Name, err))
+		return false
+	}
+	if archived == 0 {
+		return false
+	}
+	return promptConfirm(reader, msg(prompt, archived))
+}
+
+// Пункт 19: Удаление записей (архивирование для таблиц с мягким удалением)
+func deleteRecords(reader *bufio.Reader) {
+	tableIndex := selectTable(reader, msg("select_table.delete"))
+	if tableIndex == -1 {
+		return
+	}
+	table := tables[tableIndex]
+	key := tableKeyColumn(table.Name)
+	if key == "" {
+		printError(msg("common.no_key", table.Name))
+		return
+	}
+	column := softDeleteColumn(table)
+	if column != "" {
+		fmt.Fprintln(stdout, msg("delete.soft_notice", table.Name, column))
+	} else {
+		fmt.Fprintln(stdout, msg("delete.hard_notice", table.Name))
+	}
+
+	input, ok := promptValidated(reader, msg("delete.ids_prompt"), func(input string) error {
+		_, err := parseKeyList(table, key, input)
+		return err
+	})
+	if !ok {
+		return
+	}
+	requested, _ := parseKeyList(table, key, input)
+
+	// Уже архивированные записи повторно не архивируются
+	where, args := inListCondition(key, requested, 1)
+	if column != "" {
+		where = withActiveRows(table, where)
+	}
+	ids, err := matchingIDs(table.Name, where, args)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка поиска записей для удаления: %v", err))
+		printError(msg("update.check_failed"))
+		return
+	}
+	if len(ids) == 0 {
+		fmt.Fprintln(stdout, msg("delete.none_found", table.Name))
+		return
+	}
+	if missing := missingIDs(requested, ids); len(missing) > 0 {
+		fmt.Fprintln(stdout, msg("delete.skipped", strings.Join(missing, ", ")))
+	}
+
+	// Записи, которые ссылаются на физически удаляемые, удаляются только с отдельного подтверждения
+	var refs []childReference
+	if column == "" {
+		refs, err = collectReferences(table.Name, ids, map[string]bool{})
+		if err != nil {
+			logToFileAndScreen(fmt.Sprintf("Ошибка поиска ссылающихся записей для %s: %v", table.Name, err))
+			printError(msg("update.check_failed"))
+			return
+		}
+	}
+
+	confirmKey := "delete.confirm_hard"
+	if column != "" {
+		confirmKey = "delete.confirm_soft"
+	}
+	if len(refs) > 0 {
+		if !confirmCascade(reader, table, refs) {
+			fmt.Fprintln(stdout, msg("delete.cancelled"))
+			return
+		}
+	} else if !promptConfirm(reader, msg(confirmKey, len(ids), table.Name)) {
+		fmt.Fprintln(stdout, msg("delete.cancelled"))
+		return
+	}
+
+	if column != "" {
+		setArchived(table, ids, true)
+		return
+	}
+
+	var deleted int64
+	if len(refs) > 0 {
+		deleted, err = deleteCascade(table, ids, refs)
+		if dryRun {
+			printDryRunPreview(table.Name, ids)
+			return
+		}
+	} else {
+		where, args = inListCondition(key, ids, 1)
+		query := fmt.Sprintf("DELETE FROM %s WHERE %s", table.SQLName(), where)
+		if dryRun {
+			printDryRun(query, nil, args)
+			printDryRunPreview(table.Name, ids)
+			return
+		}
+		logToFileAndScreen(fmt.Sprintf("Выполнение удаления: %s с параметрами %v", query, args))
+		err = dbTransaction(func(tx *sql.Tx) error {
+			// Удаляемые записи сохраняются в журнале аудита полностью
+			removed, err := auditSnapshot(tx, table.Name, where, args)
+			if err != nil {
+				return err
+			}
+			result, err := txExec(tx, query, args...)
+			if err != nil {
+				return err
+			}
+			deleted, _ = result.RowsAffected()
+			return writeAudit(tx, auditDelete, table.Name, removed)
+		})
+	}
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка удаления из таблицы %s: %v", table.Name, err))
+		printError(msg("delete.failed"))
+		return
+	}
+	// Физическое удаление отменить нельзя
+	forgetUndo(fmt.Sprintf("удалены записи из таблицы %s", table.Name))
+	fmt.Fprintln(stdout, msg("delete.done", deleted))
+	logToFileAndScreen(fmt.Sprintf("Удаление из таблицы %s: удалено %d записей (id: %s)", table.Name, deleted, strings.Join(ids, ", ")))
+}
+
+// Пункт 20: Восстановление архивной записи по ID
+func restoreRecord(reader *bufio.Reader) {
+	var candidates []TableInfo
+	for _, table := range tables {
+		if softDeleteColumn(table) != "" {
+			candidates = append(candidates, table)
+		}
+	}
+	if len(candidates) == 0 {
+		fmt.Fprintln(stdout, msg("restore.no_tables"))
+		return
+	}
+
+	fmt.Fprintln(stdout, msg("restore.title"))
+	for i, table := range candidates {
+		fmt.Fprintf(stdout, "%d. %s\n", i+1, table.Name)
+	}
+	fmt.Fprintln(stdout, msg("common.back"))
+	choice, ok := promptInt(reader, msg("common.choose_table"), 0, len(candidates))
+	if !ok || choice == 0 {
+		return
+	}
+	table := candidates[choice-1]
+	key := tableKeyColumn(table.Name)
+	if key == "" {
+		printError(msg("common.no_key", table.Name))
+		return
+	}
+
+	id, ok := promptValidated(reader, msg("restore.id_prompt"), func(input string) error {
+		return validateTableValue(table, key, input)
+	})
+	if !ok {
+		return
+	}
+
+	var archived int
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = $1 AND %s", table.SQLName(), dialect.QuoteIdent(key), archivedRowsCondition(table))
+	if err := dbScanRow(query, []interface{}{id}, &archived); err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка проверки архивной записи %s %s=%s: %v", table.Name, key, id, err))
+		printError(msg("update.check_failed"))
+		return
+	}
+	if archived == 0 {
+		fmt.Fprintln(stdout, msg("restore.not_archived", table.Name, id))
+		return
+	}
+	setArchived(table, []string{id}, false)
+}
+
+// Функция для архивирования или восстановления записей одной транзакцией.
+// Прежние значения колонки запоминаются, чтобы операцию можно было отменить.
+func setArchived(table TableInfo, ids []string, archive bool) {
+	column := softDeleteColumn(table)
+	where, args := inListCondition(tableKeyColumn(table.Name), ids, 1)
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s", table.SQLName(), archiveAssignment(table, archive), where)
+	if dryRun {
+		printDryRun(query, nil, args)
+		printDryRunPreview(table.Name, ids)
+		return
+	}
+	logToFileAndScreen(fmt.Sprintf("Выполнение архивирования: %s с параметрами %v", query, args))
+
+	var undo undoStep
+	var affected int64
+	err := dbTransaction(func(tx *sql.Tx) error {
+		var err error
+		undo, err = captureUndoValues(tx, table.Name, column, where, args)
+		if err != nil {
+			return err
+		}
+		result, err := txExec(tx, query, args...)
+		if err != nil {
+			return err
+		}
+		affected, _ = result.RowsAffected()
+		changes, err := columnChangesAudit(tx, undo)
+		if err != nil {
+			return err
+		}
+		return writeAudit(tx, auditUpdate, table.Name, changes)
+	})
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка изменения %s.%s: %v", table.Name, column, err))
+		printError(msg("update.failed"))
+		return
+	}
+
+	if archive {
+		rememberUndo(msg("undo.kind_archive", table.Name), []undoStep{undo})
+		fmt.Fprintln(stdout, msg("delete.archived", affected))
+		logToFileAndScreen(fmt.Sprintf("Архивирование в таблице %s: %d записей (id: %s)", table.Name, affected, strings.Join(ids, ", ")))
+		return
+	}
+	rememberUndo(msg("undo.kind_restore", table.Name), []undoStep{undo})
+	fmt.Fprintln(stdout, msg("restore.done", table.Name, strings.Join(ids, ", ")))
+	logToFileAndScreen(fmt.Sprintf("Восстановление из архива в таблице %s: id %s", table.Name, strings.Join(ids, ", ")))
+}
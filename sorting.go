@@ -0,0 +1,169 @@
+package osl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Структура для настроек просмотра таблицы, запоминаемых на время сеанса
+type ViewPrefs struct {
+	SortColumn string // колонка сортировки (пусто — первичный ключ)
+	Collation  string // правило сортировки текста (пусто — правило базы данных)
+}
+
+// Настройки просмотра по именам таблиц
+var viewPrefs = map[string]*ViewPrefs{}
+
+// Функция для получения настроек просмотра таблицы
+func getViewPrefs(tableName string) *ViewPrefs {
+	prefs, ok := viewPrefs[tableName]
+	if !ok {
+		prefs = &ViewPrefs{}
+		viewPrefs[tableName] = prefs
+	}
+	return prefs
+}
+
+// Функция для проверки, является ли колонка текстовой (по типу колонки в БД)
+func isTextColumn(table TableInfo, column string) bool {
+	return isTextType(table.Types[column])
+}
+
+// Функция для построения COLLATE для текстовой колонки с учетом настроек таблицы
+func collateSuffix(tableName, column string) string {
+	prefs := getViewPrefs(tableName)
+	table, _ := findTable(tableName)
+	if prefs.Collation == "" || !isTextColumn(table, column) {
+		return ""
+	}
+	return " COLLATE " + quoteCollation(prefs.Collation)
+}
+
+// Функция для построения ORDER BY с учетом настроек таблицы.
+// По умолчанию записи упорядочиваются по первичному ключу, без ключа порядок не задается (пустая строка).
+func orderByClause(table TableInfo) string {
+	column := getViewPrefs(table.Name).SortColumn
+	if column == "" {
+		column = primaryKeyColumn(table)
+	}
+	if column == "" {
+		return ""
+	}
+	return "ORDER BY " + dialect.QuoteIdent(column) + collateSuffix(table.Name, column)
+}
+
+// Функция для экранирования имени правила сортировки
+func quoteCollation(name string) string {
+	return dialect.QuoteIdent(name)
+}
+
+// Функция для загрузки правил сортировки, доступных в текущей базе данных
+func loadCollations() ([]string, error) {
+	rows, err := dbQuery(dialect.CollationsQuery())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// Пункт 6: Параметры сортировки
+func sortSettings(reader *bufio.Reader) {
+	tableIndex := selectTable(reader, msg("select_table.sort"))
+	if tableIndex == -1 {
+		return
+	}
+
+	table := tables[tableIndex]
+	prefs := getViewPrefs(table.Name)
+
+	columnIndex := selectColumn(reader, table)
+	if columnIndex == -1 {
+		return
+	}
+	column := table.Columns[columnIndex]
+
+	collation := ""
+	if isTextColumn(table, column) {
+		var ok bool
+		collation, ok = selectCollation(reader)
+		if !ok {
+			return
+		}
+	}
+
+	prefs.SortColumn = column
+	prefs.Collation = collation
+
+	fmt.Fprintln(stdout, msg("sort.done", table.Name, orderByClause(table)))
+	logToFileAndScreen(fmt.Sprintf("Настройка сортировки таблицы %s: %s", table.Name, orderByClause(table)))
+}
+
+// Функция для выбора правила сортировки из списка доступных в базе данных.
+// Пустая строка означает правило базы данных по умолчанию.
+func selectCollation(reader *bufio.Reader) (string, bool) {
+	collations, err := loadCollations()
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка загрузки списка правил сортировки: %v", err))
+		printError(msg("sort.collations_failed"))
+		return "", false
+	}
+
+	term, ok := promptValidated(reader,
+		msg("sort.collation_prompt"),
+		func(term string) error {
+			if term != "" && len(matchCollations(collations, term)) == 0 {
+				return errors.New(msg("sort.collation_unknown", term))
+			}
+			return nil
+		})
+	if !ok {
+		return "", false
+	}
+	if term == "" {
+		return "", true
+	}
+
+	// Точное совпадение выбирается сразу
+	matches := matchCollations(collations, term)
+	for _, name := range matches {
+		if name == term {
+			return name, true
+		}
+	}
+
+	fmt.Fprintln(stdout, msg("sort.collations_title"))
+	for i, name := range matches {
+		fmt.Fprintf(stdout, "%d. %s\n", i+1, name)
+	}
+	fmt.Fprintln(stdout, msg("common.back"))
+
+	choice, ok := promptInt(reader, msg("sort.collation_choice"), 0, len(matches))
+	if !ok || choice == 0 {
+		return "", false
+	}
+
+	return matches[choice-1], true
+}
+
+// Функция для отбора правил сортировки, содержащих подстроку без учета регистра
+func matchCollations(collations []string, term string) []string {
+	var matches []string
+	for _, name := range collations {
+		if strings.Contains(strings.ToLower(name), strings.ToLower(term)) {
+			matches = append(matches, name)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,305 @@
+package osl
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/lib/pq"
+)
+
+// Режим SQL-запросов для опытных пользователей. По умолчанию разрешен только SELECT,
+// и он выполняется в транзакции только для чтения; флаг запуска --allow-write
+// (или OSL_ALLOW_WRITE_SQL=1) разрешает любые запросы. Изменяющий запрос выполняется
+// в транзакции вместе с записью в журнал аудита (операция sql, текст запроса и число строк).
+
+// Разрешено ли изменение данных в режиме SQL-запросов (задается при запуске)
+var allowWriteSQL bool
+
+// Ключевые слова запросов, которые возвращают строки
+var rowReturningKeywords = map[string]bool{
+	"SELECT": true, "WITH": true, "SHOW": true, "EXPLAIN": true, "VALUES": true, "TABLE": true,
+}
+
+// Пункт 11: SQL-запрос
+func rawSQLMode(reader *bufio.Reader) {
+	allowWrite := allowWriteSQL
+	if allowWrite {
+		fmt.Fprintln(stdout, msg("sql.write_allowed"))
+	} else {
+		fmt.Fprintln(stdout, msg("sql.select_only"))
+	}
+
+	statement, ok := readStatement(reader)
+	if !ok {
+		return
+	}
+
+	keyword := firstKeyword(statement)
+	if !allowWrite && keyword != "SELECT" {
+		printError(msg("sql.rejected"))
+		logToFileAndScreen(fmt.Sprintf("SQL-запрос отклонен (%s): %s", keyword, statement))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), envDuration("OSL_SQL_TIMEOUT", 30*time.Second))
+	defer cancel()
+
+	start := time.Now()
+	if dryRun && keyword != "SELECT" {
+		printDryRun(statement, nil, nil)
+		return
+	}
+	if !rowReturningKeywords[keyword] && !strings.Contains(strings.ToUpper(statement), "RETURNING") {
+		var affected int64
+		err := dbTransaction(func(tx *sql.Tx) error {
+			var result sql.Result
+			err := timedQuery("raw_sql", statement, nil, func(string, []interface{}) error {
+				var err error
+				result, err = tx.ExecContext(ctx, statement)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			affected, _ = result.RowsAffected()
+			return writeAudit(tx, auditSQL, "", []auditRecord{sqlAuditRecord(statement, affected)})
+		})
+		if err != nil {
+			reportSQLError(statement, err)
+			return
+		}
+		forgetUndo("выполнен SQL-запрос " + keyword)
+		fmt.Fprintln(stdout, msg("sql.executed", affected))
+		logToFileAndScreen(fmt.Sprintf("SQL-запрос: %s; время %s; затронуто записей: %d",
+			statement, time.Since(start).Round(time.Millisecond), affected))
+		return
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: !allowWrite})
+	if err != nil {
+		reportSQLError(statement, err)
+		return
+	}
+	defer tx.Rollback()
+
+	var rows *sql.Rows
+	err = timedQuery("raw_sql", statement, nil, func(string, []interface{}) error {
+		var err error
+		rows, err = tx.QueryContext(ctx, statement)
+		return err
+	})
+	if err != nil {
+		reportSQLError(statement, err)
+		return
+	}
+	rs, err := scanRows(rows)
+	rows.Close()
+	if err != nil {
+		reportSQLError(statement, err)
+		return
+	}
+	if allowWrite {
+		if keyword != "SELECT" {
+			if err := writeAudit(tx, auditSQL, "", []auditRecord{sqlAuditRecord(statement, int64(len(rs.Rows)))}); err != nil {
+				reportSQLError(statement, err)
+				return
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			reportSQLError(statement, err)
+			return
+		}
+		if keyword != "SELECT" {
+			forgetUndo("выполнен SQL-запрос " + keyword)
+		}
+	}
+
+	logToFileAndScreen(fmt.Sprintf("SQL-запрос: %s; время %s; строк: %d",
+		statement, time.Since(start).Round(time.Millisecond), len(rs.Rows)))
+	if len(rs.Columns) == 0 || len(rs.Rows) == 0 {
+		fmt.Fprintln(stdout, msg("reports.none"))
+		return
+	}
+	printResult(rs)
+	printFoundRows(len(rs.Rows))
+	offerRawDetails(reader, rs)
+}
+
+// Функция для подготовки записи журнала аудита об изменяющем запросе. Запрос может затрагивать
+// несколько таблиц, поэтому таблица в журнале не указывается, а в новых значениях записываются
+// текст запроса и количество строк.
+func sqlAuditRecord(statement string, rows int64) auditRecord {
+	return auditRecord{New: map[string]interface{}{"statement": statement, "rows": rows}}
+}
+
+// Функция для чтения запроса из нескольких строк до точки с запятой.
+// Возвращает запрос без завершающей точки с запятой и false при отмене.
+func readStatement(reader *bufio.Reader) (string, bool) {
+	fmt.Fprintln(stdout, msg("sql.prompt"))
+	var lines []string
+	for {
+		if len(lines) == 0 {
+			fmt.Fprint(stdout, "sql> ")
+		} else {
+			fmt.Fprint(stdout, "...> ")
+		}
+		line, err := readLine(reader)
+		var tooLong *inputTooLongError
+		if errors.As(err, &tooLong) {
+			printError(msg("sql.input_cancelled", err))
+			return "", false
+		}
+		if err != nil {
+			return "", false
+		}
+		if len(lines) == 0 && (line == "" || isCancelInput(line)) {
+			return "", false
+		}
+		lines = append(lines, line)
+
+		statement := strings.Join(lines, "\n")
+		separators, unterminated := scanStatement(statement)
+		if !strings.HasSuffix(statement, ";") || unterminated {
+			continue
+		}
+		statement = strings.TrimSpace(strings.TrimSuffix(statement, ";"))
+		if len(separators) > 1 {
+			printError(msg("sql.single_statement"))
+			lines = nil
+			continue
+		}
+		if statement == "" {
+			lines = nil
+			continue
+		}
+		return statement, true
+	}
+}
+
+// Функция для поиска точек с запятой, которые разделяют запросы: вне строк, идентификаторов
+// в кавычках, комментариев и тел в долларовых кавычках PostgreSQL ($$ ... $$, $tag$ ... $tag$).
+// Возвращает их позиции и признак того, что текст обрывается внутри такого фрагмента
+// (запрос продолжается на следующей строке).
+func scanStatement(text string) ([]int, bool) {
+	_, backslashEscapes := dialect.(mysqlDialect)
+	var separators []int
+	for i := 0; i < len(text); i++ {
+		if tag := dollarQuoteTag(text[i:]); tag != "" {
+			end := strings.Index(text[i+len(tag):], tag)
+			if end < 0 {
+				return separators, true
+			}
+			i += len(tag) + end + len(tag) - 1
+			continue
+		}
+		if end := skipQuotedSQL(text, i, backslashEscapes); end > i {
+			// Незакрытый фрагмент продолжается до конца текста: с добавленным символом
+			// он захватывает и этот символ, а закрытый заканчивается на том же месте
+			if end == len(text) && skipQuotedSQL(text+"\n", i, backslashEscapes) > end {
+				return separators, true
+			}
+			i = end - 1
+			continue
+		}
+		if text[i] == ';' {
+			separators = append(separators, i)
+		}
+	}
+	return separators, false
+}
+
+// Функция для получения открывающей долларовой кавычки ($$ или $tag$) в начале текста.
+// Пусто — в начале текста нет долларовой кавычки ($1 — параметр, а не кавычка).
+func dollarQuoteTag(text string) string {
+	if !strings.HasPrefix(text, "$") {
+		return ""
+	}
+	for i := 1; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '$':
+			return text[:i+1]
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 1 && c >= '0' && c <= '9':
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// Функция для получения первого ключевого слова запроса (комментарии в начале пропускаются)
+func firstKeyword(statement string) string {
+	rest := statement
+	for {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		switch {
+		case strings.HasPrefix(rest, "--"):
+			if end := strings.Index(rest, "\n"); end >= 0 {
+				rest = rest[end+1:]
+			} else {
+				rest = ""
+			}
+		case strings.HasPrefix(rest, "/*"):
+			if end := strings.Index(rest, "*/"); end >= 0 {
+				rest = rest[end+2:]
+			} else {
+				rest = ""
+			}
+		default:
+			end := strings.IndexFunc(rest, func(r rune) bool { return !unicode.IsLetter(r) })
+			if end < 0 {
+				end = len(rest)
+			}
+			return strings.ToUpper(rest[:end])
+		}
+	}
+}
+
+// Функция для вывода ошибки запроса; для ошибок PostgreSQL показывается место ошибки
+func reportSQLError(statement string, err error) {
+	logToFileAndScreen(fmt.Sprintf("Ошибка SQL-запроса: %s: %v", statement, err))
+	if errors.Is(err, context.DeadlineExceeded) {
+		printError(msg("sql.timeout"))
+		return
+	}
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		printError(msg("common.error", err))
+		return
+	}
+	printError(msg("common.error", pqErr.Message))
+	if position, convErr := strconv.Atoi(pqErr.Position); convErr == nil && position > 0 {
+		line, column := positionInStatement(statement, position)
+		fmt.Fprintln(stdout, msg("sql.error_position", line+1, column+1))
+		fmt.Fprintln(stdout, strings.Split(statement, "\n")[line])
+		fmt.Fprintln(stdout, strings.Repeat(" ", column) + "^")
+	}
+	if pqErr.Hint != "" {
+		fmt.Fprintln(stdout, msg("sql.hint", pqErr.Hint))
+	}
+}
+
+// Функция для перевода позиции символа (с 1, как ее сообщает PostgreSQL) в номер строки и колонки (с 0)
+func positionInStatement(statement string, position int) (int, int) {
+	line, column := 0, 0
+	for i, r := range []rune(statement) {
+		if i == position-1 {
+			break
+		}
+		if r == '\n' {
+			line++
+			column = 0
+		} else {
+			column++
+		}
+	}
+	return line, column
+}
@@ -0,0 +1,99 @@
+package osl
+
+import (
+	"strings"
+	"testing"
+)
+
+// Точка с запятой разделяет запросы только вне строк, идентификаторов, комментариев
+// и долларовых кавычек
+func TestScanStatement(t *testing.T) {
+	tests := []struct {
+		text         string
+		separators   int
+		unterminated bool
+	}{
+		{"SELECT 1;", 1, false},
+		{"SELECT 1; SELECT 2;", 2, false},
+		{"SELECT ';' FROM t;", 1, false},
+		{"SELECT 'it''s;' FROM t;", 1, false},
+		{"SELECT 'a;", 0, true},
+		{`SELECT "a;b" FROM t;`, 1, false},
+		{`SELECT "a;b`, 0, true},
+		{"SELECT 1 -- конец; нет\n;", 1, false},
+		{"SELECT 1 -- комментарий;", 0, true},
+		{"SELECT /* ; */ 1;", 1, false},
+		{"SELECT /* ;", 0, true},
+		{"DO $$ BEGIN PERFORM 1; END $$;", 1, false},
+		{"DO $body$ BEGIN PERFORM 1; END $body$;", 1, false},
+		{"DO $$ BEGIN PERFORM 1;", 0, true},
+		// $1 — параметр, а не долларовая кавычка
+		{"SELECT $1; SELECT $2;", 2, false},
+	}
+	for _, tt := range tests {
+		separators, unterminated := scanStatement(tt.text)
+		if len(separators) != tt.separators || unterminated != tt.unterminated {
+			t.Errorf("scanStatement(%q) = %v, %v, ожидалось разделителей %d, незакрыт=%v",
+				tt.text, separators, unterminated, tt.separators, tt.unterminated)
+		}
+	}
+}
+
+// Многострочный ввод не обрывается на точке с запятой внутри идентификатора, комментария
+// или долларовых кавычек, а два запроса в одном вводе отклоняются
+func TestReadStatement(t *testing.T) {
+	tests := []struct {
+		lines []string
+		want  string
+	}{
+		{[]string{`SELECT "a;b"`, "FROM t;"}, "SELECT \"a;b\"\nFROM t"},
+		{[]string{"SELECT 1 /* первая;", "вторая */ + 1;"}, "SELECT 1 /* первая;\nвторая */ + 1"},
+		{[]string{"SELECT 1 -- итог;", "+ 1;"}, "SELECT 1 -- итог;\n+ 1"},
+		{[]string{"DO $$ BEGIN", "PERFORM 1;", "END $$;"}, "DO $$ BEGIN\nPERFORM 1;\nEND $$"},
+		{[]string{"SELECT 1; SELECT 2;", "SELECT 3;"}, "SELECT 3"},
+	}
+	for _, tt := range tests {
+		var statement string
+		var ok bool
+		captureOutput(t, func() {
+			statement, ok = readStatement(scriptReader(tt.lines...))
+		})
+		if !ok || statement != tt.want {
+			t.Errorf("readStatement(%q) = %q, %v, ожидалось %q", tt.lines, statement, ok, tt.want)
+		}
+	}
+}
+
+// Изменяющий запрос с --allow-write записывается в журнал аудита в той же транзакции
+func TestRawSQLWriteIsAudited(t *testing.T) {
+	openBaseSchema(t, "INSERT INTO categories (name, description) VALUES ('Процессоры', 'CPU'), ('Память', 'RAM')")
+	saved := allowWriteSQL
+	allowWriteSQL = true
+	t.Cleanup(func() { allowWriteSQL = saved })
+
+	output := captureOutput(t, func() {
+		rawSQLMode(scriptReader("UPDATE categories SET description = 'x;y'", "WHERE name = 'Память';"))
+	})
+	if !strings.Contains(output, msg("sql.executed", 1)) {
+		t.Fatalf("запрос не выполнен:\n%s", output)
+	}
+	if got := queryString(t, "SELECT description FROM categories WHERE name = 'Память'"); got != "x;y" {
+		t.Errorf("description = %q", got)
+	}
+	if got := queryString(t, "SELECT COUNT(*) FROM osl_audit WHERE operation = 'sql'"); got != "1" {
+		t.Fatalf("записей аудита sql: %s", got)
+	}
+	values := queryString(t, "SELECT new_values FROM osl_audit WHERE operation = 'sql'")
+	if !strings.Contains(values, `"rows":1`) || !strings.Contains(values, "UPDATE categories") {
+		t.Errorf("запись аудита: %s", values)
+	}
+
+	// Без журнала аудита изменение откатывается
+	auditReady = false
+	output = captureOutput(t, func() {
+		rawSQLMode(scriptReader("DELETE FROM categories;"))
+	})
+	if got := queryString(t, "SELECT COUNT(*) FROM categories"); got != "2" {
+		t.Errorf("изменение без журнала аудита выполнено, записей %s:\n%s", got, output)
+	}
+}
@@ -0,0 +1,49 @@
+package osl
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// Структура для хранения состояния приложения между запусками
+type AppState struct {
+	LastSummaryDate string `json:"last_summary_date,omitempty"`
+}
+
+// Функция для получения пути к файлу состояния
+// (по умолчанию лежит рядом с файлом логов)
+func stateFilePath() string {
+	return envString("OSL_STATE_FILE", filepath.Join(filepath.Dir(logFilePath()), "osl_state.json"))
+}
+
+// Функция для загрузки состояния; отсутствующий файл означает пустое состояние
+func loadState(path string) (AppState, error) {
+	var state AppState
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, err
+	}
+	return state, nil
+}
+
+// Функция для сохранения состояния через временный файл,
+// чтобы прерванная запись не испортила предыдущее состояние
+func saveState(path string, state AppState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
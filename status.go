@@ -0,0 +1,75 @@
+package osl
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Результат проверки состояния подключения
+type ConnectionStatus struct {
+	PingDuration time.Duration
+	PingErr      error
+	Version      string
+	Database     string
+	InfoErr      error
+}
+
+// Функция для проверки подключения и получения сведений о сервере
+func checkConnectionStatus() ConnectionStatus {
+	var status ConnectionStatus
+	start := time.Now()
+	status.PingErr = db.Ping()
+	status.PingDuration = time.Since(start)
+	if status.PingErr != nil {
+		return status
+	}
+	status.InfoErr = dbScanRow(dialect.ServerInfoQuery(), nil, &status.Version, &status.Database)
+	return status
+}
+
+// Функция для вывода состояния подключения; возвращает false, если подключение не работает
+func printConnectionStatus() bool {
+	status := checkConnectionStatus()
+	stats := db.Stats()
+
+	fmt.Fprintln(stdout, msg("status.title"))
+	fmt.Fprintln(stdout, msg("status.driver", activeConfig.Driver))
+	fmt.Fprintln(stdout, msg("status.host", activeConfig.Host))
+	fmt.Fprintln(stdout, msg("status.port", activeConfig.Port))
+	fmt.Fprintln(stdout, msg("status.ssl", activeConfig.SSLMode))
+	fmt.Fprintln(stdout, msg("status.user", activeConfig.User))
+	if status.PingErr != nil {
+		fmt.Fprintln(stdout, msg("status.ping_failed", status.PingErr))
+	} else {
+		fmt.Fprintln(stdout, msg("status.ping_ok", status.PingDuration.Round(time.Microsecond)))
+	}
+	if status.PingErr == nil && status.InfoErr != nil {
+		fmt.Fprintln(stdout, msg("status.info_failed", status.InfoErr))
+	} else if status.PingErr == nil {
+		fmt.Fprintln(stdout, msg("status.server", strings.TrimSpace(status.Version)))
+		fmt.Fprintln(stdout, msg("status.database", status.Database))
+	}
+
+	fmt.Fprintln(stdout, msg("status.pool"))
+	fmt.Fprintln(stdout, msg("status.pool_open", stats.OpenConnections, stats.InUse, stats.Idle))
+	fmt.Fprintln(stdout, msg("status.pool_wait", stats.WaitCount, stats.WaitDuration.Round(time.Millisecond)))
+	fmt.Fprintln(stdout, msg("status.pool_closed", stats.MaxIdleClosed, stats.MaxLifetimeClosed))
+
+	if status.PingErr != nil {
+		logToFileAndScreen(fmt.Sprintf("Проверка состояния: ошибка подключения к %s:%s: %v",
+			activeConfig.Host, activeConfig.Port, status.PingErr))
+		return false
+	}
+	logToFileAndScreen(fmt.Sprintf("Проверка состояния: %s:%s, база %s, ответ за %s, соединений %d (занято %d)",
+		activeConfig.Host, activeConfig.Port, status.Database, status.PingDuration, stats.OpenConnections, stats.InUse))
+	return true
+}
+
+// Команда status: проверка подключения без интерактивного меню
+func statusCommand(args []string) int {
+	if !printConnectionStatus() {
+		return 1
+	}
+	return 0
+}
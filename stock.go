@@ -0,0 +1,207 @@
+package osl
+
+import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Корректировка остатков изменяет количество на складе на величину (дельту) одним запросом
+// quantity = quantity + $1, поэтому параллельные корректировки не затирают друг друга.
+
+// Ошибка корректировки, после которой остаток стал бы отрицательным
+var errNegativeStock = errors.New("negative stock")
+
+// Строка склада для корректировки
+type stockRow struct {
+	ID       string
+	Location sql.NullString
+	Quantity int64
+}
+
+// Пункт 14: Корректировка остатков
+func adjustStock(reader *bufio.Reader) {
+	fmt.Fprintln(stdout, msg("stock.title"))
+	componentID, componentName, rows, ok := pickStockComponent(reader)
+	if !ok {
+		return
+	}
+
+	// Если компонент хранится на нескольких складах, корректируется одна выбранная запись
+	row, ok := pickStockRow(reader, componentName, rows, msg("stock.location_prompt"))
+	if !ok {
+		return
+	}
+	fmt.Fprintln(stdout, msg("stock.current", row.Quantity))
+
+	delta, ok := promptStockDelta(reader, componentID)
+	if !ok {
+		return
+	}
+
+	if dryRun {
+		printDryRun(stockAdjustQuery, []string{"quantity", "component_id", "id"}, []interface{}{delta, componentID, row.ID})
+		return
+	}
+
+	var newQuantity int64
+	err := dbTransaction(func(tx *sql.Tx) error {
+		var err error
+		if newQuantity, err = updateStockQuantity(tx, componentID, row.ID, delta); err != nil {
+			return err
+		}
+		// Проверка после обновления: при отрицательном результате транзакция откатывается
+		if newQuantity < 0 {
+			return errNegativeStock
+		}
+		return writeAudit(tx, auditUpdate, "stock", []auditRecord{{
+			ID:  row.ID,
+			Old: map[string]interface{}{"quantity": newQuantity - delta},
+			New: map[string]interface{}{"quantity": newQuantity},
+		}})
+	})
+	switch {
+	case errors.Is(err, errNegativeStock):
+		printError(msg("stock.rejected", msg("stock.negative"), newQuantity-delta, delta))
+		logToFileAndScreen(fmt.Sprintf("Корректировка остатков отклонена: компонент '%s' (id=%s), изменение %+d, остаток %d",
+			componentName, componentID, delta, newQuantity-delta))
+		return
+	case errors.Is(err, sql.ErrNoRows):
+		printError(msg("stock.row_deleted"))
+		return
+	case err != nil:
+		logToFileAndScreen(fmt.Sprintf("Ошибка корректировки остатков компонента %s: %v", componentID, err))
+		return
+	}
+
+	oldQuantity := newQuantity - delta
+	rememberUndo(msg("undo.kind_stock", componentName), []undoStep{{
+		Table:    "stock",
+		Column:   "quantity",
+		Previous: map[string]sql.NullString{row.ID: {String: strconv.FormatInt(oldQuantity, 10), Valid: true}},
+		Delta:    delta,
+	}})
+	fmt.Fprintln(stdout, msg("stock.done", componentName, oldQuantity, newQuantity, delta))
+	logToFileAndScreen(fmt.Sprintf("Корректировка остатков: компонент '%s' (id=%s), склад '%s', %d -> %d (%+d)",
+		componentName, componentID, row.Location.String, oldQuantity, newQuantity, delta))
+}
+
+// Запрос изменения остатка записи склада на величину
+const stockAdjustQuery = "UPDATE stock SET quantity = quantity + $1 WHERE component_id = $2 AND id = $3"
+
+// Функция для изменения остатка записи склада компонента на delta внутри транзакции.
+// Возвращает новый остаток; sql.ErrNoRows — записи нет. Если СУБД не поддерживает
+// UPDATE ... RETURNING (MySQL), остаток читается после обновления в той же транзакции
+// с блокировкой строки.
+func updateStockQuantity(tx *sql.Tx, componentID, stockID string, delta int64) (int64, error) {
+	args := []interface{}{delta, componentID, stockID}
+	var quantity int64
+	if dialect.SupportsReturning() {
+		err := txScanRow(tx, stockAdjustQuery+" RETURNING quantity", args, &quantity)
+		return quantity, err
+	}
+
+	if _, err := txExec(tx, stockAdjustQuery, args...); err != nil {
+		return 0, err
+	}
+	query := "SELECT quantity FROM stock WHERE component_id = $1 AND id = $2"
+	if _, ok := dialect.(sqliteDialect); !ok {
+		query += " FOR UPDATE"
+	}
+	err := txScanRow(tx, query, []interface{}{componentID, stockID}, &quantity)
+	return quantity, err
+}
+
+// Функция для выбора компонента и загрузки его записей склада; false при отмене или ошибке
+func pickStockComponent(reader *bufio.Reader) (string, string, []stockRow, bool) {
+	componentID, ok := pickForeignKey(reader, "components")
+	if !ok {
+		return "", "", nil, false
+	}
+
+	var componentName string
+	if err := dbScanRow("SELECT name FROM components WHERE id = $1", []interface{}{componentID}, &componentName); err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка получения компонента %s: %v", componentID, err))
+		return "", "", nil, false
+	}
+
+	rows, err := loadStockRows(componentID)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка получения остатков компонента %s: %v", componentID, err))
+		return "", "", nil, false
+	}
+	if len(rows) == 0 {
+		printError(msg("stock.no_rows", componentName))
+		return "", "", nil, false
+	}
+	return componentID, componentName, rows, true
+}
+
+// Функция для выбора записи склада (единственная запись выбирается без вопроса); false при отмене
+func pickStockRow(reader *bufio.Reader, componentName string, rows []stockRow, prompt string) (stockRow, bool) {
+	if len(rows) == 1 {
+		return rows[0], true
+	}
+	fmt.Fprintln(stdout, msg("stock.locations", componentName))
+	for i, r := range rows {
+		fmt.Fprintln(stdout, msg("stock.location", i+1, r.Location.String, r.Quantity, r.ID))
+	}
+	fmt.Fprintln(stdout, msg("common.back"))
+	choice, ok := promptInt(reader, prompt, 0, len(rows))
+	if !ok || choice == 0 {
+		return stockRow{}, false
+	}
+	return rows[choice-1], true
+}
+
+// Функция для получения записей склада компонента
+func loadStockRows(componentID string) ([]stockRow, error) {
+	rows, err := dbQuery("SELECT id, warehouse_location, quantity FROM stock WHERE component_id = $1 ORDER BY id", componentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []stockRow
+	for rows.Next() {
+		var row stockRow
+		if err := rows.Scan(&row.ID, &row.Location, &row.Quantity); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// Функция для ввода изменения остатка со знаком (в штуках или упаковках, например -2уп); false при отмене
+func promptStockDelta(reader *bufio.Reader, componentID string) (int64, bool) {
+	units, err := unitsPerPackage(componentID)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка получения размера упаковки компонента %s: %v", componentID, err))
+	}
+	prompt := msg("stock.delta_prompt")
+	if units > 0 {
+		prompt = msg("stock.delta_packages", units, packageSuffix)
+	}
+
+	input, ok := promptValidated(reader, prompt, func(input string) error {
+		delta, _, err := parseQuantity(input, units)
+		if err != nil {
+			return err
+		}
+		if delta == 0 {
+			return errors.New(msg("stock.delta_zero"))
+		}
+		return nil
+	})
+	if !ok {
+		return 0, false
+	}
+	delta, packages, _ := parseQuantity(input, units)
+	if packages != 0 {
+		fmt.Fprintln(stdout, msg("stock.packages", packages, packageSuffix, units, delta))
+	}
+	return int64(delta), true
+}
@@ -0,0 +1,108 @@
+package osl
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Данные для корректировки остатков и перемещения: один компонент на двух складах
+func openStockSchema(t *testing.T) {
+	t.Helper()
+	openBaseSchema(t,
+		"INSERT INTO categories (name, description) VALUES ('Процессоры', 'CPU')",
+		"INSERT INTO manufacturers (name) VALUES ('Intel')",
+		"INSERT INTO components (name, category_id, manufacturer_id, model, price) VALUES ('Core i5', 1, 1, '12400F', 15990)",
+		"INSERT INTO stock (component_id, quantity, warehouse_location) VALUES (1, 12, 'A-1'), (1, 5, 'B-2')")
+}
+
+// Функция для получения остатка склада по его названию
+func stockQuantity(t *testing.T, location string) string {
+	t.Helper()
+	return queryString(t, "SELECT quantity FROM stock WHERE warehouse_location = $1", location)
+}
+
+// Корректировка на дельту и отказ, если остаток стал бы отрицательным
+func TestAdjustStock(t *testing.T) {
+	openStockSchema(t)
+
+	// Компонент, склад A-1, изменение +3
+	output := captureOutput(t, func() {
+		adjustStock(scriptReader("1", "1", "+3"))
+	})
+	if got := stockQuantity(t, "A-1"); got != "15" {
+		t.Fatalf("остаток A-1 = %s, ожидалось 15:\n%s", got, output)
+	}
+	if !strings.Contains(output, msg("stock.done", "Core i5", 12, 15, 3)) {
+		t.Errorf("нет сообщения о корректировке:\n%s", output)
+	}
+	if lastUndo == nil || len(lastUndo.Steps) != 1 || lastUndo.Steps[0].Delta != 3 {
+		t.Errorf("отмена корректировки %+v", lastUndo)
+	}
+	if got := queryString(t, "SELECT COUNT(*) FROM osl_audit WHERE table_name = 'stock'"); got != "1" {
+		t.Errorf("записей аудита %s, ожидалась 1", got)
+	}
+
+	// Списание больше остатка откатывается вместе с записью аудита
+	output = captureOutput(t, func() {
+		adjustStock(scriptReader("1", "1", "-20"))
+	})
+	if got := stockQuantity(t, "A-1"); got != "15" {
+		t.Errorf("остаток A-1 после отклоненной корректировки %s", got)
+	}
+	if !strings.Contains(output, msg("stock.rejected", msg("stock.negative"), 15, -20)) {
+		t.Errorf("нет сообщения об отказе:\n%s", output)
+	}
+	if got := queryString(t, "SELECT COUNT(*) FROM osl_audit WHERE table_name = 'stock'"); got != "1" {
+		t.Errorf("записей аудита после отката %s, ожидалась 1", got)
+	}
+}
+
+// Перемещение на существующий и на новый склад; нехватка остатка откатывает перемещение целиком
+func TestTransferStock(t *testing.T) {
+	openStockSchema(t)
+
+	// Компонент, источник A-1, склад назначения B-2 из списка, 4 шт.
+	output := captureOutput(t, func() {
+		transferStock(scriptReader("1", "1", "1", "4", "да"))
+	})
+	if a, b := stockQuantity(t, "A-1"), stockQuantity(t, "B-2"); a != "8" || b != "9" {
+		t.Fatalf("после перемещения A-1 = %s, B-2 = %s, ожидалось 8 и 9:\n%s", a, b, output)
+	}
+
+	// Новый склад C-3 создается
+	output = captureOutput(t, func() {
+		transferStock(scriptReader("1", "1", "C-3", "2", "да"))
+	})
+	if a, c := stockQuantity(t, "A-1"), stockQuantity(t, "C-3"); a != "6" || c != "2" {
+		t.Fatalf("после перемещения на новый склад A-1 = %s, C-3 = %s:\n%s", a, c, output)
+	}
+	if got := queryString(t, "SELECT component_id FROM stock WHERE warehouse_location = 'C-3'"); got != "1" {
+		t.Errorf("component_id нового склада %s", got)
+	}
+
+	// Остаток источника уменьшился после проверки ввода (параллельное списание)
+	sourceID := queryString(t, "SELECT id FROM stock WHERE warehouse_location = 'A-1'")
+	var result transferResult
+	err := dbTransaction(func(tx *sql.Tx) error {
+		var err error
+		result, err = executeTransfer(tx, "1", sourceID, "B-2", 100)
+		return err
+	})
+	if !errors.Is(err, errNegativeStock) || result.SourceBefore != 6 {
+		t.Errorf("перемещение больше остатка: %+v, %v", result, err)
+	}
+	if a, b := stockQuantity(t, "A-1"), stockQuantity(t, "B-2"); a != "6" || b != "9" {
+		t.Errorf("после отката A-1 = %s, B-2 = %s, ожидалось 6 и 9", a, b)
+	}
+
+	// Запись склада удалена до перемещения
+	err = dbTransaction(func(tx *sql.Tx) error {
+		_, err := executeTransfer(tx, "1", "999", "B-2", 1)
+		return err
+	})
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("перемещение с удаленного склада: %v", err)
+	}
+}
@@ -0,0 +1,413 @@
+package osl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Store — доступ к данным без меню: методы принимают параметры операции и возвращают результат,
+// ничего не запрашивая и не выводя на экран. Запросы строятся и значения проверяются теми же
+// функциями, что и в меню (buildFilterQuery, validateTableValue, правила из конфигурации),
+// изменения записываются в журнал аудита. Подтверждений нет: предупреждения правил возвращаются
+// в результате, блокирующие правила и подозрительно большие изменения отклоняют операцию.
+//
+// Store хранит свое подключение и структуру БД, но выполняет запросы через общее состояние
+// программы, поэтому вызовы Store и сеансы Run выполняются по одному. Вызывать методы Store
+// из сеанса Run нельзя.
+type Store struct {
+	state sessionState
+	// Принимать подозрительно большие изменения (правила change_rules) без отказа
+	AllowLargeChanges bool
+}
+
+// Параметры просмотра таблицы
+type ViewRequest struct {
+	Table           string
+	Columns         []string // пусто — все колонки
+	IncludeArchived bool     // показывать архивные записи (для таблиц с мягким удалением)
+}
+
+// Обновление значения колонки в записях, выбранных по ключам или по условиям
+type UpdateRequest struct {
+	Table      string
+	Column     string
+	Value      string
+	IDs        []string
+	Conditions []SavedCondition // условия в формате сохраненных фильтров; вместо IDs
+	Operator   string           // AND (по умолчанию) или OR
+}
+
+// Результат обновления
+type UpdateResult struct {
+	IDs          []string // ключи записей, выбранных для обновления
+	RowsAffected int64
+	Warnings     []string // нарушенные правила с уровнем warn
+}
+
+// Результат добавления записей
+type InsertResult struct {
+	IDs      []string // ключи добавленных записей; пусто, если СУБД их не вернула
+	Warnings []string
+}
+
+// Добавление записи дочерней таблицы вместе с родительской: внешний ключ дочерней записи
+// заполняется id родительской
+type RelatedInsert struct {
+	Parent, Child string
+	ParentID      string // id существующей родительской записи; пусто — добавляется новая
+	ParentColumns []string
+	ParentValues  []string
+	ChildColumns  []string // без колонки внешнего ключа
+	ChildValues   []string
+}
+
+// Результат добавления в связанные таблицы
+type RelatedResult struct {
+	ParentID string
+	ChildID  string
+	Warnings []string
+}
+
+// Функция для подключения к БД и загрузки ее структуры и правил из файла конфигурации
+func OpenStore(ctx context.Context, config DBConfig) (*Store, error) {
+	storeDialect, err := dialectFor(config.Driver)
+	if err != nil {
+		return nil, err
+	}
+	registerSecret(config.Password)
+	handle, err := sql.Open(storeDialect.DriverName(), connectionDSN(storeDialect, config))
+	if err != nil {
+		return nil, err
+	}
+	if err := handle.PingContext(ctx); err != nil {
+		handle.Close()
+		return nil, err
+	}
+	applyPoolSettings(handle, poolSettings())
+
+	s := &Store{state: sessionState{db: handle, dialect: storeDialect, config: config}}
+	err = s.use(ctx, func() error {
+		if err := loadAppConfig(); err != nil {
+			return fmt.Errorf("чтение конфигурации: %w", err)
+		}
+		loadSchema()
+		return nil
+	})
+	if err != nil {
+		handle.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Функция для закрытия подключения Store
+func (s *Store) Close() error {
+	return s.state.db.Close()
+}
+
+// Функция для выполнения fn с состоянием Store вместо состояния программы: ввод пуст,
+// вывод на экран отбрасывается, ctx становится контекстом запросов
+func (s *Store) use(ctx context.Context, fn func() error) error {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+	saved := captureSession()
+	defer saved.restore()
+
+	state := s.state
+	state.readOnly, state.dryRun, state.interactive = false, false, false
+	state.stdin, state.stdout, state.stderr, state.ctx = strings.NewReader(""), io.Discard, io.Discard, ctx
+	state.restore()
+	err := fn()
+	// Структура БД загружается в fn, подключение могло быть заменено при восстановлении соединения
+	s.state = captureSession()
+	return err
+}
+
+// Функция для поиска таблицы Store по имени
+func storeTable(name string) (TableInfo, error) {
+	table, ok := findTable(name)
+	if !ok {
+		return TableInfo{}, errors.New(msg("common.table_not_found", name))
+	}
+	return table, nil
+}
+
+// Функция для проверки, что колонки есть в таблице
+func checkColumns(table TableInfo, columns []string) error {
+	for _, column := range columns {
+		if !containsString(table.Columns, column) {
+			return fmt.Errorf("колонка '%s' не найдена в таблице '%s'", column, table.Name)
+		}
+	}
+	return nil
+}
+
+// Функция для чтения результата запроса
+func queryResult(query string, args []interface{}) (*ResultSet, error) {
+	rows, err := dbQuery(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanRows(rows)
+}
+
+// Функция для просмотра таблицы: архивные записи скрыты, если не запрошены
+func (s *Store) View(ctx context.Context, req ViewRequest) (*ResultSet, error) {
+	var rs *ResultSet
+	err := s.use(ctx, func() error {
+		table, err := storeTable(req.Table)
+		if err != nil {
+			return err
+		}
+		columns := req.Columns
+		if len(columns) == 0 {
+			columns = table.Columns
+		} else if err := checkColumns(table, columns); err != nil {
+			return err
+		}
+		condition := ""
+		if !req.IncludeArchived {
+			condition = activeRowsCondition(table)
+		}
+		rs, err = queryResult(viewQuery(table, columns, condition), nil)
+		return err
+	})
+	return rs, err
+}
+
+// Функция для фильтрации по условиям в формате сохраненных фильтров (как в команде run).
+// Условия и значения проверяются по типам колонок; параметры «спросить» не допускаются.
+func (s *Store) Filter(ctx context.Context, filter SavedFilter) (*ResultSet, error) {
+	var rs *ResultSet
+	err := s.use(ctx, func() error {
+		spec, params, err := filter.filterSpec()
+		if err != nil {
+			return err
+		}
+		if len(params) > 0 {
+			return fmt.Errorf("параметр '%s' не допускается: значение условия по '%s' должно быть задано", askParam, params[0].Name)
+		}
+		table, _ := findTable(spec.Table)
+		query, args, _ := buildFilterQuery(table, spec)
+		rs, err = queryResult(query, args)
+		return err
+	})
+	return rs, err
+}
+
+// Функция для проверки строк по правилам из конфигурации: нарушение блокирующего правила —
+// ошибка, нарушения предупреждений возвращаются текстом
+func checkStoreRules(table, operation string, rows []ruleRow) ([]string, error) {
+	violations, err := evaluateRowRules(table, operation, rows, dbRuleLookup)
+	if err != nil {
+		return nil, fmt.Errorf("проверка правил для %s: %w", table, err)
+	}
+	var warnings []string
+	for _, violation := range violations {
+		if violation.Rule.Severity == "block" {
+			return nil, errors.New(msg("rules.violation", msg("rules.blocked"), violation.Rule.Name, violation.Row, violation.Rule.Message))
+		}
+		warnings = append(warnings, msg("rules.violation", msg("rules.warning"), violation.Rule.Name, violation.Row, violation.Rule.Message))
+	}
+	return warnings, nil
+}
+
+// Функция для проверки и приведения значений новой записи к формату БД
+func normalizeRecord(table TableInfo, columns, values []string) ([]string, error) {
+	if len(columns) == 0 {
+		return nil, errors.New(msg("insert.all_default", table.Name))
+	}
+	if len(values) != len(columns) {
+		return nil, fmt.Errorf("в записи %d значений, ожидалось %d", len(values), len(columns))
+	}
+	if err := checkColumns(table, columns); err != nil {
+		return nil, err
+	}
+	record := make([]string, len(values))
+	for i, column := range columns {
+		if autoGeneratedColumn(table, column) {
+			return nil, fmt.Errorf("колонку '%s' заполняет СУБД", column)
+		}
+		if err := validateTableValue(table, column, values[i]); err != nil {
+			return nil, fmt.Errorf("колонка '%s': %w", column, err)
+		}
+		record[i] = normalizeTableValue(table, column, values[i])
+	}
+	return record, nil
+}
+
+// Функция для обновления колонки в записях по ключам или по условиям одной транзакцией
+// с записью в журнал аудита
+func (s *Store) Update(ctx context.Context, req UpdateRequest) (UpdateResult, error) {
+	var result UpdateResult
+	err := s.use(ctx, func() error {
+		table, err := storeTable(req.Table)
+		if err != nil {
+			return err
+		}
+		key := tableKeyColumn(table.Name)
+		if key == "" {
+			return errors.New(msg("common.no_key", table.Name))
+		}
+		if err := checkColumns(table, []string{req.Column}); err != nil {
+			return err
+		}
+		if containsString(primaryKeyColumns(table), req.Column) {
+			return fmt.Errorf("колонка '%s' входит в первичный ключ и не обновляется", req.Column)
+		}
+		if err := validateTableValue(table, req.Column, req.Value); err != nil {
+			return fmt.Errorf("колонка '%s': %w", req.Column, err)
+		}
+
+		spec := UpdateSpec{Table: table.Name, Column: req.Column, Value: normalizeTableValue(table, req.Column, req.Value)}
+		switch {
+		case len(req.Conditions) > 0 && len(req.IDs) > 0:
+			return errors.New("записи выбираются либо по ключам, либо по условиям")
+		case len(req.Conditions) > 0:
+			filter, _, err := SavedFilter{Table: table.Name, Operator: req.Operator, Conditions: req.Conditions}.filterSpec()
+			if err != nil {
+				return err
+			}
+			spec.Conditions, spec.Operator = filter.Conditions, filter.Operator
+			where, whereArgs, _ := buildWhereClause(table, spec.Conditions, spec.Operator, 1)
+			if result.IDs, err = matchingIDs(table.Name, where, whereArgs); err != nil {
+				return err
+			}
+		case len(req.IDs) > 0:
+			for _, id := range req.IDs {
+				if err := validateTableValue(table, key, id); err != nil {
+					return fmt.Errorf("ключ '%s': %w", id, err)
+				}
+			}
+			if result.IDs, err = existingIDs(table.Name, req.IDs); err != nil {
+				return err
+			}
+			spec.IDs = result.IDs
+		default:
+			return errors.New("не заданы ни ключи, ни условия обновления")
+		}
+		if len(result.IDs) == 0 {
+			return nil
+		}
+
+		ruleRows, err := updatedRuleRows(table.Name, result.IDs, spec.Column, spec.Value)
+		if err != nil {
+			return err
+		}
+		if result.Warnings, err = checkStoreRules(table.Name, "update", ruleRows); err != nil {
+			return err
+		}
+		warnings, err := checkChangeRules(table.Name, spec.Column, result.IDs, spec.Value)
+		if err != nil {
+			return err
+		}
+		if len(warnings) > 0 && !s.AllowLargeChanges {
+			return fmt.Errorf("%s %s", strings.TrimSpace(msg("rules.change_title")), warnings[0].describe())
+		}
+
+		_, result.RowsAffected, err = applyUpdate(table, key, spec, result.IDs)
+		return err
+	})
+	return result, err
+}
+
+// Функция для добавления записей одной транзакцией с записью в журнал аудита
+func (s *Store) Insert(ctx context.Context, spec InsertSpec) (InsertResult, error) {
+	var result InsertResult
+	err := s.use(ctx, func() error {
+		table, err := storeTable(spec.Table)
+		if err != nil {
+			return err
+		}
+		records := make([][]string, len(spec.Records))
+		ruleRows := make([]ruleRow, len(spec.Records))
+		for i, values := range spec.Records {
+			if records[i], err = normalizeRecord(table, spec.Columns, values); err != nil {
+				return fmt.Errorf("запись %d: %w", i+1, err)
+			}
+			ruleRows[i] = newRuleRow(spec.Columns, stringArgs(records[i]))
+		}
+		if result.Warnings, err = checkStoreRules(table.Name, "insert", ruleRows); err != nil {
+			return err
+		}
+		return dbTransaction(func(tx *sql.Tx) error {
+			var err error
+			result.IDs, err = insertRecords(tx, table.Name, spec.Columns, records)
+			return err
+		})
+	})
+	return result, err
+}
+
+// Функция для добавления записи в дочернюю таблицу вместе с новой или существующей
+// родительской записью одной транзакцией
+func (s *Store) InsertRelated(ctx context.Context, req RelatedInsert) (RelatedResult, error) {
+	var result RelatedResult
+	err := s.use(ctx, func() error {
+		var relation tableRelation
+		found := false
+		for _, candidate := range relatedTables {
+			if candidate.Parent == req.Parent && candidate.Child == req.Child {
+				relation, found = candidate, true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("связь %s → %s не найдена", req.Parent, req.Child)
+		}
+		parentTable, _ := findTable(relation.Parent)
+		childTable, _ := findTable(relation.Child)
+
+		parent := &productRecord{Table: parentTable, ExistingID: req.ParentID}
+		if req.ParentID == "" {
+			values, err := normalizeRecord(parentTable, req.ParentColumns, req.ParentValues)
+			if err != nil {
+				return fmt.Errorf("%s: %w", parentTable.Name, err)
+			}
+			for i, column := range req.ParentColumns {
+				parent.add(column, values[i])
+			}
+			warnings, err := checkStoreRules(parentTable.Name, "insert", []ruleRow{newRuleRow(parent.Columns, parent.Values)})
+			if err != nil {
+				return err
+			}
+			result.Warnings = append(result.Warnings, warnings...)
+		} else if err := validateTableValue(parentTable, tableKeyColumn(parentTable.Name), req.ParentID); err != nil {
+			return fmt.Errorf("ключ '%s': %w", req.ParentID, err)
+		}
+
+		if containsString(req.ChildColumns, relation.Column) {
+			return fmt.Errorf("колонка '%s' заполняется id родительской записи", relation.Column)
+		}
+		values, err := normalizeRecord(childTable, req.ChildColumns, req.ChildValues)
+		if err != nil {
+			return fmt.Errorf("%s: %w", childTable.Name, err)
+		}
+		child := &productRecord{Table: childTable}
+		for i, column := range req.ChildColumns {
+			child.add(column, values[i])
+		}
+		child.addParent(relation.Column, parent)
+		// Для правил внешний ключ новой родительской записи пока неизвестен (NULL)
+		warnings, err := checkStoreRules(childTable.Name, "insert", []ruleRow{newRuleRow(child.Columns, child.Values)})
+		if err != nil {
+			return err
+		}
+		result.Warnings = append(result.Warnings, warnings...)
+
+		if _, err := insertRecordPlan(productPlan(child, nil), "Вставка в связанные таблицы"); err != nil {
+			return err
+		}
+		result.ParentID, result.ChildID = parent.ExistingID, child.InsertedID
+		if result.ParentID == "" {
+			result.ParentID = parent.InsertedID
+		}
+		return nil
+	})
+	return result, err
+}
@@ -0,0 +1,264 @@
+package osl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Функция для открытия Store на файле SQLite с основными таблицами и правилами из примера
+// конфигурации. Store работает со своим состоянием, глобальное состояние программы не меняется.
+func openTestStore(t *testing.T, statements ...string) (*Store, *sql.DB) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "store.db")
+	check, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { check.Close() })
+	savedDialect := dialect
+	dialect = sqliteDialect{}
+	for _, table := range baseSchema {
+		statements = append([]string{table.createQuery()}, statements...)
+	}
+	dialect = savedDialect
+	for _, statement := range statements {
+		if _, err := check.Exec(statement); err != nil {
+			t.Fatalf("подготовка базы: %v\n%s", err, statement)
+		}
+	}
+
+	t.Setenv("OSL_CONFIG", "config.example.json")
+	store, err := OpenStore(context.Background(), DBConfig{Driver: "sqlite", Name: path})
+	if err != nil {
+		t.Fatalf("OpenStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store, check
+}
+
+// Функция для чтения одного значения из базы Store
+func storeValue(t *testing.T, check *sql.DB, query string, args ...interface{}) string {
+	t.Helper()
+	var value sql.NullString
+	if err := check.QueryRow(query, args...).Scan(&value); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return value.String
+}
+
+// Данные для проверки Store: компонент с ценой и остатком
+var storeSeed = []string{
+	"INSERT INTO categories (name, description) VALUES ('Процессоры', 'CPU')",
+	"INSERT INTO manufacturers (name) VALUES ('Intel'), ('AMD')",
+	"INSERT INTO components (name, category_id, manufacturer_id, model, price) VALUES ('Core i5', 1, 1, '12400F', 15990), ('Ryzen 5', 1, 2, '5600', 12990)",
+	"INSERT INTO stock (component_id, quantity, warehouse_location) VALUES (1, 12, 'A-1')",
+}
+
+// Просмотр и фильтрация возвращают результат без вывода на экран; условия проверяются
+// по типам колонок
+func TestStoreViewFilter(t *testing.T) {
+	store, _ := openTestStore(t, storeSeed...)
+	ctx := context.Background()
+	savedOut := stdout
+
+	rs, err := store.View(ctx, ViewRequest{Table: "components", Columns: []string{"name", "price"}})
+	if err != nil {
+		t.Fatalf("View: %v", err)
+	}
+	if strings.Join(rs.Columns, ",") != "name,price" || len(rs.Rows) != 2 || rs.Rows[0][0] != "Core i5" {
+		t.Errorf("View = %+v", rs)
+	}
+	if _, err := store.View(ctx, ViewRequest{Table: "components", Columns: []string{"color"}}); err == nil {
+		t.Error("View с неизвестной колонкой выполнен")
+	}
+	if _, err := store.View(ctx, ViewRequest{Table: "orders"}); err == nil {
+		t.Error("View неизвестной таблицы выполнен")
+	}
+
+	rs, err = store.Filter(ctx, SavedFilter{Table: "components", Columns: []string{"name"}, Conditions: []SavedCondition{
+		{Column: "price", Type: "range", Values: []string{"10000", "14000"}},
+	}})
+	if err != nil {
+		t.Fatalf("Filter: %v", err)
+	}
+	if len(rs.Rows) != 1 || rs.Rows[0][0] != "Ryzen 5" {
+		t.Errorf("Filter по диапазону = %+v", rs.Rows)
+	}
+	rs, err = store.Filter(ctx, SavedFilter{Table: "components", Operator: "OR", Conditions: []SavedCondition{
+		{Column: "manufacturer_id", Type: "equals", Values: []string{"1"}},
+		{Column: "model", Type: "in", Values: []string{"5600", "7600"}},
+	}})
+	if err != nil || len(rs.Rows) != 2 {
+		t.Errorf("Filter с OR = %+v, %v", rs, err)
+	}
+
+	for _, filter := range []SavedFilter{
+		{Table: "components", Conditions: []SavedCondition{{Column: "price", Type: "range", Values: []string{"дешево", ""}}}},
+		{Table: "components", Conditions: []SavedCondition{{Column: "price", Type: "range", Values: []string{"20000", "10000"}}}},
+		{Table: "components", Conditions: []SavedCondition{{Column: "manufacturer_id", Type: "equals", Values: []string{"?"}}}},
+		{Table: "components", Conditions: []SavedCondition{{Column: "color", Type: "equals", Values: []string{"red"}}}},
+		{Table: "components", Conditions: nil},
+	} {
+		if _, err := store.Filter(ctx, filter); err == nil {
+			t.Errorf("Filter(%+v) выполнен без ошибки", filter.Conditions)
+		}
+	}
+
+	if stdout != savedOut || db != nil && db == store.state.db {
+		t.Error("Store изменил глобальное состояние программы")
+	}
+}
+
+// Обновление по ключам и по условиям с записью в журнал аудита; значение проверяется,
+// подозрительно большое изменение отклоняется без AllowLargeChanges
+func TestStoreUpdate(t *testing.T) {
+	store, check := openTestStore(t, storeSeed...)
+	ctx := context.Background()
+
+	result, err := store.Update(ctx, UpdateRequest{Table: "components", Column: "price", Value: "14990", IDs: []string{"1", "99"}})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if result.RowsAffected != 1 || strings.Join(result.IDs, ",") != "1" {
+		t.Errorf("Update = %+v", result)
+	}
+	if got := storeValue(t, check, "SELECT price FROM components WHERE id = 1"); got != "14990" {
+		t.Errorf("цена после обновления %s", got)
+	}
+	if got := storeValue(t, check, "SELECT COUNT(*) FROM osl_audit WHERE operation = 'update'"); got != "1" {
+		t.Errorf("записей аудита обновления %s", got)
+	}
+
+	result, err = store.Update(ctx, UpdateRequest{Table: "components", Column: "model", Value: "5600X", Conditions: []SavedCondition{
+		{Column: "manufacturer_id", Type: "equals", Values: []string{"2"}},
+	}})
+	if err != nil || result.RowsAffected != 1 || strings.Join(result.IDs, ",") != "2" {
+		t.Fatalf("Update по условию = %+v, %v", result, err)
+	}
+	if got := storeValue(t, check, "SELECT model FROM components WHERE id = 2"); got != "5600X" {
+		t.Errorf("модель после обновления %s", got)
+	}
+
+	for _, req := range []UpdateRequest{
+		{Table: "components", Column: "price", Value: "дорого", IDs: []string{"1"}},
+		{Table: "components", Column: "id", Value: "5", IDs: []string{"1"}},
+		{Table: "components", Column: "color", Value: "red", IDs: []string{"1"}},
+		{Table: "components", Column: "price", Value: "100"},
+		{Table: "components", Column: "price", Value: "100", IDs: []string{"x"}},
+	} {
+		if _, err := store.Update(ctx, req); err == nil {
+			t.Errorf("Update(%+v) выполнен без ошибки", req)
+		}
+	}
+
+	// Снижение цены на 90% отклоняется, с AllowLargeChanges — выполняется
+	req := UpdateRequest{Table: "components", Column: "price", Value: "1499", IDs: []string{"1"}}
+	if _, err := store.Update(ctx, req); err == nil {
+		t.Error("подозрительное изменение выполнено")
+	}
+	store.AllowLargeChanges = true
+	if _, err := store.Update(ctx, req); err != nil {
+		t.Errorf("подозрительное изменение с AllowLargeChanges: %v", err)
+	}
+	if got := storeValue(t, check, "SELECT price FROM components WHERE id = 1"); got != "1499" {
+		t.Errorf("цена %s, ожидалась 1499", got)
+	}
+}
+
+// Добавление записей: значения проверяются, блокирующее правило отклоняет запись,
+// отмененный контекст не выполняет вставку
+func TestStoreInsert(t *testing.T) {
+	store, check := openTestStore(t, storeSeed...)
+	ctx := context.Background()
+
+	result, err := store.Insert(ctx, InsertSpec{Table: "manufacturers", Columns: []string{"name", "founded_year"},
+		Records: [][]string{{"Kingston", "1987"}}})
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if strings.Join(result.IDs, ",") != "3" {
+		t.Errorf("ключ добавленной записи %v", result.IDs)
+	}
+	// Несколько записей добавляются одним запросом (ключи в SQLite при этом не возвращаются)
+	if _, err := store.Insert(ctx, InsertSpec{Table: "manufacturers", Columns: []string{"name"},
+		Records: [][]string{{"Crucial"}, {"Micron"}}}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if got := storeValue(t, check, "SELECT COUNT(*) FROM osl_audit WHERE operation = 'insert'"); got != "3" {
+		t.Errorf("записей аудита вставки %s", got)
+	}
+
+	for _, spec := range []InsertSpec{
+		{Table: "manufacturers", Columns: []string{"name", "founded_year"}, Records: [][]string{{"Samsung", "давно"}}},
+		{Table: "manufacturers", Columns: []string{"id", "name"}, Records: [][]string{{"9", "Samsung"}}},
+		{Table: "manufacturers", Columns: []string{"name"}, Records: [][]string{{"Samsung", "1969"}}},
+		// Остаток компонента без цены запрещен правилом stock_requires_priced_component
+		{Table: "stock", Columns: []string{"component_id", "quantity"}, Records: [][]string{{"99", "1"}}},
+	} {
+		if _, err := store.Insert(ctx, spec); err == nil {
+			t.Errorf("Insert(%+v) выполнен без ошибки", spec)
+		}
+	}
+	if got := storeValue(t, check, "SELECT COUNT(*) FROM manufacturers"); got != "5" {
+		t.Errorf("производителей %s, ожидалось 5", got)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = store.Insert(cancelled, InsertSpec{Table: "manufacturers", Columns: []string{"name"}, Records: [][]string{{"Samsung"}}})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Insert с отмененным контекстом: %v", err)
+	}
+}
+
+// Добавление в связанные таблицы: id новой или существующей родительской записи
+// подставляется во внешний ключ дочерней
+func TestStoreInsertRelated(t *testing.T) {
+	store, check := openTestStore(t, storeSeed...)
+	ctx := context.Background()
+
+	result, err := store.InsertRelated(ctx, RelatedInsert{
+		Parent: "categories", Child: "components",
+		ParentColumns: []string{"name"}, ParentValues: []string{"Память"},
+		ChildColumns: []string{"name", "manufacturer_id", "price"}, ChildValues: []string{"DDR5", "1", "5000"},
+	})
+	if err != nil {
+		t.Fatalf("InsertRelated: %v", err)
+	}
+	if result.ParentID != "2" || result.ChildID != "3" {
+		t.Errorf("InsertRelated = %+v", result)
+	}
+	if got := storeValue(t, check, "SELECT category_id FROM components WHERE id = $1", result.ChildID); got != "2" {
+		t.Errorf("category_id новой записи %s", got)
+	}
+
+	result, err = store.InsertRelated(ctx, RelatedInsert{
+		Parent: "components", Child: "stock", ParentID: "1",
+		ChildColumns: []string{"quantity", "warehouse_location"}, ChildValues: []string{"3", "C-3"},
+	})
+	if err != nil || result.ParentID != "1" {
+		t.Fatalf("InsertRelated с существующей записью = %+v, %v", result, err)
+	}
+	if got := storeValue(t, check, "SELECT component_id FROM stock WHERE warehouse_location = 'C-3'"); got != "1" {
+		t.Errorf("component_id нового остатка %s", got)
+	}
+
+	for _, req := range []RelatedInsert{
+		{Parent: "stock", Child: "categories", ParentColumns: []string{"quantity"}, ParentValues: []string{"1"}},
+		{Parent: "categories", Child: "components", ParentColumns: []string{"name"}, ParentValues: []string{"Диски"},
+			ChildColumns: []string{"name", "category_id"}, ChildValues: []string{"SSD", "1"}},
+		{Parent: "categories", Child: "components", ParentColumns: []string{"name"}, ParentValues: []string{"Диски"},
+			ChildColumns: []string{"name", "price"}, ChildValues: []string{"SSD", "бесплатно"}},
+	} {
+		if _, err := store.InsertRelated(ctx, req); err == nil {
+			t.Errorf("InsertRelated(%+v) выполнен без ошибки", req)
+		}
+	}
+	if got := storeValue(t, check, "SELECT COUNT(*) FROM categories"); got != "2" {
+		t.Errorf("категорий %s, ожидалось 2: ошибка дочерней записи не должна оставлять родительскую", got)
+	}
+}
@@ -0,0 +1,185 @@
+package osl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Источник текущего времени (подменяется в тестах)
+var now = time.Now
+
+// Структура ежедневной сводки
+type DailySummary struct {
+	Date              time.Time
+	TotalComponents   int
+	TotalStockValue   float64
+	LowStockThreshold int
+	LowStockItems     int
+	ChangedYesterday  int // -1, если данных об изменениях нет
+}
+
+// Функция для формирования ежедневной сводки при первом запуске за день
+func runDailySummary() {
+	if !envBool("OSL_DAILY_SUMMARY", true) {
+		return
+	}
+
+	path := stateFilePath()
+	state, err := loadState(path)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Не удалось прочитать файл состояния %s: %v", path, err))
+		return
+	}
+
+	today := now()
+	if state.LastSummaryDate == today.Format("2006-01-02") {
+		return
+	}
+
+	// Сводка не должна заметно задерживать запуск: на медленной базе она пропускается
+	// и будет сформирована при следующем запуске
+	ctx, cancel := context.WithTimeout(context.Background(), envDuration("OSL_DAILY_SUMMARY_TIMEOUT", 500*time.Millisecond))
+	defer cancel()
+
+	summary, err := computeDailySummary(ctx, today)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ежедневная сводка не сформирована: %v", err))
+		return
+	}
+
+	logToFileAndScreen(fmt.Sprintf("Ежедневная сводка за %s: компонентов %d, стоимость остатков %.2f, "+
+		"ниже порога %d: %d, изменено строк вчера: %s",
+		summary.Date.Format("2006-01-02"), summary.TotalComponents, summary.TotalStockValue,
+		summary.LowStockThreshold, summary.LowStockItems, formatChangedRows(summary.ChangedYesterday)))
+
+	if dir := envString("OSL_DAILY_SUMMARY_DIR", ""); dir != "" {
+		filePath, err := writeDailySummary(dir, summary)
+		if err != nil {
+			logToFileAndScreen(fmt.Sprintf("Не удалось записать ежедневную сводку: %v", err))
+		} else {
+			logToFileAndScreen(fmt.Sprintf("Ежедневная сводка записана в %s", filePath))
+		}
+	}
+
+	state.LastSummaryDate = today.Format("2006-01-02")
+	if err := saveState(path, state); err != nil {
+		logToFileAndScreen(fmt.Sprintf("Не удалось сохранить файл состояния %s: %v", path, err))
+	}
+}
+
+// Функция для вычисления показателей ежедневной сводки
+func computeDailySummary(ctx context.Context, day time.Time) (DailySummary, error) {
+	summary := DailySummary{
+		Date:              day,
+		LowStockThreshold: envInt("OSL_LOW_STOCK_THRESHOLD", 10),
+	}
+
+	if err := dbScanRowContext(ctx, "SELECT COUNT(*) FROM components", nil, &summary.TotalComponents); err != nil {
+		return summary, fmt.Errorf("подсчет компонентов: %w", err)
+	}
+
+	err := dbScanRowContext(ctx,
+		`SELECT COALESCE(SUM(s.quantity * c.price), 0)
+		 FROM stock s JOIN components c ON c.id = s.component_id`, nil, &summary.TotalStockValue)
+	if err != nil {
+		return summary, fmt.Errorf("подсчет стоимости остатков: %w", err)
+	}
+
+	err = dbScanRowContext(ctx,
+		`SELECT COUNT(*) FROM (
+		     SELECT c.id FROM components c
+		     LEFT JOIN stock s ON s.component_id = c.id
+		     GROUP BY c.id
+		     HAVING COALESCE(SUM(s.quantity), 0) < $1
+		 ) low`, []interface{}{summary.LowStockThreshold}, &summary.LowStockItems)
+	if err != nil {
+		return summary, fmt.Errorf("подсчет позиций ниже порога: %w", err)
+	}
+
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	summary.ChangedYesterday, err = countChangedRows(ctx, start.AddDate(0, 0, -1), start)
+	if err != nil {
+		return summary, fmt.Errorf("подсчет изменений: %w", err)
+	}
+
+	return summary, nil
+}
+
+// Функция для подсчета строк, измененных в интервале [from, to).
+// Используется журнал аудита osl_audit, а при его отсутствии — колонки updated_at.
+// Возвращает -1, если ни одного источника нет.
+func countChangedRows(ctx context.Context, from, to time.Time) (int, error) {
+	var auditTables int
+	if err := dbScanRowContext(ctx, dialect.TableExistsQuery(), []interface{}{"osl_audit"}, &auditTables); err != nil {
+		return 0, err
+	}
+	if auditTables > 0 {
+		var count int
+		err := dbScanRowContext(ctx,
+			"SELECT COUNT(*) FROM osl_audit WHERE changed_at >= $1 AND changed_at < $2", []interface{}{from, to}, &count)
+		return count, err
+	}
+
+	rows, err := dbQueryContext(ctx, dialect.TablesWithColumnQuery(), "updated_at")
+	if err != nil {
+		return 0, err
+	}
+	var tableNames []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		tableNames = append(tableNames, name)
+	}
+	rows.Close()
+
+	if len(tableNames) == 0 {
+		return -1, nil
+	}
+
+	total := 0
+	for _, name := range tableNames {
+		var count int
+		query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE updated_at >= $1 AND updated_at < $2", tableRef(name))
+		if err := dbScanRowContext(ctx, query, []interface{}{from, to}, &count); err != nil {
+			return 0, err
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// Функция для записи сводки в Markdown-файл с датой в имени
+func writeDailySummary(dir string, summary DailySummary) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	date := summary.Date.Format("2006-01-02")
+	filePath := filepath.Join(dir, fmt.Sprintf("summary-%s.md", date))
+	return filePath, os.WriteFile(filePath, []byte(formatDailySummary(summary)), 0644)
+}
+
+// Функция для форматирования сводки в Markdown
+func formatDailySummary(summary DailySummary) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Сводка за %s\n\n", summary.Date.Format("2006-01-02"))
+	fmt.Fprintf(&b, "- Всего компонентов: %d\n", summary.TotalComponents)
+	fmt.Fprintf(&b, "- Стоимость остатков: %.2f\n", summary.TotalStockValue)
+	fmt.Fprintf(&b, "- Позиций ниже порога (%d): %d\n", summary.LowStockThreshold, summary.LowStockItems)
+	fmt.Fprintf(&b, "- Изменено строк вчера: %s\n", formatChangedRows(summary.ChangedYesterday))
+	return b.String()
+}
+
+// Функция для вывода числа изменений с учетом отсутствия данных
+func formatChangedRows(count int) string {
+	if count < 0 {
+		return "нет данных"
+	}
+	return fmt.Sprintf("%d", count)
+}
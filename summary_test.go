@@ -0,0 +1,72 @@
+package osl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Сводка формируется один раз за день: повторный запуск в тот же день ее пропускает,
+// запуск после полуночи формирует сводку за новый день и считает изменения за прошедший
+func TestDailySummaryDayBoundary(t *testing.T) {
+	openBaseSchema(t,
+		"INSERT INTO categories (id, name) VALUES (1, 'Процессоры')",
+		"INSERT INTO manufacturers (id, name) VALUES (1, 'Intel')",
+		"INSERT INTO components (id, name, category_id, manufacturer_id, price) VALUES (1, 'i5', 1, 1, 100), (2, 'i7', 1, 1, 200)",
+		"INSERT INTO stock (component_id, quantity) VALUES (1, 3), (2, 20)")
+	dir := t.TempDir()
+	statePath := filepath.Join(dir, "state.json")
+	t.Setenv("OSL_STATE_FILE", statePath)
+	t.Setenv("OSL_DAILY_SUMMARY_DIR", dir)
+	savedNow := now
+	t.Cleanup(func() { now = savedNow })
+
+	// Изменения: одно позавчера, два вчера (включая первую и последнюю секунду дня), одно сегодня
+	for _, changedAt := range []time.Time{
+		time.Date(2026, 3, 9, 23, 59, 59, 0, time.UTC),
+		time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 10, 23, 59, 59, 0, time.UTC),
+		time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC),
+	} {
+		mustExec(t, "INSERT INTO osl_audit (changed_at, login, operation, table_name) VALUES ($1, 'test', 'update', 'stock')", changedAt)
+	}
+
+	now = func() time.Time { return time.Date(2026, 3, 10, 23, 59, 59, 0, time.UTC) }
+	runDailySummary()
+	first, err := os.ReadFile(filepath.Join(dir, "summary-2026-03-10.md"))
+	if err != nil {
+		t.Fatalf("сводка за 10 марта не записана: %v", err)
+	}
+	for _, want := range []string{"Всего компонентов: 2", "Стоимость остатков: 4300.00", "Позиций ниже порога (10): 1", "Изменено строк вчера: 1"} {
+		if !strings.Contains(string(first), want) {
+			t.Errorf("в сводке нет %q:\n%s", want, first)
+		}
+	}
+	state, err := loadState(statePath)
+	if err != nil || state.LastSummaryDate != "2026-03-10" {
+		t.Fatalf("состояние после сводки: %+v, %v", state, err)
+	}
+
+	// Повторный запуск в тот же день сводку не формирует
+	os.Remove(filepath.Join(dir, "summary-2026-03-10.md"))
+	runDailySummary()
+	if _, err := os.Stat(filepath.Join(dir, "summary-2026-03-10.md")); err == nil {
+		t.Error("сводка сформирована повторно в тот же день")
+	}
+
+	// Первая секунда следующего дня
+	now = func() time.Time { return time.Date(2026, 3, 11, 0, 0, 1, 0, time.UTC) }
+	runDailySummary()
+	second, err := os.ReadFile(filepath.Join(dir, "summary-2026-03-11.md"))
+	if err != nil {
+		t.Fatalf("сводка за 11 марта не записана: %v", err)
+	}
+	if !strings.Contains(string(second), "Изменено строк вчера: 2") {
+		t.Errorf("изменения за 10 марта:\n%s", second)
+	}
+	if state, _ := loadState(statePath); state.LastSummaryDate != "2026-03-11" {
+		t.Errorf("дата в состоянии %q, ожидалось 2026-03-11", state.LastSummaryDate)
+	}
+}
@@ -0,0 +1,81 @@
+package osl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+)
+
+// Смена базы данных (и схемы PostgreSQL) без перезапуска: подключение выполняется с уже
+// введенными логином и паролем. Новое соединение открывается до закрытия текущего, поэтому
+// при ошибке подключения работа продолжается с прежней базой.
+
+// Функция для получения имени текущей базы для заголовка меню (со схемой, если она выбрана)
+func databaseLabel() string {
+	if activeConfig.SearchPath != "" {
+		return fmt.Sprintf("%s, %s", activeConfig.Name, activeConfig.SearchPath)
+	}
+	return activeConfig.Name
+}
+
+// Функция для проверки имени базы: для SQLite это путь к файлу, для остальных СУБД — идентификатор
+func validateDatabaseName(input string) error {
+	if input == "" {
+		return errors.New(msg("switch_db.name_required"))
+	}
+	if _, ok := dialect.(sqliteDialect); ok {
+		return nil
+	}
+	if !databaseNameRegex.MatchString(input) {
+		return errors.New(msg("switch_db.bad_name"))
+	}
+	return nil
+}
+
+// Пункт 25: Сменить базу данных
+func switchDatabase(reader *bufio.Reader) {
+	fmt.Fprintln(stdout, msg("switch_db.current", databaseLabel()))
+	name, ok := promptValidated(reader, msg("switch_db.name_prompt"), validateDatabaseName)
+	if !ok {
+		return
+	}
+
+	config := activeConfig
+	config.Name = name
+	if _, ok := dialect.(postgresDialect); ok {
+		schema, ok := promptValidated(reader, msg("switch_db.schema_prompt"), func(input string) error {
+			if input != "" && !databaseNameRegex.MatchString(input) {
+				return errors.New(msg("switch_db.bad_schema"))
+			}
+			return nil
+		})
+		if !ok {
+			return
+		}
+		config.SearchPath = schema
+	}
+
+	// Сначала подключение к новой базе: текущее соединение закрывается только после успеха
+	newDB, err := openDatabase(config)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка подключения к базе %s: %v", config.Name, err))
+		printError(msg("switch_db.failed", config.Name))
+		fmt.Fprintln(stdout, msg("switch_db.kept", databaseLabel()))
+		return
+	}
+	applyPoolSettings(newDB, poolSettings())
+
+	previous := databaseLabel()
+	oldDB := db
+	db = newDB
+	activeConfig = config
+	oldDB.Close()
+
+	// Отмена относится к записям прежней базы
+	forgetUndo("выполнена смена базы данных")
+	forgetIndexQueries()
+	loadSchema()
+
+	fmt.Fprintln(stdout, msg("switch_db.done", databaseLabel(), len(tables)))
+	logToFileAndScreen(fmt.Sprintf("Смена базы данных: %s -> %s", previous, databaseLabel()))
+}
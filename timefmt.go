@@ -0,0 +1,100 @@
+package osl
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Вывод и ввод даты и времени в формате TIME_FORMAT (по умолчанию 2006-01-02 15:04:05)
+// и часовом поясе DISPLAY_TZ (по умолчанию — местный пояс процесса, который задает TZ).
+// Значения TIMESTAMPTZ переводятся в этот пояс при выводе, а введенное время считается
+// временем этого пояса. Колонки без часового пояса хранят время как есть и не пересчитываются.
+
+// Формат ввода и вывода даты и времени по умолчанию
+const defaultTimeFormat = "2006-01-02 15:04:05"
+
+// Часовой пояс вывода после первой загрузки
+var displayZone *time.Location
+
+// Функция для получения формата даты и времени (TIME_FORMAT)
+func timeFormat() string {
+	return envString("TIME_FORMAT", defaultTimeFormat)
+}
+
+// Функция для получения часового пояса вывода (DISPLAY_TZ, иначе местный пояс из TZ).
+// Неизвестный пояс записывается в журнал, и используется местный.
+func displayLocation() *time.Location {
+	if displayZone != nil {
+		return displayZone
+	}
+	displayZone = time.Local
+	if name := envString("DISPLAY_TZ", ""); name != "" {
+		location, err := time.LoadLocation(name)
+		if err != nil {
+			logToFileAndScreen(fmt.Sprintf("Ошибка часового пояса DISPLAY_TZ=%s: %v, используется местный пояс", name, err))
+		} else {
+			displayZone = location
+		}
+	}
+	return displayZone
+}
+
+// Функция для вывода даты или времени из БД: дата — без времени, время с часовым поясом —
+// в поясе вывода, время без пояса — как хранится
+func formatTimeValue(value time.Time, dbType string) string {
+	switch strings.ToUpper(dbType) {
+	case "DATE":
+		return value.Format(dateInputLayout)
+	case "TIMESTAMPTZ":
+		return value.In(displayLocation()).Format(timeFormat())
+	}
+	return value.Format(timeFormat())
+}
+
+// Функция для разбора введенной даты или времени колонки: ГГГГ-ММ-ДД, RFC3339 или (для колонок
+// со временем суток) TIME_FORMAT. Время без часового пояса считается временем пояса вывода,
+// одна дата — началом дня. Для колонки DATE из RFC3339 берется дата в поясе вывода.
+func parseTimeInput(table TableInfo, column, value string) (time.Time, error) {
+	if parsed, err := time.ParseInLocation(dateInputLayout, value, displayLocation()); err == nil {
+		if !isTimestampColumn(table, column) {
+			// Дата без времени не зависит от часового пояса
+			return time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+		return parsed, nil
+	}
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		if !isTimestampColumn(table, column) {
+			local := parsed.In(displayLocation())
+			return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+		return parsed, nil
+	}
+	if !isTimestampColumn(table, column) {
+		return time.Time{}, errors.New(msg("validation.bad_date", column, time.Now().Format(dateInputLayout)))
+	}
+	if parsed, err := time.ParseInLocation(timeFormat(), value, displayLocation()); err == nil {
+		return parsed, nil
+	}
+	return time.Time{}, errors.New(msg("validation.bad_time", column, time.Now().In(displayLocation()).Format(timeFormat())))
+}
+
+// Функция для проверки, введена ли одна дата без времени (ГГГГ-ММ-ДД)
+func isDateOnlyInput(value string) bool {
+	_, err := time.Parse(dateInputLayout, value)
+	return err == nil
+}
+
+// Функция для получения параметра запроса из проверенной даты или времени колонки.
+// Время для колонки с часовым поясом передается со смещением пояса ввода, для колонки
+// без пояса — как время пояса вывода.
+func timeParam(table TableInfo, column string, value time.Time) string {
+	switch {
+	case !isTimestampColumn(table, column):
+		return value.Format(dateInputLayout)
+	case strings.EqualFold(table.Types[column], "TIMESTAMPTZ"):
+		return value.Format("2006-01-02 15:04:05.999999-07:00")
+	}
+	return value.In(displayLocation()).Format("2006-01-02 15:04:05.999999")
+}
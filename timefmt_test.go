@@ -0,0 +1,94 @@
+package osl
+
+import (
+	"testing"
+	"time"
+)
+
+// Функция для задания часового пояса вывода на время теста
+func setDisplayZone(t *testing.T, name string) {
+	t.Helper()
+	t.Setenv("DISPLAY_TZ", name)
+	displayZone = nil
+	t.Cleanup(func() { displayZone = nil })
+}
+
+// Введенное время разбирается в поясе и формате вывода и выводится обратно без изменений
+func TestTimeRoundTrip(t *testing.T) {
+	openSchema(t, []string{"events"},
+		"CREATE TABLE events (id INTEGER PRIMARY KEY, local_at TIMESTAMP, zoned_at TIMESTAMPTZ, day DATE)")
+	table := tables[0]
+	setDisplayZone(t, "Europe/Moscow")
+
+	tests := []struct {
+		column, input, param string
+	}{
+		{"local_at", "2024-03-10 15:30:00", "2024-03-10 15:30:00"},
+		{"zoned_at", "2024-03-10 15:30:00", "2024-03-10 15:30:00+03:00"},
+		{"day", "2024-03-10", "2024-03-10"},
+	}
+	for _, tt := range tests {
+		parsed, err := parseTimeInput(table, tt.column, tt.input)
+		if err != nil {
+			t.Fatalf("parseTimeInput(%s, %q): %v", tt.column, tt.input, err)
+		}
+		if got := timeParam(table, tt.column, parsed); got != tt.param {
+			t.Errorf("timeParam(%s) = %q, ожидалось %q", tt.column, got, tt.param)
+		}
+		if got := formatTimeValue(parsed, table.Types[tt.column]); got != tt.input {
+			t.Errorf("formatTimeValue(%s) = %q, ожидалось %q", tt.column, got, tt.input)
+		}
+	}
+
+	// Время с часовым поясом хранится как момент времени и выводится в поясе вывода
+	parsed, _ := parseTimeInput(table, "zoned_at", "2024-03-10 15:30:00")
+	if !parsed.Equal(time.Date(2024, 3, 10, 12, 30, 0, 0, time.UTC)) {
+		t.Errorf("момент времени %v, ожидалось 12:30 UTC", parsed)
+	}
+	parsed, _ = parseTimeInput(table, "zoned_at", "2024-03-10T23:00:00Z")
+	if got := formatTimeValue(parsed, "TIMESTAMPTZ"); got != "2024-03-11 02:00:00" {
+		t.Errorf("RFC3339 в поясе вывода: %q", got)
+	}
+	// Для даты из RFC3339 берется дата в поясе вывода
+	parsed, _ = parseTimeInput(table, "day", "2024-03-10T23:00:00Z")
+	if got := timeParam(table, "day", parsed); got != "2024-03-11" {
+		t.Errorf("дата из RFC3339: %q", got)
+	}
+	// Одна дата в колонке со временем — начало дня
+	parsed, _ = parseTimeInput(table, "local_at", "2024-03-10")
+	if got := formatTimeValue(parsed, "TIMESTAMP"); got != "2024-03-10 00:00:00" {
+		t.Errorf("дата в колонке со временем: %q", got)
+	}
+
+	for _, tt := range []struct{ column, input string }{
+		{"local_at", "2024-13-40 10:00:00"},
+		{"local_at", "10.03.2024 15:30"},
+		{"day", "2024-03-10 15:30:00"},
+		{"day", "вчера"},
+	} {
+		if _, err := parseTimeInput(table, tt.column, tt.input); err == nil {
+			t.Errorf("parseTimeInput(%s, %q) принято", tt.column, tt.input)
+		}
+	}
+}
+
+// Формат TIME_FORMAT используется и для ввода, и для вывода
+func TestCustomTimeFormat(t *testing.T) {
+	openSchema(t, []string{"events"}, "CREATE TABLE events (id INTEGER PRIMARY KEY, zoned_at TIMESTAMPTZ)")
+	setDisplayZone(t, "Asia/Vladivostok")
+	t.Setenv("TIME_FORMAT", "02.01.2006 15:04")
+
+	parsed, err := parseTimeInput(tables[0], "zoned_at", "10.03.2024 09:05")
+	if err != nil {
+		t.Fatalf("parseTimeInput: %v", err)
+	}
+	if !parsed.Equal(time.Date(2024, 3, 9, 23, 5, 0, 0, time.UTC)) {
+		t.Errorf("момент времени %v", parsed)
+	}
+	if got := formatTimeValue(parsed.UTC(), "TIMESTAMPTZ"); got != "10.03.2024 09:05" {
+		t.Errorf("formatTimeValue = %q", got)
+	}
+	if _, err := parseTimeInput(tables[0], "zoned_at", "2024-03-10 09:05:00"); err == nil {
+		t.Error("время не в формате TIME_FORMAT принято")
+	}
+}
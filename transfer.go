@@ -0,0 +1,260 @@
+package osl
+
+import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Перемещение остатка между складами (warehouse_location) одной транзакцией: количество
+// списывается с записи склада-источника и добавляется к записи склада назначения того же
+// компонента; если такой записи нет, она создается. При нехватке остатка откатывается все перемещение.
+
+// Результат перемещения: остатки обоих складов после операции
+type transferResult struct {
+	SourceBefore, SourceAfter int64
+	TargetID                  string
+	TargetBefore, TargetAfter int64
+	TargetCreated             bool
+}
+
+// Пункт 21: Перемещение между складами
+func transferStock(reader *bufio.Reader) {
+	fmt.Fprintln(stdout, msg("transfer.title"))
+	componentID, componentName, rows, ok := pickStockComponent(reader)
+	if !ok {
+		return
+	}
+
+	source, ok := pickStockRow(reader, componentName, rows, msg("transfer.source_prompt"))
+	if !ok {
+		return
+	}
+	fmt.Fprintln(stdout, msg("transfer.source", source.Location.String, source.Quantity))
+	if source.Quantity <= 0 {
+		printError(msg("transfer.source_empty", source.Location.String))
+		return
+	}
+
+	target, ok := promptTransferTarget(reader, rows, source)
+	if !ok {
+		return
+	}
+
+	quantity, ok := promptTransferQuantity(reader, componentID, source.Quantity)
+	if !ok {
+		return
+	}
+
+	if !promptConfirm(reader, msg("transfer.confirm", quantity, componentName, source.Location.String, target)) {
+		fmt.Fprintln(stdout, msg("input.cancelled"))
+		return
+	}
+
+	if dryRun {
+		printDryRun(stockAdjustQuery, []string{"quantity", "component_id", "id"}, []interface{}{-quantity, componentID, source.ID})
+		for _, row := range rows {
+			if row.Location.Valid && row.Location.String == target {
+				printDryRun(stockAdjustQuery, []string{"quantity", "component_id", "id"}, []interface{}{quantity, componentID, row.ID})
+				return
+			}
+		}
+		printDryRun(transferInsertQuery, []string{"component_id", "quantity", "warehouse_location"}, []interface{}{componentID, quantity, target})
+		return
+	}
+
+	var result transferResult
+	err := dbTransaction(func(tx *sql.Tx) error {
+		var err error
+		result, err = executeTransfer(tx, componentID, source.ID, target, quantity)
+		if err != nil {
+			return err
+		}
+		return auditTransfer(tx, componentID, source.ID, target, quantity, result)
+	})
+	switch {
+	case errors.Is(err, errNegativeStock):
+		printError(msg("transfer.insufficient", result.SourceBefore, quantity))
+		logToFileAndScreen(fmt.Sprintf("Перемещение отклонено: компонент '%s' (id=%s), склад '%s', остаток %d, требуется %d",
+			componentName, componentID, source.Location.String, result.SourceBefore, quantity))
+		return
+	case errors.Is(err, sql.ErrNoRows):
+		printError(msg("stock.row_deleted"))
+		return
+	case err != nil:
+		logToFileAndScreen(fmt.Sprintf("Ошибка перемещения компонента %s со склада '%s' на склад '%s': %v",
+			componentID, source.Location.String, target, err))
+		printError(msg("transfer.failed"))
+		return
+	}
+
+	targetStep := undoStep{Table: "stock", IDs: []string{result.TargetID}}
+	if !result.TargetCreated {
+		targetStep = undoStep{
+			Table:    "stock",
+			Column:   "quantity",
+			Previous: map[string]sql.NullString{result.TargetID: {String: strconv.FormatInt(result.TargetBefore, 10), Valid: true}},
+			Delta:    quantity,
+		}
+	}
+	rememberUndo(msg("undo.kind_transfer", componentName), []undoStep{{
+		Table:    "stock",
+		Column:   "quantity",
+		Previous: map[string]sql.NullString{source.ID: {String: strconv.FormatInt(result.SourceBefore, 10), Valid: true}},
+		Delta:    -quantity,
+	}, targetStep})
+
+	fmt.Fprintln(stdout, msg("transfer.done", quantity, componentName))
+	fmt.Fprintln(stdout, msg("transfer.balance", source.Location.String, result.SourceBefore, result.SourceAfter))
+	fmt.Fprintln(stdout, msg("transfer.balance", target, result.TargetBefore, result.TargetAfter))
+	if result.TargetCreated {
+		fmt.Fprintln(stdout, msg("transfer.created", target, result.TargetID))
+	}
+	logToFileAndScreen(fmt.Sprintf("Перемещение: компонент '%s' (id=%s), %d шт. со склада '%s' (%d -> %d) на склад '%s' (%d -> %d, id=%s)",
+		componentName, componentID, quantity, source.Location.String, result.SourceBefore, result.SourceAfter,
+		target, result.TargetBefore, result.TargetAfter, result.TargetID))
+}
+
+// Запросы перемещения
+const (
+	transferTargetQuery = "SELECT id, quantity FROM stock WHERE component_id = $1 AND warehouse_location = $2 ORDER BY id LIMIT 1"
+	transferInsertQuery = "INSERT INTO stock (component_id, quantity, warehouse_location) VALUES ($1, $2, $3)"
+)
+
+// Функция для выполнения перемещения внутри транзакции.
+// Остаток источника проверяется после списания: при отрицательном результате возвращается errNegativeStock.
+func executeTransfer(tx *sql.Tx, componentID, sourceID, target string, quantity int64) (transferResult, error) {
+	var result transferResult
+	var err error
+	if result.SourceAfter, err = updateStockQuantity(tx, componentID, sourceID, -quantity); err != nil {
+		return result, err
+	}
+	result.SourceBefore = result.SourceAfter + quantity
+	if result.SourceAfter < 0 {
+		return result, errNegativeStock
+	}
+
+	query := transferTargetQuery
+	if _, ok := dialect.(sqliteDialect); !ok {
+		query += " FOR UPDATE"
+	}
+	err = txScanRow(tx, query, []interface{}{componentID, target}, &result.TargetID, &result.TargetBefore)
+	switch {
+	case err == nil:
+		result.TargetAfter, err = updateStockQuantity(tx, componentID, result.TargetID, quantity)
+		return result, err
+	case !errors.Is(err, sql.ErrNoRows):
+		return result, err
+	}
+
+	// Записи склада назначения нет: создается новая с тем же component_id
+	ids, err := insertChunk(tx, "stock", "id", transferInsertQuery, []interface{}{componentID, quantity, target}, 1)
+	if err != nil {
+		return result, err
+	}
+	if len(ids) == 0 {
+		return result, errors.New("не удалось получить id новой записи склада")
+	}
+	result.TargetID, result.TargetBefore, result.TargetAfter, result.TargetCreated = ids[0], 0, quantity, true
+	return result, nil
+}
+
+// Функция для записи перемещения в журнал аудита: списание со склада-источника
+// и пополнение или создание записи склада назначения
+func auditTransfer(tx *sql.Tx, componentID, sourceID, target string, quantity int64, result transferResult) error {
+	err := writeAudit(tx, auditUpdate, "stock", []auditRecord{{
+		ID:  sourceID,
+		Old: map[string]interface{}{"quantity": result.SourceBefore},
+		New: map[string]interface{}{"quantity": result.SourceAfter},
+	}})
+	if err != nil {
+		return err
+	}
+	if result.TargetCreated {
+		return writeAudit(tx, auditInsert, "stock", []auditRecord{{
+			ID:  result.TargetID,
+			New: map[string]interface{}{"component_id": componentID, "quantity": quantity, "warehouse_location": target},
+		}})
+	}
+	return writeAudit(tx, auditUpdate, "stock", []auditRecord{{
+		ID:  result.TargetID,
+		Old: map[string]interface{}{"quantity": result.TargetBefore},
+		New: map[string]interface{}{"quantity": result.TargetAfter},
+	}})
+}
+
+// Функция для выбора склада назначения: номер другого склада компонента или название нового склада
+func promptTransferTarget(reader *bufio.Reader, rows []stockRow, source stockRow) (string, bool) {
+	var others []stockRow
+	for _, row := range rows {
+		if row.ID != source.ID && row.Location.Valid && row.Location.String != source.Location.String {
+			others = append(others, row)
+		}
+	}
+	if len(others) > 0 {
+		fmt.Fprintln(stdout, msg("transfer.targets"))
+		for i, row := range others {
+			fmt.Fprintln(stdout, msg("stock.location", i+1, row.Location.String, row.Quantity, row.ID))
+		}
+	}
+
+	parse := func(input string) (string, error) {
+		if n, err := strconv.Atoi(input); err == nil && n >= 1 && n <= len(others) {
+			return others[n-1].Location.String, nil
+		}
+		stock, _ := findTable("stock")
+		if err := checkAllowedChars(stock, "warehouse_location", input); err != nil {
+			return "", err
+		}
+		if strings.EqualFold(input, source.Location.String) {
+			return "", errors.New(msg("transfer.same_location"))
+		}
+		return input, nil
+	}
+	input, ok := promptValidated(reader, msg("transfer.target_prompt"), func(input string) error {
+		_, err := parse(input)
+		return err
+	})
+	if !ok {
+		return "", false
+	}
+	target, _ := parse(input)
+	return target, true
+}
+
+// Функция для ввода перемещаемого количества (в штуках или упаковках); false при отмене
+func promptTransferQuantity(reader *bufio.Reader, componentID string, available int64) (int64, bool) {
+	units, err := unitsPerPackage(componentID)
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка получения размера упаковки компонента %s: %v", componentID, err))
+	}
+	prompt := msg("transfer.quantity_prompt", available)
+	if units > 0 {
+		prompt = msg("transfer.quantity_packages", available, units, packageSuffix)
+	}
+
+	input, ok := promptValidated(reader, prompt, func(input string) error {
+		quantity, _, err := parseQuantity(input, units)
+		if err != nil {
+			return err
+		}
+		if quantity <= 0 {
+			return errors.New(msg("transfer.quantity_positive"))
+		}
+		if int64(quantity) > available {
+			return errors.New(msg("transfer.quantity_too_large", available))
+		}
+		return nil
+	})
+	if !ok {
+		return 0, false
+	}
+	quantity, packages, _ := parseQuantity(input, units)
+	if packages != 0 {
+		fmt.Fprintln(stdout, msg("stock.packages", packages, packageSuffix, units, quantity))
+	}
+	return int64(quantity), true
+}
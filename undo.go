@@ -0,0 +1,214 @@
+package osl
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Отмена последней изменяющей операции (один уровень, только в памяти на время сеанса).
+// Для обновления перед UPDATE в той же транзакции запоминаются прежние значения колонки,
+// для добавления — id новых записей. Изменения, которые отменить нельзя (SQL-запрос, импорт),
+// сбрасывают сохраненную отмену, чтобы не откатить более раннюю операцию вместо последней.
+
+// Шаг отмены: восстановление прежних значений колонки или удаление добавленных записей
+type undoStep struct {
+	Table    string
+	Column   string                    // восстанавливаемая колонка; пусто — удаление записей IDs
+	Previous map[string]sql.NullString // id -> прежнее значение колонки
+	IDs      []string                  // id добавленных записей
+	Delta    int64                     // изменение числовой колонки; отменяется вычитанием, а не записью прежнего значения
+}
+
+// Последняя операция, которую можно отменить
+type undoEntry struct {
+	Kind  string
+	At    time.Time
+	Steps []undoStep // отменяются в обратном порядке
+}
+
+// Сохраненная отмена (nil — отменять нечего)
+var lastUndo *undoEntry
+
+// Функция для сохранения отмены последней операции
+func rememberUndo(kind string, steps []undoStep) {
+	if len(steps) == 0 {
+		lastUndo = nil
+		return
+	}
+	lastUndo = &undoEntry{Kind: kind, At: time.Now(), Steps: steps}
+}
+
+// Функция для сброса отмены после изменения, которое отменить нельзя
+func forgetUndo(reason string) {
+	if lastUndo != nil {
+		logToFileAndScreen(fmt.Sprintf("Отмена операции больше недоступна: %s", reason))
+	}
+	lastUndo = nil
+}
+
+// Функция для получения прежних значений колонки в записях, удовлетворяющих условию.
+// Записи блокируются до конца транзакции, чтобы их не изменили между чтением и обновлением.
+func captureUndoValues(tx *sql.Tx, table, column, where string, args []interface{}) (undoStep, error) {
+	key, err := tableKeyRef(table)
+	if err != nil {
+		return undoStep{}, err
+	}
+	query := fmt.Sprintf("SELECT %s, %s FROM %s WHERE %s", key, dialect.QuoteIdent(column), tableRef(table), where)
+	if _, ok := dialect.(sqliteDialect); !ok {
+		query += " FOR UPDATE"
+	}
+	boundQuery, boundArgs := rebind(query, args)
+	rows, err := tx.Query(boundQuery, boundArgs...)
+	if err != nil {
+		return undoStep{}, err
+	}
+	defer rows.Close()
+
+	step := undoStep{Table: table, Column: column, Previous: make(map[string]sql.NullString)}
+	for rows.Next() {
+		var id string
+		var value sql.NullString
+		if err := rows.Scan(&id, &value); err != nil {
+			return undoStep{}, err
+		}
+		step.Previous[id] = value
+	}
+	return step, rows.Err()
+}
+
+// Функция для описания шага отмены
+func (s undoStep) describe() string {
+	if s.Column != "" && s.Delta != 0 {
+		return msg("undo.delta_step", s.Table, s.Column, -s.Delta, len(s.Previous))
+	}
+	if s.Column != "" {
+		return msg("undo.restore_step", s.Table, s.Column, len(s.Previous))
+	}
+	return msg("undo.delete_step", s.Table, len(s.IDs))
+}
+
+// Пункт 17: Отменить последнюю операцию
+func undoLastOperation(reader *bufio.Reader) {
+	if lastUndo == nil {
+		fmt.Fprintln(stdout, msg("undo.nothing"))
+		return
+	}
+	entry := lastUndo
+
+	fmt.Fprintln(stdout, msg("undo.title"))
+	fmt.Fprintln(stdout, msg("undo.operation", entry.At.Format("15:04:05"), entry.Kind))
+	for i := len(entry.Steps) - 1; i >= 0; i-- {
+		fmt.Fprintln(stdout, "  " + entry.Steps[i].describe())
+	}
+	if !promptConfirm(reader, msg("undo.confirm")) {
+		fmt.Fprintln(stdout, msg("undo.cancelled"))
+		return
+	}
+
+	if dryRun {
+		for i := len(entry.Steps) - 1; i >= 0; i-- {
+			for _, statement := range entry.Steps[i].statements() {
+				printDryRun(statement.Query, nil, statement.Args)
+			}
+		}
+		return
+	}
+
+	affected, expected := int64(0), int64(0)
+	err := dbTransaction(func(tx *sql.Tx) error {
+		affected, expected = 0, 0
+		for i := len(entry.Steps) - 1; i >= 0; i-- {
+			err := auditedUndoStep(tx, entry.Steps[i], func() error {
+				for _, statement := range entry.Steps[i].statements() {
+					logToFileAndScreen(fmt.Sprintf("Отмена операции: %s с параметрами %v", statement.Query, statement.Args))
+					result, err := txExec(tx, statement.Query, statement.Args...)
+					if err != nil {
+						return err
+					}
+					n, _ := result.RowsAffected()
+					affected += n
+					expected += statement.Rows
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logToFileAndScreen(fmt.Sprintf("Ошибка отмены операции (%s): %v", entry.Kind, err))
+		printError(msg("undo.failed"))
+		return
+	}
+
+	lastUndo = nil
+	fmt.Fprintln(stdout, msg("undo.done", affected))
+	if affected < expected {
+		fmt.Fprintln(stdout, msg("undo.partial", expected-affected))
+	}
+	logToFileAndScreen(fmt.Sprintf("Отменена операция %s от %s: затронуто записей %d из %d",
+		entry.Kind, entry.At.Format("15:04:05"), affected, expected))
+}
+
+// Запрос отмены и количество записей, которые он должен затронуть
+type undoStatement struct {
+	Query string
+	Args  []interface{}
+	Rows  int64
+}
+
+// Функция для построения запросов отмены шага
+func (s undoStep) statements() []undoStatement {
+	key := tableKeyColumn(s.Table)
+	if s.Column == "" {
+		where, args := inListCondition(key, s.IDs, 1)
+		return []undoStatement{{Query: fmt.Sprintf("DELETE FROM %s WHERE %s", tableRef(s.Table), where), Args: args, Rows: int64(len(s.IDs))}}
+	}
+
+	statements := make([]undoStatement, 0, len(s.Previous))
+	for _, id := range sortedUndoIDs(s.Previous) {
+		if s.Delta != 0 {
+			// Изменение на величину отменяется так же, чтобы не затереть параллельные изменения
+			statements = append(statements, undoStatement{
+				Query: fmt.Sprintf("UPDATE %s SET %[2]s = %[2]s - $1 WHERE %[3]s = $2", tableRef(s.Table), dialect.QuoteIdent(s.Column), dialect.QuoteIdent(key)),
+				Args:  []interface{}{s.Delta, id},
+				Rows:  1,
+			})
+			continue
+		}
+		var value interface{}
+		if previous := s.Previous[id]; previous.Valid {
+			value = previous.String
+		}
+		statements = append(statements, undoStatement{
+			Query: fmt.Sprintf("UPDATE %s SET %s = $1 WHERE %s = $2", tableRef(s.Table), dialect.QuoteIdent(s.Column), dialect.QuoteIdent(key)),
+			Args:  []interface{}{value, id},
+			Rows:  1,
+		})
+	}
+	return statements
+}
+
+// Функция для получения id записей по возрастанию (числовые id сравниваются как числа)
+func sortedUndoIDs(values map[string]sql.NullString) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return idLess(keys[i], keys[j]) })
+	return keys
+}
+
+// Функция для сравнения id: сначала по длине, затем по строке (для чисел это числовой порядок)
+func idLess(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return strings.Compare(a, b) < 0
+}
@@ -0,0 +1,292 @@
+package osl
+
+import (
+	"errors"
+	"log"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Все значения передаются в запросы только как параметры ($1, $2, ...), поэтому проверка
+// символов — дополнительная защита, а не защита от SQL-инъекций. По умолчанию запрещены
+// только управляющие символы, поэтому текст вида "2 шт.; в коробке" или "A--B" допустим.
+// OSL_STRICT_WHITELIST=1 возвращает прежний строгий white list и дополнительно запрещает
+// управляющие последовательности SQL. Разрешенный набор символов можно сузить шаблоном:
+// INPUT_WHITELIST для всех колонок или OSL_WHITELIST_TEXT / OSL_WHITELIST_NUMERIC по типу колонки.
+
+// Управляющие последовательности SQL, запрещенные в строгом режиме
+var sqlControlSequences = []string{";", "--", "/*", "*/"}
+
+// Скомпилированные пользовательские шаблоны по исходному тексту
+var customPatterns = map[string]*regexp.Regexp{}
+
+// Функция для проверки допустимости символов значения колонки
+func checkAllowedChars(table TableInfo, column, value string) error {
+	if value == "" {
+		return errors.New(msg("validation.empty"))
+	}
+
+	if envBool("OSL_STRICT_WHITELIST", false) {
+		if !whiteListRegex.MatchString(value) {
+			return errors.New(msg("validation.bad_chars"))
+		}
+		for _, sequence := range sqlControlSequences {
+			if strings.Contains(value, sequence) {
+				return errors.New(msg("validation.bad_sequence", sequence))
+			}
+		}
+		return nil
+	}
+
+	for _, r := range value {
+		if unicode.IsControl(r) {
+			return errors.New(msg("validation.control_char", r))
+		}
+	}
+
+	if pattern := valuePattern(table, column); pattern != nil && !pattern.MatchString(value) {
+		return errors.New(msg("validation.bad_chars"))
+	}
+	return nil
+}
+
+// Функция для выбора пользовательского шаблона по типу колонки (nil — шаблон не задан)
+func valuePattern(table TableInfo, column string) *regexp.Regexp {
+	name := "OSL_WHITELIST_TEXT"
+	if isNumericColumn(table, column) {
+		name = "OSL_WHITELIST_NUMERIC"
+	}
+	if source := envString(name, ""); source != "" {
+		return compilePattern(name, source)
+	}
+	return compilePattern("INPUT_WHITELIST", envString("INPUT_WHITELIST", ""))
+}
+
+// Функция для компиляции пользовательского шаблона; некорректный шаблон заменяется строгим
+func compilePattern(name, source string) *regexp.Regexp {
+	if source == "" {
+		return nil
+	}
+	if pattern, ok := customPatterns[source]; ok {
+		recordCacheAccess("validation_patterns", true)
+		return pattern
+	}
+	recordCacheAccess("validation_patterns", false)
+	pattern, err := regexp.Compile(source)
+	if err != nil {
+		log.Printf("Некорректный шаблон %s=%q: %v, используется строгий white list", name, source, err)
+		pattern = whiteListRegex
+	}
+	customPatterns[source] = pattern
+	return pattern
+}
+
+// Допустимый диапазон значений колонки сверх ограничений ее типа
+type columnRange struct {
+	Min, Max       float64
+	MaxCurrentYear bool // верхняя граница — текущий год
+}
+
+// Диапазоны значений колонок: "таблица.колонка" -> диапазон
+var columnRanges = map[string]columnRange{
+	"manufacturers.founded_year": {Min: 1800, MaxCurrentYear: true},
+	"stock.quantity":             {Min: 0, Max: math.Inf(1)},
+	"components.price":           {Min: 0, Max: math.Inf(1)},
+}
+
+// Диапазоны целочисленных типов (имена типов драйвера); BIGINT проверяется разбором числа
+var integerTypeRanges = map[string][2]float64{
+	"INT2":      {math.MinInt16, math.MaxInt16},
+	"SMALLINT":  {math.MinInt16, math.MaxInt16},
+	"INT4":      {math.MinInt32, math.MaxInt32},
+	"INT":       {math.MinInt32, math.MaxInt32},
+	"INTEGER":   {math.MinInt32, math.MaxInt32},
+	"MEDIUMINT": {-1 << 23, 1<<23 - 1},
+	"TINYINT":   {math.MinInt8, math.MaxInt8},
+	"INT8":      {math.MinInt64, math.MaxInt64},
+	"BIGINT":    {math.MinInt64, math.MaxInt64},
+}
+
+// Шаблон целого числа (диапазон типа проверяется отдельно)
+var integerRegex = regexp.MustCompile(`^[+-]?\d+$`)
+
+// Шаблон десятичного числа для проверки точности
+var decimalRegex = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+
+// Функция для получения длины или точности из имени типа вида VARCHAR(100) или NUMERIC(5,2)
+func typeLimits(columnType string) (maxLength, precision, scale int) {
+	upper := strings.ToUpper(columnType)
+	start, end := strings.Index(upper, "("), strings.Index(upper, ")")
+	if start < 0 || end <= start {
+		return 0, 0, 0
+	}
+	parts := strings.Split(upper[start+1:end], ",")
+	first, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, 0
+	}
+	switch strings.TrimSpace(upper[:start]) {
+	case "NUMERIC", "DECIMAL":
+		precision = first
+		if len(parts) > 1 {
+			scale, _ = strconv.Atoi(strings.TrimSpace(parts[1]))
+		}
+	case "VARCHAR", "CHAR", "CHARACTER", "CHARACTER VARYING", "NVARCHAR", "NCHAR":
+		maxLength = first
+	}
+	return maxLength, precision, scale
+}
+
+// Функция для проверки значения колонки таблицы: символы и формат (validateColumnValue),
+// длина строки, диапазон целочисленного типа, точность десятичного числа и диапазон из columnRanges.
+// Используется при добавлении, обновлении и импорте записей.
+func validateTableValue(table TableInfo, column, value string) error {
+	// Дата и время проверяются разбором: двоеточие и смещение пояса не проходят проверку символов
+	if isDateColumn(table, column) {
+		_, err := parseTimeInput(table, column, value)
+		return err
+	}
+	if err := validateColumnValue(table, column, value); err != nil {
+		return err
+	}
+
+	if isBooleanColumn(table, column) {
+		if _, ok := parseBoolValue(value); !ok {
+			return errors.New(msg("validation.not_bool", column))
+		}
+		return nil
+	}
+
+	info := columnDetails(table, column)
+	if info.MaxLength > 0 {
+		if length := utf8.RuneCountInString(value); length > info.MaxLength {
+			return errors.New(msg("validation.too_long", info.MaxLength, length))
+		}
+	}
+
+	// В SQLite INTEGER — 64-битное число, беззнаковые типы MySQL имеют другой диапазон
+	bounds, isInteger := integerTypeRanges[strings.ToUpper(table.Types[column])]
+	if _, ok := dialect.(sqliteDialect); ok || strings.Contains(strings.ToLower(info.Type), "unsigned") {
+		isInteger = false
+	}
+	limits, hasRange := columnRanges[table.Name+"."+column]
+	if !isInteger && info.Precision == 0 && !hasRange {
+		return nil
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		return errors.New(msg("input.not_number", column))
+	}
+	if isInteger {
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil || number < bounds[0] || number > bounds[1] {
+			return errors.New(msg("validation.out_of_range", formatLimit(bounds[0]), formatLimit(bounds[1])))
+		}
+	}
+	if info.Precision > 0 {
+		if err := checkPrecision(column, value, info.Precision, info.Scale); err != nil {
+			return err
+		}
+	}
+	if hasRange {
+		if limits.MaxCurrentYear {
+			limits.Max = float64(time.Now().Year())
+		}
+		if number < limits.Min || number > limits.Max {
+			if math.IsInf(limits.Max, 1) {
+				return errors.New(msg("validation.below_min", formatLimit(limits.Min), value))
+			}
+			return errors.New(msg("validation.out_of_range", formatLimit(limits.Min), formatLimit(limits.Max)))
+		}
+	}
+	return nil
+}
+
+// Функция для проверки количества цифр десятичного числа до и после запятой
+func checkPrecision(column, value string, precision, scale int) error {
+	if !decimalRegex.MatchString(value) {
+		return errors.New(msg("input.not_number", column))
+	}
+	integer, fraction, _ := strings.Cut(strings.TrimLeft(value, "+-"), ".")
+	if len(fraction) > scale {
+		return errors.New(msg("validation.scale", scale, len(fraction)))
+	}
+	if digits := len(strings.TrimLeft(integer, "0")); digits > precision-scale {
+		return errors.New(msg("validation.precision", precision-scale, digits))
+	}
+	return nil
+}
+
+// Функция для вывода границы диапазона без лишних нулей
+func formatLimit(limit float64) string {
+	return strconv.FormatFloat(limit, 'f', -1, 64)
+}
+
+// Логические типы колонок (имена типов драйвера)
+var booleanTypes = map[string]bool{"BOOL": true, "BOOLEAN": true}
+
+// Функция для получения структуры колонки из каталога (пустая структура — колонка неизвестна)
+func columnDetails(table TableInfo, column string) ColumnInfo {
+	for _, detail := range table.Details {
+		if detail.Name == column {
+			return detail
+		}
+	}
+	return ColumnInfo{}
+}
+
+// Функция для проверки, что колонка логическая: BOOLEAN или TINYINT(1) в MySQL
+func isBooleanColumn(table TableInfo, column string) bool {
+	if booleanTypes[strings.ToUpper(table.Types[column])] {
+		return true
+	}
+	if _, ok := dialect.(mysqlDialect); ok {
+		return strings.EqualFold(columnDetails(table, column).Type, "tinyint(1)")
+	}
+	return false
+}
+
+// Функция для разбора логического значения: true/false, 1/0, да/нет, yes/no, а также t/f,
+// в которых PostgreSQL возвращает значения в текстовом виде
+func parseBoolValue(input string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "true", "t", "1", "да", "yes":
+		return true, true
+	case "false", "f", "0", "нет", "no":
+		return false, true
+	}
+	return false, false
+}
+
+// Функция для получения параметра запроса для логического значения:
+// SQLite и MySQL хранят логические значения числами 1/0
+func boolParam(value bool) string {
+	if _, ok := dialect.(postgresDialect); ok {
+		return strconv.FormatBool(value)
+	}
+	if value {
+		return "1"
+	}
+	return "0"
+}
+
+// Функция для приведения проверенного значения к виду, который передается в запрос
+// (логические значения в любой допустимой форме — к true/false или 1/0)
+func normalizeTableValue(table TableInfo, column, value string) string {
+	if isBooleanColumn(table, column) {
+		if b, ok := parseBoolValue(value); ok {
+			return boolParam(b)
+		}
+	}
+	if isDateColumn(table, column) {
+		if t, err := parseTimeInput(table, column, value); err == nil {
+			return timeParam(table, column, t)
+		}
+	}
+	return value
+}
@@ -0,0 +1,48 @@
+package osl
+
+import "testing"
+
+// Числовой формат значения определяется типом колонки, а не ее именем
+func TestValidateValueByColumnType(t *testing.T) {
+	openSchema(t, []string{"items"},
+		`CREATE TABLE items (id INTEGER PRIMARY KEY, price NUMERIC(10, 2), weight REAL,
+			quantity TEXT, code VARCHAR(10), rank INTEGER)`)
+	table := tables[0]
+
+	tests := []struct {
+		column, value string
+		ok            bool
+	}{
+		{"price", "12.50", true},
+		{"price", "12", true},
+		{"price", "-0.5", true},
+		{"price", "12.505", false},
+		{"price", "12,50", false},
+		{"price", "abc", false},
+		{"weight", "1.5e3", true},
+		{"weight", "NaN", false},
+		{"rank", "7", true},
+		{"rank", "+7", true},
+		{"rank", "7.5", false},
+		{"rank", "1e3", false},
+		{"id", "abc", false},
+		// Имя колонки не делает ее числовой
+		{"quantity", "много", true},
+		{"code", "A-12", true},
+	}
+	for _, tt := range tests {
+		err := validateTableValue(table, tt.column, tt.value)
+		if (err == nil) != tt.ok {
+			t.Errorf("validateTableValue(%s, %q) = %v, ожидалось допустимо=%v", tt.column, tt.value, err, tt.ok)
+		}
+	}
+
+	for column, want := range map[string]bool{"id": true, "price": true, "weight": true, "rank": true, "quantity": false, "code": false} {
+		if got := isNumericColumn(table, column); got != want {
+			t.Errorf("isNumericColumn(%s) = %v, ожидалось %v", column, got, want)
+		}
+		if got := isTextColumn(table, column); got == want {
+			t.Errorf("isTextColumn(%s) = %v", column, got)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package osl
+
+import (
+	"database/sql"
+	"testing"
+)
+
+// Режимы проверки символов: нестрогий по умолчанию, строгий white list и шаблоны по типу колонки
+func TestWhitelistModes(t *testing.T) {
+	openSchema(t, []string{"parts"},
+		"CREATE TABLE parts (id INTEGER PRIMARY KEY, name TEXT, price NUMERIC(10, 2))")
+	table := tables[0]
+
+	type check struct {
+		column, value string
+		ok            bool
+	}
+	tests := []struct {
+		mode   string
+		env    map[string]string
+		checks []check
+	}{
+		{"по умолчанию", nil, []check{
+			{"name", "Kingston/HyperX", true},
+			{"name", "DDR4 (16GB)", true},
+			{"name", "e-mail@vendor.com", true},
+			{"name", "O'Neil", true},
+			{"name", "Кулер, тихий", true},
+			// Значения передаются параметрами, поэтому последовательности SQL в тексте допустимы
+			{"name", "2 pcs; boxed", true},
+			{"name", "A--B", true},
+			{"name", "/* x */", true},
+			{"name", "строка\nвторая", false},
+			{"name", "табуляция\t", false},
+			{"name", "", false},
+		}},
+		{"строгий", map[string]string{"OSL_STRICT_WHITELIST": "1"}, []check{
+			{"name", "Кулер DDR4-3200.v2", true},
+			{"name", "Kingston/HyperX", false},
+			{"name", "DDR4 (16GB)", false},
+			{"name", "O'Neil", false},
+			{"name", "A--B", false},
+			{"price", "12.50", true},
+		}},
+		{"строгий для чисел, свободный текст", map[string]string{"OSL_WHITELIST_NUMERIC": `^[0-9.]+$`}, []check{
+			{"price", "12.50", true},
+			{"price", "-12.50", false},
+			{"price", "1e3", false},
+			{"name", "Kingston/HyperX", true},
+		}},
+		{"шаблон для текста", map[string]string{"OSL_WHITELIST_TEXT": `^[A-Za-z ]+$`}, []check{
+			{"name", "Kingston HyperX", true},
+			{"name", "Кулер", false},
+			{"price", "12.50", true},
+		}},
+		// Управляющие символы запрещены и при разрешающем шаблоне
+		{"разрешающий шаблон", map[string]string{"OSL_WHITELIST_TEXT": `.*`}, []check{
+			{"name", "a;b", true},
+			{"name", "a\x00b", false},
+		}},
+		// Некорректный шаблон заменяется строгим white list
+		{"некорректный шаблон", map[string]string{"OSL_WHITELIST_TEXT": `[a-`}, []check{
+			{"name", "Кулер DDR4", true},
+			{"name", "Kingston/HyperX", false},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			for _, c := range tt.checks {
+				err := checkAllowedChars(table, c.column, c.value)
+				if (err == nil) != c.ok {
+					t.Errorf("checkAllowedChars(%s, %q) = %v, ожидалось допустимо=%v", c.column, c.value, err, c.ok)
+				}
+			}
+		})
+	}
+}
+
+// Адреса электронной почты, текст с запятыми и модели с косой чертой принимаются и сохраняются
+// как есть; шаблон INPUT_WHITELIST действует для всех колонок
+func TestWhitelistEmailsAndCommas(t *testing.T) {
+	openSchema(t, []string{"vendors"},
+		"CREATE TABLE vendors (id INTEGER PRIMARY KEY, email TEXT, location TEXT, model TEXT)")
+	table := tables[0]
+
+	values := []string{"a@b.com", "Москва, ул. Ленина, 1", "RTX 4070/Ti"}
+	for i, column := range []string{"email", "location", "model"} {
+		if err := validateTableValue(table, column, values[i]); err != nil {
+			t.Errorf("validateTableValue(%s, %q): %v", column, values[i], err)
+		}
+	}
+	err := dbTransaction(func(tx *sql.Tx) error {
+		_, err := insertRecords(tx, "vendors", []string{"email", "location", "model"}, [][]string{values})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("insertRecords: %v", err)
+	}
+	if got := queryString(t, "SELECT location FROM vendors"); got != values[1] {
+		t.Errorf("сохранено %q, ожидалось %q", got, values[1])
+	}
+
+	t.Setenv("INPUT_WHITELIST", `^[\p{L}\d.@, ]+$`)
+	for _, tt := range []struct {
+		value string
+		ok    bool
+	}{
+		{"a@b.com", true},
+		{"first.last@vendor.ru", true},
+		{"Москва, ул. Ленина", true},
+		{"RTX 4070/Ti", false},
+		{"a@b.com; b@c.com", false},
+	} {
+		err := validateTableValue(table, "email", tt.value)
+		if (err == nil) != tt.ok {
+			t.Errorf("INPUT_WHITELIST: validateTableValue(%q) = %v, ожидалось допустимо=%v", tt.value, err, tt.ok)
+		}
+	}
+}